@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/url"
+	"time"
+
+	"github.com/charmbracelet/crush/api/models"
+)
+
+// AuditOptions 审计日志的筛选条件，零值表示不筛选
+type AuditOptions struct {
+	// SessionID 只返回该会话的条目
+	SessionID string
+	// Kind 条目类型：tool 或 permission
+	Kind string
+	// Tool 工具名称
+	Tool string
+	// Decider 权限的决定方，如 tui、auto 或 api_key:<名称>
+	Decider string
+	// From 开始日期（UTC，包含），只使用日期部分
+	From time.Time
+	// To 结束日期（UTC，包含），只使用日期部分
+	To time.Time
+}
+
+// query 在 q 中设置筛选参数
+func (o AuditOptions) query(q url.Values) url.Values {
+	for name, value := range map[string]string{
+		"sessionID": o.SessionID,
+		"kind":      o.Kind,
+		"tool":      o.Tool,
+		"decider":   o.Decider,
+	} {
+		if value != "" {
+			q.Set(name, value)
+		}
+	}
+	if !o.From.IsZero() {
+		q.Set("from", o.From.UTC().Format(time.DateOnly))
+	}
+	if !o.To.IsZero() {
+		q.Set("to", o.To.UTC().Format(time.DateOnly))
+	}
+	return q
+}
+
+// ListAudit 获取一页审计日志，按时间倒序，Total 为符合条件的条目总数
+// 未指定 Limit 时服务器返回 100 条
+func (p *Project) ListAudit(ctx context.Context, opts AuditOptions, page ListOptions) (*models.AuditResponse, error) {
+	var out models.AuditResponse
+	if err := p.get(ctx, "/audit", page.query(opts.query(p.query())), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Audit 按页遍历符合条件的审计日志，pageSize 为 0 时使用 DefaultPageSize
+// 出错时返回错误并结束遍历
+func (p *Project) Audit(ctx context.Context, opts AuditOptions, pageSize int) iter.Seq2[models.AuditEntry, error] {
+	return paginate(ctx, pageSize, func(ctx context.Context, page ListOptions) ([]models.AuditEntry, int, error) {
+		out, err := p.ListAudit(ctx, opts, page)
+		if err != nil {
+			return nil, 0, err
+		}
+		return out.Entries, out.Total, nil
+	})
+}
@@ -0,0 +1,364 @@
+// Package client 是 zorkagent HTTP API 的 Go 客户端
+//
+// 全局接口（项目注册、健康检查、指标等）直接在 Client 上调用，项目内的接口通过
+// Client.Project 获取的 Project 调用，请求会自动带上 directory 参数：
+//
+//	c, err := client.New("http://localhost:8080", client.WithToken(token))
+//	if err != nil {
+//		return err
+//	}
+//	p := c.Project("/path/to/project")
+//	created, err := p.CreateSession(ctx, models.CreateSessionRequest{Title: "bot"})
+//	...
+//	for ev, err := range p.Events(ctx, "") {
+//		...
+//	}
+//
+// 请求和响应直接使用 api/models 中的类型。服务器返回的错误为 *APIError。
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy 重试策略
+//
+// 429 响应（限流或运行数已满，请求未被处理）对所有方法都会重试；网络错误、408 和
+// 502/503/504 只对 GET、PUT、DELETE 等幂等请求重试。响应带 Retry-After 时按其等待，
+// 否则按指数退避加随机抖动等待。
+type RetryPolicy struct {
+	// MaxRetries 最大重试次数，0 表示不重试
+	MaxRetries int
+	// MinBackoff 首次重试前的等待时间
+	MinBackoff time.Duration
+	// MaxBackoff 单次等待的上限，同样限制 Retry-After
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy 默认重试策略
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	MinBackoff: 500 * time.Millisecond,
+	MaxBackoff: 30 * time.Second,
+}
+
+// backoff 返回第 attempt 次重试（从 0 开始）前的等待时间
+func (p RetryPolicy) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return min(retryAfter, p.MaxBackoff)
+	}
+	d := p.MinBackoff << min(attempt, 16)
+	if d <= 0 || d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	// 在 [d/2, d) 之间随机，避免多个客户端同时重试
+	half := d / 2
+	if half <= 0 {
+		return d
+	}
+	return half + rand.N(half)
+}
+
+// Client zorkagent API 客户端，可在多个 goroutine 中并发使用
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	token      string
+	apiKey     string
+	userAgent  string
+	retry      RetryPolicy
+}
+
+// Option 客户端选项
+type Option func(*Client)
+
+// WithHTTPClient 使用自定义的 http.Client
+// 同步 prompt 和事件流可能持续很长时间，不要设置过短的 Timeout，应通过 context 控制超时
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithToken 使用静态 Bearer Token 认证
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithAPIKey 使用 API Key 认证（通过 serve keys create 创建）
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithUserAgent 设置 User-Agent 请求头
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+// WithRetry 设置重试策略
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// New 创建客户端，baseURL 为服务器地址，例如 http://localhost:8080
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid base url %q: scheme must be http or https", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: &http.Client{},
+		userAgent:  "zorkagent-go-client",
+		retry:      DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// APIError 服务器返回的错误响应
+type APIError struct {
+	// StatusCode HTTP 状态码
+	StatusCode int
+	// Code 错误码，例如 PROJECT_NOT_FOUND、TOO_MANY_RUNS
+	Code string
+	// Message 错误信息
+	Message string
+	// Details 附加信息
+	Details map[string]any
+	// RetryAfter 服务器要求的重试等待时间，来自 Retry-After 响应头
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("zorkagent: %d %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("zorkagent: %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// IsNotFound 判断错误是否为 404
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// request 一次 API 调用
+type request struct {
+	method string
+	// path 已转义的路径，例如 /session/abc
+	path  string
+	query url.Values
+	body  any
+	// header 额外的请求头
+	header http.Header
+}
+
+// do 发送请求并将 JSON 响应解码到 out，out 为 nil 时丢弃响应
+func (c *Client) do(ctx context.Context, req request, out any) error {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s %s response: %w", req.method, req.path, err)
+	}
+	return nil
+}
+
+// send 发送请求并按重试策略重试，返回状态码为 2xx 的响应，调用方负责关闭 Body
+func (c *Client) send(ctx context.Context, req request) (*http.Response, error) {
+	var body []byte
+	if req.body != nil {
+		var err error
+		if body, err = json.Marshal(req.body); err != nil {
+			return nil, fmt.Errorf("failed to encode request body: %w", err)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.sendOnce(ctx, req, body)
+		if err == nil {
+			return resp, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		var apiErr *APIError
+		isAPIErr := errors.As(err, &apiErr)
+		if attempt >= c.retry.MaxRetries || !retryable(req.method, apiErr, isAPIErr) {
+			return nil, err
+		}
+
+		var retryAfter time.Duration
+		if isAPIErr {
+			retryAfter = apiErr.RetryAfter
+		}
+		timer := time.NewTimer(c.retry.backoff(attempt, retryAfter))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// sendOnce 发送一次请求，非 2xx 响应转换为 *APIError
+func (c *Client) sendOnce(ctx context.Context, req request, body []byte) (*http.Response, error) {
+	u := c.baseURL.JoinPath(req.path)
+	if len(req.query) > 0 {
+		u.RawQuery = req.query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if c.userAgent != "" {
+		httpReq.Header.Set("User-Agent", c.userAgent)
+	}
+	if c.token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.apiKey != "" {
+		httpReq.Header.Set("X-API-Key", c.apiKey)
+	}
+	for k, v := range req.header {
+		httpReq.Header[k] = v
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+	return nil, parseAPIError(resp)
+}
+
+// parseAPIError 解析错误响应，响应体不是标准错误格式时使用原始内容作为错误信息
+func parseAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+	if s := resp.Header.Get("Retry-After"); s != "" {
+		if seconds, err := strconv.Atoi(s); err == nil && seconds >= 0 {
+			apiErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+	}
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	var payload struct {
+		Error struct {
+			Code    string         `json:"code"`
+			Message string         `json:"message"`
+			Details map[string]any `json:"details"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &payload); err == nil && payload.Error.Code != "" {
+		apiErr.Code = payload.Error.Code
+		apiErr.Message = payload.Error.Message
+		apiErr.Details = payload.Error.Details
+		return apiErr
+	}
+
+	apiErr.Message = strings.TrimSpace(string(data))
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+	return apiErr
+}
+
+// retryable 判断失败的请求是否可以重试
+func retryable(method string, apiErr *APIError, isAPIErr bool) bool {
+	idempotent := method == http.MethodGet || method == http.MethodHead ||
+		method == http.MethodPut || method == http.MethodDelete
+	if !isAPIErr {
+		// 网络错误时无法确定服务器是否已处理请求
+		return idempotent
+	}
+	switch apiErr.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusRequestTimeout, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return idempotent
+	default:
+		return false
+	}
+}
+
+// GlobalHealth 健康检查结果
+type GlobalHealth struct {
+	Healthy bool   `json:"healthy"`
+	Version string `json:"version"`
+}
+
+// Health 检查服务器是否可用
+func (c *Client) Health(ctx context.Context) (*GlobalHealth, error) {
+	var out GlobalHealth
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/global/health"}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Metrics 获取 Prometheus 文本格式的服务器指标
+func (c *Client) Metrics(ctx context.Context) (string, error) {
+	resp, err := c.send(ctx, request{
+		method: http.MethodGet,
+		path:   "/metrics",
+		header: http.Header{"Accept": {"text/plain"}},
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
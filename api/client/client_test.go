@@ -0,0 +1,518 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/crush/api/models"
+	"github.com/charmbracelet/crush/docs"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+var testUpgrader = websocket.Upgrader{}
+
+var testRetry = RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts ...Option) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	c, err := New(srv.URL, append([]Option{WithRetry(testRetry)}, opts...)...)
+	require.NoError(t, err)
+	return c
+}
+
+// TestClientCoversSpec calls every client method and checks that together they
+// hit every operation in the generated OpenAPI document.
+func TestClientCoversSpec(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu   sync.Mutex
+		seen []string
+	)
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = append(seen, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+
+		if r.URL.Path != "/project" && r.URL.Path != "/global/health" && r.URL.Path != "/global/dispose" &&
+			r.URL.Path != "/metrics" && r.URL.Path != "/project/current" && r.URL.Path != "/global/stats" {
+			require.Equal(t, "/tmp/proj", r.URL.Query().Get("directory"), r.URL.Path)
+		}
+		switch r.URL.Path {
+		case "/event":
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "event: server.connected\ndata: {\"type\":\"server.connected\",\"properties\":{}}\n\n")
+		case "/ws":
+			conn, err := testUpgrader.Upgrade(w, r, nil)
+			require.NoError(t, err)
+			defer conn.Close()
+			require.NoError(t, conn.WriteJSON(models.WSEvent{Type: EventConnected}))
+		case "/job/j/stream":
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "event: job.finished\ndata: {\"type\":\"job.finished\",\"properties\":{}}\n\n")
+		case "/metrics":
+			fmt.Fprint(w, "zorkagent_app_instances 1\n")
+		default:
+			fmt.Fprint(w, "null")
+		}
+	})
+
+	ctx := t.Context()
+	p := c.Project("/tmp/proj")
+	calls := []func() error{
+		func() error { _, err := c.Health(ctx); return err },
+		func() error { _, err := c.Metrics(ctx); return err },
+		func() error { _, err := c.ListProjects(ctx); return err },
+		func() error {
+			_, err := c.CreateProject(ctx, models.CreateProjectRequest{Path: "/tmp/proj"})
+			return err
+		},
+		func() error { _, err := c.CurrentProject(ctx, ""); return err },
+		func() error { _, err := c.DisposeAll(ctx); return err },
+		func() error { _, err := c.GlobalStats(ctx, StatsOptions{Model: "gpt-4o"}); return err },
+		func() error { _, err := p.Dispose(ctx); return err },
+		func() error { _, err := p.Config(ctx); return err },
+		func() error { _, err := p.ConfigSection(ctx, "mcp"); return err },
+		func() error {
+			_, err := p.PatchConfigSection(ctx, "options", map[string]any{"debug": true})
+			return err
+		},
+		func() error {
+			_, err := p.PutConfigEntry(ctx, "mcp", "fs", map[string]any{"type": "stdio"})
+			return err
+		},
+		func() error { _, err := p.DeleteConfigEntry(ctx, "mcp", "fs"); return err },
+		func() error { _, err := p.Providers(ctx); return err },
+		func() error { _, err := p.TestProvider(ctx, "openai", nil); return err },
+		func() error { _, err := p.Skills(ctx); return err },
+		func() error { _, err := p.Skill(ctx, "deploy"); return err },
+		func() error { _, err := p.Path(ctx); return err },
+		func() error { _, err := p.SystemPrompt(ctx); return err },
+		func() error { _, err := p.UpdateSystemPrompt(ctx, models.UpdateSystemPromptRequest{}); return err },
+		func() error { _, err := p.Permissions(ctx, ""); return err },
+		func() error { _, err := p.ReplyPermission(ctx, "req", models.PermissionReplyRequest{}); return err },
+		func() error { _, err := p.Search(ctx, "foo", SearchOptions{}); return err },
+		func() error { _, err := p.FindFiles(ctx, "*.go"); return err },
+		func() error { _, err := p.FindSymbols(ctx, "Client", 0); return err },
+		func() error { _, err := p.FindDefinition(ctx, "main.go", 1, 1); return err },
+		func() error { _, err := p.FindReferences(ctx, "main.go", 1, 1, true); return err },
+		func() error { _, err := p.ListFiles(ctx, "", false); return err },
+		func() error { _, err := p.ReadFile(ctx, "main.go", 0, 0); return err },
+		func() error { _, err := p.GitStatus(ctx); return err },
+		func() error { _, err := p.WriteFile(ctx, models.FileWriteRequest{}); return err },
+		func() error { _, err := p.PatchFile(ctx, models.FilePatchRequest{}); return err },
+		func() error { _, err := p.RenameFile(ctx, models.FileRenameRequest{}); return err },
+		func() error { _, err := p.DeleteFile(ctx, "main.go", "s"); return err },
+		func() error { _, err := p.LSPStatus(ctx); return err },
+		func() error { _, err := p.Diagnostics(ctx, "main.go"); return err },
+		func() error { _, err := p.RestartLSP(ctx, "gopls"); return err },
+		func() error { _, err := p.MCPStatus(ctx); return err },
+		func() error { _, err := p.RestartMCP(ctx, "fs"); return err },
+		func() error { _, err := p.SetMCPEnabled(ctx, "fs", true); return err },
+		func() error { _, err := p.SetMCPEnabled(ctx, "fs", false); return err },
+		func() error { _, err := p.MCPTools(ctx, "fs"); return err },
+		func() error { _, err := p.MCPPrompts(ctx, "fs"); return err },
+		func() error { _, err := p.MCPResources(ctx, "fs"); return err },
+		func() error { _, err := p.CallMCPTool(ctx, "fs", "read", nil); return err },
+		func() error { _, err := p.Stats(ctx, StatsOptions{From: time.Now(), To: time.Now()}); return err },
+		func() error {
+			_, err := p.ListAudit(ctx, AuditOptions{Kind: "permission"}, ListOptions{Limit: 10})
+			return err
+		},
+		func() error { _, err := p.WebhookStatus(ctx); return err },
+		func() error { _, err := p.Jobs(ctx); return err },
+		func() error { _, err := p.Job(ctx, "j"); return err },
+		func() error { _, err := p.JobOutput(ctx, "j", 0, 0); return err },
+		func() error { _, err := p.KillJob(ctx, "j"); return err },
+		func() error {
+			for ev, err := range p.StreamJobOutput(ctx, "j", 0, 0) {
+				if err != nil {
+					return err
+				}
+				require.Equal(t, EventJobFinished, ev.Type)
+			}
+			return nil
+		},
+		func() error { _, err := p.ListSessions(ctx, ListOptions{}); return err },
+		func() error { _, err := p.CreateSession(ctx, models.CreateSessionRequest{}); return err },
+		func() error { _, err := p.GetSession(ctx, "s"); return err },
+		func() error { _, err := p.UpdateSession(ctx, "s", models.UpdateSessionRequest{}); return err },
+		func() error { return p.DeleteSession(ctx, "s") },
+		func() error { return p.AbortSession(ctx, "s") },
+		func() error { _, err := p.SessionStatus(ctx); return err },
+		func() error { _, err := p.ChildSessions(ctx, "s"); return err },
+		func() error { _, err := p.ExportSession(ctx, "s", "markdown"); return err },
+		func() error { _, err := p.ImportSession(ctx, []byte("{}")); return err },
+		func() error { _, err := p.RevertSession(ctx, "s", models.SessionRevertRequest{}); return err },
+		func() error { _, err := p.UnrevertSession(ctx, "s"); return err },
+		func() error { return p.SummarizeSession(ctx, "s", models.SessionSummarizeRequest{}) },
+		func() error { return p.InitSession(ctx, "s", models.SessionInitRequest{}) },
+		func() error { _, err := p.Shell(ctx, "s", models.SessionShellRequest{}); return err },
+		func() error { _, err := p.Command(ctx, "s", models.SessionCommandRequest{}); return err },
+		func() error { _, err := p.ListCommands(ctx); return err },
+		func() error { _, err := p.ListMessages(ctx, "s", ListOptions{}); return err },
+		func() error { _, err := p.GetMessage(ctx, "m"); return err },
+		func() error { _, err := p.Prompt(ctx, "s", models.PromptRequest{}); return err },
+		func() error { _, err := p.PromptAsync(ctx, "s", models.PromptRequest{}); return err },
+		func() error { _, err := p.ListRuns(ctx, ""); return err },
+		func() error { _, err := p.GetRun(ctx, "r"); return err },
+		func() error { _, err := p.CancelRun(ctx, "r"); return err },
+		func() error {
+			for ev, err := range p.Events(ctx, "") {
+				if err != nil {
+					return err
+				}
+				require.Equal(t, EventConnected, ev.Type)
+				break
+			}
+			return nil
+		},
+		func() error {
+			conn, err := p.Connect(ctx, ConnectOptions{})
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+			ev, err := conn.Next()
+			require.Equal(t, EventConnected, ev.Type)
+			return err
+		},
+	}
+	for i, call := range calls {
+		require.NoError(t, call(), "call %d", i)
+	}
+
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(docs.SwaggerInfo.ReadDoc()), &spec))
+	require.NotEmpty(t, spec.Paths)
+
+	param := regexp.MustCompile(`\{[^}]+\}`)
+	for path, ops := range spec.Paths {
+		pattern := regexp.MustCompile("^" + param.ReplaceAllString(path, `[^/]+`) + "$")
+		for method := range ops {
+			method = strings.ToUpper(method)
+			covered := slices.ContainsFunc(seen, func(s string) bool {
+				m, p, _ := strings.Cut(s, " ")
+				return m == method && pattern.MatchString(p) && !shadowedBy(spec.Paths, p, path)
+			})
+			require.True(t, covered, "no client method for %s %s", method, path)
+		}
+	}
+}
+
+// shadowedBy reports whether the request path is better matched by a static
+// spec path than by the templated one, e.g. /session/status vs /session/{id}.
+func shadowedBy(paths map[string]map[string]json.RawMessage, requestPath, template string) bool {
+	if !strings.Contains(template, "{") {
+		return false
+	}
+	_, ok := paths[requestPath]
+	return ok
+}
+
+func TestAPIError(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":{"code":"PROJECT_NOT_FOUND","message":"project not found: /nope"}}`)
+	})
+
+	_, err := c.Project("/nope").Config(t.Context())
+	require.Error(t, err)
+	require.True(t, IsNotFound(err))
+	apiErr, ok := err.(*APIError)
+	require.True(t, ok)
+	require.Equal(t, "PROJECT_NOT_FOUND", apiErr.Code)
+	require.Equal(t, "project not found: /nope", apiErr.Message)
+}
+
+func TestAuthHeaders(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		require.Equal(t, "key", r.Header.Get("X-API-Key"))
+		fmt.Fprint(w, `{"healthy":true,"version":"1.0.0"}`)
+	}, WithToken("secret"), WithAPIKey("key"))
+
+	health, err := c.Health(t.Context())
+	require.NoError(t, err)
+	require.True(t, health.Healthy)
+}
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+
+	t.Run("retries 429 for POST", func(t *testing.T) {
+		t.Parallel()
+		var attempts atomic.Int32
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			var body models.PromptRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Len(t, body.Parts, 1)
+			if attempts.Add(1) < 3 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				fmt.Fprint(w, `{"error":{"code":"TOO_MANY_RUNS","message":"busy"}}`)
+				return
+			}
+			fmt.Fprint(w, `{"info":{"id":"msg"}}`)
+		})
+
+		resp, err := c.Project("/tmp/proj").Prompt(t.Context(), "s", models.PromptRequest{
+			Parts: []models.PartInput{models.TextPartInput{Text: "hi"}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "msg", resp.Info.ID)
+		require.EqualValues(t, 3, attempts.Load())
+	})
+
+	t.Run("does not retry 503 for POST", func(t *testing.T) {
+		t.Parallel()
+		var attempts atomic.Int32
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+
+		_, err := c.Project("/tmp/proj").CreateSession(t.Context(), models.CreateSessionRequest{})
+		require.Error(t, err)
+		require.EqualValues(t, 1, attempts.Load())
+	})
+
+	t.Run("retries 503 for GET up to MaxRetries", func(t *testing.T) {
+		t.Parallel()
+		var attempts atomic.Int32
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+
+		_, err := c.ListProjects(t.Context())
+		var apiErr *APIError
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+		require.EqualValues(t, testRetry.MaxRetries+1, attempts.Load())
+	})
+
+	t.Run("does not retry 400", func(t *testing.T) {
+		t.Parallel()
+		var attempts atomic.Int32
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			w.WriteHeader(http.StatusBadRequest)
+		})
+
+		_, err := c.ListProjects(t.Context())
+		require.Error(t, err)
+		require.EqualValues(t, 1, attempts.Load())
+	})
+}
+
+func TestSessionsPagination(t *testing.T) {
+	t.Parallel()
+
+	const total = 7
+	var pages atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		pages.Add(1)
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		resp := models.SessionsResponse{Total: total}
+		for i := offset; i < min(offset+limit, total); i++ {
+			resp.Sessions = append(resp.Sessions, models.SessionResponse{ID: strconv.Itoa(i)})
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(resp))
+	})
+
+	var ids []string
+	for s, err := range c.Project("/tmp/proj").Sessions(t.Context(), 3) {
+		require.NoError(t, err)
+		ids = append(ids, s.ID)
+	}
+	require.Equal(t, []string{"0", "1", "2", "3", "4", "5", "6"}, ids)
+	require.EqualValues(t, 3, pages.Load())
+}
+
+func TestAuditPagination(t *testing.T) {
+	t.Parallel()
+
+	const total = 5
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		require.Equal(t, "s1", q.Get("sessionID"))
+		require.Equal(t, "tool", q.Get("kind"))
+		require.Equal(t, "2025-03-01", q.Get("from"))
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		offset, _ := strconv.Atoi(q.Get("offset"))
+		resp := models.AuditResponse{Total: total}
+		for i := offset; i < min(offset+limit, total); i++ {
+			resp.Entries = append(resp.Entries, models.AuditEntry{ID: strconv.Itoa(i)})
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(resp))
+	})
+
+	opts := AuditOptions{SessionID: "s1", Kind: "tool", From: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)}
+	var ids []string
+	for e, err := range c.Project("/tmp/proj").Audit(t.Context(), opts, 2) {
+		require.NoError(t, err)
+		ids = append(ids, e.ID)
+	}
+	require.Equal(t, []string{"0", "1", "2", "3", "4"}, ids)
+}
+
+func TestMessagesPaginationError(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{"error":{"code":"INTERNAL_ERROR","message":"boom"}}`)
+	})
+
+	var errs []error
+	for _, err := range c.Project("/tmp/proj").Messages(t.Context(), "s", 0) {
+		errs = append(errs, err)
+	}
+	require.Len(t, errs, 1)
+	require.ErrorContains(t, errs[0], "boom")
+}
+
+func TestEventsReconnect(t *testing.T) {
+	t.Parallel()
+
+	var connections atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		switch connections.Add(1) {
+		case 1:
+			require.Empty(t, r.Header.Get("Last-Event-ID"))
+			fmt.Fprint(w, "event: server.connected\ndata: {\"type\":\"server.connected\",\"properties\":{\"status\":\"connected\"}}\n\n")
+			fmt.Fprint(w, ": heartbeat\n\n")
+			fmt.Fprint(w, "id: 41\nevent: session.updated\ndata: {\"type\":\"session.updated\",\"properties\":{\"id\":\"s1\"}}\n\n")
+		default:
+			require.Equal(t, "41", r.Header.Get("Last-Event-ID"))
+			fmt.Fprint(w, "id: 42\nevent: message.updated\ndata: {\"type\":\"message.updated\",\n")
+			fmt.Fprint(w, "data: \"properties\":{\"id\":\"m1\"}}\n\n")
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+
+	var got []Event
+	for ev, err := range c.Project("/tmp/proj").Events(ctx, "") {
+		require.NoError(t, err)
+		got = append(got, ev)
+		if len(got) == 3 {
+			break
+		}
+	}
+	require.Len(t, got, 3)
+	require.Equal(t, EventConnected, got[0].Type)
+	require.Equal(t, "session.updated", got[1].Type)
+	require.Equal(t, "41", got[1].ID)
+	require.Equal(t, "message.updated", got[2].Type)
+	require.Equal(t, "42", got[2].ID)
+
+	var props struct {
+		ID string `json:"id"`
+	}
+	require.NoError(t, got[2].Decode(&props))
+	require.Equal(t, "m1", props.ID)
+}
+
+func TestEventsStopsOnClientError(t *testing.T) {
+	t.Parallel()
+
+	var connections atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		connections.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":{"code":"UNAUTHORIZED","message":"missing credentials"}}`)
+	})
+
+	var errs []error
+	for _, err := range c.Project("/tmp/proj").Events(t.Context(), "") {
+		errs = append(errs, err)
+	}
+	require.Len(t, errs, 1)
+	var apiErr *APIError
+	require.ErrorAs(t, errs[0], &apiErr)
+	require.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+	require.EqualValues(t, 1, connections.Load())
+}
+
+func TestConnect(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/ws", r.URL.Path)
+		require.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		require.Equal(t, "a,b", r.URL.Query().Get("session_id"))
+		require.Equal(t, "41", r.URL.Query().Get("last_event_id"))
+
+		conn, err := testUpgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		require.NoError(t, conn.WriteJSON(models.WSEvent{ID: "42", Type: "session.updated", Properties: map[string]string{"sessionID": "a"}}))
+		var cmd models.WSCommand
+		require.NoError(t, conn.ReadJSON(&cmd))
+		require.Equal(t, models.WSCommandAbort, cmd.Type)
+		require.NoError(t, conn.WriteJSON(models.WSEvent{
+			Type:       EventCommandResult,
+			Properties: models.WSCommandResult{ID: cmd.ID, Command: cmd.Type, OK: true},
+		}))
+		_, _, _ = conn.ReadMessage()
+	}, WithToken("secret"))
+
+	conn, err := c.Project("/tmp/proj").Connect(t.Context(), ConnectOptions{SessionIDs: []string{"a", "b"}, LastEventID: "41"})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ev, err := conn.Next()
+	require.NoError(t, err)
+	require.Equal(t, "42", ev.ID)
+	require.Equal(t, "session.updated", ev.Type)
+
+	require.NoError(t, conn.Send(models.WSCommand{ID: "c1", Type: models.WSCommandAbort, SessionID: "a"}))
+	ev, err = conn.Next()
+	require.NoError(t, err)
+	require.Equal(t, EventCommandResult, ev.Type)
+	var result models.WSCommandResult
+	require.NoError(t, ev.Decode(&result))
+	require.Equal(t, "c1", result.ID)
+	require.True(t, result.OK)
+}
+
+func TestConnectHandshakeError(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":{"code":"PROJECT_NOT_FOUND","message":"project not found: /nope"}}`)
+	})
+
+	_, err := c.Project("/nope").Connect(t.Context(), ConnectOptions{})
+	require.True(t, IsNotFound(err), "got %v", err)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, "PROJECT_NOT_FOUND", apiErr.Code)
+}
@@ -0,0 +1,240 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// 服务器在特定情况下发送的事件类型
+const (
+	// EventConnected 每次连接建立后的第一个事件
+	EventConnected = "server.connected"
+	// EventReset 断线期间的事件已不在服务器缓冲中，客户端需要重新获取状态
+	EventReset = "reset"
+	// EventInstanceDisposed 项目实例已被释放，服务器随后关闭连接，重连时会重新创建实例
+	EventInstanceDisposed = "instance.disposed"
+)
+
+// Event 事件流中的一个事件
+type Event struct {
+	// ID 事件 ID，可作为 Events 的 lastEventID 在之后继续接收；server.connected 没有 ID
+	ID string
+	// Type 事件类型，例如 message.updated、permission.updated
+	Type string
+	// Properties 事件数据，结构取决于 Type
+	Properties json.RawMessage
+}
+
+// Decode 将事件数据解码到 v
+func (e Event) Decode(v any) error {
+	return json.Unmarshal(e.Properties, v)
+}
+
+// Events 订阅项目的实时事件流，lastEventID 不为空时从该 ID 之后开始接收
+//
+// 连接断开后会自动携带最后收到的事件 ID 重连，服务器会补发断线期间的事件；补发不完整时
+// 会先收到 reset 事件。连接失败的次数超过重试策略的 MaxRetries，或服务器返回不可重试的
+// 错误时，返回该错误并结束遍历。ctx 取消时直接结束遍历。
+func (p *Project) Events(ctx context.Context, lastEventID string) iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		failures := 0
+		for {
+			req := request{
+				method: http.MethodGet,
+				path:   "/event",
+				query:  p.query(),
+				header: http.Header{
+					"Accept":        {"text/event-stream"},
+					"Cache-Control": {"no-cache"},
+				},
+			}
+			if lastEventID != "" {
+				req.header.Set("Last-Event-ID", lastEventID)
+			}
+
+			resp, err := p.client.sendOnce(ctx, req, nil)
+			if err == nil {
+				failures = 0
+				stopped := false
+				reader := newEventReader(resp.Body)
+				for {
+					ev, readErr := reader.next()
+					if readErr != nil {
+						break
+					}
+					if ev.ID != "" {
+						lastEventID = ev.ID
+					}
+					if !yield(ev, nil) {
+						stopped = true
+						break
+					}
+				}
+				resp.Body.Close()
+				if stopped {
+					return
+				}
+			}
+			if ctx.Err() != nil {
+				return
+			}
+
+			var retryAfter time.Duration
+			if err != nil {
+				var apiErr *APIError
+				isAPIErr := errors.As(err, &apiErr)
+				if failures >= p.client.retry.MaxRetries || !retryable(http.MethodGet, apiErr, isAPIErr) {
+					yield(Event{}, err)
+					return
+				}
+				if isAPIErr {
+					retryAfter = apiErr.RetryAfter
+				}
+				failures++
+			}
+
+			timer := time.NewTimer(p.client.retry.backoff(max(failures-1, 0), retryAfter))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// 后台任务输出流的事件类型
+const (
+	// EventJobOutput 后台任务有新的输出，Properties 为 models.JobOutput
+	EventJobOutput = "job.output"
+	// EventJobFinished 后台任务结束且输出已全部发送，Properties 为 models.Job，之后服务器关闭连接
+	EventJobFinished = "job.finished"
+)
+
+// StreamJobOutput 流式获取后台任务在指定偏移量之后的输出，收到 job.finished 事件后结束遍历
+//
+// 不会自动重连，连接断开时返回错误；可以用最后收到的 job.output 事件中的偏移量重新调用以继续读取。
+func (p *Project) StreamJobOutput(ctx context.Context, id string, stdoutOffset, stderrOffset int) iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		req := request{
+			method: http.MethodGet,
+			path:   "/job/" + url.PathEscape(id) + "/stream",
+			query:  p.query("stdout_offset", strconv.Itoa(stdoutOffset), "stderr_offset", strconv.Itoa(stderrOffset)),
+			header: http.Header{
+				"Accept":        {"text/event-stream"},
+				"Cache-Control": {"no-cache"},
+			},
+		}
+		resp, err := p.client.sendOnce(ctx, req, nil)
+		if err != nil {
+			yield(Event{}, err)
+			return
+		}
+		defer resp.Body.Close()
+
+		reader := newEventReader(resp.Body)
+		for {
+			ev, err := reader.next()
+			if err != nil {
+				if ctx.Err() == nil {
+					yield(Event{}, fmt.Errorf("job output stream closed before the job finished: %w", err))
+				}
+				return
+			}
+			if !yield(ev, nil) || ev.Type == EventJobFinished {
+				return
+			}
+		}
+	}
+}
+
+// eventReader 解析 text/event-stream
+type eventReader struct {
+	r *bufio.Reader
+}
+
+func newEventReader(r io.Reader) *eventReader {
+	return &eventReader{r: bufio.NewReader(r)}
+}
+
+// next 读取下一个事件，连接结束时返回 io.EOF
+func (er *eventReader) next() (Event, error) {
+	var (
+		id, name string
+		data     bytes.Buffer
+		hasData  bool
+	)
+	for {
+		line, err := er.r.ReadBytes('\n')
+		if err != nil && (len(line) == 0 || !errors.Is(err, io.EOF)) {
+			return Event{}, err
+		}
+		line = bytes.TrimRight(line, "\r\n")
+
+		if len(line) == 0 {
+			if hasData {
+				return decodeEvent(id, name, data.Bytes()), nil
+			}
+			// 只有 id 或 event 字段的空事件直接忽略
+			id, name = "", ""
+			if err != nil {
+				return Event{}, err
+			}
+			continue
+		}
+		if line[0] == ':' {
+			// 注释，服务器用于心跳
+			continue
+		}
+
+		field, value, _ := bytes.Cut(line, []byte(":"))
+		value = bytes.TrimPrefix(value, []byte(" "))
+		switch string(field) {
+		case "id":
+			id = string(value)
+		case "event":
+			name = string(value)
+		case "data":
+			if hasData {
+				data.WriteByte('\n')
+			}
+			data.Write(value)
+			hasData = true
+		}
+		if err != nil {
+			// 最后一行没有换行符，连接已结束
+			return Event{}, err
+		}
+	}
+}
+
+// decodeEvent 解析事件数据，数据格式为 {"type": ..., "properties": ...}
+func decodeEvent(id, name string, data []byte) Event {
+	ev := Event{ID: id, Type: name}
+	var payload struct {
+		Type       string          `json:"type"`
+		Properties json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		if json.Valid(data) {
+			ev.Properties = json.RawMessage(bytes.Clone(data))
+		}
+		return ev
+	}
+	if payload.Type != "" {
+		ev.Type = payload.Type
+	}
+	ev.Properties = payload.Properties
+	return ev
+}
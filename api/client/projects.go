@@ -0,0 +1,537 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/charmbracelet/crush/api/models"
+)
+
+// ListProjects 获取已注册的项目
+func (c *Client) ListProjects(ctx context.Context) (*models.ProjectsResponse, error) {
+	var out models.ProjectsResponse
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/project"}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateProject 注册项目
+func (c *Client) CreateProject(ctx context.Context, req models.CreateProjectRequest) (*models.CreateProjectResponse, error) {
+	var out models.CreateProjectResponse
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/project", body: req}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CurrentProject 获取当前项目，directory 为空时返回服务器启动目录对应的项目
+func (c *Client) CurrentProject(ctx context.Context, directory string) (*models.CurrentProjectResponse, error) {
+	var query url.Values
+	if directory != "" {
+		query = url.Values{"directory": {directory}}
+	}
+	var out models.CurrentProjectResponse
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/project/current", query: query}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DisposeAll 释放所有项目的 app 实例
+func (c *Client) DisposeAll(ctx context.Context) (*models.DisposeAllResponse, error) {
+	var out models.DisposeAllResponse
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/global/dispose"}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Project 项目内接口的客户端，所有请求都带有 directory 参数
+type Project struct {
+	client    *Client
+	directory string
+}
+
+// Project 返回指定项目目录的客户端
+func (c *Client) Project(directory string) *Project {
+	return &Project{client: c, directory: directory}
+}
+
+// Directory 返回项目目录
+func (p *Project) Directory() string {
+	return p.directory
+}
+
+// query 返回带 directory 的查询参数，kv 为额外的键值对，值为空的参数会被忽略
+func (p *Project) query(kv ...string) url.Values {
+	q := url.Values{"directory": {p.directory}}
+	for i := 0; i+1 < len(kv); i += 2 {
+		if kv[i+1] != "" {
+			q.Set(kv[i], kv[i+1])
+		}
+	}
+	return q
+}
+
+// get 发送项目内的 GET 请求
+func (p *Project) get(ctx context.Context, path string, query url.Values, out any) error {
+	return p.client.do(ctx, request{method: http.MethodGet, path: path, query: query}, out)
+}
+
+// send 发送项目内带请求体的请求
+func (p *Project) send(ctx context.Context, method, path string, body, out any) error {
+	return p.client.do(ctx, request{method: method, path: path, query: p.query(), body: body}, out)
+}
+
+// Dispose 释放项目的 app 实例，下次请求时会重新创建
+func (p *Project) Dispose(ctx context.Context) (*models.DisposeProjectResponse, error) {
+	var out models.DisposeProjectResponse
+	if err := p.send(ctx, http.MethodPost, "/instance/dispose", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Config 获取项目配置
+func (p *Project) Config(ctx context.Context) (*models.ConfigResponse, error) {
+	var out models.ConfigResponse
+	if err := p.get(ctx, "/project/config", p.query(), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ConfigSection 获取生效的配置段（providers、models、mcp、lsp、options、permissions），密钥已脱敏
+func (p *Project) ConfigSection(ctx context.Context, section string) (*models.ConfigSectionResponse, error) {
+	var out models.ConfigSectionResponse
+	if err := p.get(ctx, configPath(section, ""), p.query(), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PatchConfigSection 按字段修改 options 或 permissions 配置段，值为 nil 的字段会被删除
+func (p *Project) PatchConfigSection(ctx context.Context, section string, fields map[string]any) (*models.ConfigSectionResponse, error) {
+	var out models.ConfigSectionResponse
+	if err := p.send(ctx, http.MethodPatch, configPath(section, ""), fields, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PutConfigEntry 添加或替换 providers、models、mcp 或 lsp 配置段中的条目，
+// entry 可以是 config.ProviderConfig 等配置结构或 map
+func (p *Project) PutConfigEntry(ctx context.Context, section, name string, entry any) (*models.ConfigSectionResponse, error) {
+	var out models.ConfigSectionResponse
+	if err := p.send(ctx, http.MethodPut, configPath(section, name), entry, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteConfigEntry 删除 providers、models、mcp 或 lsp 配置段中的条目
+func (p *Project) DeleteConfigEntry(ctx context.Context, section, name string) (*models.ConfigSectionResponse, error) {
+	var out models.ConfigSectionResponse
+	if err := p.send(ctx, http.MethodDelete, configPath(section, name), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Providers 获取 provider 和模型目录
+func (p *Project) Providers(ctx context.Context) (*models.ProvidersResponse, error) {
+	var out models.ProvidersResponse
+	if err := p.get(ctx, "/config/providers", p.query(), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// TestProvider 测试 provider 连接，req 为 nil 时使用当前配置
+func (p *Project) TestProvider(ctx context.Context, id string, req *models.ProviderTestRequest) (*models.ProviderTestResponse, error) {
+	if req == nil {
+		req = &models.ProviderTestRequest{}
+	}
+	var out models.ProviderTestResponse
+	if err := p.send(ctx, http.MethodPost, "/config/providers/"+url.PathEscape(id)+"/test", req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Skills 获取项目可用的 Agent Skills 及其校验结果
+func (p *Project) Skills(ctx context.Context) (*models.SkillsResponse, error) {
+	var out models.SkillsResponse
+	if err := p.get(ctx, "/skill", p.query(), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Skill 按名称获取 skill，包括 SKILL.md 的正文
+func (p *Project) Skill(ctx context.Context, name string) (*models.Skill, error) {
+	var out models.Skill
+	if err := p.get(ctx, "/skill/"+url.PathEscape(name), p.query(), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// configPath 返回配置段或配置条目的路径
+func configPath(section, name string) string {
+	path := "/project/config/" + url.PathEscape(section)
+	if name != "" {
+		path += "/" + url.PathEscape(name)
+	}
+	return path
+}
+
+// PathInfo 项目相关的路径
+type PathInfo struct {
+	Home      string `json:"home"`
+	State     string `json:"state"`
+	Config    string `json:"config"`
+	Worktree  string `json:"worktree"`
+	Directory string `json:"directory"`
+}
+
+// Path 获取项目相关的路径
+func (p *Project) Path(ctx context.Context) (*PathInfo, error) {
+	var out PathInfo
+	if err := p.get(ctx, "/path", p.query(), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SystemPrompt 获取项目的系统提示词
+func (p *Project) SystemPrompt(ctx context.Context) (*models.GetSystemPromptResponse, error) {
+	var out models.GetSystemPromptResponse
+	if err := p.get(ctx, "/system-prompt", p.query(), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateSystemPrompt 更新项目的系统提示词
+func (p *Project) UpdateSystemPrompt(ctx context.Context, req models.UpdateSystemPromptRequest) (*models.UpdateSystemPromptResponse, error) {
+	var out models.UpdateSystemPromptResponse
+	if err := p.send(ctx, http.MethodPut, "/system-prompt", req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Permissions 获取待处理的权限请求，sessionID 为空时返回项目内所有会话的请求
+func (p *Project) Permissions(ctx context.Context, sessionID string) (*models.PermissionsResponse, error) {
+	var out models.PermissionsResponse
+	if err := p.get(ctx, "/project/permissions", p.query("sessionID", sessionID), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PermissionReply 权限回复结果
+type PermissionReply struct {
+	Status    string `json:"status"`
+	RequestID string `json:"request_id"`
+	Granted   string `json:"granted"`
+}
+
+// ReplyPermission 回复权限请求
+func (p *Project) ReplyPermission(ctx context.Context, requestID string, req models.PermissionReplyRequest) (*PermissionReply, error) {
+	var out PermissionReply
+	if err := p.send(ctx, http.MethodPost, "/project/permissions/"+url.PathEscape(requestID)+"/reply", req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SearchOptions 文本搜索选项
+type SearchOptions struct {
+	Regex           bool
+	CaseInsensitive bool
+}
+
+// Search 在项目中搜索文本内容
+func (p *Project) Search(ctx context.Context, query string, opts SearchOptions) (*models.SearchResponse, error) {
+	q := p.query("query", query)
+	if opts.Regex {
+		q.Set("regex", "true")
+	}
+	if opts.CaseInsensitive {
+		q.Set("case_insensitive", "true")
+	}
+	var out models.SearchResponse
+	if err := p.get(ctx, "/find", q, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// FindFiles 按 glob 模式搜索文件名
+func (p *Project) FindFiles(ctx context.Context, pattern string) (*models.FileListResponse, error) {
+	var out models.FileListResponse
+	if err := p.get(ctx, "/find/file", p.query("pattern", pattern), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// FindSymbols 通过 LSP 搜索名称包含 query 的工作区符号，limit 为 0 时使用服务器默认值
+func (p *Project) FindSymbols(ctx context.Context, query string, limit int) ([]models.Symbol, error) {
+	q := p.query("query", query)
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	var out []models.Symbol
+	if err := p.get(ctx, "/find/symbol", q, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FindDefinition 查找文件中指定位置符号的声明，line 和 column 从 1 开始
+func (p *Project) FindDefinition(ctx context.Context, path string, line, column int) ([]models.Symbol, error) {
+	q := p.query("path", path, "line", strconv.Itoa(line), "column", strconv.Itoa(column))
+	var out []models.Symbol
+	if err := p.get(ctx, "/find/definition", q, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FindReferences 查找文件中指定位置符号的所有引用，line 和 column 从 1 开始
+func (p *Project) FindReferences(ctx context.Context, path string, line, column int, includeDeclaration bool) ([]models.Location, error) {
+	q := p.query("path", path, "line", strconv.Itoa(line), "column", strconv.Itoa(column))
+	q.Set("include_declaration", strconv.FormatBool(includeDeclaration))
+	var out []models.Location
+	if err := p.get(ctx, "/find/references", q, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListFiles 列出目录内容，path 为相对项目的路径，为空时列出项目根目录
+func (p *Project) ListFiles(ctx context.Context, path string, recursive bool) (*models.FileListResponse, error) {
+	q := p.query("path", path)
+	if recursive {
+		q.Set("recursive", "true")
+	}
+	var out models.FileListResponse
+	if err := p.get(ctx, "/file", q, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ReadFile 读取文件内容，offset 和 limit 以行为单位，为 0 时使用服务器默认值
+func (p *Project) ReadFile(ctx context.Context, path string, offset, limit int) (*models.FileContentResponse, error) {
+	q := p.query("path", path)
+	if offset > 0 {
+		q.Set("offset", strconv.Itoa(offset))
+	}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	var out models.FileContentResponse
+	if err := p.get(ctx, "/file/content", q, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GitStatus 获取项目的 Git 状态
+func (p *Project) GitStatus(ctx context.Context) (*models.GitStatusResponse, error) {
+	var out models.GitStatusResponse
+	if err := p.get(ctx, "/file/status", p.query(), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// WriteFile 写入文件，不存在时创建，修改记录到 req.SessionID 会话的文件历史
+func (p *Project) WriteFile(ctx context.Context, req models.FileWriteRequest) (*models.FileEditResponse, error) {
+	var out models.FileEditResponse
+	if err := p.send(ctx, http.MethodPut, "/file/content", req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PatchFile 将 unified diff 应用到文件
+func (p *Project) PatchFile(ctx context.Context, req models.FilePatchRequest) (*models.FileEditResponse, error) {
+	var out models.FileEditResponse
+	if err := p.send(ctx, http.MethodPost, "/file/patch", req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RenameFile 重命名或移动文件
+func (p *Project) RenameFile(ctx context.Context, req models.FileRenameRequest) (*models.FileEditResponse, error) {
+	var out models.FileEditResponse
+	if err := p.send(ctx, http.MethodPost, "/file/rename", req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteFile 删除文件，删除前的内容记录到 sessionID 会话的文件历史
+func (p *Project) DeleteFile(ctx context.Context, path, sessionID string) (*models.FileEditResponse, error) {
+	var out models.FileEditResponse
+	q := p.query("path", path, "session_id", sessionID)
+	if err := p.client.do(ctx, request{method: http.MethodDelete, path: "/file", query: q}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// LSPStatus 获取项目的 LSP 客户端状态
+func (p *Project) LSPStatus(ctx context.Context) ([]models.LSPStatus, error) {
+	var out []models.LSPStatus
+	if err := p.get(ctx, "/lsp", p.query(), &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Diagnostics 获取 LSP 诊断，path 为相对项目的路径，为空时返回所有文件的诊断
+func (p *Project) Diagnostics(ctx context.Context, path string) (*models.DiagnosticsResponse, error) {
+	var out models.DiagnosticsResponse
+	if err := p.get(ctx, "/lsp/diagnostics", p.query("path", path), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RestartLSP 重启 LSP 服务器，服务器就绪后返回状态
+func (p *Project) RestartLSP(ctx context.Context, name string) (*models.LSPStatus, error) {
+	var out models.LSPStatus
+	if err := p.send(ctx, http.MethodPost, "/lsp/"+url.PathEscape(name)+"/restart", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// MCPStatus 获取项目配置的 MCP 服务器状态
+func (p *Project) MCPStatus(ctx context.Context) (map[string]models.MCPStatus, error) {
+	var out map[string]models.MCPStatus
+	if err := p.get(ctx, "/mcp", p.query(), &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RestartMCP 重连 MCP 服务器，连接完成后返回状态
+func (p *Project) RestartMCP(ctx context.Context, name string) (*models.MCPServerStatus, error) {
+	var out models.MCPServerStatus
+	if err := p.send(ctx, http.MethodPost, mcpPath(name, "/restart"), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SetMCPEnabled 在运行时启用或禁用 MCP 服务器，修改不写入配置文件
+func (p *Project) SetMCPEnabled(ctx context.Context, name string, enabled bool) (*models.MCPServerStatus, error) {
+	action := "/disable"
+	if enabled {
+		action = "/enable"
+	}
+	var out models.MCPServerStatus
+	if err := p.send(ctx, http.MethodPost, mcpPath(name, action), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// MCPTools 获取 MCP 服务器的工具，包括禁用的工具
+func (p *Project) MCPTools(ctx context.Context, name string) ([]models.MCPTool, error) {
+	var out []models.MCPTool
+	if err := p.get(ctx, mcpPath(name, "/tools"), p.query(), &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MCPPrompts 获取 MCP 服务器的 prompt
+func (p *Project) MCPPrompts(ctx context.Context, name string) ([]models.MCPPrompt, error) {
+	var out []models.MCPPrompt
+	if err := p.get(ctx, mcpPath(name, "/prompts"), p.query(), &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MCPResources 获取 MCP 服务器的资源
+func (p *Project) MCPResources(ctx context.Context, name string) ([]models.MCPResource, error) {
+	var out []models.MCPResource
+	if err := p.get(ctx, mcpPath(name, "/resources"), p.query(), &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CallMCPTool 直接调用 MCP 工具，不经过 agent 和权限确认
+func (p *Project) CallMCPTool(ctx context.Context, name, tool string, args map[string]any) (*models.MCPToolCallResponse, error) {
+	var out models.MCPToolCallResponse
+	path := mcpPath(name, "/tools/"+url.PathEscape(tool)+"/call")
+	if err := p.send(ctx, http.MethodPost, path, models.MCPToolCallRequest{Arguments: args}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// mcpPath 返回 MCP 服务器相关的路径
+func mcpPath(name, suffix string) string {
+	return "/mcp/" + url.PathEscape(name) + suffix
+}
+
+// Jobs 获取 agent 在项目中启动的后台任务
+func (p *Project) Jobs(ctx context.Context) ([]models.Job, error) {
+	var out []models.Job
+	if err := p.get(ctx, "/job", p.query(), &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Job 获取后台任务
+func (p *Project) Job(ctx context.Context, id string) (*models.Job, error) {
+	var out models.Job
+	if err := p.get(ctx, "/job/"+url.PathEscape(id), p.query(), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// JobOutput 获取后台任务在指定偏移量之后的输出，传入上次返回的偏移量即可只获取新的输出
+func (p *Project) JobOutput(ctx context.Context, id string, stdoutOffset, stderrOffset int) (*models.JobOutput, error) {
+	var out models.JobOutput
+	query := p.query("stdout_offset", strconv.Itoa(stdoutOffset), "stderr_offset", strconv.Itoa(stderrOffset))
+	if err := p.get(ctx, "/job/"+url.PathEscape(id)+"/output", query, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// KillJob 终止后台任务，返回任务的最终状态
+func (p *Project) KillJob(ctx context.Context, id string) (*models.Job, error) {
+	var out models.Job
+	if err := p.send(ctx, http.MethodPost, "/job/"+url.PathEscape(id)+"/kill", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// WebhookStatus 获取项目中 webhook 的投递统计和最近的投递
+func (p *Project) WebhookStatus(ctx context.Context) (*models.WebhookStatusResponse, error) {
+	var out models.WebhookStatusResponse
+	if err := p.get(ctx, "/webhook", p.query(), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,314 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/charmbracelet/crush/api/models"
+)
+
+// DefaultPageSize Sessions 和 Messages 迭代时每页的条数
+const DefaultPageSize = 100
+
+// ListOptions 分页参数
+type ListOptions struct {
+	// Limit 单页条数，0 表示返回全部，服务器最多返回 1000 条
+	Limit int
+	// Offset 跳过的条数
+	Offset int
+}
+
+// query 在 q 中设置分页参数
+func (o ListOptions) query(q url.Values) url.Values {
+	if o.Limit > 0 {
+		q.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Offset > 0 {
+		q.Set("offset", strconv.Itoa(o.Offset))
+	}
+	return q
+}
+
+// paginate 逐页请求并依次返回每一项，fetch 返回单页数据和总数
+func paginate[T any](ctx context.Context, pageSize int, fetch func(context.Context, ListOptions) ([]T, int, error)) iter.Seq2[T, error] {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	return func(yield func(T, error) bool) {
+		opts := ListOptions{Limit: pageSize}
+		for {
+			items, total, err := fetch(ctx, opts)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			opts.Offset += len(items)
+			if len(items) == 0 || opts.Offset >= total {
+				return
+			}
+		}
+	}
+}
+
+// sessionPath 返回会话接口的路径
+func sessionPath(id string, rest string) string {
+	return "/session/" + url.PathEscape(id) + rest
+}
+
+// ListSessions 获取一页会话，Total 为会话总数
+func (p *Project) ListSessions(ctx context.Context, opts ListOptions) (*models.SessionsResponse, error) {
+	var out models.SessionsResponse
+	if err := p.get(ctx, "/session", opts.query(p.query()), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Sessions 按页遍历项目的所有会话，pageSize 为 0 时使用 DefaultPageSize
+// 出错时返回错误并结束遍历
+func (p *Project) Sessions(ctx context.Context, pageSize int) iter.Seq2[models.SessionResponse, error] {
+	return paginate(ctx, pageSize, func(ctx context.Context, opts ListOptions) ([]models.SessionResponse, int, error) {
+		page, err := p.ListSessions(ctx, opts)
+		if err != nil {
+			return nil, 0, err
+		}
+		return page.Sessions, page.Total, nil
+	})
+}
+
+// CreateSession 创建会话
+func (p *Project) CreateSession(ctx context.Context, req models.CreateSessionRequest) (*models.CreateSessionResponse, error) {
+	var out models.CreateSessionResponse
+	if err := p.send(ctx, http.MethodPost, "/session", req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetSession 获取会话详情
+func (p *Project) GetSession(ctx context.Context, id string) (*models.SessionDetailResponse, error) {
+	var out models.SessionDetailResponse
+	if err := p.get(ctx, sessionPath(id, ""), p.query(), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateSession 更新会话
+func (p *Project) UpdateSession(ctx context.Context, id string, req models.UpdateSessionRequest) (*models.UpdateSessionResponse, error) {
+	var out models.UpdateSessionResponse
+	if err := p.send(ctx, http.MethodPut, sessionPath(id, ""), req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteSession 删除会话
+func (p *Project) DeleteSession(ctx context.Context, id string) error {
+	return p.send(ctx, http.MethodDelete, sessionPath(id, ""), nil, nil)
+}
+
+// AbortSession 中止会话中正在进行的 agent 运行
+func (p *Project) AbortSession(ctx context.Context, id string) error {
+	return p.send(ctx, http.MethodPost, sessionPath(id, "/abort"), nil, nil)
+}
+
+// SessionStatus 项目的会话状态
+type SessionStatus struct {
+	TotalSessions int  `json:"total_sessions"`
+	AppConfigured bool `json:"app_configured"`
+	AgentReady    bool `json:"agent_ready"`
+}
+
+// SessionStatus 获取项目的会话状态
+func (p *Project) SessionStatus(ctx context.Context) (*SessionStatus, error) {
+	var out SessionStatus
+	if err := p.get(ctx, "/session/status", p.query(), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ChildSessions 获取会话的子会话
+func (p *Project) ChildSessions(ctx context.Context, id string) ([]models.Session, error) {
+	var out []models.Session
+	if err := p.get(ctx, sessionPath(id, "/children"), p.query(), &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ExportSession 导出会话及其消息、待办事项、子任务会话和文件历史
+// format 为 json 或 markdown，为空时为 json；json 格式的结果可以通过 ImportSession 导入到其他项目
+func (p *Project) ExportSession(ctx context.Context, id, format string) ([]byte, error) {
+	resp, err := p.client.send(ctx, request{
+		method: http.MethodGet,
+		path:   sessionPath(id, "/export"),
+		query:  p.query("format", format),
+		header: http.Header{"Accept": {"application/json, text/markdown"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// ImportSession 导入 ExportSession 导出的 json，以新的 ID 创建会话
+func (p *Project) ImportSession(ctx context.Context, data []byte) (*models.SessionResponse, error) {
+	var out models.SessionResponse
+	if err := p.send(ctx, http.MethodPost, "/session/import", json.RawMessage(data), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RevertSession 将会话回退到指定消息
+func (p *Project) RevertSession(ctx context.Context, id string, req models.SessionRevertRequest) (*models.Session, error) {
+	var out models.Session
+	if err := p.send(ctx, http.MethodPost, sessionPath(id, "/revert"), req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UnrevertSession 撤销会话的回退
+func (p *Project) UnrevertSession(ctx context.Context, id string) (*models.Session, error) {
+	var out models.Session
+	if err := p.send(ctx, http.MethodPost, sessionPath(id, "/unrevert"), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SummarizeSession 总结会话，完成后返回
+func (p *Project) SummarizeSession(ctx context.Context, id string, req models.SessionSummarizeRequest) error {
+	return p.send(ctx, http.MethodPost, sessionPath(id, "/summarize"), req, nil)
+}
+
+// InitSession 分析项目并生成 AGENTS.md，完成后返回
+func (p *Project) InitSession(ctx context.Context, id string, req models.SessionInitRequest) error {
+	return p.send(ctx, http.MethodPost, sessionPath(id, "/init"), req, nil)
+}
+
+// Shell 在会话中执行 shell 命令
+func (p *Project) Shell(ctx context.Context, id string, req models.SessionShellRequest) (*models.AssistantMessage, error) {
+	var out models.AssistantMessage
+	if err := p.send(ctx, http.MethodPost, sessionPath(id, "/shell"), req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Command 在会话中执行命令，完成后返回最终的 assistant 消息
+func (p *Project) Command(ctx context.Context, id string, req models.SessionCommandRequest) (*models.PromptResponse, error) {
+	var out models.PromptResponse
+	if err := p.send(ctx, http.MethodPost, sessionPath(id, "/command"), req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListCommands 获取用户命令、项目命令和 MCP prompt
+func (p *Project) ListCommands(ctx context.Context) ([]models.Command, error) {
+	var out []models.Command
+	if err := p.get(ctx, "/command", p.query(), &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListMessages 获取会话的一页消息，Total 为消息总数
+func (p *Project) ListMessages(ctx context.Context, sessionID string, opts ListOptions) (*models.MessagesResponse, error) {
+	var out models.MessagesResponse
+	if err := p.get(ctx, sessionPath(sessionID, "/message"), opts.query(p.query()), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Messages 按页遍历会话的所有消息，pageSize 为 0 时使用 DefaultPageSize
+// 出错时返回错误并结束遍历
+func (p *Project) Messages(ctx context.Context, sessionID string, pageSize int) iter.Seq2[models.MessageResponse, error] {
+	return paginate(ctx, pageSize, func(ctx context.Context, opts ListOptions) ([]models.MessageResponse, int, error) {
+		page, err := p.ListMessages(ctx, sessionID, opts)
+		if err != nil {
+			return nil, 0, err
+		}
+		return page.Messages, page.Total, nil
+	})
+}
+
+// GetMessage 获取消息详情
+func (p *Project) GetMessage(ctx context.Context, id string) (*models.MessageDetailResponse, error) {
+	var out models.MessageDetailResponse
+	if err := p.get(ctx, "/message/"+url.PathEscape(id), p.query(), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Prompt 发送消息并等待 agent 运行结束，返回最终的 assistant 消息
+// 运行可能持续很长时间，长任务建议使用 PromptAsync
+func (p *Project) Prompt(ctx context.Context, sessionID string, req models.PromptRequest) (*models.PromptResponse, error) {
+	var out models.PromptResponse
+	if err := p.send(ctx, http.MethodPost, sessionPath(sessionID, "/prompt"), req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PromptAsync 在后台发送消息并立即返回运行，可通过 GetRun 查询状态或 CancelRun 取消
+func (p *Project) PromptAsync(ctx context.Context, sessionID string, req models.PromptRequest) (*models.RunResponse, error) {
+	var out models.RunResponse
+	q := p.query()
+	q.Set("async", "true")
+	err := p.client.do(ctx, request{
+		method: http.MethodPost,
+		path:   sessionPath(sessionID, "/prompt"),
+		query:  q,
+		body:   req,
+	}, &out)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListRuns 获取项目中的异步运行，sessionID 不为空时只返回该会话的运行
+func (p *Project) ListRuns(ctx context.Context, sessionID string) (*models.RunsResponse, error) {
+	var out models.RunsResponse
+	if err := p.get(ctx, "/run", p.query("sessionID", sessionID), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetRun 获取异步运行的状态
+func (p *Project) GetRun(ctx context.Context, id string) (*models.RunResponse, error) {
+	var out models.RunResponse
+	if err := p.get(ctx, "/run/"+url.PathEscape(id), p.query(), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CancelRun 取消异步运行，返回取消后的状态
+func (p *Project) CancelRun(ctx context.Context, id string) (*models.RunResponse, error) {
+	var out models.RunResponse
+	if err := p.send(ctx, http.MethodDelete, "/run/"+url.PathEscape(id), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
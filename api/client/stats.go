@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/crush/api/models"
+)
+
+// StatsOptions 统计的筛选条件，零值表示不筛选
+type StatsOptions struct {
+	// From 开始日期（UTC，包含），只使用日期部分
+	From time.Time
+	// To 结束日期（UTC，包含），只使用日期部分
+	To time.Time
+	// Model 只统计有该模型回复的会话
+	Model string
+	// SessionLimit 返回的费用最高的会话数，0 表示使用服务器默认值
+	SessionLimit int
+}
+
+// query 在 q 中设置筛选参数
+func (o StatsOptions) query(q url.Values) url.Values {
+	if !o.From.IsZero() {
+		q.Set("from", o.From.UTC().Format(time.DateOnly))
+	}
+	if !o.To.IsZero() {
+		q.Set("to", o.To.UTC().Format(time.DateOnly))
+	}
+	if o.Model != "" {
+		q.Set("model", o.Model)
+	}
+	if o.SessionLimit > 0 {
+		q.Set("session_limit", strconv.Itoa(o.SessionLimit))
+	}
+	return q
+}
+
+// Stats 获取项目的用量和费用统计
+func (p *Project) Stats(ctx context.Context, opts StatsOptions) (*models.StatsResponse, error) {
+	var out models.StatsResponse
+	if err := p.get(ctx, "/stats", opts.query(p.query()), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GlobalStats 获取所有项目的用量和费用统计
+func (c *Client) GlobalStats(ctx context.Context, opts StatsOptions) (*models.GlobalStatsResponse, error) {
+	var out models.GlobalStatsResponse
+	req := request{method: http.MethodGet, path: "/global/stats", query: opts.query(url.Values{})}
+	if err := c.do(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
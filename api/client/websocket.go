@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/crush/api/models"
+	"github.com/gorilla/websocket"
+)
+
+// EventCommandResult WebSocket 命令结果的事件类型，Properties 为 models.WSCommandResult
+const EventCommandResult = models.WSEventCommandResult
+
+// ConnectOptions WebSocket 连接选项
+type ConnectOptions struct {
+	// SessionIDs 初始订阅的会话，为空时接收所有会话的事件
+	SessionIDs []string
+	// LastEventID 最后收到的事件 ID，不为空时服务器补发之后的事件
+	LastEventID string
+}
+
+// Conn WebSocket 连接，同时接收事件和发送命令
+//
+// 服务器通过 ping 检测连接，ping 只在 Next 中处理，因此需要持续调用 Next 读取消息。
+// Next 只能在一个 goroutine 中调用，Send 和 Close 可以并发调用。
+type Conn struct {
+	conn *websocket.Conn
+
+	mu sync.Mutex // 保护写操作
+}
+
+// Connect 建立项目的 WebSocket 连接
+// 连接断开后不会自动重连，可以携带最后收到的事件 ID 重新调用 Connect 补发断线期间的事件
+func (p *Project) Connect(ctx context.Context, opts ConnectOptions) (*Conn, error) {
+	c := p.client
+	u := c.baseURL.JoinPath("/ws")
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	default:
+		u.Scheme = "ws"
+	}
+	q := p.query("last_event_id", opts.LastEventID)
+	if len(opts.SessionIDs) > 0 {
+		q.Set("session_id", strings.Join(opts.SessionIDs, ","))
+	}
+	u.RawQuery = q.Encode()
+
+	header := http.Header{}
+	if c.userAgent != "" {
+		header.Set("User-Agent", c.userAgent)
+	}
+	if c.token != "" {
+		header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.apiKey != "" {
+		header.Set("X-API-Key", c.apiKey)
+	}
+
+	dialer := *websocket.DefaultDialer
+	// 沿用 http.Client 的代理和 TLS 配置
+	if t, ok := c.httpClient.Transport.(*http.Transport); ok {
+		dialer.Proxy = t.Proxy
+		dialer.TLSClientConfig = t.TLSClientConfig
+	}
+
+	conn, resp, err := dialer.DialContext(ctx, u.String(), header)
+	if err != nil {
+		if errors.Is(err, websocket.ErrBadHandshake) && resp != nil {
+			defer resp.Body.Close()
+			return nil, parseAPIError(resp)
+		}
+		return nil, err
+	}
+	return &Conn{conn: conn}, nil
+}
+
+// Next 读取下一条消息，包括事件和命令结果，连接关闭时返回错误
+func (c *Conn) Next() (Event, error) {
+	var msg struct {
+		ID         string          `json:"id"`
+		Type       string          `json:"type"`
+		Properties json.RawMessage `json:"properties"`
+	}
+	if err := c.conn.ReadJSON(&msg); err != nil {
+		return Event{}, err
+	}
+	return Event{ID: msg.ID, Type: msg.Type, Properties: msg.Properties}, nil
+}
+
+// Send 发送命令，结果通过 Next 以 command.result 事件返回，可用 cmd.ID 对应
+func (c *Conn) Send(cmd models.WSCommand) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn.WriteJSON(cmd)
+}
+
+// Close 通知服务器并关闭连接
+func (c *Conn) Close() error {
+	c.mu.Lock()
+	_ = c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	c.mu.Unlock()
+	return c.conn.Close()
+}
@@ -6,21 +6,28 @@ import (
 	"log/slog"
 	"os"
 	"sync"
+	"time"
 
 	internalapp "github.com/charmbracelet/crush/internal/app"
 	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/csync"
 	"github.com/charmbracelet/crush/internal/db"
 	"github.com/charmbracelet/crush/internal/projects"
+	"github.com/charmbracelet/crush/internal/shell"
 )
 
 // AppManager 管理不同项目的 app 实例
 type AppManager struct {
 	apps map[string]*internalapp.App
 	mu   sync.RWMutex
+
+	// lastUsed 记录每个项目最近一次被访问的时间，用于闲置回收和 LRU 淘汰
+	lastUsed *csync.Map[string, time.Time]
 }
 
 var globalAppManager = &AppManager{
-	apps: make(map[string]*internalapp.App),
+	apps:     make(map[string]*internalapp.App),
+	lastUsed: csync.NewMap[string, time.Time](),
 }
 
 // createAppInstance 创建 app 实例的辅助方法
@@ -63,7 +70,8 @@ func (am *AppManager) createAppInstance(ctx context.Context, projectPath string)
 	slog.Info("Database connection established", "project", projectPath)
 
 	// 创建 app 实例
-	appInstance, err := internalapp.New(ctx, conn, cfg)
+	// app 实例的生命周期长于触发创建的请求，LSP 等后台任务不能随请求结束而取消
+	appInstance, err := internalapp.New(context.WithoutCancel(ctx), conn, cfg)
 	if err != nil {
 		conn.Close()
 		return nil, fmt.Errorf("failed to create app instance: %w", err)
@@ -85,8 +93,10 @@ func (h *Handlers) DisposeProject(ctx context.Context, projectPath string) error
 	}
 
 	// 关闭 app 实例
+	stopEventHub(projectPath, disposeReasonRequested)
 	appInstance.Shutdown()
 	delete(globalAppManager.apps, projectPath)
+	globalAppManager.lastUsed.Del(projectPath)
 	slog.Info("Disposed project app instance", "project", projectPath)
 
 	return nil
@@ -100,6 +110,7 @@ func (h *Handlers) GetAppForProject(ctx context.Context, projectPath string) (*i
 	globalAppManager.mu.RUnlock()
 
 	if ok {
+		globalAppManager.touch(projectPath)
 		return appInstance, nil
 	}
 
@@ -109,6 +120,7 @@ func (h *Handlers) GetAppForProject(ctx context.Context, projectPath string) (*i
 
 	// Double-check pattern: another goroutine might have created it while we waited
 	if appInstance, ok := globalAppManager.apps[projectPath]; ok {
+		globalAppManager.touch(projectPath)
 		return appInstance, nil
 	}
 
@@ -118,9 +130,16 @@ func (h *Handlers) GetAppForProject(ctx context.Context, projectPath string) (*i
 		return nil, err
 	}
 
+	// 无人回复的权限请求超时后拒绝
+	appInstance.Permissions.SetRequestTimeout(*h.opts.PermissionTimeout)
+
 	globalAppManager.apps[projectPath] = appInstance
+	globalAppManager.touch(projectPath)
 	slog.Info("Auto-created project app instance", "project", projectPath)
 
+	// 实例数量超过上限时淘汰最久未使用的空闲实例
+	h.evictForCapacity(projectPath)
+
 	return appInstance, nil
 }
 
@@ -130,10 +149,11 @@ func (h *Handlers) GetAppForSession(ctx context.Context, sessionID string) (*int
 	defer globalAppManager.mu.RUnlock()
 
 	// 遍历所有app实例，查找包含该会话的实例
-	for _, appInstance := range globalAppManager.apps {
+	for path, appInstance := range globalAppManager.apps {
 		_, err := appInstance.Sessions.Get(ctx, sessionID)
 		if err == nil {
 			// 找到了会话
+			globalAppManager.touch(path)
 			return appInstance, nil
 		}
 	}
@@ -147,6 +167,7 @@ func (h *Handlers) DisposeAll(ctx context.Context) ([]string, error) {
 	defer globalAppManager.mu.Unlock()
 
 	disposedProjects := make([]string, 0, len(globalAppManager.apps))
+	stopAllEventHubs(disposeReasonRequested)
 
 	for path, appInstance := range globalAppManager.apps {
 		appInstance.Shutdown()
@@ -155,6 +176,96 @@ func (h *Handlers) DisposeAll(ctx context.Context) ([]string, error) {
 	}
 
 	globalAppManager.apps = make(map[string]*internalapp.App)
+	globalAppManager.lastUsed.Reset(map[string]time.Time{})
 
 	return disposedProjects, nil
 }
+
+// touch 记录项目最近一次被访问的时间
+func (am *AppManager) touch(projectPath string) {
+	am.lastUsed.Set(projectPath, time.Now())
+}
+
+// isAppBusy 判断项目实例是否正在使用，忙碌的实例不会被回收：
+// 有正在运行或排队的 agent 请求、有运行中的后台任务（回收会终止 agent 启动的开发服务器等进程），
+// 或仍有 SSE/WebSocket 订阅者
+func isAppBusy(projectPath string, appInstance *internalapp.App) bool {
+	if appInstance.AgentCoordinator != nil && appInstance.AgentCoordinator.IsBusy() {
+		return true
+	}
+	return shell.GetBackgroundShellManager().HasRunningInDir(projectPath) || hasEventSubscribers(projectPath)
+}
+
+// evictForCapacity 实例数量超过上限时淘汰最久未使用且空闲的实例，keep 为刚创建的实例
+// 调用方需持有 globalAppManager.mu 写锁。所有实例都忙碌时允许暂时超过上限
+func (h *Handlers) evictForCapacity(keep string) {
+	limit := h.opts.MaxInstances
+	if limit <= 0 {
+		return
+	}
+
+	for len(globalAppManager.apps) > limit {
+		var (
+			victimPath string
+			victimUsed time.Time
+		)
+		for path, appInstance := range globalAppManager.apps {
+			if path == keep || isAppBusy(path, appInstance) {
+				continue
+			}
+			used, _ := globalAppManager.lastUsed.Get(path)
+			if victimPath == "" || used.Before(victimUsed) {
+				victimPath, victimUsed = path, used
+			}
+		}
+		if victimPath == "" {
+			slog.Warn("All project app instances are busy, exceeding instance limit", "limit", limit, "instances", len(globalAppManager.apps))
+			return
+		}
+
+		appInstance := globalAppManager.apps[victimPath]
+		delete(globalAppManager.apps, victimPath)
+		globalAppManager.lastUsed.Del(victimPath)
+		slog.Info("Evicting least recently used project app instance", "project", victimPath, "last_used", victimUsed)
+
+		// 关闭 LSP 等可能耗时，不在持有锁时等待
+		stopEventHub(victimPath, disposeReasonCapacity)
+		go appInstance.Shutdown()
+	}
+}
+
+// evictIdleApps 释放闲置超过 InstanceIdleTTL 且空闲的实例
+func (h *Handlers) evictIdleApps() {
+	ttl := h.opts.InstanceIdleTTL
+	now := time.Now()
+
+	evicted := make(map[string]*internalapp.App)
+	globalAppManager.mu.Lock()
+	for path, appInstance := range globalAppManager.apps {
+		used, _ := globalAppManager.lastUsed.Get(path)
+		if now.Sub(used) < ttl || isAppBusy(path, appInstance) {
+			continue
+		}
+		delete(globalAppManager.apps, path)
+		globalAppManager.lastUsed.Del(path)
+		evicted[path] = appInstance
+	}
+	globalAppManager.mu.Unlock()
+
+	for path, appInstance := range evicted {
+		slog.Info("Evicting idle project app instance", "project", path, "idle_ttl", ttl)
+		stopEventHub(path, disposeReasonIdle)
+		appInstance.Shutdown()
+	}
+}
+
+// runIdleEviction 定期检查并释放闲置实例
+func (h *Handlers) runIdleEviction() {
+	interval := min(h.opts.InstanceIdleTTL/4, time.Minute)
+	ticker := time.NewTicker(max(interval, time.Second))
+	defer ticker.Stop()
+
+	for range ticker.C {
+		h.evictIdleApps()
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	internalapp "github.com/charmbracelet/crush/internal/app"
+	"github.com/charmbracelet/crush/internal/shell"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvictionKeepsProjectsWithRunningJobs(t *testing.T) {
+	busyDir, newDir := t.TempDir(), t.TempDir()
+
+	globalAppManager.mu.Lock()
+	prevApps := globalAppManager.apps
+	globalAppManager.apps = map[string]*internalapp.App{
+		busyDir: {},
+		newDir:  {},
+	}
+	globalAppManager.mu.Unlock()
+	globalAppManager.lastUsed.Set(busyDir, time.Now().Add(-time.Hour))
+	globalAppManager.lastUsed.Set(newDir, time.Now())
+	t.Cleanup(func() {
+		globalAppManager.mu.Lock()
+		globalAppManager.apps = prevApps
+		globalAppManager.mu.Unlock()
+		globalAppManager.lastUsed.Del(busyDir)
+		globalAppManager.lastUsed.Del(newDir)
+	})
+
+	// 例如 agent 启动的开发服务器
+	job, err := shell.GetBackgroundShellManager().Start(t.Context(), busyDir, nil, "sleep 10", "dev server")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = shell.GetBackgroundShellManager().Kill(job.ID) })
+
+	h := &Handlers{opts: Options{InstanceIdleTTL: time.Minute, MaxInstances: 1}}
+	require.True(t, isAppBusy(busyDir, globalAppManager.apps[busyDir]))
+	require.False(t, isAppBusy(newDir, globalAppManager.apps[newDir]))
+
+	h.evictIdleApps()
+	require.Contains(t, globalAppManager.apps, busyDir, "idle eviction must keep projects with running jobs")
+
+	globalAppManager.mu.Lock()
+	h.evictForCapacity(newDir)
+	globalAppManager.mu.Unlock()
+	require.Len(t, globalAppManager.apps, 2, "capacity eviction must keep projects with running jobs")
+	require.False(t, job.IsDone())
+
+	require.NoError(t, shell.GetBackgroundShellManager().Kill(job.ID))
+	require.False(t, isAppBusy(busyDir, globalAppManager.apps[busyDir]))
+}
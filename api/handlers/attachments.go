@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/crush/api/models"
+	"github.com/charmbracelet/crush/internal/agent"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/message"
+)
+
+// 附件大小限制（与 TUI 一致，单个附件 5MB）
+const (
+	maxAttachmentSize      = 5 * 1024 * 1024
+	maxTotalAttachmentSize = 20 * 1024 * 1024
+)
+
+// errAttachmentTooLarge 附件超过大小限制
+var errAttachmentTooLarge = errors.New("attachment too large")
+
+// partsToAttachments 将请求中的 file 部分转换为 agent 附件
+// 内容可以是 base64 数据，也可以是相对项目目录的文件路径
+func partsToAttachments(workingDir string, parts []models.PartInput) ([]message.Attachment, error) {
+	var attachments []message.Attachment
+	total := 0
+	for i, part := range parts {
+		p, ok := part.(models.FilePartInput)
+		if !ok {
+			continue
+		}
+
+		attachment, err := fileToAttachment(workingDir, p)
+		if err != nil {
+			return nil, fmt.Errorf("parts[%d]: %w", i, err)
+		}
+		total += len(attachment.Content)
+		if total > maxTotalAttachmentSize {
+			return nil, fmt.Errorf("%w: total size exceeds %d MB", errAttachmentTooLarge, maxTotalAttachmentSize/1024/1024)
+		}
+		attachments = append(attachments, attachment)
+	}
+	return attachments, nil
+}
+
+// fileToAttachment 读取单个 file 部分的内容并识别 MIME 类型
+func fileToAttachment(workingDir string, p models.FilePartInput) (message.Attachment, error) {
+	var (
+		content  []byte
+		filePath string
+	)
+	switch {
+	case p.Data != "" && p.Path != "":
+		return message.Attachment{}, errors.New("file part must have either data or path, not both")
+	case p.Data != "":
+		if base64.StdEncoding.DecodedLen(len(p.Data)) > maxAttachmentSize+2 {
+			return message.Attachment{}, fmt.Errorf("%w: %s exceeds %d MB", errAttachmentTooLarge, p.Name, maxAttachmentSize/1024/1024)
+		}
+		data, err := base64.StdEncoding.DecodeString(p.Data)
+		if err != nil {
+			return message.Attachment{}, fmt.Errorf("invalid base64 data: %w", err)
+		}
+		content = data
+		filePath = p.Name
+	case p.Path != "":
+		fullPath, err := resolveProjectPath(workingDir, p.Path)
+		if err != nil {
+			return message.Attachment{}, err
+		}
+		info, err := os.Stat(fullPath)
+		if err != nil {
+			return message.Attachment{}, fmt.Errorf("file not found: %s", p.Path)
+		}
+		if info.IsDir() {
+			return message.Attachment{}, fmt.Errorf("path is a directory: %s", p.Path)
+		}
+		if info.Size() > maxAttachmentSize {
+			return message.Attachment{}, fmt.Errorf("%w: %s exceeds %d MB", errAttachmentTooLarge, p.Path, maxAttachmentSize/1024/1024)
+		}
+		data, err := os.ReadFile(fullPath)
+		if err != nil {
+			return message.Attachment{}, fmt.Errorf("failed to read %s: %w", p.Path, err)
+		}
+		content = data
+		filePath = p.Path
+	default:
+		return message.Attachment{}, errors.New("file part requires data or path")
+	}
+
+	if len(content) > maxAttachmentSize {
+		return message.Attachment{}, fmt.Errorf("%w: %s exceeds %d MB", errAttachmentTooLarge, filePath, maxAttachmentSize/1024/1024)
+	}
+
+	fileName := p.Name
+	if fileName == "" {
+		fileName = filepath.Base(filePath)
+	}
+	if filePath == "" {
+		filePath = fileName
+	}
+
+	return message.Attachment{
+		FilePath: filePath,
+		FileName: fileName,
+		MimeType: sniffMIMEType(content, fileName, p.Mime),
+		Content:  content,
+	}, nil
+}
+
+// sniffMIMEType 根据内容识别 MIME 类型，无法识别时依次使用客户端声明的类型和扩展名
+func sniffMIMEType(content []byte, fileName, declared string) string {
+	mimeBufferSize := min(512, len(content))
+	mimeType := http.DetectContentType(content[:mimeBufferSize])
+	if mimeType != "application/octet-stream" {
+		return mimeType
+	}
+	if declared != "" {
+		return declared
+	}
+	return models.DetectMIMEType(strings.ToLower(fileName))
+}
+
+// modelSupportsImages 判断本次运行使用的模型是否支持图片等二进制附件
+// 返回模型名称便于生成错误信息
+func modelSupportsImages(cfg *config.Config, opts agent.RunOptions) (string, bool) {
+	selected, ok := cfg.Models[config.SelectedModelTypeLarge]
+	if opts.LargeModel != nil {
+		selected, ok = *opts.LargeModel, true
+	}
+	if !ok {
+		return "", false
+	}
+	model := cfg.GetModel(selected.Provider, selected.Model)
+	if model == nil {
+		return selected.Model, false
+	}
+	return model.Name, model.SupportsImages
+}
+
+// hasBinaryAttachment 判断附件中是否包含非文本内容
+func hasBinaryAttachment(attachments []message.Attachment) bool {
+	return slices.ContainsFunc(attachments, func(a message.Attachment) bool {
+		return !a.IsText()
+	})
+}
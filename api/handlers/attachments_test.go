@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/crush/api/models"
+	"github.com/charmbracelet/crush/internal/agent"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+var testPNG = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestSniffMIMEType(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  []byte
+		fileName string
+		declared string
+		want     string
+	}{
+		{"png content wins over declared", testPNG, "image.txt", "text/plain", "image/png"},
+		{"text content", []byte("hello"), "notes", "", "text/plain; charset=utf-8"},
+		{"empty content", nil, "empty.png", "", "text/plain; charset=utf-8"},
+		{"unknown content uses declared", []byte{0x00, 0x01, 0x02}, "data.png", "application/x-custom", "application/x-custom"},
+		{"unknown content uses extension", []byte{0x00, 0x01, 0x02}, "ARCHIVE.ZIP", "", "application/zip"},
+		{"unknown content and extension", []byte{0x00, 0x01, 0x02}, "data.bin", "", "application/octet-stream"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, sniffMIMEType(tt.content, tt.fileName, tt.declared))
+		})
+	}
+}
+
+func TestFileToAttachment(t *testing.T) {
+	workingDir := t.TempDir()
+	outsideDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(workingDir, "image.png"), testPNG, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(workingDir, "large.bin"), make([]byte, maxAttachmentSize+1), 0o644))
+	require.NoError(t, os.Mkdir(filepath.Join(workingDir, "dir"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(outsideDir, "secret.txt"), []byte("secret"), 0o644))
+	require.NoError(t, os.Symlink(outsideDir, filepath.Join(workingDir, "link")))
+
+	encode := func(data []byte) string { return base64.StdEncoding.EncodeToString(data) }
+
+	tests := []struct {
+		name     string
+		part     models.FilePartInput
+		wantErr  string
+		tooLarge bool
+		wantPath string
+		wantMIME string
+	}{
+		{name: "data", part: models.FilePartInput{Data: encode(testPNG), Name: "shot.png"}, wantPath: "shot.png", wantMIME: "image/png"},
+		{name: "data at size limit", part: models.FilePartInput{Data: encode(make([]byte, maxAttachmentSize)), Name: "max.bin"}, wantPath: "max.bin", wantMIME: "application/octet-stream"},
+		{name: "data over size limit", part: models.FilePartInput{Data: encode(make([]byte, maxAttachmentSize+1)), Name: "big.bin"}, tooLarge: true},
+		{name: "invalid data", part: models.FilePartInput{Data: "not base64!"}, wantErr: "invalid base64 data"},
+		{name: "data and path", part: models.FilePartInput{Data: encode(testPNG), Path: "image.png"}, wantErr: "either data or path"},
+		{name: "neither data nor path", part: models.FilePartInput{Name: "empty"}, wantErr: "requires data or path"},
+		{name: "relative path", part: models.FilePartInput{Path: "image.png"}, wantPath: "image.png", wantMIME: "image/png"},
+		{name: "absolute path in project", part: models.FilePartInput{Path: filepath.Join(workingDir, "image.png")}, wantPath: filepath.Join(workingDir, "image.png"), wantMIME: "image/png"},
+		{name: "path over size limit", part: models.FilePartInput{Path: "large.bin"}, tooLarge: true},
+		{name: "missing path", part: models.FilePartInput{Path: "missing.png"}, wantErr: "file not found"},
+		{name: "directory", part: models.FilePartInput{Path: "dir"}, wantErr: "path is a directory"},
+		{name: "path traversal", part: models.FilePartInput{Path: "../secret.txt"}, wantErr: "path traversal"},
+		{name: "absolute path outside project", part: models.FilePartInput{Path: filepath.Join(outsideDir, "secret.txt")}, wantErr: "outside the project"},
+		{name: "symlink outside project", part: models.FilePartInput{Path: "link/secret.txt"}, wantErr: "resolves outside the project"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attachment, err := fileToAttachment(workingDir, tt.part)
+			switch {
+			case tt.tooLarge:
+				require.ErrorIs(t, err, errAttachmentTooLarge)
+			case tt.wantErr != "":
+				require.ErrorContains(t, err, tt.wantErr)
+			default:
+				require.NoError(t, err)
+				require.Equal(t, tt.wantPath, attachment.FilePath)
+				require.Equal(t, tt.wantMIME, attachment.MimeType)
+			}
+		})
+	}
+}
+
+func TestPartsToAttachmentsTotalSize(t *testing.T) {
+	part := models.FilePartInput{Data: base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{0x00}, maxAttachmentSize)), Name: "part.bin"}
+	text := models.TextPartInput{Text: "hello"}
+
+	tests := []struct {
+		name    string
+		files   int
+		wantErr bool
+	}{
+		{"no files", 0, false},
+		{"at total limit", maxTotalAttachmentSize / maxAttachmentSize, false},
+		{"over total limit", maxTotalAttachmentSize/maxAttachmentSize + 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := []models.PartInput{text}
+			for range tt.files {
+				parts = append(parts, part)
+			}
+			attachments, err := partsToAttachments(t.TempDir(), parts)
+			if tt.wantErr {
+				require.ErrorIs(t, err, errAttachmentTooLarge)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, attachments, tt.files)
+		})
+	}
+}
+
+func TestModelSupportsImages(t *testing.T) {
+	cfg := newTestConfig(t, map[string]any{
+		"providers": map[string]any{
+			"custom": map[string]any{
+				"type":     "openai-compat",
+				"base_url": "https://example.com/v1",
+				"api_key":  "sk-test",
+				"models": []any{
+					map[string]any{"id": "text", "name": "Text"},
+					map[string]any{"id": "vision", "name": "Vision", "supports_attachments": true},
+				},
+			},
+		},
+		"models": map[string]any{
+			"large": map[string]any{"provider": "custom", "model": "text"},
+			"small": map[string]any{"provider": "custom", "model": "text"},
+		},
+	})
+
+	tests := []struct {
+		name      string
+		cfg       *config.Config
+		override  *config.SelectedModel
+		wantName  string
+		wantImage bool
+	}{
+		{"configured model", cfg, nil, "Text", false},
+		{"override with vision model", cfg, &config.SelectedModel{Provider: "custom", Model: "vision"}, "Vision", true},
+		{"override with unknown model", cfg, &config.SelectedModel{Provider: "custom", Model: "missing"}, "missing", false},
+		{"no large model", &config.Config{}, nil, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, ok := modelSupportsImages(tt.cfg, agent.RunOptions{LargeModel: tt.override})
+			require.Equal(t, tt.wantName, name)
+			require.Equal(t, tt.wantImage, ok)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/charmbracelet/crush/api/models"
+	"github.com/charmbracelet/crush/internal/audit"
+	hertzapp "github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// defaultAuditLimit 未指定 limit 时返回的审计日志条数
+const defaultAuditLimit = 100
+
+// HandleListAudit 查询审计日志
+//
+//	@Summary		查询审计日志
+//	@Description	返回项目中工具执行和权限决定的审计日志，按时间倒序，可通过 limit/offset 分页，total 为符合条件的条目总数。
+//	@Description	工具执行（kind 为 tool）记录参数、状态、退出码和起止时间；权限决定（kind 为 permission）记录结果（granted、granted_session 或 denied）和决定方：
+//	@Description	tui、API Key 或 Token 的标识（如 api_key:ci）、未启用认证时的 api，或自动决定的 auto，自动决定的原因记录在 reason 中。
+//	@Description	通过 API 发起的操作在 actor 中记录调用方（格式同 decider），包括 agent 运行中的工具执行，以及直接通过 API 执行的 shell 命令（bash）、文件修改（file_edit）和 MCP 工具调用。
+//	@Tags			Audit
+//	@Produce		json
+//	@Param			directory	query		string	true	"项目路径"
+//	@Param			sessionID	query		string	false	"按会话 ID 过滤"
+//	@Param			kind		query		string	false	"条目类型"	Enums(tool, permission)
+//	@Param			tool		query		string	false	"工具名称"
+//	@Param			decider		query		string	false	"权限的决定方"
+//	@Param			actor		query		string	false	"发起操作的调用方"
+//	@Param			from		query		string	false	"开始日期（YYYY-MM-DD，包含）"
+//	@Param			to			query		string	false	"结束日期（YYYY-MM-DD，包含）"
+//	@Param			limit		query		int		false	"单页条数，默认 100，最大 1000"
+//	@Param			offset		query		int		false	"跳过的条数"
+//	@Success		200			{object}	models.AuditResponse
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		500			{object}	map[string]interface{}
+//	@Router			/audit [get]
+func (h *Handlers) HandleListAudit(c context.Context, ctx *hertzapp.RequestContext) {
+	from, to, ok := parseDateRange(c, ctx)
+	if !ok {
+		return
+	}
+	kind := audit.Kind(ctx.Query("kind"))
+	if kind != "" && kind != audit.KindTool && kind != audit.KindPermission {
+		WriteError(c, ctx, "INVALID_REQUEST", "kind must be tool or permission", consts.StatusBadRequest)
+		return
+	}
+	appInstance, ok := h.projectApp(c, ctx)
+	if !ok {
+		return
+	}
+
+	limit, offset := ParsePaginationParams(ctx, defaultAuditLimit, maxPageLimit)
+	entries, total, err := appInstance.Audit.List(c, audit.Filter{
+		SessionID: string(ctx.Query("sessionID")),
+		Kind:      kind,
+		ToolName:  string(ctx.Query("tool")),
+		Decider:   string(ctx.Query("decider")),
+		Actor:     string(ctx.Query("actor")),
+		From:      from,
+		To:        to,
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to list audit entries: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+	WriteJSON(c, ctx, consts.StatusOK, models.AuditResponse{Entries: entries, Total: total})
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/crush/api/models"
+	"github.com/charmbracelet/crush/internal/commands"
+	hertzapp "github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// HandleListCommands 获取可执行的命令 (参考 OpenCode: /command)
+//
+//	@Summary		获取命令列表
+//	@Description	返回用户命令（~/.config/crush/commands 和 ~/.crush/commands）、项目命令（数据目录下的 commands）和已连接 MCP 服务器提供的 prompt，以及它们的参数。
+//	@Description	MCP prompt 的名称为 "服务器:prompt"，列表只包含已连接的 MCP 服务器。
+//	@Tags			Session
+//	@Produce		json
+//	@Param			directory	query		string	true	"项目路径"
+//	@Success		200			{array}		models.Command
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		500			{object}	map[string]interface{}
+//	@Router			/command [get]
+func (h *Handlers) HandleListCommands(c context.Context, ctx *hertzapp.RequestContext) {
+	appInstance, ok := h.projectApp(c, ctx)
+	if !ok {
+		return
+	}
+
+	customCommands, err := commands.LoadCustomCommands(appInstance.Config())
+	if err != nil {
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to load commands: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+	mcpPrompts, err := commands.LoadMCPPrompts()
+	if err != nil {
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to load MCP prompts: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+
+	result := make([]models.Command, 0, len(customCommands)+len(mcpPrompts))
+	for _, cmd := range customCommands {
+		source := models.CommandSourceUser
+		if strings.HasPrefix(cmd.ID, "project:") {
+			source = models.CommandSourceProject
+		}
+		result = append(result, models.Command{
+			Name:      cmd.ID,
+			Source:    source,
+			Template:  cmd.Content,
+			Arguments: toCommandArguments(cmd.Arguments),
+		})
+	}
+	// MCP prompt 来自 map，排序保证顺序稳定
+	slices.SortFunc(mcpPrompts, func(a, b commands.MCPPrompt) int { return cmp.Compare(a.ID, b.ID) })
+	for _, prompt := range mcpPrompts {
+		result = append(result, models.Command{
+			Name:        prompt.ID,
+			Source:      models.CommandSourceMCP,
+			Title:       cmp.Or(prompt.Title, prompt.PromptID),
+			Description: prompt.Description,
+			MCP:         prompt.ClientID,
+			Arguments:   toCommandArguments(prompt.Arguments),
+		})
+	}
+
+	WriteJSON(c, ctx, consts.StatusOK, result)
+}
+
+// findMCPPrompt 按 "服务器:prompt" 查找已连接 MCP 服务器的 prompt
+func findMCPPrompt(name string) (commands.MCPPrompt, bool) {
+	name = strings.TrimPrefix(name, "/")
+	mcpPrompts, _ := commands.LoadMCPPrompts()
+	idx := slices.IndexFunc(mcpPrompts, func(p commands.MCPPrompt) bool { return p.ID == name })
+	if idx < 0 {
+		return commands.MCPPrompt{}, false
+	}
+	return mcpPrompts[idx], true
+}
+
+// toCommandArguments 转换为 API 的命令参数
+func toCommandArguments(args []commands.Argument) []models.CommandArgument {
+	out := make([]models.CommandArgument, 0, len(args))
+	for _, arg := range args {
+		out = append(out, models.CommandArgument{
+			Name:        arg.ID,
+			Title:       arg.Title,
+			Description: arg.Description,
+			Required:    arg.Required,
+		})
+	}
+	return out
+}
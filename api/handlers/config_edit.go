@@ -0,0 +1,511 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/crush/api/middleware"
+	"github.com/charmbracelet/crush/api/models"
+	internalapp "github.com/charmbracelet/crush/internal/app"
+	"github.com/charmbracelet/crush/internal/config"
+	hertzapp "github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// configSectionDefs 可编辑的配置段及其条目在 schema.json 中对应的定义
+var configSectionDefs = map[string]string{
+	models.ConfigSectionProviders:   "ProviderConfig",
+	models.ConfigSectionModels:      "SelectedModel",
+	models.ConfigSectionMCP:         "MCPConfig",
+	models.ConfigSectionLSP:         "LSPConfig",
+	models.ConfigSectionOptions:     "Options",
+	models.ConfigSectionPermissions: "Permissions",
+}
+
+// configKeyedSections 以名称为键的配置段，通过 PUT/DELETE 按条目编辑；
+// 其余配置段是单个对象，通过 PATCH 按字段编辑
+var configKeyedSections = []string{
+	models.ConfigSectionProviders,
+	models.ConfigSectionModels,
+	models.ConfigSectionMCP,
+	models.ConfigSectionLSP,
+}
+
+// configSecretPaths 各配置段条目中的密钥字段，"*" 匹配对象的所有键
+var configSecretPaths = map[string][][]string{
+	models.ConfigSectionProviders: {{"api_key"}, {"oauth", "access_token"}, {"oauth", "refresh_token"}, {"extra_headers", "*"}},
+	models.ConfigSectionMCP:       {{"env", "*"}, {"headers", "*"}},
+	models.ConfigSectionLSP:       {{"env", "*"}},
+}
+
+// configNameRe 条目名称，写入配置文件时作为 JSON 路径的一部分
+var configNameRe = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// maskedSecretPrefix 脱敏后的密钥前缀
+const maskedSecretPrefix = "****"
+
+// HandleGetConfigSection 获取配置段
+//
+//	@Summary		获取配置段
+//	@Description	获取项目当前生效的 providers、models、mcp、lsp、options 或 permissions 配置，密钥已脱敏
+//	@Tags			Config
+//	@Produce		json
+//	@Param			directory	query		string	true	"项目路径"
+//	@Param			section		path		string	true	"配置段"	Enums(providers, models, mcp, lsp, options, permissions)
+//	@Success		200			{object}	models.ConfigSectionResponse
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		500			{object}	map[string]interface{}
+//	@Router			/project/config/{section} [get]
+func (h *Handlers) HandleGetConfigSection(c context.Context, ctx *hertzapp.RequestContext) {
+	appInstance, section, ok := h.configApp(c, ctx, false)
+	if !ok {
+		return
+	}
+	writeConfigSection(c, ctx, appInstance.Config(), section)
+}
+
+// HandlePatchConfigSection 修改 options 或 permissions 配置段
+//
+//	@Summary		修改配置段字段
+//	@Description	按字段修改 options 或 permissions 配置段：请求体中的每个字段写入数据目录下的 crush.json，值为 null 时删除该字段。
+//	@Description	修改前按 schema.json 校验，修改后重新加载配置并更新 Agent，不会重启项目实例。部分选项（如 data_directory）需要重启后生效。
+//	@Description	配置文件为所有项目共用，受项目范围限制的 API Key 不能修改。
+//	@Tags			Config
+//	@Accept			json
+//	@Produce		json
+//	@Param			directory	query		string					true	"项目路径"
+//	@Param			section		path		string					true	"配置段"	Enums(options, permissions)
+//	@Param			request		body		map[string]interface{}	true	"要修改的字段"
+//	@Success		200			{object}	models.ConfigSectionResponse
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		403			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		500			{object}	map[string]interface{}
+//	@Router			/project/config/{section} [patch]
+func (h *Handlers) HandlePatchConfigSection(c context.Context, ctx *hertzapp.RequestContext) {
+	appInstance, section, ok := h.configApp(c, ctx, true)
+	if !ok {
+		return
+	}
+	if slices.Contains(configKeyedSections, section) {
+		WriteError(c, ctx, "INVALID_REQUEST", "Section "+section+" is edited per entry with PUT /project/config/"+section+"/{name}", consts.StatusBadRequest)
+		return
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(ctx.Request.Body(), &fields); err != nil || fields == nil {
+		WriteError(c, ctx, "INVALID_REQUEST", "Request body must be a JSON object", consts.StatusBadRequest)
+		return
+	}
+	set := make(map[string]json.RawMessage, len(fields))
+	for key, value := range fields {
+		if string(value) != "null" {
+			set[key] = value
+		}
+	}
+	data, _ := json.Marshal(set)
+	if err := config.ValidateSchema(configSectionDefs[section], data); err != nil {
+		WriteError(c, ctx, "SCHEMA_VALIDATION_FAILED", err.Error(), consts.StatusBadRequest)
+		return
+	}
+
+	err := appInstance.UpdateConfig(c, func(cfg *config.Config) error {
+		for _, key := range slices.Sorted(maps.Keys(fields)) {
+			path := section + "." + escapeConfigPath(key)
+			if value, ok := set[key]; ok {
+				if err := cfg.SetConfigField(path, value); err != nil {
+					return err
+				}
+			} else if cfg.HasConfigField(path) {
+				if err := cfg.RemoveConfigField(path); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		writeConfigUpdateError(c, ctx, err)
+		return
+	}
+	reloadConfig(c, appInstance)
+	writeConfigSection(c, ctx, appInstance.Config(), section)
+}
+
+// HandlePutConfigEntry 添加或替换配置条目
+//
+//	@Summary		添加或替换配置条目
+//	@Description	将 providers、models、mcp 或 lsp 配置段中名为 name 的条目写入数据目录下的 crush.json，整体替换该文件中的原有条目。
+//	@Description	models 的 name 为 large 或 small，模型必须存在。provider 的 api_key 通过 SetProviderAPIKey 保存，请求体中的 oauth 会被忽略。
+//	@Description	值为 "****" 开头的脱敏密钥表示保留原值。修改前按 schema.json 校验，修改后重新加载配置，更新 Agent 模型、重连变更的 MCP 服务器、重启变更的 LSP 服务器，不会重启项目实例。
+//	@Description	配置文件为所有项目共用，受项目范围限制的 API Key 不能修改。
+//	@Tags			Config
+//	@Accept			json
+//	@Produce		json
+//	@Param			directory	query		string					true	"项目路径"
+//	@Param			section		path		string					true	"配置段"	Enums(providers, models, mcp, lsp)
+//	@Param			name		path		string					true	"条目名称"
+//	@Param			request		body		map[string]interface{}	true	"条目内容"
+//	@Success		200			{object}	models.ConfigSectionResponse
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		403			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		500			{object}	map[string]interface{}
+//	@Router			/project/config/{section}/{name} [put]
+func (h *Handlers) HandlePutConfigEntry(c context.Context, ctx *hertzapp.RequestContext) {
+	appInstance, section, name, ok := h.configEntry(c, ctx)
+	if !ok {
+		return
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(ctx.Request.Body(), &entry); err != nil || entry == nil {
+		WriteError(c, ctx, "INVALID_REQUEST", "Request body must be a JSON object", consts.StatusBadRequest)
+		return
+	}
+
+	cfg := appInstance.Config()
+	key := section + "." + name
+	var apiKey string
+	var hasAPIKey bool
+	if section == models.ConfigSectionProviders {
+		// OAuth 令牌只能通过登录流程获取
+		delete(entry, "oauth")
+	}
+	restoreMaskedSecrets(cfg, section, key, entry)
+	if section == models.ConfigSectionProviders {
+		apiKey, hasAPIKey = entry["api_key"].(string)
+	}
+
+	data, _ := json.Marshal(entry)
+	if err := config.ValidateSchema(configSectionDefs[section], data); err != nil {
+		WriteError(c, ctx, "SCHEMA_VALIDATION_FAILED", err.Error(), consts.StatusBadRequest)
+		return
+	}
+
+	var edit, check func(*config.Config) error
+	switch section {
+	case models.ConfigSectionModels:
+		modelType := config.SelectedModelType(name)
+		if modelType != config.SelectedModelTypeLarge && modelType != config.SelectedModelTypeSmall {
+			WriteError(c, ctx, "INVALID_REQUEST", "Model type must be large or small", consts.StatusBadRequest)
+			return
+		}
+		var model config.SelectedModel
+		if err := json.Unmarshal(data, &model); err != nil {
+			WriteError(c, ctx, "INVALID_REQUEST", "Invalid model: "+err.Error(), consts.StatusBadRequest)
+			return
+		}
+		if cfg.GetModel(model.Provider, model.Model) == nil {
+			WriteError(c, ctx, "MODEL_NOT_FOUND", "Model "+model.Model+" not found for provider "+model.Provider, consts.StatusBadRequest)
+			return
+		}
+		edit = func(cfg *config.Config) error { return cfg.UpdatePreferredModel(modelType, model) }
+	case models.ConfigSectionProviders:
+		var provider config.ProviderConfig
+		if err := json.Unmarshal(data, &provider); err != nil {
+			WriteError(c, ctx, "INVALID_REQUEST", "Invalid provider: "+err.Error(), consts.StatusBadRequest)
+			return
+		}
+		delete(entry, "api_key")
+		edit = func(cfg *config.Config) error {
+			oauth := cfg.ConfigField(key + ".oauth")
+			oldKey := cfg.ConfigField(key + ".api_key")
+			if err := cfg.SetConfigField(key, entry); err != nil {
+				return err
+			}
+			// 未修改 api_key 时保留登录获得的 OAuth 令牌，api_key 即其访问令牌
+			if oauth != nil && (!hasAPIKey || string(oldKey) == mustMarshal(apiKey)) {
+				if oldKey != nil {
+					if err := cfg.SetConfigField(key+".api_key", json.RawMessage(oldKey)); err != nil {
+						return err
+					}
+				}
+				return cfg.SetConfigField(key+".oauth", json.RawMessage(oauth))
+			}
+			if !hasAPIKey {
+				return nil
+			}
+			// SetProviderAPIKey 只能更新已加载或已知的 provider
+			if _, ok := cfg.Providers.Get(name); !ok {
+				provider.ID = name
+				cfg.Providers.Set(name, provider)
+			}
+			return cfg.SetProviderAPIKey(name, apiKey)
+		}
+		// 加载配置时会跳过缺少 base_url、models 等字段的自定义 provider
+		check = func(cfg *config.Config) error {
+			if _, ok := cfg.Providers.Get(name); !ok && !provider.Disable {
+				return fmt.Errorf("provider %s was skipped when loading the configuration, check its type, base_url and models", name)
+			}
+			return nil
+		}
+	default:
+		edit = func(cfg *config.Config) error { return cfg.SetConfigField(key, entry) }
+	}
+
+	if err := appInstance.UpdateConfig(c, edit, check); err != nil {
+		writeConfigUpdateError(c, ctx, err)
+		return
+	}
+	reloadConfig(c, appInstance)
+	writeConfigSection(c, ctx, appInstance.Config(), section)
+}
+
+// HandleDeleteConfigEntry 删除配置条目
+//
+//	@Summary		删除配置条目
+//	@Description	从数据目录下的 crush.json 删除 providers、models、mcp 或 lsp 配置段中名为 name 的条目，随后重新加载配置并更新受影响的子系统。
+//	@Description	只定义在其他配置文件（如项目的 crush.json）中的条目不能删除，返回 409。删除 models 条目后恢复默认模型。
+//	@Tags			Config
+//	@Produce		json
+//	@Param			directory	query		string	true	"项目路径"
+//	@Param			section		path		string	true	"配置段"	Enums(providers, models, mcp, lsp)
+//	@Param			name		path		string	true	"条目名称"
+//	@Success		200			{object}	models.ConfigSectionResponse
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		403			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		409			{object}	map[string]interface{}
+//	@Failure		500			{object}	map[string]interface{}
+//	@Router			/project/config/{section}/{name} [delete]
+func (h *Handlers) HandleDeleteConfigEntry(c context.Context, ctx *hertzapp.RequestContext) {
+	appInstance, section, name, ok := h.configEntry(c, ctx)
+	if !ok {
+		return
+	}
+
+	cfg := appInstance.Config()
+	key := section + "." + name
+	if !cfg.HasConfigField(key) {
+		if _, exists := configSectionValue(cfg, section).(map[string]any)[name]; exists {
+			WriteError(c, ctx, "CONFIG_ENTRY_READ_ONLY", "Entry "+name+" is defined in another config file and cannot be deleted here", consts.StatusConflict)
+			return
+		}
+		WriteError(c, ctx, "CONFIG_ENTRY_NOT_FOUND", "Entry "+name+" not found in section "+section, consts.StatusNotFound)
+		return
+	}
+
+	remove := func(cfg *config.Config) error { return cfg.RemoveConfigField(key) }
+	if err := appInstance.UpdateConfig(c, remove, nil); err != nil {
+		writeConfigUpdateError(c, ctx, err)
+		return
+	}
+	reloadConfig(c, appInstance)
+	writeConfigSection(c, ctx, appInstance.Config(), section)
+}
+
+// configApp 校验 directory 和配置段参数并获取项目的 app 实例，write 表示修改操作
+func (h *Handlers) configApp(c context.Context, ctx *hertzapp.RequestContext, write bool) (*internalapp.App, string, bool) {
+	directory := string(ctx.Query("directory"))
+	if directory == "" {
+		WriteError(c, ctx, "MISSING_DIRECTORY_PARAM", "Directory query parameter is required", consts.StatusBadRequest)
+		return nil, "", false
+	}
+	section := ctx.Param("section")
+	if _, ok := configSectionDefs[section]; !ok {
+		WriteError(c, ctx, "INVALID_SECTION", "Unknown config section: "+section, consts.StatusNotFound)
+		return nil, "", false
+	}
+	// 修改写入所有项目共用的配置文件，不能由只能访问部分项目的调用方执行
+	if principal, ok := middleware.PrincipalFrom(ctx); write && ok && principal.Scoped() {
+		WriteError(c, ctx, "FORBIDDEN", "API key is restricted to specific projects and cannot change the shared configuration", consts.StatusForbidden)
+		return nil, "", false
+	}
+
+	appInstance, err := h.GetAppForProject(c, directory)
+	if err != nil {
+		if strings.Contains(err.Error(), "project not found") {
+			WriteError(c, ctx, "PROJECT_NOT_FOUND", err.Error(), consts.StatusNotFound)
+			return nil, "", false
+		}
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to get app for project: "+err.Error(), consts.StatusInternalServerError)
+		return nil, "", false
+	}
+	return appInstance, section, true
+}
+
+// configEntry 在 configApp 的基础上校验条目名称
+func (h *Handlers) configEntry(c context.Context, ctx *hertzapp.RequestContext) (*internalapp.App, string, string, bool) {
+	section := ctx.Param("section")
+	if _, ok := configSectionDefs[section]; ok && !slices.Contains(configKeyedSections, section) {
+		WriteError(c, ctx, "INVALID_REQUEST", "Section "+section+" is edited with PATCH /project/config/"+section, consts.StatusBadRequest)
+		return nil, "", "", false
+	}
+	name := ctx.Param("name")
+	if !configNameRe.MatchString(name) {
+		WriteError(c, ctx, "INVALID_REQUEST", "Entry name may only contain letters, digits, '-' and '_'", consts.StatusBadRequest)
+		return nil, "", "", false
+	}
+	appInstance, section, ok := h.configApp(c, ctx, true)
+	return appInstance, section, name, ok
+}
+
+// reloadConfig 让其他项目实例重新加载配置，数据目录下的配置文件由所有项目共用
+func reloadConfig(c context.Context, source *internalapp.App) {
+	globalAppManager.mu.RLock()
+	apps := slices.Collect(maps.Values(globalAppManager.apps))
+	globalAppManager.mu.RUnlock()
+
+	for _, appInstance := range apps {
+		if appInstance == source {
+			continue
+		}
+		if err := appInstance.ReloadConfig(c); err != nil {
+			slog.Warn("Failed to reload config of project", "directory", appInstance.Config().WorkingDir(), "error", err)
+		}
+	}
+}
+
+// writeConfigUpdateError 返回 UpdateConfig 的错误：配置无法加载时修改已回滚，返回 400
+func writeConfigUpdateError(c context.Context, ctx *hertzapp.RequestContext, err error) {
+	if errors.Is(err, internalapp.ErrConfigNotApplied) {
+		WriteError(c, ctx, "CONFIG_NOT_APPLIED", err.Error(), consts.StatusInternalServerError)
+		return
+	}
+	WriteError(c, ctx, "INVALID_CONFIG", "Configuration rejected, no changes were saved: "+err.Error(), consts.StatusBadRequest)
+}
+
+// writeConfigSection 返回脱敏后的配置段
+func writeConfigSection(c context.Context, ctx *hertzapp.RequestContext, cfg *config.Config, section string) {
+	WriteJSON(c, ctx, consts.StatusOK, models.ConfigSectionResponse{
+		Section: section,
+		Value:   configSectionValue(cfg, section),
+		Path:    cfg.DataConfigPath(),
+	})
+}
+
+// configSectionValue 返回当前生效的配置段，转换为 JSON 对象并脱敏
+func configSectionValue(cfg *config.Config, section string) any {
+	var value any
+	switch section {
+	case models.ConfigSectionProviders:
+		providers := make(map[string]config.ProviderConfig)
+		for id, p := range cfg.Providers.Seq2() {
+			// 返回配置中的写法（如 $OPENAI_API_KEY），而不是解析后的值
+			if p.APIKeyTemplate != "" {
+				p.APIKey = p.APIKeyTemplate
+			}
+			providers[id] = p
+		}
+		value = providers
+	case models.ConfigSectionModels:
+		value = cfg.Models
+	case models.ConfigSectionMCP:
+		value = cfg.MCP
+	case models.ConfigSectionLSP:
+		value = cfg.LSP
+	case models.ConfigSectionOptions:
+		value = cfg.Options
+	case models.ConfigSectionPermissions:
+		value = cfg.Permissions
+	}
+
+	out := map[string]any{}
+	if data, err := json.Marshal(value); err == nil {
+		// nil 的配置段序列化为 null，保持返回空对象
+		_ = json.Unmarshal(data, &out)
+	}
+	if out == nil {
+		out = map[string]any{}
+	}
+	if paths, ok := configSecretPaths[section]; ok {
+		for _, entry := range out {
+			if entry, ok := entry.(map[string]any); ok {
+				walkConfigSecrets(entry, paths, func(_ []string, secret string) (string, bool) {
+					return maskSecret(secret), true
+				})
+			}
+		}
+	}
+	return out
+}
+
+// restoreMaskedSecrets 将条目中脱敏的密钥替换为数据目录配置文件中保存的原值；
+// 原值不在该文件中（来自其他配置文件或环境变量）时删除该字段，沿用合并后的配置
+func restoreMaskedSecrets(cfg *config.Config, section, key string, entry map[string]any) {
+	paths, ok := configSecretPaths[section]
+	if !ok {
+		return
+	}
+	walkConfigSecrets(entry, paths, func(path []string, secret string) (string, bool) {
+		if !strings.HasPrefix(secret, maskedSecretPrefix) {
+			return secret, true
+		}
+		parts := make([]string, 0, len(path))
+		for _, p := range path {
+			parts = append(parts, escapeConfigPath(p))
+		}
+		var stored string
+		if raw := cfg.ConfigField(key + "." + strings.Join(parts, ".")); raw != nil && json.Unmarshal(raw, &stored) == nil && maskSecret(stored) == secret {
+			return stored, true
+		}
+		return "", false
+	})
+}
+
+// walkConfigSecrets 对条目中 paths 指定的每个字符串字段调用 fn，
+// fn 返回新的值，第二个返回值为 false 时删除该字段
+func walkConfigSecrets(entry map[string]any, paths [][]string, fn func(path []string, secret string) (string, bool)) {
+	var walk func(node map[string]any, path, prefix []string)
+	walk = func(node map[string]any, path, prefix []string) {
+		keys := []string{path[0]}
+		if path[0] == "*" {
+			keys = slices.Collect(maps.Keys(node))
+		}
+		for _, k := range keys {
+			v, ok := node[k]
+			if !ok {
+				continue
+			}
+			full := append(slices.Clone(prefix), k)
+			if len(path) > 1 {
+				if child, ok := v.(map[string]any); ok {
+					walk(child, path[1:], full)
+				}
+				continue
+			}
+			if s, ok := v.(string); ok {
+				if s, keep := fn(full, s); keep {
+					node[k] = s
+				} else {
+					delete(node, k)
+				}
+			}
+		}
+	}
+	for _, path := range paths {
+		walk(entry, path, nil)
+	}
+}
+
+// maskSecret 脱敏密钥：空值和 $VAR、$(command) 形式的引用不是密钥，原样返回
+func maskSecret(secret string) string {
+	if secret == "" || strings.HasPrefix(secret, "$") {
+		return secret
+	}
+	if len(secret) <= 12 {
+		return maskedSecretPrefix
+	}
+	return maskedSecretPrefix + secret[len(secret)-4:]
+}
+
+// escapeConfigPath 转义 JSON 路径中的特殊字符，用于以任意字符串作为键
+func escapeConfigPath(key string) string {
+	r := strings.NewReplacer(`\`, `\\`, ".", `\.`, "*", `\*`, "?", `\?`)
+	return r.Replace(key)
+}
+
+// mustMarshal 返回值的 JSON 编码
+func mustMarshal(v any) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/crush/api/models"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAPIKey       = "sk-test-0123456789abcd"
+	testAccessToken  = "access-token-0123456789wxyz"
+	testRefreshToken = "refresh-token-0123456789qrst"
+	testHeader       = "Bearer header-0123456789hdrs"
+	testEnvSecret    = "env-secret-0123456789envs"
+)
+
+// newTestConfig 以 data 作为数据目录下的配置文件加载配置，不访问网络也不读取用户的配置
+func newTestConfig(t *testing.T, data map[string]any) *config.Config {
+	t.Helper()
+	globalConfig, globalData := t.TempDir(), t.TempDir()
+	t.Setenv("CRUSH_GLOBAL_CONFIG", globalConfig)
+	t.Setenv("CRUSH_GLOBAL_DATA", globalData)
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	data["options"] = map[string]any{"disable_provider_auto_update": true}
+	raw, err := json.Marshal(data)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(globalData, "crush.json"), raw, 0o600))
+
+	workingDir := t.TempDir()
+	cfg, err := config.Load(workingDir, filepath.Join(workingDir, ".crush"), false)
+	require.NoError(t, err)
+	return cfg
+}
+
+func newSecretsTestConfig(t *testing.T) *config.Config {
+	t.Helper()
+	return newTestConfig(t, map[string]any{
+		"providers": map[string]any{
+			"custom": map[string]any{
+				"type":     "openai-compat",
+				"base_url": "https://example.com/v1",
+				"api_key":  testAPIKey,
+				"oauth": map[string]any{
+					"access_token":  testAccessToken,
+					"refresh_token": testRefreshToken,
+				},
+				"extra_headers": map[string]any{"Authorization": testHeader},
+				"models":        []any{map[string]any{"id": "model", "name": "Model"}},
+			},
+			"templated": map[string]any{
+				"type":     "openai-compat",
+				"base_url": "https://example.com/v1",
+				"api_key":  "$TEMPLATED_API_KEY",
+				"models":   []any{map[string]any{"id": "model", "name": "Model"}},
+			},
+		},
+		"mcp": map[string]any{
+			"server": map[string]any{
+				"type":    "http",
+				"url":     "https://example.com/mcp",
+				"env":     map[string]any{"TOKEN": testEnvSecret},
+				"headers": map[string]any{"Authorization": testHeader},
+			},
+		},
+		"lsp": map[string]any{
+			"gopls": map[string]any{
+				"command": "gopls",
+				"env":     map[string]any{"TOKEN": testEnvSecret},
+			},
+		},
+	})
+}
+
+// lookupConfigValue 按路径取出 JSON 对象中的值
+func lookupConfigValue(t *testing.T, value any, path ...string) any {
+	t.Helper()
+	for _, key := range path {
+		node, ok := value.(map[string]any)
+		require.True(t, ok, "%v is not an object", path)
+		value, ok = node[key]
+		require.True(t, ok, "%v not found", path)
+	}
+	return value
+}
+
+func TestMaskSecret(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret string
+		want   string
+	}{
+		{"empty", "", ""},
+		{"environment variable", "$OPENAI_API_KEY", "$OPENAI_API_KEY"},
+		{"command", "$(pass show openai)", "$(pass show openai)"},
+		{"short", "secret", "****"},
+		{"twelve characters", "abcdefghijkl", "****"},
+		{"long", testAPIKey, "****abcd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, maskSecret(tt.secret))
+		})
+	}
+}
+
+func TestConfigSectionValueMasksSecrets(t *testing.T) {
+	cfg := newSecretsTestConfig(t)
+
+	tests := []struct {
+		name    string
+		section string
+		path    []string
+		want    string
+	}{
+		{"provider api_key", models.ConfigSectionProviders, []string{"custom", "api_key"}, maskSecret(testAPIKey)},
+		{"provider api_key template", models.ConfigSectionProviders, []string{"templated", "api_key"}, "$TEMPLATED_API_KEY"},
+		{"provider oauth access_token", models.ConfigSectionProviders, []string{"custom", "oauth", "access_token"}, maskSecret(testAccessToken)},
+		{"provider oauth refresh_token", models.ConfigSectionProviders, []string{"custom", "oauth", "refresh_token"}, maskSecret(testRefreshToken)},
+		{"provider extra_headers", models.ConfigSectionProviders, []string{"custom", "extra_headers", "Authorization"}, maskSecret(testHeader)},
+		{"mcp env", models.ConfigSectionMCP, []string{"server", "env", "TOKEN"}, maskSecret(testEnvSecret)},
+		{"mcp headers", models.ConfigSectionMCP, []string{"server", "headers", "Authorization"}, maskSecret(testHeader)},
+		{"mcp url", models.ConfigSectionMCP, []string{"server", "url"}, "https://example.com/mcp"},
+		{"lsp env", models.ConfigSectionLSP, []string{"gopls", "env", "TOKEN"}, maskSecret(testEnvSecret)},
+		{"lsp command", models.ConfigSectionLSP, []string{"gopls", "command"}, "gopls"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value := configSectionValue(cfg, tt.section)
+			require.Equal(t, tt.want, lookupConfigValue(t, value, tt.path...))
+		})
+	}
+}
+
+func TestRestoreMaskedSecrets(t *testing.T) {
+	cfg := newSecretsTestConfig(t)
+
+	tests := []struct {
+		name    string
+		section string
+		key     string
+		entry   map[string]any
+		want    map[string]any
+	}{
+		{
+			name:    "provider api_key restored",
+			section: models.ConfigSectionProviders,
+			key:     "providers.custom",
+			entry:   map[string]any{"api_key": maskSecret(testAPIKey), "base_url": "https://example.org/v1"},
+			want:    map[string]any{"api_key": testAPIKey, "base_url": "https://example.org/v1"},
+		},
+		{
+			name:    "provider api_key replaced",
+			section: models.ConfigSectionProviders,
+			key:     "providers.custom",
+			entry:   map[string]any{"api_key": "sk-new"},
+			want:    map[string]any{"api_key": "sk-new"},
+		},
+		{
+			name:    "provider api_key mask of another value",
+			section: models.ConfigSectionProviders,
+			key:     "providers.custom",
+			entry:   map[string]any{"api_key": "****zzzz"},
+			want:    map[string]any{},
+		},
+		{
+			name:    "provider oauth tokens restored",
+			section: models.ConfigSectionProviders,
+			key:     "providers.custom",
+			entry: map[string]any{"oauth": map[string]any{
+				"access_token":  maskSecret(testAccessToken),
+				"refresh_token": maskSecret(testRefreshToken),
+			}},
+			want: map[string]any{"oauth": map[string]any{
+				"access_token":  testAccessToken,
+				"refresh_token": testRefreshToken,
+			}},
+		},
+		{
+			name:    "provider extra_headers restored",
+			section: models.ConfigSectionProviders,
+			key:     "providers.custom",
+			entry:   map[string]any{"extra_headers": map[string]any{"Authorization": maskSecret(testHeader), "X-New": "value"}},
+			want:    map[string]any{"extra_headers": map[string]any{"Authorization": testHeader, "X-New": "value"}},
+		},
+		{
+			name:    "provider not in data config",
+			section: models.ConfigSectionProviders,
+			key:     "providers.other",
+			entry:   map[string]any{"api_key": maskSecret(testAPIKey)},
+			want:    map[string]any{},
+		},
+		{
+			name:    "mcp env and headers restored",
+			section: models.ConfigSectionMCP,
+			key:     "mcp.server",
+			entry: map[string]any{
+				"env":     map[string]any{"TOKEN": maskSecret(testEnvSecret)},
+				"headers": map[string]any{"Authorization": maskSecret(testHeader)},
+			},
+			want: map[string]any{
+				"env":     map[string]any{"TOKEN": testEnvSecret},
+				"headers": map[string]any{"Authorization": testHeader},
+			},
+		},
+		{
+			name:    "mcp env of unknown variable",
+			section: models.ConfigSectionMCP,
+			key:     "mcp.server",
+			entry:   map[string]any{"env": map[string]any{"OTHER": maskSecret(testEnvSecret)}},
+			want:    map[string]any{"env": map[string]any{}},
+		},
+		{
+			name:    "lsp env restored",
+			section: models.ConfigSectionLSP,
+			key:     "lsp.gopls",
+			entry:   map[string]any{"env": map[string]any{"TOKEN": maskSecret(testEnvSecret)}},
+			want:    map[string]any{"env": map[string]any{"TOKEN": testEnvSecret}},
+		},
+		{
+			name:    "section without secrets",
+			section: models.ConfigSectionModels,
+			key:     "models.large",
+			entry:   map[string]any{"model": "****"},
+			want:    map[string]any{"model": "****"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restoreMaskedSecrets(cfg, tt.section, tt.key, tt.entry)
+			require.Equal(t, tt.want, tt.entry)
+		})
+	}
+}
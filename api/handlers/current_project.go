@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/crush/api/middleware"
 	"github.com/charmbracelet/crush/api/models"
 	"github.com/charmbracelet/crush/internal/projects"
 	hertzapp "github.com/cloudwego/hertz/pkg/app"
@@ -62,6 +63,17 @@ func (h *Handlers) HandleGetCurrentProject(c context.Context, ctx *hertzapp.Requ
 		return
 	}
 
+	// 受限的 API Key 只能看到其授权范围内的项目
+	if principal, ok := middleware.PrincipalFrom(ctx); ok && principal.Scoped() {
+		allowed := projectList[:0]
+		for _, p := range projectList {
+			if principal.AllowsProject(p.Path) {
+				allowed = append(allowed, p)
+			}
+		}
+		projectList = allowed
+	}
+
 	// 如果没有项目，返回 404
 	if len(projectList) == 0 {
 		WriteError(c, ctx, "NO_PROJECTS", "No projects available. Please register a project first.", consts.StatusNotFound)
@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/crush/api/models"
+	internalapp "github.com/charmbracelet/crush/internal/app"
+	"github.com/charmbracelet/crush/internal/pubsub"
+)
+
+const (
+	// eventBufferSize 每个项目保留的最近事件数量，用于断线重连后重放
+	eventBufferSize = 1000
+	// eventSubscriberBufferSize 每个连接的待发送事件缓冲
+	eventSubscriberBufferSize = 256
+)
+
+// hubEvent 已编码的事件
+type hubEvent struct {
+	// data 事件的 JSON 编码，即 SSE 的 data 和 WebSocket 消息的内容
+	data []byte
+	// frame SSE 格式的事件（不含 id 行）
+	frame []byte
+	// sessionID 事件所属的会话，与会话无关的事件为空
+	sessionID string
+}
+
+// newHubEvent 编码事件
+func newHubEvent(ev models.SSEEvent) hubEvent {
+	data := encodeEvent(ev)
+	return hubEvent{
+		data:      data,
+		frame:     fmt.Appendf(nil, "event: %s\ndata: %s\n\n", ev.Type, data),
+		sessionID: eventSessionID(ev),
+	}
+}
+
+// eventSessionID 返回事件所属会话的 ID，用于按会话过滤
+func eventSessionID(ev models.SSEEvent) string {
+	switch props := ev.Properties.(type) {
+	case models.PermissionRequest:
+		return props.SessionID
+	case models.FileEditedEvent:
+		return props.SessionID
+	case map[string]interface{}:
+		if id, ok := props["sessionID"].(string); ok {
+			return id
+		}
+		switch info := props["info"].(type) {
+		case models.SessionResponse:
+			return info.ID
+		case models.MessageResponse:
+			return info.SessionID
+		}
+	}
+	return ""
+}
+
+// hubEntry 带 ID 的事件
+type hubEntry = pubsub.RingEntry[hubEvent]
+
+// eventHub 项目级事件流
+// 每个项目只订阅一次 app 事件并计算一次增量，所有 SSE 和 WebSocket 连接共享同一序列的事件 ID，
+// 最近的事件保存在环形缓冲中，客户端重连时可通过 Last-Event-ID 补发
+type eventHub struct {
+	app    *internalapp.App
+	ring   *pubsub.Ring[hubEvent]
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	subs   map[chan hubEntry]struct{}
+	closed bool
+}
+
+// eventHubManager 管理各项目的事件流
+type eventHubManager struct {
+	hubs map[string]*eventHub
+	mu   sync.Mutex
+}
+
+var globalEventHubs = &eventHubManager{
+	hubs: make(map[string]*eventHub),
+}
+
+// eventHubFor 获取项目的事件流，不存在或 app 实例已重建时创建新的事件流
+func (h *Handlers) eventHubFor(projectPath string, appInstance *internalapp.App) *eventHub {
+	globalEventHubs.mu.Lock()
+	defer globalEventHubs.mu.Unlock()
+
+	if hub, ok := globalEventHubs.hubs[projectPath]; ok {
+		if hub.app == appInstance {
+			return hub
+		}
+		hub.stop()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hub := &eventHub{
+		app: appInstance,
+		// ID 以创建时间（毫秒）为起点，app 实例重建后客户端持有的旧 ID 不会与新 ID 混淆
+		ring:   pubsub.NewRing[hubEvent](eventBufferSize, uint64(time.Now().UnixMilli())),
+		cancel: cancel,
+		subs:   make(map[chan hubEntry]struct{}),
+	}
+	globalEventHubs.hubs[projectPath] = hub
+	go hub.run(ctx, h)
+
+	slog.Info("Started project event hub", "project", projectPath)
+	return hub
+}
+
+// 项目实例被释放的原因，随 instance.disposed 事件发送
+const (
+	disposeReasonRequested = "requested" // 通过 /instance/dispose 或 /global/dispose 释放
+	disposeReasonIdle      = "idle"      // 闲置超时
+	disposeReasonCapacity  = "capacity"  // 实例数量达到上限
+)
+
+// stopEventHub 通知连接项目实例已释放，然后停止事件流并断开所有 SSE 连接
+func stopEventHub(projectPath, reason string) {
+	globalEventHubs.mu.Lock()
+	defer globalEventHubs.mu.Unlock()
+
+	if hub, ok := globalEventHubs.hubs[projectPath]; ok {
+		hub.publishDisposed(projectPath, reason)
+		hub.stop()
+		delete(globalEventHubs.hubs, projectPath)
+	}
+}
+
+// stopAllEventHubs 停止所有项目的事件流
+func stopAllEventHubs(reason string) {
+	globalEventHubs.mu.Lock()
+	defer globalEventHubs.mu.Unlock()
+
+	for path, hub := range globalEventHubs.hubs {
+		hub.publishDisposed(path, reason)
+		hub.stop()
+		delete(globalEventHubs.hubs, path)
+	}
+}
+
+// hasEventSubscribers 判断项目是否有 SSE 连接
+func hasEventSubscribers(projectPath string) bool {
+	globalEventHubs.mu.Lock()
+	hub, ok := globalEventHubs.hubs[projectPath]
+	globalEventHubs.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	return len(hub.subs) > 0
+}
+
+// publishProjectEvent 向项目的事件流发送 API 产生的事件，没有事件流时忽略
+// 事件流属于已重建的旧 app 实例时同样忽略，新的连接会创建新的事件流
+func publishProjectEvent(projectPath string, appInstance *internalapp.App, ev models.SSEEvent) {
+	globalEventHubs.mu.Lock()
+	hub, ok := globalEventHubs.hubs[projectPath]
+	globalEventHubs.mu.Unlock()
+	if !ok || hub.app != appInstance {
+		return
+	}
+	hub.publish(ev)
+}
+
+// run 订阅 app 事件，编码后分配 ID 并分发给所有连接
+func (hub *eventHub) run(ctx context.Context, h *Handlers) {
+	defer hub.close()
+
+	eventCh := h.createEventChannelForProject(ctx, hub.app)
+	messageStates := make(map[string]*messagePartState)
+	recorder := &eventRecorder{}
+
+	for event := range eventCh {
+		recorder.events = recorder.events[:0]
+		if err := h.writeSSEEventWithDelta(recorder, event, messageStates); err != nil {
+			slog.Error("Failed to encode SSE event", "error", err)
+			continue
+		}
+		for _, ev := range recorder.events {
+			hub.publish(ev)
+		}
+	}
+}
+
+// publish 编码并保存事件，然后发送给所有连接
+// 连接的缓冲已满时直接断开，客户端重连后通过 Last-Event-ID 补发，避免静默丢失事件
+func (hub *eventHub) publish(ev models.SSEEvent) {
+	encoded := newHubEvent(ev)
+
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	entry := hubEntry{ID: hub.ring.Append(encoded), Value: encoded}
+	for sub := range hub.subs {
+		select {
+		case sub <- entry:
+		default:
+			slog.Warn("SSE subscriber too slow, disconnecting", "event_id", entry.ID)
+			delete(hub.subs, sub)
+			close(sub)
+		}
+	}
+}
+
+// publishDisposed 发送 instance.disposed 事件
+// 客户端收到后连接会被关闭，之后的请求（包括重连）会重新创建项目实例
+func (hub *eventHub) publishDisposed(projectPath, reason string) {
+	hub.publish(models.SSEEvent{
+		Type: "instance.disposed",
+		Properties: map[string]string{
+			"directory": projectPath,
+			"reason":    reason,
+		},
+	})
+}
+
+// subscribe 注册新的连接
+// resume 为 true 时返回 lastEventID 之后需要补发的事件；缓冲中已缺失部分事件时 complete 为 false，
+// 此时客户端需要重新获取状态。latestID 为注册时最新的事件 ID
+func (hub *eventHub) subscribe(lastEventID uint64, resume bool) (ch chan hubEntry, replay []hubEntry, complete bool, latestID uint64) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	ch = make(chan hubEntry, eventSubscriberBufferSize)
+	if hub.closed {
+		close(ch)
+		return ch, nil, true, hub.ring.LastID()
+	}
+
+	complete = true
+	if resume {
+		replay, complete = hub.ring.Since(lastEventID)
+	}
+	hub.subs[ch] = struct{}{}
+	return ch, replay, complete, hub.ring.LastID()
+}
+
+// unsubscribe 注销连接（幂等）
+func (hub *eventHub) unsubscribe(ch chan hubEntry) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	if _, ok := hub.subs[ch]; ok {
+		delete(hub.subs, ch)
+		close(ch)
+	}
+}
+
+// stop 停止订阅 app 事件，run 退出时关闭所有连接
+func (hub *eventHub) stop() {
+	hub.cancel()
+}
+
+// close 关闭所有连接
+func (hub *eventHub) close() {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	hub.closed = true
+	for sub := range hub.subs {
+		delete(hub.subs, sub)
+		close(sub)
+	}
+}
+
+// eventWriter 接收 writeSSEEventWithDelta 生成的事件
+type eventWriter interface {
+	writeEvent(models.SSEEvent) error
+}
+
+// eventRecorder 收集生成的事件，由事件流统一编码和分发
+type eventRecorder struct {
+	events []models.SSEEvent
+}
+
+func (r *eventRecorder) writeEvent(ev models.SSEEvent) error {
+	r.events = append(r.events, ev)
+	return nil
+}
+
+// resetEntry 返回 reset 事件，通知客户端缺失的事件已不在缓冲中，需要重新获取状态
+// 事件携带当前最新的 ID，客户端下次重连时从这里继续
+func resetEntry(latestID uint64) hubEntry {
+	return hubEntry{
+		ID: latestID,
+		Value: newHubEvent(models.SSEEvent{
+			Type: "reset",
+			Properties: map[string]interface{}{
+				"reason":      "events since Last-Event-ID are no longer available",
+				"lastEventID": strconv.FormatUint(latestID, 10),
+			},
+		}),
+	}
+}
+
+// writeSSEEntry 以 SSE 格式写入带 ID 的事件
+func writeSSEEntry(w io.Writer, entry hubEntry) error {
+	buf := make([]byte, 0, len(entry.Value.frame)+24)
+	buf = fmt.Appendf(buf, "id: %d\n", entry.ID)
+	buf = append(buf, entry.Value.frame...)
+	_, err := w.Write(buf)
+	return err
+}
+
+// parseLastEventID 解析 Last-Event-ID 请求头
+// 请求头为空时 resume 为 false；无法解析时返回 0，对应的补发会被判定为不完整
+func parseLastEventID(header string) (id uint64, resume bool) {
+	if header == "" {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(header, 10, 64)
+	if err != nil {
+		return 0, true
+	}
+	return id, true
+}
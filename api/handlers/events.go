@@ -12,10 +12,13 @@ import (
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/charmbracelet/crush/api/models"
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
 	internalapp "github.com/charmbracelet/crush/internal/app"
 	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/permission"
 	"github.com/charmbracelet/crush/internal/pubsub"
 	"github.com/charmbracelet/crush/internal/session"
+	"github.com/charmbracelet/crush/internal/shell"
 	hertzapp "github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
 )
@@ -37,11 +40,13 @@ func newMessagePartState() *messagePartState {
 // HandleSSE 处理 Server-Sent Events 请求
 //
 //	@Summary		订阅服务器事件
-//	@Description	订阅项目的实时事件流
+//	@Description	订阅项目的实时事件流。每个事件带有递增的 id，断线重连时通过 Last-Event-ID 请求头补发期间的事件；
+//	@Description	缺失的事件已超出缓冲范围时发送 reset 事件，客户端应重新获取会话和消息
 //	@Tags			Event
 //	@Accept			json
 //	@Produce		text/event-stream
-//	@Param			directory	query		string	true	"项目路径"
+//	@Param			directory		query		string	true	"项目路径"
+//	@Param			Last-Event-ID	header		string	false	"最后收到的事件 ID"
 //	@Success		200		{object}	models.SSEEvent	"Event stream"
 //	@Failure		400		{object}	map[string]interface{}
 //	@Failure		404		{object}	map[string]interface{}
@@ -80,8 +85,10 @@ func (h *Handlers) HandleSSE(c context.Context, ctx *hertzapp.RequestContext) {
 	ctx.Response.Header.Set("Access-Control-Allow-Headers", "Cache-Control")
 	ctx.Response.Header.Set("Transfer-Encoding", "chunked")
 
-	// 创建事件通道，只订阅指定项目的 app 实例事件
-	eventCh := h.createEventChannelForProject(c, appInstance)
+	// 注册到项目事件流，根据 Last-Event-ID 计算需要补发的事件
+	lastEventID, resume := parseLastEventID(string(ctx.GetHeader("Last-Event-ID")))
+	hub := h.eventHubFor(projectPath, appInstance)
+	eventCh, replay, complete, latestID := hub.subscribe(lastEventID, resume)
 
 	// 使用 io.Pipe 进行流式传输
 	pr, pw := io.Pipe()
@@ -90,11 +97,9 @@ func (h *Handlers) HandleSSE(c context.Context, ctx *hertzapp.RequestContext) {
 	// 在新的 goroutine 中处理事件并写入 Response
 	go func() {
 		defer pw.Close()
+		defer hub.unsubscribe(eventCh)
 
-		slog.Info("Starting SSE writer goroutine", "remote_addr", remoteAddr)
-
-		// 创建消息状态缓存，用于计算增量
-		messageStates := make(map[string]*messagePartState)
+		slog.Info("Starting SSE writer goroutine", "remote_addr", remoteAddr, "last_event_id", lastEventID, "replay", len(replay))
 
 		// 发送初始连接确认
 		initEvent := models.SSEEvent{
@@ -110,6 +115,24 @@ func (h *Handlers) HandleSSE(c context.Context, ctx *hertzapp.RequestContext) {
 		}
 		slog.Info("Init event written to pipe", "remote_addr", remoteAddr)
 
+		// 缺失的事件已不在缓冲中，通知客户端重新获取状态
+		// reset 事件携带当前最新的 ID，客户端下次重连时从这里继续
+		if !complete {
+			slog.Info("SSE replay gap too large, sending reset", "remote_addr", remoteAddr, "last_event_id", lastEventID, "latest_id", latestID)
+			if err := writeSSEEntry(pw, resetEntry(latestID)); err != nil {
+				slog.Info("Client disconnected during reset", "remote_addr", remoteAddr)
+				return
+			}
+		}
+
+		// 补发断线期间的事件
+		for _, entry := range replay {
+			if err := writeSSEEntry(pw, entry); err != nil {
+				slog.Info("Client disconnected during replay", "remote_addr", remoteAddr)
+				return
+			}
+		}
+
 		// 创建心跳定时器
 		heartbeat := time.NewTicker(30 * time.Second)
 		defer heartbeat.Stop()
@@ -125,14 +148,14 @@ func (h *Handlers) HandleSSE(c context.Context, ctx *hertzapp.RequestContext) {
 					slog.Info("Client disconnected (heartbeat write failed)", "remote_addr", remoteAddr)
 					return
 				}
-			case event, ok := <-eventCh:
+			case entry, ok := <-eventCh:
 				if !ok {
+					// 项目已释放或连接过慢被断开，客户端可携带 Last-Event-ID 重连
 					slog.Info("Event channel closed", "remote_addr", remoteAddr)
 					return
 				}
 
-				// 处理并发送事件(带增量计算)
-				if err := h.writeSSEEventWithDelta(pw, event, messageStates); err != nil {
+				if err := writeSSEEntry(pw, entry); err != nil {
 					// 管道关闭是客户端断开连接的正常情况
 					if strings.Contains(err.Error(), "closed pipe") {
 						slog.Info("Client disconnected during event write", "remote_addr", remoteAddr)
@@ -146,14 +169,25 @@ func (h *Handlers) HandleSSE(c context.Context, ctx *hertzapp.RequestContext) {
 	}()
 }
 
-// writeSSEEventWithDelta 将 SSE 事件写入 io.Writer，对消息更新事件计算增量
-func (h *Handlers) writeSSEEventWithDelta(w io.Writer, event tea.Msg, messageStates map[string]*messagePartState) error {
+// writeSSEEventWithDelta 将 app 事件转换为 SSE 事件写入 w，对消息更新事件计算增量
+func (h *Handlers) writeSSEEventWithDelta(w eventWriter, event tea.Msg, messageStates map[string]*messagePartState) error {
 	// 处理不同类型的事件
 	switch e := event.(type) {
 	case pubsub.Event[internalapp.LSPEvent]:
 		// LSP 事件 - 直接发送，无需增量计算
 		return h.writeLSPEvent(w, e)
 
+	case pubsub.Event[mcp.Event]:
+		// MCP 事件 - 直接发送，无需增量计算
+		return h.writeMCPEvent(w, e)
+
+	case pubsub.Event[shell.JobEvent]:
+		// 后台任务事件
+		return h.sendSSEEvent(w, models.SSEEvent{
+			Type:       "job." + string(e.Payload.Type),
+			Properties: models.JobFromInfo(e.Payload.Job),
+		})
+
 	case pubsub.Event[message.Message]:
 		// 消息事件 - 需要增量计算
 		return h.writeMessageEventWithDelta(w, e, messageStates)
@@ -162,6 +196,23 @@ func (h *Handlers) writeSSEEventWithDelta(w io.Writer, event tea.Msg, messageSta
 		// 会话事件 - 直接发送，无需增量计算
 		return h.writeSessionEvent(w, e)
 
+	case pubsub.Event[permission.PermissionRequest]:
+		// 权限请求事件 - 等待客户端通过 /project/permissions/{requestID}/reply 回复
+		return h.sendSSEEvent(w, models.SSEEvent{
+			Type:       "permission.updated",
+			Properties: models.PermissionRequestFromInternal(e.Payload),
+		})
+
+	case pubsub.Event[permission.PermissionNotification]:
+		// 权限回复事件
+		return h.sendSSEEvent(w, models.SSEEvent{
+			Type: "permission.replied",
+			Properties: map[string]interface{}{
+				"tool_call_id": e.Payload.ToolCallID,
+				"granted":      e.Payload.Granted,
+			},
+		})
+
 	default:
 		// Unknown event type, ignore
 		return nil
@@ -169,7 +220,7 @@ func (h *Handlers) writeSSEEventWithDelta(w io.Writer, event tea.Msg, messageSta
 }
 
 // writeLSPEvent 写入 LSP 事件
-func (h *Handlers) writeLSPEvent(w io.Writer, e pubsub.Event[internalapp.LSPEvent]) error {
+func (h *Handlers) writeLSPEvent(w eventWriter, e pubsub.Event[internalapp.LSPEvent]) error {
 	var resp models.SSEEvent
 
 	switch e.Payload.Type {
@@ -187,6 +238,65 @@ func (h *Handlers) writeLSPEvent(w io.Writer, e pubsub.Event[internalapp.LSPEven
 			"serverID":         e.Payload.Name,
 			"diagnostic_count": e.Payload.DiagnosticCount,
 		}
+		if err := h.sendSSEEvent(w, resp); err != nil {
+			return err
+		}
+
+		// 按严重程度统计的诊断数
+		counts := e.Payload.Diagnostics
+		resp.Type = "lsp.diagnostics"
+		resp.Properties = map[string]interface{}{
+			"name":        e.Payload.Name,
+			"total":       e.Payload.DiagnosticCount,
+			"error":       counts.Error,
+			"warning":     counts.Warning,
+			"information": counts.Information,
+			"hint":        counts.Hint,
+		}
+	default:
+		return nil
+	}
+
+	return h.sendSSEEvent(w, resp)
+}
+
+// writeMCPEvent 写入 MCP 事件
+func (h *Handlers) writeMCPEvent(w eventWriter, e pubsub.Event[mcp.Event]) error {
+	var resp models.SSEEvent
+
+	switch {
+	case e.Type == pubsub.DeletedEvent:
+		resp.Type = "mcp.server.removed"
+		resp.Properties = map[string]interface{}{
+			"name": e.Payload.Name,
+		}
+	case e.Payload.Type == mcp.EventStateChanged:
+		status := e.Payload.State.String()
+		var errMsg string
+		if e.Payload.State == mcp.StateError {
+			status = "failed"
+			if e.Payload.Error != nil {
+				errMsg = e.Payload.Error.Error()
+			}
+		}
+		resp.Type = "mcp.server.state_changed"
+		resp.Properties = map[string]interface{}{
+			"name":    e.Payload.Name,
+			"status":  status,
+			"message": errMsg,
+			"tools":   e.Payload.Counts.Tools,
+			"prompts": e.Payload.Counts.Prompts,
+		}
+	case e.Payload.Type == mcp.EventToolsListChanged:
+		resp.Type = "mcp.tools.changed"
+		resp.Properties = map[string]interface{}{
+			"name": e.Payload.Name,
+		}
+	case e.Payload.Type == mcp.EventPromptsListChanged:
+		resp.Type = "mcp.prompts.changed"
+		resp.Properties = map[string]interface{}{
+			"name": e.Payload.Name,
+		}
 	default:
 		return nil
 	}
@@ -195,7 +305,7 @@ func (h *Handlers) writeLSPEvent(w io.Writer, e pubsub.Event[internalapp.LSPEven
 }
 
 // writeSessionEvent 写入会话事件
-func (h *Handlers) writeSessionEvent(w io.Writer, e pubsub.Event[session.Session]) error {
+func (h *Handlers) writeSessionEvent(w eventWriter, e pubsub.Event[session.Session]) error {
 	var resp models.SSEEvent
 	sessResp := models.SessionToResponse(e.Payload)
 
@@ -223,7 +333,7 @@ func (h *Handlers) writeSessionEvent(w io.Writer, e pubsub.Event[session.Session
 }
 
 // writeMessageEventWithDelta 写入消息事件，对更新事件计算增量
-func (h *Handlers) writeMessageEventWithDelta(w io.Writer, e pubsub.Event[message.Message], messageStates map[string]*messagePartState) error {
+func (h *Handlers) writeMessageEventWithDelta(w eventWriter, e pubsub.Event[message.Message], messageStates map[string]*messagePartState) error {
 	msg := e.Payload
 	msgID := msg.ID
 
@@ -261,7 +371,7 @@ func (h *Handlers) writeMessageEventWithDelta(w io.Writer, e pubsub.Event[messag
 }
 
 // processMessageUpdate 处理消息更新，计算并发送增量事件
-func (h *Handlers) processMessageUpdate(w io.Writer, msg message.Message, messageStates map[string]*messagePartState) error {
+func (h *Handlers) processMessageUpdate(w eventWriter, msg message.Message, messageStates map[string]*messagePartState) error {
 	msgID := msg.ID
 
 	// 获取或创建状态
@@ -420,7 +530,12 @@ func (h *Handlers) processMessageUpdate(w io.Writer, msg message.Message, messag
 }
 
 // sendSSEEvent 发送 SSE 事件
-func (h *Handlers) sendSSEEvent(w io.Writer, resp models.SSEEvent) error {
+func (h *Handlers) sendSSEEvent(w eventWriter, resp models.SSEEvent) error {
+	return w.writeEvent(resp)
+}
+
+// encodeEvent 将事件编码为 JSON
+func encodeEvent(resp models.SSEEvent) []byte {
 	data, err := json.Marshal(resp)
 	if err != nil {
 		// 如果序列化失败，发送 error 事件
@@ -432,10 +547,7 @@ func (h *Handlers) sendSSEEvent(w io.Writer, resp models.SSEEvent) error {
 		}
 		data, _ = json.Marshal(errResp)
 	}
-
-	// 发送SSE格式的事件
-	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", resp.Type, data)
-	return err
+	return data
 }
 
 // createEventChannelForProject 为指定项目的 app 实例创建事件通道
@@ -502,12 +614,6 @@ func (h *Handlers) createEventChannelForProject(ctx context.Context, appInstance
 		defer wg.Done()
 		lspCh := internalapp.SubscribeLSPEvents(ctx)
 
-		// 获取该项目的 LSP 客户端名称集合
-		projectLSPNames := make(map[string]bool)
-		for name := range appInstance.LSPClients.Seq2() {
-			projectLSPNames[name] = true
-		}
-
 		for {
 			select {
 			case <-ctx.Done():
@@ -520,8 +626,11 @@ func (h *Handlers) createEventChannelForProject(ctx context.Context, appInstance
 				// 过滤：只发送属于该项目的 LSP 事件
 				// event 已经是 pubsub.Event[app.LSPEvent] 类型
 				lspName := event.Payload.Name
-				// 检查该 LSP 客户端是否属于当前项目
-				if !projectLSPNames[lspName] {
+				// 检查该 LSP 客户端是否属于当前项目，客户端在就绪后才加入 LSPClients，
+				// 因此也接受配置文件中的服务器
+				_, running := appInstance.LSPClients.Get(lspName)
+				_, configured := appInstance.Config().LSP[lspName]
+				if !running && !configured {
 					// 不属于当前项目，跳过
 					continue
 				}
@@ -538,6 +647,117 @@ func (h *Handlers) createEventChannelForProject(ctx context.Context, appInstance
 		}
 	}()
 
+	// 订阅 MCP 事件
+	// MCP 客户端由所有项目共用，只发送该项目配置的服务器的事件，删除事件总是发送
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		mcpCh := mcp.SubscribeEvents(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-mcpCh:
+				if !ok {
+					return
+				}
+				if _, configured := appInstance.Config().MCP[event.Payload.Name]; !configured && event.Type != pubsub.DeletedEvent {
+					continue
+				}
+				select {
+				case eventCh <- event:
+				case <-ctx.Done():
+					return
+				default:
+					// 通道已满，跳过此事件
+					continue
+				}
+			}
+		}
+	}()
+
+	// 订阅后台任务事件
+	// 后台任务由所有项目共用，只发送工作目录在该项目中的任务的事件
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		jobsCh := shell.SubscribeJobEvents(ctx)
+		root := appInstance.Config().WorkingDir()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-jobsCh:
+				if !ok {
+					return
+				}
+				if !isWithin(root, event.Payload.Job.WorkingDir) {
+					continue
+				}
+				select {
+				case eventCh <- event:
+				case <-ctx.Done():
+					return
+				default:
+					// 通道已满，跳过此事件
+					continue
+				}
+			}
+		}
+	}()
+
+	// 订阅该项目的权限请求事件
+	// 权限请求需要客户端回复，通道满时等待而不是丢弃
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		permissionsCh := appInstance.Permissions.Subscribe(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-permissionsCh:
+				if !ok {
+					return
+				}
+				select {
+				case eventCh <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	// 订阅该项目的权限回复通知
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		notificationsCh := appInstance.Permissions.SubscribeNotifications(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-notificationsCh:
+				if !ok {
+					return
+				}
+				// 请求开始时的通知没有结果，跳过
+				if !event.Payload.Granted && !event.Payload.Denied {
+					continue
+				}
+				select {
+				case eventCh <- event:
+				case <-ctx.Done():
+					return
+				default:
+					// 通道已满，跳过此事件
+					continue
+				}
+			}
+		}
+	}()
+
 	// 在后台等待所有goroutine完成，然后关闭通道
 	go func() {
 		wg.Wait()
@@ -0,0 +1,488 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/crush/api/models"
+	internalapp "github.com/charmbracelet/crush/internal/app"
+	"github.com/charmbracelet/crush/internal/audit"
+	"github.com/charmbracelet/crush/internal/diff"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/x/powernap/pkg/lsp/protocol"
+	hertzapp "github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// fileEditAuditTool 审计日志中通过 API 修改文件的工具名称，action 为修改类型
+const fileEditAuditTool = "file_edit"
+
+// fileEditMu 串行化文件修改，避免并发的读取-修改-写入互相覆盖
+var fileEditMu sync.Mutex
+
+// fileEdit 一次文件修改请求的上下文
+type fileEdit struct {
+	projectPath string
+	app         *internalapp.App
+	root        string
+	sessionID   string
+}
+
+// HandleWriteFile 写入文件
+//
+//	@Summary		写入文件
+//	@Description	写入项目内的文件，不存在时创建。修改前后的内容记录到会话的文件历史，通知 LSP 并发送 file.edited 事件。
+//	@Tags			File
+//	@Accept			json
+//	@Produce		json
+//	@Param			directory	query		string					true	"项目路径"
+//	@Param			request		body		models.FileWriteRequest	true	"写入请求"
+//	@Success		200			{object}	models.FileEditResponse
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		500			{object}	map[string]interface{}
+//	@Router			/file/content [put]
+func (h *Handlers) HandleWriteFile(c context.Context, ctx *hertzapp.RequestContext) {
+	var req models.FileWriteRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		WriteError(c, ctx, "INVALID_REQUEST", "Invalid request body: "+err.Error(), consts.StatusBadRequest)
+		return
+	}
+
+	content := []byte(req.Content)
+	switch req.Encoding {
+	case "", "utf8":
+	case "base64":
+		decoded, err := base64.StdEncoding.DecodeString(req.Content)
+		if err != nil {
+			WriteError(c, ctx, "INVALID_REQUEST", "Invalid base64 content: "+err.Error(), consts.StatusBadRequest)
+			return
+		}
+		content = decoded
+	default:
+		WriteError(c, ctx, "INVALID_REQUEST", "encoding must be utf8 or base64", consts.StatusBadRequest)
+		return
+	}
+
+	edit, ok := h.prepareFileEdit(c, ctx, req.SessionID)
+	if !ok {
+		return
+	}
+	fullPath, ok := edit.resolve(c, ctx, req.Path)
+	if !ok {
+		return
+	}
+
+	fileEditMu.Lock()
+	defer fileEditMu.Unlock()
+
+	oldContent, ok := readFileForEdit(c, ctx, fullPath, req.Path, true)
+	if !ok {
+		return
+	}
+	if err := writeFileForEdit(fullPath, content); err != nil {
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to write file: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+	edit.finish(c, ctx, models.FileEditWrite, "", req.Path, fullPath, oldContent, string(content))
+}
+
+// HandlePatchFile 应用 unified diff
+//
+//	@Summary		应用 unified diff
+//	@Description	将单个文件的 unified diff 应用到项目内的文件，任一 hunk 无法应用时不修改文件并返回 409。修改前后的内容记录到会话的文件历史，通知 LSP 并发送 file.edited 事件。
+//	@Tags			File
+//	@Accept			json
+//	@Produce		json
+//	@Param			directory	query		string					true	"项目路径"
+//	@Param			request		body		models.FilePatchRequest	true	"补丁请求"
+//	@Success		200			{object}	models.FileEditResponse
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		409			{object}	map[string]interface{}
+//	@Failure		500			{object}	map[string]interface{}
+//	@Router			/file/patch [post]
+func (h *Handlers) HandlePatchFile(c context.Context, ctx *hertzapp.RequestContext) {
+	var req models.FilePatchRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		WriteError(c, ctx, "INVALID_REQUEST", "Invalid request body: "+err.Error(), consts.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Diff) == "" {
+		WriteError(c, ctx, "INVALID_REQUEST", "diff is required", consts.StatusBadRequest)
+		return
+	}
+
+	edit, ok := h.prepareFileEdit(c, ctx, req.SessionID)
+	if !ok {
+		return
+	}
+	fullPath, ok := edit.resolve(c, ctx, req.Path)
+	if !ok {
+		return
+	}
+
+	fileEditMu.Lock()
+	defer fileEditMu.Unlock()
+
+	oldContent, ok := readFileForEdit(c, ctx, fullPath, req.Path, true)
+	if !ok {
+		return
+	}
+	newContent, err := diff.Apply(oldContent, req.Diff)
+	if err != nil {
+		WriteError(c, ctx, "PATCH_FAILED", "Failed to apply patch: "+err.Error(), consts.StatusConflict)
+		return
+	}
+	if err := writeFileForEdit(fullPath, []byte(newContent)); err != nil {
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to write file: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+	edit.finish(c, ctx, models.FileEditPatch, "", req.Path, fullPath, oldContent, newContent)
+}
+
+// HandleRenameFile 重命名文件
+//
+//	@Summary		重命名文件
+//	@Description	重命名或移动项目内的文件，目标已存在时返回 409。原路径记录为空内容的版本，新路径记录文件内容，通知 LSP 并发送 file.edited 事件。
+//	@Tags			File
+//	@Accept			json
+//	@Produce		json
+//	@Param			directory	query		string						true	"项目路径"
+//	@Param			request		body		models.FileRenameRequest	true	"重命名请求"
+//	@Success		200			{object}	models.FileEditResponse
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		409			{object}	map[string]interface{}
+//	@Failure		500			{object}	map[string]interface{}
+//	@Router			/file/rename [post]
+func (h *Handlers) HandleRenameFile(c context.Context, ctx *hertzapp.RequestContext) {
+	var req models.FileRenameRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		WriteError(c, ctx, "INVALID_REQUEST", "Invalid request body: "+err.Error(), consts.StatusBadRequest)
+		return
+	}
+
+	edit, ok := h.prepareFileEdit(c, ctx, req.SessionID)
+	if !ok {
+		return
+	}
+	fromPath, ok := edit.resolve(c, ctx, req.From)
+	if !ok {
+		return
+	}
+	toPath, ok := edit.resolve(c, ctx, req.To)
+	if !ok {
+		return
+	}
+	if fromPath == toPath {
+		WriteError(c, ctx, "INVALID_REQUEST", "from and to are the same path", consts.StatusBadRequest)
+		return
+	}
+
+	fileEditMu.Lock()
+	defer fileEditMu.Unlock()
+
+	content, ok := readFileForEdit(c, ctx, fromPath, req.From, false)
+	if !ok {
+		return
+	}
+	if _, err := os.Lstat(toPath); err == nil {
+		WriteError(c, ctx, "FILE_EXISTS", "File already exists: "+req.To, consts.StatusConflict)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(toPath), 0o755); err != nil {
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to create directory: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+	if err := os.Rename(fromPath, toPath); err != nil {
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to rename file: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+
+	if _, err := recordFileVersion(c, edit.app.History, edit.sessionID, fromPath, content, ""); err != nil {
+		slog.Error("Failed to record file history", "path", fromPath, "error", err)
+	}
+	notifyLSPDeleted(c, edit.app, fromPath)
+	edit.finish(c, ctx, models.FileEditRename, req.From, req.To, toPath, "", content)
+}
+
+// HandleDeleteFile 删除文件
+//
+//	@Summary		删除文件
+//	@Description	删除项目内的文件，删除前的内容和空内容记录到会话的文件历史，可通过历史恢复。通知 LSP 并发送 file.edited 事件。
+//	@Tags			File
+//	@Produce		json
+//	@Param			directory	query		string	true	"项目路径"
+//	@Param			path		query		string	true	"文件路径（相对于项目根目录）"
+//	@Param			session_id	query		string	true	"记录文件历史版本的会话"
+//	@Success		200			{object}	models.FileEditResponse
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		500			{object}	map[string]interface{}
+//	@Router			/file [delete]
+func (h *Handlers) HandleDeleteFile(c context.Context, ctx *hertzapp.RequestContext) {
+	path := string(ctx.Query("path"))
+
+	edit, ok := h.prepareFileEdit(c, ctx, string(ctx.Query("session_id")))
+	if !ok {
+		return
+	}
+	fullPath, ok := edit.resolve(c, ctx, path)
+	if !ok {
+		return
+	}
+
+	fileEditMu.Lock()
+	defer fileEditMu.Unlock()
+
+	oldContent, ok := readFileForEdit(c, ctx, fullPath, path, false)
+	if !ok {
+		return
+	}
+	if err := os.Remove(fullPath); err != nil {
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to delete file: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+	edit.finish(c, ctx, models.FileEditDelete, "", path, fullPath, oldContent, "")
+}
+
+// prepareFileEdit 获取项目的 app 实例并校验会话
+func (h *Handlers) prepareFileEdit(c context.Context, ctx *hertzapp.RequestContext, sessionID string) (*fileEdit, bool) {
+	projectPath := string(ctx.Query("directory"))
+	if projectPath == "" {
+		WriteError(c, ctx, "MISSING_DIRECTORY_PARAM", "Directory query parameter is required", consts.StatusBadRequest)
+		return nil, false
+	}
+	if sessionID == "" {
+		WriteError(c, ctx, "INVALID_REQUEST", "session_id is required", consts.StatusBadRequest)
+		return nil, false
+	}
+
+	// 获取项目的 app 实例
+	appInstance, err := h.GetAppForProject(c, projectPath)
+	if err != nil {
+		if strings.Contains(err.Error(), "project not found") {
+			WriteError(c, ctx, "PROJECT_NOT_FOUND", err.Error(), consts.StatusNotFound)
+			return nil, false
+		}
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to get or create app for project: "+err.Error(), consts.StatusInternalServerError)
+		return nil, false
+	}
+
+	if _, err := appInstance.Sessions.Get(c, sessionID); err != nil {
+		WriteError(c, ctx, "SESSION_NOT_FOUND", "Session not found: "+err.Error(), consts.StatusNotFound)
+		return nil, false
+	}
+
+	return &fileEdit{
+		projectPath: projectPath,
+		app:         appInstance,
+		root:        appInstance.Config().WorkingDir(),
+		sessionID:   sessionID,
+	}, true
+}
+
+// resolve 校验路径并返回绝对路径
+func (e *fileEdit) resolve(c context.Context, ctx *hertzapp.RequestContext, path string) (string, bool) {
+	fullPath, err := resolveProjectPath(e.root, path)
+	if err != nil {
+		WriteError(c, ctx, "INVALID_PATH", err.Error(), consts.StatusBadRequest)
+		return "", false
+	}
+	return fullPath, true
+}
+
+// resolveProjectPath 校验路径并返回绝对路径，路径必须位于项目根目录内，包括符号链接指向的位置
+func resolveProjectPath(root, path string) (string, error) {
+	if err := validatePath(path); err != nil {
+		return "", err
+	}
+
+	fullPath := path
+	if !filepath.IsAbs(path) {
+		fullPath = filepath.Join(root, path)
+	}
+	fullPath = filepath.Clean(fullPath)
+	if !isWithin(root, fullPath) || fullPath == root {
+		return "", fmt.Errorf("path is outside the project: %s", path)
+	}
+
+	// 从最近的已存在的上级开始解析符号链接
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		realRoot = root
+	}
+	for dir := fullPath; ; dir = filepath.Dir(dir) {
+		real, err := filepath.EvalSymlinks(dir)
+		if err == nil {
+			if !isWithin(realRoot, real) {
+				return "", fmt.Errorf("path resolves outside the project: %s", path)
+			}
+			break
+		}
+		if dir == filepath.Dir(dir) {
+			break
+		}
+	}
+	return fullPath, nil
+}
+
+// finish 记录文件历史，通知 LSP，发送 file.edited 事件并返回结果
+func (e *fileEdit) finish(c context.Context, ctx *hertzapp.RequestContext, action, from, path, fullPath, oldContent, newContent string) {
+	file, err := recordFileVersion(c, e.app.History, e.sessionID, fullPath, oldContent, newContent)
+	if err != nil {
+		// 文件已经修改，历史记录失败不影响结果
+		slog.Error("Failed to record file history", "path", fullPath, "error", err)
+	}
+
+	if action == models.FileEditDelete {
+		notifyLSPDeleted(c, e.app, fullPath)
+	} else {
+		notifyLSPChanged(c, e.app, fullPath)
+	}
+
+	publishProjectEvent(e.projectPath, e.app, models.SSEEvent{
+		Type: "file.edited",
+		Properties: models.FileEditedEvent{
+			File:      path,
+			From:      from,
+			Action:    action,
+			SessionID: e.sessionID,
+		},
+	})
+
+	params, _ := json.Marshal(struct {
+		Path string `json:"path"`
+		From string `json:"from,omitempty"`
+	}{Path: path, From: from})
+	e.app.Audit.Record(c, audit.Entry{
+		Kind:      audit.KindTool,
+		SessionID: e.sessionID,
+		ToolName:  fileEditAuditTool,
+		Action:    action,
+		Path:      fullPath,
+		Params:    string(params),
+		Status:    audit.StatusSuccess,
+		Actor:     requestActor(ctx),
+	})
+
+	_, additions, removals := diff.GenerateDiff(oldContent, newContent, path)
+	slog.Info("File edited", "project", e.projectPath, "path", path, "action", action, "session_id", e.sessionID)
+
+	WriteJSON(c, ctx, consts.StatusOK, models.FileEditResponse{
+		Path:      path,
+		From:      from,
+		Action:    action,
+		SessionID: e.sessionID,
+		Version:   file.Version,
+		Additions: additions,
+		Removals:  removals,
+	})
+}
+
+// readFileForEdit 读取修改前的内容，missingOK 为 true 时不存在的文件视为空内容
+func readFileForEdit(c context.Context, ctx *hertzapp.RequestContext, fullPath, path string, missingOK bool) (string, bool) {
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			if missingOK {
+				return "", true
+			}
+			WriteError(c, ctx, "FILE_NOT_FOUND", "File not found: "+path, consts.StatusNotFound)
+			return "", false
+		}
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to access file: "+err.Error(), consts.StatusInternalServerError)
+		return "", false
+	}
+	if info.IsDir() {
+		WriteError(c, ctx, "INVALID_PATH", "Path is a directory, not a file", consts.StatusBadRequest)
+		return "", false
+	}
+	content, err := os.ReadFile(fullPath)
+	if err != nil {
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to read file: "+err.Error(), consts.StatusInternalServerError)
+		return "", false
+	}
+	return string(content), true
+}
+
+// writeFileForEdit 写入文件，保留已有文件的权限
+func writeFileForEdit(fullPath string, content []byte) error {
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(fullPath); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(fullPath, content, mode)
+}
+
+// recordFileVersion 在会话的文件历史中记录新内容
+// 与 agent 的编辑工具一致：文件首次出现在会话中时先记录修改前的内容，
+// 会话外的修改也先记录为一个版本，保证历史中可以看到每次变化
+func recordFileVersion(c context.Context, files history.Service, sessionID, path, oldContent, newContent string) (history.File, error) {
+	file, err := files.GetByPathAndSession(c, path, sessionID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		if _, err := files.Create(c, sessionID, path, oldContent); err != nil {
+			return history.File{}, fmt.Errorf("creating file history: %w", err)
+		}
+	case err != nil:
+		return history.File{}, err
+	case file.Content != oldContent:
+		if _, err := files.CreateVersion(c, sessionID, path, oldContent); err != nil {
+			return history.File{}, fmt.Errorf("creating file history version: %w", err)
+		}
+	}
+	return files.CreateVersion(c, sessionID, path, newContent)
+}
+
+// notifyLSPChanged 通知处理该文件的 LSP 客户端文件内容已变化
+func notifyLSPChanged(c context.Context, appInstance *internalapp.App, path string) {
+	for client := range appInstance.LSPClients.Seq() {
+		if !client.HandlesFile(path) {
+			continue
+		}
+		if err := client.OpenFileOnDemand(c, path); err != nil {
+			slog.Debug("Failed to open file in LSP", "lsp", client.GetName(), "path", path, "error", err)
+			continue
+		}
+		if err := client.NotifyChange(c, path); err != nil {
+			slog.Debug("Failed to notify LSP of file change", "lsp", client.GetName(), "path", path, "error", err)
+		}
+	}
+}
+
+// notifyLSPDeleted 通知处理该文件的 LSP 客户端文件已删除
+func notifyLSPDeleted(c context.Context, appInstance *internalapp.App, path string) {
+	for client := range appInstance.LSPClients.Seq() {
+		if !client.HandlesFile(path) {
+			continue
+		}
+		if err := client.DidChangeWatchedFiles(c, protocol.DidChangeWatchedFilesParams{
+			Changes: []protocol.FileEvent{{URI: protocol.URIFromPath(path), Type: protocol.Deleted}},
+		}); err != nil {
+			slog.Debug("Failed to notify LSP of file deletion", "lsp", client.GetName(), "path", path, "error", err)
+		}
+	}
+}
+
+// isWithin 判断 path 是否位于 root 内（包括 root 本身）
+func isWithin(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
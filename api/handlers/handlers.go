@@ -1,10 +1,79 @@
 package handlers
 
+import (
+	"log/slog"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/metrics"
+	"github.com/charmbracelet/crush/internal/permission"
+)
+
+// 权限相关默认值
+const (
+	defaultPermissionMode    = permission.SessionModeAsk
+	defaultPermissionTimeout = 2 * time.Minute
+)
+
+// 项目实例回收默认值
+const (
+	defaultInstanceIdleTTL = 30 * time.Minute
+	defaultMaxInstances    = 16
+)
+
+// Options 处理器配置
+type Options struct {
+	// PermissionMode 创建会话时未指定权限模式时使用的默认模式
+	PermissionMode permission.SessionMode
+	// PermissionTimeout 权限请求等待回复的超时时间，超时后拒绝；nil 使用默认值，0 表示一直等待
+	PermissionTimeout *time.Duration
+	// MaxConcurrentRuns 全局同时进行的 agent 运行数，0 使用默认值，负数表示不限制
+	MaxConcurrentRuns int
+	// MaxProjectRuns 单个项目同时进行的 agent 运行数，0 使用默认值，负数表示不限制
+	MaxProjectRuns int
+	// InstanceIdleTTL 项目实例闲置多久后释放，0 使用默认值，负数表示不释放
+	InstanceIdleTTL time.Duration
+	// MaxInstances 同时保留的项目实例数，超出时淘汰最久未使用的空闲实例，0 使用默认值，负数表示不限制
+	MaxInstances int
+}
+
 // Handlers 包含所有 API 处理器
 type Handlers struct {
+	opts Options
+	runs *runLimiter
 }
 
 // New 创建新的处理器实例
-func New() *Handlers {
-	return &Handlers{}
+func New(opts Options) *Handlers {
+	if opts.PermissionMode == "" {
+		opts.PermissionMode = defaultPermissionMode
+	}
+	if opts.PermissionTimeout == nil {
+		timeout := defaultPermissionTimeout
+		opts.PermissionTimeout = &timeout
+	}
+	if opts.MaxConcurrentRuns == 0 {
+		opts.MaxConcurrentRuns = defaultMaxConcurrentRuns
+	}
+	if opts.MaxProjectRuns == 0 {
+		opts.MaxProjectRuns = defaultMaxProjectRuns
+	}
+	if opts.InstanceIdleTTL == 0 {
+		opts.InstanceIdleTTL = defaultInstanceIdleTTL
+	}
+	if opts.MaxInstances == 0 {
+		opts.MaxInstances = defaultMaxInstances
+	}
+	registerMetricsOnce.Do(func() {
+		if err := metrics.Register(appCollector{}); err != nil {
+			slog.Error("Failed to register app metrics", "error", err)
+		}
+	})
+	h := &Handlers{
+		opts: opts,
+		runs: newRunLimiter(opts.MaxConcurrentRuns, opts.MaxProjectRuns),
+	}
+	if opts.InstanceIdleTTL > 0 {
+		go h.runIdleEviction()
+	}
+	return h
 }
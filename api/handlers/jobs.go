@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/crush/api/models"
+	"github.com/charmbracelet/crush/internal/shell"
+	hertzapp "github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// jobOutputPollInterval 流式输出检查新输出的间隔
+const jobOutputPollInterval = 200 * time.Millisecond
+
+// HandleListJobs 获取后台任务列表
+//
+//	@Summary		获取后台任务列表
+//	@Description	返回 agent 通过 bash 工具的 run_in_background 在项目目录中启动的后台任务，按启动时间排序。
+//	@Description	已结束的任务在被 agent 或 API 终止、或结束 8 小时后不再返回。
+//	@Tags			Job
+//	@Produce		json
+//	@Param			directory	query	string	true	"项目路径"
+//	@Success		200			{array}		models.Job
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		500			{object}	map[string]interface{}
+//	@Router			/job [get]
+func (h *Handlers) HandleListJobs(c context.Context, ctx *hertzapp.RequestContext) {
+	appInstance, ok := h.projectApp(c, ctx)
+	if !ok {
+		return
+	}
+	root := appInstance.Config().WorkingDir()
+
+	jobs := []models.Job{}
+	for _, info := range shell.GetBackgroundShellManager().Jobs() {
+		if isWithin(root, info.WorkingDir) {
+			jobs = append(jobs, models.JobFromInfo(info))
+		}
+	}
+	WriteJSON(c, ctx, consts.StatusOK, jobs)
+}
+
+// HandleGetJob 获取后台任务
+//
+//	@Summary		获取后台任务
+//	@Tags			Job
+//	@Produce		json
+//	@Param			directory	query		string	true	"项目路径"
+//	@Param			id			path		string	true	"任务 ID"
+//	@Success		200			{object}	models.Job
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		500			{object}	map[string]interface{}
+//	@Router			/job/{id} [get]
+func (h *Handlers) HandleGetJob(c context.Context, ctx *hertzapp.RequestContext) {
+	job, ok := h.projectJob(c, ctx)
+	if !ok {
+		return
+	}
+	WriteJSON(c, ctx, consts.StatusOK, models.JobFromInfo(job.Info()))
+}
+
+// HandleGetJobOutput 获取后台任务的输出
+//
+//	@Summary		获取后台任务的输出
+//	@Description	返回 stdout 和 stderr 在指定偏移量（字节）之后的输出，以及下次读取的偏移量。轮询时传入上次返回的偏移量即可只获取新的输出。
+//	@Tags			Job
+//	@Produce		json
+//	@Param			directory		query		string	true	"项目路径"
+//	@Param			id				path		string	true	"任务 ID"
+//	@Param			stdout_offset	query		int		false	"stdout 偏移量，默认 0"
+//	@Param			stderr_offset	query		int		false	"stderr 偏移量，默认 0"
+//	@Success		200				{object}	models.JobOutput
+//	@Failure		400				{object}	map[string]interface{}
+//	@Failure		404				{object}	map[string]interface{}
+//	@Failure		500				{object}	map[string]interface{}
+//	@Router			/job/{id}/output [get]
+func (h *Handlers) HandleGetJobOutput(c context.Context, ctx *hertzapp.RequestContext) {
+	stdoutOffset, stderrOffset, ok := jobOutputOffsets(c, ctx)
+	if !ok {
+		return
+	}
+	job, ok := h.projectJob(c, ctx)
+	if !ok {
+		return
+	}
+
+	done := job.IsDone()
+	output := models.JobOutput{Done: done}
+	output.Stdout, output.Stderr, output.StdoutOffset, output.StderrOffset = job.OutputSince(stdoutOffset, stderrOffset)
+	WriteJSON(c, ctx, consts.StatusOK, output)
+}
+
+// HandleStreamJobOutput 流式获取后台任务的输出
+//
+//	@Summary		流式获取后台任务的输出
+//	@Description	以 SSE 发送指定偏移量之后的输出，有新的输出时发送 job.output 事件（properties 格式同 /job/{id}/output），
+//	@Description	任务结束并发送完所有输出后发送 job.finished 事件（properties 为任务）并关闭连接。断线后可以传入最后收到的偏移量继续读取。
+//	@Tags			Job
+//	@Produce		text/event-stream
+//	@Param			directory		query		string	true	"项目路径"
+//	@Param			id				path		string	true	"任务 ID"
+//	@Param			stdout_offset	query		int		false	"stdout 偏移量，默认 0"
+//	@Param			stderr_offset	query		int		false	"stderr 偏移量，默认 0"
+//	@Success		200				{object}	models.SSEEvent	"Event stream"
+//	@Failure		400				{object}	map[string]interface{}
+//	@Failure		404				{object}	map[string]interface{}
+//	@Router			/job/{id}/stream [get]
+func (h *Handlers) HandleStreamJobOutput(c context.Context, ctx *hertzapp.RequestContext) {
+	stdoutOffset, stderrOffset, ok := jobOutputOffsets(c, ctx)
+	if !ok {
+		return
+	}
+	job, ok := h.projectJob(c, ctx)
+	if !ok {
+		return
+	}
+
+	ctx.SetContentType("text/event-stream")
+	ctx.Response.Header.Set("Cache-Control", "no-cache")
+	ctx.Response.Header.Set("Connection", "keep-alive")
+	ctx.Response.Header.Set("X-Accel-Buffering", "no")
+
+	pr, pw := io.Pipe()
+	ctx.Response.SetBodyStream(pr, -1)
+
+	go func() {
+		defer pw.Close()
+
+		ticker := time.NewTicker(jobOutputPollInterval)
+		defer ticker.Stop()
+		// 长时间没有输出时通过心跳发现客户端断开
+		heartbeat := time.NewTicker(30 * time.Second)
+		defer heartbeat.Stop()
+
+		for {
+			// 先检查是否结束，保证结束前的输出都已发送
+			done := job.IsDone()
+			output := models.JobOutput{Done: done}
+			output.Stdout, output.Stderr, output.StdoutOffset, output.StderrOffset = job.OutputSince(stdoutOffset, stderrOffset)
+			if output.Stdout != "" || output.Stderr != "" {
+				if err := writeJobEvent(pw, "job.output", output); err != nil {
+					slog.Debug("Job output stream closed", "job", job.ID, "error", err)
+					return
+				}
+				stdoutOffset, stderrOffset = output.StdoutOffset, output.StderrOffset
+			}
+			if done {
+				_ = writeJobEvent(pw, "job.finished", models.JobFromInfo(job.Info()))
+				return
+			}
+			select {
+			case <-c.Done():
+				return
+			case <-heartbeat.C:
+				if _, err := fmt.Fprintf(pw, ": heartbeat\n\n"); err != nil {
+					return
+				}
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// HandleKillJob 终止后台任务
+//
+//	@Summary		终止后台任务
+//	@Description	终止后台任务并停止跟踪，与 agent 的 job_kill 工具相同。返回任务的最终状态，终止后任务不再出现在列表中。
+//	@Tags			Job
+//	@Produce		json
+//	@Param			directory	query		string	true	"项目路径"
+//	@Param			id			path		string	true	"任务 ID"
+//	@Success		200			{object}	models.Job
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		500			{object}	map[string]interface{}
+//	@Router			/job/{id}/kill [post]
+func (h *Handlers) HandleKillJob(c context.Context, ctx *hertzapp.RequestContext) {
+	job, ok := h.projectJob(c, ctx)
+	if !ok {
+		return
+	}
+	if err := shell.GetBackgroundShellManager().Kill(job.ID); err != nil {
+		// 已被 agent 或其他请求终止
+		WriteError(c, ctx, "JOB_NOT_FOUND", err.Error(), consts.StatusNotFound)
+		return
+	}
+	slog.Info("Background job killed", "job", job.ID, "command", job.Command)
+	WriteJSON(c, ctx, consts.StatusOK, models.JobFromInfo(job.Info()))
+}
+
+// projectJob 获取项目中的后台任务，不在项目目录中的任务视为不存在
+func (h *Handlers) projectJob(c context.Context, ctx *hertzapp.RequestContext) (*shell.BackgroundShell, bool) {
+	appInstance, ok := h.projectApp(c, ctx)
+	if !ok {
+		return nil, false
+	}
+	id := ctx.Param("id")
+	job, ok := shell.GetBackgroundShellManager().Get(id)
+	if !ok || !isWithin(appInstance.Config().WorkingDir(), job.WorkingDir) {
+		WriteError(c, ctx, "JOB_NOT_FOUND", "Job not found: "+id, consts.StatusNotFound)
+		return nil, false
+	}
+	return job, true
+}
+
+// jobOutputOffsets 解析 stdout_offset 和 stderr_offset 参数
+func jobOutputOffsets(c context.Context, ctx *hertzapp.RequestContext) (stdoutOffset, stderrOffset int, ok bool) {
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{{"stdout_offset", &stdoutOffset}, {"stderr_offset", &stderrOffset}} {
+		raw := string(ctx.Query(p.name))
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			WriteError(c, ctx, "INVALID_REQUEST", p.name+" must be a non-negative integer", consts.StatusBadRequest)
+			return 0, 0, false
+		}
+		*p.dst = n
+	}
+	return stdoutOffset, stderrOffset, true
+}
+
+// writeJobEvent 写入后台任务输出流的 SSE 事件
+func writeJobEvent(w io.Writer, eventType string, properties any) error {
+	data := encodeEvent(models.SSEEvent{Type: eventType, Properties: properties})
+	_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventType, data)
+	return err
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+
+	"github.com/charmbracelet/crush/api/models"
+	internalapp "github.com/charmbracelet/crush/internal/app"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/x/powernap/pkg/lsp/protocol"
+	hertzapp "github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// HandleGetDiagnostics 获取 LSP 诊断
+//
+//	@Summary		获取 LSP 诊断
+//	@Description	返回项目中已就绪的 LSP 服务器报告的诊断，与 agent 通过 diagnostics 工具看到的一致。
+//	@Description	指定 path 时只返回该文件的诊断，文件未打开时会先在处理它的 LSP 服务器中打开；不指定时返回 LSP 服务器已报告的所有诊断。
+//	@Tags			LSP
+//	@Produce		json
+//	@Param			directory	query		string	true	"项目路径"
+//	@Param			path		query		string	false	"文件路径（相对于项目根目录）"
+//	@Success		200			{object}	models.DiagnosticsResponse
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		500			{object}	map[string]interface{}
+//	@Router			/lsp/diagnostics [get]
+func (h *Handlers) HandleGetDiagnostics(c context.Context, ctx *hertzapp.RequestContext) {
+	appInstance, ok := h.projectApp(c, ctx)
+	if !ok {
+		return
+	}
+	root := appInstance.Config().WorkingDir()
+
+	var diagnostics []models.Diagnostic
+	if path := string(ctx.Query("path")); path != "" {
+		fullPath, err := resolveProjectPath(root, path)
+		if err != nil {
+			WriteError(c, ctx, "INVALID_PATH", err.Error(), consts.StatusBadRequest)
+			return
+		}
+		if _, err := os.Stat(fullPath); err != nil {
+			if os.IsNotExist(err) {
+				WriteError(c, ctx, "FILE_NOT_FOUND", "File not found: "+path, consts.StatusNotFound)
+				return
+			}
+			WriteError(c, ctx, "INTERNAL_ERROR", "Failed to access file: "+err.Error(), consts.StatusInternalServerError)
+			return
+		}
+
+		uri := protocol.URIFromPath(fullPath)
+		for _, client := range readyLSPClients(appInstance) {
+			if !client.HandlesFile(fullPath) {
+				continue
+			}
+			diags, err := client.GetDiagnosticsForFile(c, fullPath)
+			if err != nil {
+				// 其他服务器可能仍有结果
+				slog.Warn("Failed to get LSP diagnostics", "lsp", client.GetName(), "path", path, "error", err)
+				continue
+			}
+			diagnostics = appendDiagnostics(diagnostics, root, client.GetName(), uri, diags)
+		}
+	} else {
+		for _, client := range readyLSPClients(appInstance) {
+			for uri, diags := range client.GetDiagnostics() {
+				diagnostics = appendDiagnostics(diagnostics, root, client.GetName(), uri, diags)
+			}
+		}
+	}
+
+	slices.SortFunc(diagnostics, func(a, b models.Diagnostic) int {
+		return cmp.Or(
+			cmp.Compare(a.Path, b.Path),
+			cmp.Compare(a.Range.Start.Line, b.Range.Start.Line),
+			cmp.Compare(a.Range.Start.Character, b.Range.Start.Character),
+			cmp.Compare(a.Server, b.Server),
+		)
+	})
+
+	response := models.DiagnosticsResponse{Diagnostics: make([]models.Diagnostic, 0, len(diagnostics))}
+	for _, d := range diagnostics {
+		switch d.Severity {
+		case "error":
+			response.Counts.Error++
+		case "warning":
+			response.Counts.Warning++
+		case "information":
+			response.Counts.Information++
+		case "hint":
+			response.Counts.Hint++
+		}
+		response.Diagnostics = append(response.Diagnostics, d)
+	}
+
+	WriteJSON(c, ctx, consts.StatusOK, response)
+}
+
+// HandleRestartLSP 重启 LSP 服务器
+//
+//	@Summary		重启 LSP 服务器
+//	@Description	关闭 LSP 服务器并按相同配置重新启动，重新打开之前打开的文件，用于服务器崩溃或卡住时恢复，无需释放项目实例。等待服务器就绪后返回状态。
+//	@Description	配置文件中的 LSP 服务器未运行时（例如启动失败）在后台启动，返回的 status 为 starting，可以通过 SSE 的 lsp.server.state_changed 事件获取结果。
+//	@Tags			LSP
+//	@Produce		json
+//	@Param			directory	query		string	true	"项目路径"
+//	@Param			name		path		string	true	"LSP 服务器名称"
+//	@Success		200			{object}	models.LSPStatus
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		500			{object}	map[string]interface{}
+//	@Failure		502			{object}	map[string]interface{}
+//	@Router			/lsp/{name}/restart [post]
+func (h *Handlers) HandleRestartLSP(c context.Context, ctx *hertzapp.RequestContext) {
+	appInstance, ok := h.projectApp(c, ctx)
+	if !ok {
+		return
+	}
+	name := ctx.Param("name")
+
+	if err := appInstance.RestartLSP(name); err != nil {
+		if errors.Is(err, internalapp.ErrLSPNotFound) {
+			WriteError(c, ctx, "LSP_NOT_FOUND", "LSP server not found: "+name, consts.StatusNotFound)
+			return
+		}
+		WriteError(c, ctx, "LSP_RESTART_FAILED", "Failed to restart LSP server: "+err.Error(), consts.StatusBadGateway)
+		return
+	}
+
+	WriteJSON(c, ctx, consts.StatusOK, lspStatus(name, appInstance.Config().WorkingDir()))
+}
+
+// lspStatus 返回 LSP 服务器的状态
+func lspStatus(name, root string) models.LSPStatus {
+	status := models.LSPStatus{
+		ID:     name,
+		Name:   name,
+		Root:   root,
+		Status: "starting",
+	}
+	if info, ok := internalapp.GetLSPState(name); ok {
+		switch info.State {
+		case lsp.StateReady:
+			status.Status = "connected"
+		case lsp.StateError:
+			status.Status = "error"
+		case lsp.StateDisabled:
+			status.Status = "disabled"
+		}
+	}
+	return status
+}
+
+// appendDiagnostics 转换为 API 的诊断并追加到 out
+func appendDiagnostics(out []models.Diagnostic, root, server string, uri protocol.DocumentURI, diags []protocol.Diagnostic) []models.Diagnostic {
+	for _, d := range diags {
+		loc := toLocationModel(root, protocol.Location{URI: uri, Range: d.Range})
+		diagnostic := models.Diagnostic{
+			Path:     loc.Path,
+			Range:    loc.Range,
+			Severity: diagnosticSeverity(d.Severity),
+			Source:   d.Source,
+			Message:  d.Message,
+			Server:   server,
+		}
+		if d.Code != nil {
+			diagnostic.Code = fmt.Sprint(d.Code)
+		}
+		out = append(out, diagnostic)
+	}
+	return out
+}
+
+// diagnosticSeverity 返回诊断严重程度的名称，未指定时视为 error
+func diagnosticSeverity(severity protocol.DiagnosticSeverity) string {
+	switch severity {
+	case protocol.SeverityWarning:
+		return "warning"
+	case protocol.SeverityInformation:
+		return "information"
+	case protocol.SeverityHint:
+		return "hint"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,378 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/charmbracelet/crush/api/middleware"
+	"github.com/charmbracelet/crush/api/models"
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
+	internalapp "github.com/charmbracelet/crush/internal/app"
+	"github.com/charmbracelet/crush/internal/audit"
+	"github.com/charmbracelet/crush/internal/config"
+	hertzapp "github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// HandleRestartMCP 重连 MCP 服务器
+//
+//	@Summary		重连 MCP 服务器
+//	@Description	关闭 MCP 服务器的连接（stdio 服务器会重启进程）并按当前配置重新连接，重新加载工具和 prompt，用于服务器崩溃或卡住时恢复，无需释放项目实例。
+//	@Description	MCP 客户端由所有项目共用，所有已加载项目的 agent 工具都会更新，调用方不能是受项目范围限制的 API Key。连接完成后返回状态，连接失败时 status 为 failed。
+//	@Tags			MCP
+//	@Produce		json
+//	@Param			directory	query		string	true	"项目路径"
+//	@Param			name		path		string	true	"MCP 服务器名称"
+//	@Success		200			{object}	models.MCPServerStatus
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		403			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Router			/mcp/{name}/restart [post]
+func (h *Handlers) HandleRestartMCP(c context.Context, ctx *hertzapp.RequestContext) {
+	appInstance, name, ok := h.mcpServer(c, ctx, true)
+	if !ok {
+		return
+	}
+	if err := appInstance.RestartMCP(c, name); err != nil {
+		writeMCPAppError(c, ctx, name, err)
+		return
+	}
+	syncMCP(c, appInstance, name)
+	WriteJSON(c, ctx, consts.StatusOK, mcpServerStatus(name, appInstance.Config().MCP[name]))
+}
+
+// HandleEnableMCP 启用 MCP 服务器
+//
+//	@Summary		启用 MCP 服务器
+//	@Description	在运行时启用 MCP 服务器并连接，对所有已加载的项目生效。修改不写入配置文件，重新加载配置后恢复为配置文件中的设置。调用方不能是受项目范围限制的 API Key。
+//	@Tags			MCP
+//	@Produce		json
+//	@Param			directory	query		string	true	"项目路径"
+//	@Param			name		path		string	true	"MCP 服务器名称"
+//	@Success		200			{object}	models.MCPServerStatus
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		403			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Router			/mcp/{name}/enable [post]
+func (h *Handlers) HandleEnableMCP(c context.Context, ctx *hertzapp.RequestContext) {
+	h.setMCPDisabled(c, ctx, false)
+}
+
+// HandleDisableMCP 禁用 MCP 服务器
+//
+//	@Summary		禁用 MCP 服务器
+//	@Description	在运行时断开并禁用 MCP 服务器，所有已加载项目的 agent 不再使用它的工具。修改不写入配置文件，持久禁用请使用 PUT /project/config/mcp/{name}。调用方不能是受项目范围限制的 API Key。
+//	@Tags			MCP
+//	@Produce		json
+//	@Param			directory	query		string	true	"项目路径"
+//	@Param			name		path		string	true	"MCP 服务器名称"
+//	@Success		200			{object}	models.MCPServerStatus
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		403			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Router			/mcp/{name}/disable [post]
+func (h *Handlers) HandleDisableMCP(c context.Context, ctx *hertzapp.RequestContext) {
+	h.setMCPDisabled(c, ctx, true)
+}
+
+// setMCPDisabled 在运行时启用或禁用 MCP 服务器并返回其状态
+func (h *Handlers) setMCPDisabled(c context.Context, ctx *hertzapp.RequestContext, disabled bool) {
+	appInstance, name, ok := h.mcpServer(c, ctx, true)
+	if !ok {
+		return
+	}
+	if err := appInstance.SetMCPDisabled(c, name, disabled); err != nil {
+		writeMCPAppError(c, ctx, name, err)
+		return
+	}
+	syncMCP(c, appInstance, name)
+	WriteJSON(c, ctx, consts.StatusOK, mcpServerStatus(name, appInstance.Config().MCP[name]))
+}
+
+// HandleListMCPTools 获取 MCP 服务器的工具
+//
+//	@Summary		获取 MCP 工具
+//	@Description	从 MCP 服务器获取工具列表及其参数的 JSON Schema，包括配置的 disabled_tools 中的工具（disabled 为 true）。
+//	@Description	服务器未连接时返回 409 MCP_NOT_CONNECTED，请求失败时返回 502 MCP_REQUEST_FAILED。
+//	@Tags			MCP
+//	@Produce		json
+//	@Param			directory	query		string	true	"项目路径"
+//	@Param			name		path		string	true	"MCP 服务器名称"
+//	@Success		200			{array}		models.MCPTool
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		409			{object}	map[string]interface{}
+//	@Failure		502			{object}	map[string]interface{}
+//	@Router			/mcp/{name}/tools [get]
+func (h *Handlers) HandleListMCPTools(c context.Context, ctx *hertzapp.RequestContext) {
+	appInstance, name, ok := h.connectedMCPServer(c, ctx, false)
+	if !ok {
+		return
+	}
+	tools, err := mcp.ListTools(c, name)
+	if err != nil {
+		WriteError(c, ctx, "MCP_REQUEST_FAILED", "Failed to list MCP tools: "+err.Error(), consts.StatusBadGateway)
+		return
+	}
+
+	disabledTools := appInstance.Config().MCP[name].DisabledTools
+	result := make([]models.MCPTool, 0, len(tools))
+	for _, tool := range tools {
+		result = append(result, models.MCPTool{
+			Name:         tool.Name,
+			Title:        tool.Title,
+			Description:  tool.Description,
+			InputSchema:  tool.InputSchema,
+			OutputSchema: tool.OutputSchema,
+			Disabled:     slices.Contains(disabledTools, tool.Name),
+		})
+	}
+	WriteJSON(c, ctx, consts.StatusOK, result)
+}
+
+// HandleListMCPPrompts 获取 MCP 服务器的 prompt
+//
+//	@Summary		获取 MCP prompt
+//	@Description	从 MCP 服务器获取 prompt 列表。prompt 可以通过 /session/{id}/command 以 "服务器:prompt" 执行。
+//	@Tags			MCP
+//	@Produce		json
+//	@Param			directory	query		string	true	"项目路径"
+//	@Param			name		path		string	true	"MCP 服务器名称"
+//	@Success		200			{array}		models.MCPPrompt
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		409			{object}	map[string]interface{}
+//	@Failure		502			{object}	map[string]interface{}
+//	@Router			/mcp/{name}/prompts [get]
+func (h *Handlers) HandleListMCPPrompts(c context.Context, ctx *hertzapp.RequestContext) {
+	_, name, ok := h.connectedMCPServer(c, ctx, false)
+	if !ok {
+		return
+	}
+	prompts, err := mcp.ListPrompts(c, name)
+	if err != nil {
+		WriteError(c, ctx, "MCP_REQUEST_FAILED", "Failed to list MCP prompts: "+err.Error(), consts.StatusBadGateway)
+		return
+	}
+
+	result := make([]models.MCPPrompt, 0, len(prompts))
+	for _, prompt := range prompts {
+		args := make([]models.MCPPromptArgument, 0, len(prompt.Arguments))
+		for _, arg := range prompt.Arguments {
+			args = append(args, models.MCPPromptArgument{
+				Name:        arg.Name,
+				Title:       arg.Title,
+				Description: arg.Description,
+				Required:    arg.Required,
+			})
+		}
+		result = append(result, models.MCPPrompt{
+			Name:        prompt.Name,
+			Title:       prompt.Title,
+			Description: prompt.Description,
+			Arguments:   args,
+		})
+	}
+	WriteJSON(c, ctx, consts.StatusOK, result)
+}
+
+// HandleListMCPResources 获取 MCP 服务器的资源
+//
+//	@Summary		获取 MCP 资源
+//	@Description	从 MCP 服务器获取资源列表，不支持资源的服务器返回空列表。
+//	@Tags			MCP
+//	@Produce		json
+//	@Param			directory	query		string	true	"项目路径"
+//	@Param			name		path		string	true	"MCP 服务器名称"
+//	@Success		200			{array}		models.MCPResource
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		409			{object}	map[string]interface{}
+//	@Failure		502			{object}	map[string]interface{}
+//	@Router			/mcp/{name}/resources [get]
+func (h *Handlers) HandleListMCPResources(c context.Context, ctx *hertzapp.RequestContext) {
+	_, name, ok := h.connectedMCPServer(c, ctx, false)
+	if !ok {
+		return
+	}
+	resources, err := mcp.ListResources(c, name)
+	if err != nil {
+		WriteError(c, ctx, "MCP_REQUEST_FAILED", "Failed to list MCP resources: "+err.Error(), consts.StatusBadGateway)
+		return
+	}
+
+	result := make([]models.MCPResource, 0, len(resources))
+	for _, resource := range resources {
+		result = append(result, models.MCPResource{
+			URI:         resource.URI,
+			Name:        resource.Name,
+			Title:       resource.Title,
+			Description: resource.Description,
+			MIMEType:    resource.MIMEType,
+			Size:        resource.Size,
+		})
+	}
+	WriteJSON(c, ctx, consts.StatusOK, result)
+}
+
+// HandleCallMCPTool 直接调用 MCP 工具
+//
+//	@Summary		调用 MCP 工具
+//	@Description	不经过 agent 和权限确认直接调用 MCP 工具，用于调试。disabled_tools 中的工具也可以调用。调用方不能是受项目范围限制的 API Key。
+//	@Description	工具返回的错误内容在 content 中，调用本身失败时返回 502 MCP_REQUEST_FAILED。
+//	@Tags			MCP
+//	@Accept			json
+//	@Produce		json
+//	@Param			directory	query		string						true	"项目路径"
+//	@Param			name		path		string						true	"MCP 服务器名称"
+//	@Param			tool		path		string						true	"工具名称"
+//	@Param			request		body		models.MCPToolCallRequest	false	"工具参数"
+//	@Success		200			{object}	models.MCPToolCallResponse
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		403			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		409			{object}	map[string]interface{}
+//	@Failure		502			{object}	map[string]interface{}
+//	@Router			/mcp/{name}/tools/{tool}/call [post]
+func (h *Handlers) HandleCallMCPTool(c context.Context, ctx *hertzapp.RequestContext) {
+	var req models.MCPToolCallRequest
+	if len(ctx.Request.Body()) > 0 {
+		if err := ctx.BindJSON(&req); err != nil {
+			WriteError(c, ctx, "INVALID_REQUEST", "Invalid request body: "+err.Error(), consts.StatusBadRequest)
+			return
+		}
+	}
+	if req.Arguments == nil {
+		req.Arguments = map[string]any{}
+	}
+	input, err := json.Marshal(req.Arguments)
+	if err != nil {
+		WriteError(c, ctx, "INVALID_REQUEST", "Invalid arguments: "+err.Error(), consts.StatusBadRequest)
+		return
+	}
+
+	appInstance, name, ok := h.connectedMCPServer(c, ctx, true)
+	if !ok {
+		return
+	}
+
+	start := time.Now()
+	result, err := mcp.RunTool(c, name, ctx.Param("tool"), string(input))
+	entry := audit.Entry{
+		Kind:       audit.KindTool,
+		ToolName:   fmt.Sprintf("mcp_%s_%s", name, ctx.Param("tool")),
+		Params:     string(input),
+		Status:     audit.StatusSuccess,
+		Actor:      requestActor(ctx),
+		CreatedAt:  start,
+		FinishedAt: time.Now(),
+	}
+	if err != nil {
+		entry.Status = audit.StatusError
+		entry.Error = err.Error()
+	}
+	appInstance.Audit.Record(c, entry)
+	if err != nil {
+		WriteError(c, ctx, "MCP_REQUEST_FAILED", "Failed to call MCP tool: "+err.Error(), consts.StatusBadGateway)
+		return
+	}
+	WriteJSON(c, ctx, consts.StatusOK, models.MCPToolCallResponse{
+		Type:       result.Type,
+		Content:    result.Content,
+		Data:       result.Data,
+		MediaType:  result.MediaType,
+		DurationMs: time.Since(start).Milliseconds(),
+	})
+}
+
+// syncMCP 让其他项目实例同步 MCP 服务器的变化并更新 agent 的工具，MCP 客户端由所有项目共用
+func syncMCP(c context.Context, source *internalapp.App, name string) {
+	disabled := source.Config().MCP[name].Disabled
+
+	globalAppManager.mu.RLock()
+	apps := slices.Collect(maps.Values(globalAppManager.apps))
+	globalAppManager.mu.RUnlock()
+
+	for _, appInstance := range apps {
+		if appInstance != source {
+			appInstance.SyncMCP(c, name, disabled)
+		}
+	}
+}
+
+// mcpServer 校验 directory 参数和路径中的 MCP 服务器名称，返回项目 app 实例
+// write 为 true 时拒绝受项目范围限制的调用方，MCP 客户端由所有项目共用
+func (h *Handlers) mcpServer(c context.Context, ctx *hertzapp.RequestContext, write bool) (*internalapp.App, string, bool) {
+	if principal, ok := middleware.PrincipalFrom(ctx); write && ok && principal.Scoped() {
+		WriteError(c, ctx, "FORBIDDEN", "API key is restricted to specific projects and cannot manage the shared MCP servers", consts.StatusForbidden)
+		return nil, "", false
+	}
+	appInstance, ok := h.projectApp(c, ctx)
+	if !ok {
+		return nil, "", false
+	}
+	name := ctx.Param("name")
+	if _, ok := appInstance.Config().MCP[name]; !ok {
+		WriteError(c, ctx, "MCP_NOT_FOUND", "MCP server not found: "+name, consts.StatusNotFound)
+		return nil, "", false
+	}
+	return appInstance, name, true
+}
+
+// connectedMCPServer 在 mcpServer 的基础上要求服务器已连接
+func (h *Handlers) connectedMCPServer(c context.Context, ctx *hertzapp.RequestContext, write bool) (*internalapp.App, string, bool) {
+	appInstance, name, ok := h.mcpServer(c, ctx, write)
+	if !ok {
+		return nil, "", false
+	}
+	if status := mcpServerStatus(name, appInstance.Config().MCP[name]); status.Status != "connected" {
+		msg := "MCP server " + name + " is " + status.Status
+		if status.Message != "" {
+			msg += ": " + status.Message
+		}
+		WriteError(c, ctx, "MCP_NOT_CONNECTED", msg, consts.StatusConflict)
+		return nil, "", false
+	}
+	return appInstance, name, true
+}
+
+// writeMCPAppError 写入 app 的 MCP 操作错误
+func writeMCPAppError(c context.Context, ctx *hertzapp.RequestContext, name string, err error) {
+	if errors.Is(err, internalapp.ErrMCPNotFound) {
+		WriteError(c, ctx, "MCP_NOT_FOUND", "MCP server not found: "+name, consts.StatusNotFound)
+		return
+	}
+	WriteError(c, ctx, "INTERNAL_ERROR", err.Error(), consts.StatusInternalServerError)
+}
+
+// mcpServerStatus 返回 MCP 服务器的状态，尚未初始化的服务器按配置视为 disabled 或 starting
+func mcpServerStatus(name string, m config.MCPConfig) models.MCPServerStatus {
+	status := models.MCPServerStatus{Name: name}
+	info, ok := mcp.GetState(name)
+	if !ok {
+		status.Status = mcp.StateStarting.String()
+		if m.Disabled {
+			status.Status = mcp.StateDisabled.String()
+		}
+		return status
+	}
+
+	status.Status = info.State.String()
+	if info.State == mcp.StateError {
+		status.Status = "failed"
+		if info.Error != nil {
+			status.Message = info.Error.Error()
+		}
+	}
+	status.Tools = info.Counts.Tools
+	status.Prompts = info.Counts.Prompts
+	if info.State == mcp.StateConnected {
+		status.ConnectedAt = info.ConnectedAt.UnixMilli()
+	}
+	return status
+}
@@ -3,13 +3,17 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/crush/api/models"
+	"github.com/charmbracelet/crush/internal/agent"
 	internalapp "github.com/charmbracelet/crush/internal/app"
+	"github.com/charmbracelet/crush/internal/audit"
 	"github.com/charmbracelet/crush/internal/message"
 	hertzapp "github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
@@ -18,12 +22,14 @@ import (
 // HandleListMessages 处理获取会话消息列表的请求
 //
 //	@Summary		获取消息列表
-//	@Description	获取指定会话的所有消息
+//	@Description	获取指定会话的消息，可通过 limit/offset 分页，total 为消息总数
 //	@Tags			Message
 //	@Accept			json
 //	@Produce		json
 //	@Param			directory	query		string	true	"项目路径"
 //	@Param			sessionID	path		string	true	"会话ID"
+//	@Param			limit		query		int		false	"单页条数，最大 1000，不指定时返回全部"
+//	@Param			offset		query		int		false	"跳过的条数"
 //	@Success		200			{object}	models.MessagesResponse
 //	@Failure		400			{object}	map[string]interface{}
 //	@Failure		404			{object}	map[string]interface{}
@@ -54,11 +60,12 @@ func (h *Handlers) HandleListMessages(c context.Context, ctx *hertzapp.RequestCo
 		return
 	}
 
+	page := paginate(ctx, messages)
 	response := models.MessagesResponse{
-		Messages: make([]models.MessageResponse, len(messages)),
+		Messages: make([]models.MessageResponse, len(page)),
 		Total:    len(messages),
 	}
-	for i, m := range messages {
+	for i, m := range page {
 		response.Messages[i] = models.MessageToResponse(m)
 	}
 
@@ -74,10 +81,14 @@ func (h *Handlers) HandleListMessages(c context.Context, ctx *hertzapp.RequestCo
 //	@Produce		json
 //	@Param			directory	query		string					true	"Project path"
 //	@Param			sessionID	path		string					true	"Session ID"
+//	@Param			async		query		bool					false	"Run in the background and return a run ID immediately"
 //	@Param			request		body		models.PromptRequest	true	"Prompt request"
 //	@Success		200			{object}	models.PromptResponse
+//	@Success		202			{object}	models.RunResponse
 //	@Failure		400			{object}	map[string]interface{}
 //	@Failure		404			{object}	map[string]interface{}
+//	@Failure		413			{object}	map[string]interface{}
+//	@Failure		429			{object}	map[string]interface{}
 //	@Router			/session/{sessionID}/prompt [post]
 func (h *Handlers) HandlePrompt(c context.Context, ctx *hertzapp.RequestContext) {
 	projectPath := string(ctx.Query("directory"))
@@ -86,6 +97,7 @@ func (h *Handlers) HandlePrompt(c context.Context, ctx *hertzapp.RequestContext)
 		return
 	}
 	sessionID := ctx.Param("sessionID")
+	async, _ := strconv.ParseBool(string(ctx.Query("async")))
 
 	var req models.PromptRequest
 	if err := ctx.BindJSON(&req); err != nil {
@@ -109,21 +121,89 @@ func (h *Handlers) HandlePrompt(c context.Context, ctx *hertzapp.RequestContext)
 		return
 	}
 
-	// 自动批准权限请求
-	appInstance.Permissions.AutoApproveSession(sessionID)
-
-	// 从 Parts 中提取 prompt 文本
-	promptText := models.ExtractPromptTextFromParts(req.Parts)
+	prepared, apiErr := h.preparePrompt(c, appInstance, sessionID, req)
+	if apiErr != nil {
+		apiErr.write(c, ctx)
+		return
+	}
 
 	if req.NoReply {
 		// NoReply 模式 - 仅创建用户消息，不运行 AI
-		h.handleNoReplyPrompt(c, ctx, sessionID, req, appInstance)
-	} else {
+		if err := commitSessionRevert(c, appInstance, sessionID); err != nil {
+			WriteError(c, ctx, "INTERNAL_ERROR", "Failed to apply session revert: "+err.Error(), consts.StatusInternalServerError)
+			return
+		}
+		h.handleNoReplyPrompt(c, ctx, sessionID, req, prepared.attachments, appInstance)
+		return
+	}
+
+	// 占用运行名额，agent 运行结束时释放
+	release, ok := h.acquireRunSlot(c, ctx, appInstance)
+	if !ok {
+		return
+	}
+
+	switch {
+	case async:
+		// 异步模式 - 后台运行 AI，立即返回运行 ID
+		h.handleAsyncPrompt(c, ctx, sessionID, prepared.text, prepared.attachments, prepared.opts, appInstance, release)
+	default:
 		// 运行 AI 并获取响应
-		h.handleSyncPrompt(c, ctx, sessionID, promptText, appInstance)
+		h.handleSyncPrompt(c, ctx, sessionID, prepared.text, prepared.attachments, prepared.opts, appInstance, release)
 	}
 }
 
+// preparedPrompt 已校验的 prompt
+type preparedPrompt struct {
+	text        string
+	attachments []message.Attachment
+	opts        agent.RunOptions
+}
+
+// preparePrompt 校验 prompt 请求并准备运行所需的参数，HTTP 和 WebSocket 共用
+// 同时应用会话的权限模式。会话之前的回退由调用方在运行开始前确认，
+// 避免请求被拒绝（429、409）时丢失回退
+func (h *Handlers) preparePrompt(c context.Context, appInstance *internalapp.App, sessionID string, req models.PromptRequest) (*preparedPrompt, *apiError) {
+	// 验证会话存在
+	if _, err := appInstance.Sessions.Get(c, sessionID); err != nil {
+		return nil, &apiError{"SESSION_NOT_FOUND", "Session not found: " + err.Error(), consts.StatusNotFound}
+	}
+	if len(req.Parts) == 0 {
+		return nil, &apiError{"INVALID_REQUEST", "Parts array is required", consts.StatusBadRequest}
+	}
+
+	// 本次 prompt 的模型和参数覆盖
+	runOpts, err := runOptionsFromRequest(appInstance.Config(), req)
+	if err != nil {
+		return nil, &apiError{"INVALID_REQUEST", err.Error(), consts.StatusBadRequest}
+	}
+
+	// file 部分转换为附件
+	attachments, err := partsToAttachments(appInstance.Config().WorkingDir(), req.Parts)
+	if err != nil {
+		status := consts.StatusBadRequest
+		if errors.Is(err, errAttachmentTooLarge) {
+			status = consts.StatusRequestEntityTooLarge
+		}
+		return nil, &apiError{"INVALID_ATTACHMENT", err.Error(), status}
+	}
+	if hasBinaryAttachment(attachments) {
+		if name, ok := modelSupportsImages(appInstance.Config(), runOpts); !ok {
+			return nil, &apiError{"UNSUPPORTED_ATTACHMENT", fmt.Sprintf("Model %s does not support image or binary file attachments", name), consts.StatusBadRequest}
+		}
+	}
+
+	// 按会话的权限模式处理权限请求
+	h.applyPermissionMode(c, appInstance, sessionID)
+
+	return &preparedPrompt{
+		// 从 Parts 中提取 prompt 文本
+		text:        models.ExtractPromptTextFromParts(req.Parts),
+		attachments: attachments,
+		opts:        runOpts,
+	}, nil
+}
+
 // 消息处理相关常量
 const (
 	promptTimeout    = 5 * time.Minute        // AI 推理超时时间
@@ -132,17 +212,20 @@ const (
 )
 
 // handleSyncPrompt 处理同步消息响应（Opencode 兼容）
-func (h *Handlers) handleSyncPrompt(c context.Context, ctx *hertzapp.RequestContext, sessionID, prompt string, appInstance *internalapp.App) {
-	assistantMsg, err := h.waitForAIResponse(c, sessionID, prompt, appInstance)
+// 先确认会话之前的回退再运行；release 释放运行名额，在 agent 运行结束（或未能启动）时调用
+func (h *Handlers) handleSyncPrompt(c context.Context, ctx *hertzapp.RequestContext, sessionID, prompt string, attachments []message.Attachment, opts agent.RunOptions, appInstance *internalapp.App, release func()) {
+	// 新消息会确认之前的回退，删除被回退的消息
+	if err := commitSessionRevert(c, appInstance, sessionID); err != nil {
+		release()
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to apply session revert: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+
+	// 运行中的工具执行在审计日志中记录为当前调用方发起
+	c = audit.WithActor(c, requestActor(ctx))
+	assistantMsg, err := h.waitForAIResponse(c, sessionID, prompt, attachments, opts, appInstance, release)
 	if err != nil {
-		switch err.Error() {
-		case "request_cancelled":
-			WriteError(c, ctx, "REQUEST_CANCELLED", "Request cancelled", consts.StatusRequestTimeout)
-		case "timeout":
-			WriteError(c, ctx, "TIMEOUT", "Request timeout", consts.StatusRequestTimeout)
-		default:
-			WriteError(c, ctx, "INTERNAL_ERROR", "Failed to run agent: "+err.Error(), consts.StatusInternalServerError)
-		}
+		writePromptError(c, ctx, err)
 		return
 	}
 
@@ -153,7 +236,8 @@ func (h *Handlers) handleSyncPrompt(c context.Context, ctx *hertzapp.RequestCont
 
 // waitForAIResponse 等待 AI 响应完成
 // 返回 assistant 消息和错误（如果有）
-func (h *Handlers) waitForAIResponse(c context.Context, sessionID, prompt string, appInstance *internalapp.App) (message.Message, error) {
+// release 在 agent 运行结束时调用，而不是在返回时：最后一条消息完成或等待超时后运行可能仍在进行
+func (h *Handlers) waitForAIResponse(c context.Context, sessionID, prompt string, attachments []message.Attachment, opts agent.RunOptions, appInstance *internalapp.App, release func()) (message.Message, error) {
 	var assistantMsg message.Message
 
 	// 运行 AI（AgentCoordinator 内部会创建用户消息）
@@ -163,6 +247,7 @@ func (h *Handlers) waitForAIResponse(c context.Context, sessionID, prompt string
 	}, 1)
 
 	go func() {
+		defer release()
 		if appInstance.AgentCoordinator == nil {
 			done <- struct {
 				result interface{}
@@ -172,7 +257,7 @@ func (h *Handlers) waitForAIResponse(c context.Context, sessionID, prompt string
 		}
 
 		// 执行AI推理
-		result, err := appInstance.AgentCoordinator.Run(c, sessionID, prompt)
+		result, err := appInstance.AgentCoordinator.RunWithOptions(c, sessionID, prompt, opts, attachments...)
 		if err != nil {
 			slog.Error("AI run failed", "session_id", sessionID, "error", err)
 		}
@@ -228,11 +313,15 @@ func (h *Handlers) waitForAIResponse(c context.Context, sessionID, prompt string
 }
 
 // handleNoReplyPrompt 处理 NoReply 模式的消息（仅创建用户消息）
-func (h *Handlers) handleNoReplyPrompt(c context.Context, ctx *hertzapp.RequestContext, sessionID string, req models.PromptRequest, appInstance *internalapp.App) {
-	// 创建用户消息参数
+func (h *Handlers) handleNoReplyPrompt(c context.Context, ctx *hertzapp.RequestContext, sessionID string, req models.PromptRequest, attachments []message.Attachment, appInstance *internalapp.App) {
+	// 创建用户消息参数，附件与 agent 创建的用户消息一样保存为二进制内容
+	parts := models.PartsToMessageParts(sessionID, req.Parts)
+	for _, attachment := range attachments {
+		parts = append(parts, message.BinaryContent{Path: attachment.FilePath, MIMEType: attachment.MimeType, Data: attachment.Content})
+	}
 	params := message.CreateMessageParams{
 		Role:  message.User,
-		Parts: models.PartsToMessageParts(sessionID, req.Parts),
+		Parts: parts,
 	}
 
 	// 如果请求中指定了模型，使用它
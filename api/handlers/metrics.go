@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/crush/api/middleware"
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/metrics"
+	hertzapp "github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metricsScrapeTimeout 采集时查询会话列表的超时时间
+const metricsScrapeTimeout = 5 * time.Second
+
+var (
+	appInstancesDesc = prometheus.NewDesc(
+		"zorkagent_app_instances",
+		"Number of live project app instances.",
+		nil, nil,
+	)
+	activeRunsDesc = prometheus.NewDesc(
+		"zorkagent_agent_runs_active",
+		"Number of sessions with an agent run in progress.",
+		[]string{"project"}, nil,
+	)
+	queuedPromptsDesc = prometheus.NewDesc(
+		"zorkagent_agent_prompts_queued",
+		"Number of prompts queued behind a running agent.",
+		[]string{"project"}, nil,
+	)
+	mcpStateDesc = prometheus.NewDesc(
+		"zorkagent_mcp_client_state",
+		"Current state of each MCP client; the series with the current state is 1.",
+		[]string{"name", "state"}, nil,
+	)
+	lspStateDesc = prometheus.NewDesc(
+		"zorkagent_lsp_client_state",
+		"Current state of each LSP client; the series with the current state is 1.",
+		[]string{"project", "name", "state"}, nil,
+	)
+)
+
+var registerMetricsOnce sync.Once
+
+// appCollector 在采集时从 AppManager 中的 app 实例读取运行状态
+type appCollector struct{}
+
+func (appCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- appInstancesDesc
+	ch <- activeRunsDesc
+	ch <- queuedPromptsDesc
+	ch <- mcpStateDesc
+	ch <- lspStateDesc
+}
+
+func (appCollector) Collect(ch chan<- prometheus.Metric) {
+	globalAppManager.mu.RLock()
+	apps := maps.Clone(globalAppManager.apps)
+	globalAppManager.mu.RUnlock()
+
+	ch <- prometheus.MustNewConstMetric(appInstancesDesc, prometheus.GaugeValue, float64(len(apps)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), metricsScrapeTimeout)
+	defer cancel()
+
+	for path, appInstance := range apps {
+		if appInstance.AgentCoordinator != nil {
+			sessions, err := appInstance.Sessions.List(ctx)
+			if err != nil {
+				slog.Warn("Failed to list sessions for metrics", "project", path, "error", err)
+			}
+			var active, queued int
+			for _, s := range sessions {
+				if appInstance.AgentCoordinator.IsSessionBusy(s.ID) {
+					active++
+				}
+				queued += appInstance.AgentCoordinator.QueuedPrompts(s.ID)
+			}
+			ch <- prometheus.MustNewConstMetric(activeRunsDesc, prometheus.GaugeValue, float64(active), path)
+			ch <- prometheus.MustNewConstMetric(queuedPromptsDesc, prometheus.GaugeValue, float64(queued), path)
+		}
+
+		for name, client := range appInstance.LSPClients.Seq2() {
+			ch <- prometheus.MustNewConstMetric(lspStateDesc, prometheus.GaugeValue, 1, path, name, lspStateName(client.GetServerState()))
+		}
+	}
+
+	// MCP 客户端在进程内全局共享
+	for name, info := range mcp.GetStates() {
+		ch <- prometheus.MustNewConstMetric(mcpStateDesc, prometheus.GaugeValue, 1, name, info.State.String())
+	}
+}
+
+// lspStateName 返回 LSP 状态的名称
+func lspStateName(state lsp.ServerState) string {
+	switch state {
+	case lsp.StateStarting:
+		return "starting"
+	case lsp.StateReady:
+		return "ready"
+	case lsp.StateError:
+		return "error"
+	case lsp.StateDisabled:
+		return "disabled"
+	default:
+		return "unknown"
+	}
+}
+
+// HandleMetrics 以 Prometheus 文本格式输出服务器指标
+//
+//	@Summary		获取服务器指标
+//	@Description	以 Prometheus 文本格式输出 HTTP 请求、app 实例、agent 运行、token 用量与费用、工具调用以及 MCP/LSP 客户端状态等指标
+//	@Description	指标包含所有项目的路径和活动，受项目范围限制的 API Key 无权访问
+//	@Tags			Global
+//	@Produce		plain
+//	@Success		200	{string}	string	"Prometheus metrics"
+//	@Failure		403	{object}	map[string]interface{}
+//	@Failure		500	{object}	map[string]interface{}
+//	@Router			/metrics [get]
+func (h *Handlers) HandleMetrics(c context.Context, ctx *hertzapp.RequestContext) {
+	if principal, ok := middleware.PrincipalFrom(ctx); ok && principal.Scoped() {
+		WriteError(c, ctx, "FORBIDDEN", "API key is restricted to specific projects and cannot read server-wide metrics", consts.StatusForbidden)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := metrics.WriteText(&buf); err != nil {
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to gather metrics: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+	ctx.Response.Header.Set("Content-Type", metrics.ContentType)
+	ctx.SetStatusCode(consts.StatusOK)
+	ctx.Response.SetBody(buf.Bytes())
+}
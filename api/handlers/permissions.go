@@ -2,9 +2,14 @@ package handlers
 
 import (
 	"context"
+	"log/slog"
+	"slices"
 	"strings"
 
+	"github.com/charmbracelet/crush/api/middleware"
 	"github.com/charmbracelet/crush/api/models"
+	internalapp "github.com/charmbracelet/crush/internal/app"
+	"github.com/charmbracelet/crush/internal/permission"
 	hertzapp "github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
 )
@@ -12,11 +17,12 @@ import (
 // HandleListPermissions 获取待处理的权限请求列表 (参考 OpenCode: /permission)
 //
 //	@Summary		获取权限请求列表
-//	@Description	获取指定项目的权限请求列表
+//	@Description	获取指定项目中等待回复的权限请求列表
 //	@Tags			Permission
 //	@Accept			json
 //	@Produce		json
 //	@Param			directory	query		string	true	"项目路径"
+//	@Param			sessionID	query		string	false	"按会话 ID 过滤"
 //	@Success		200		{object}	models.PermissionsResponse
 //	@Failure		400		{object}	map[string]interface{}
 //	@Failure		404		{object}	map[string]interface{}
@@ -39,11 +45,16 @@ func (h *Handlers) HandleListPermissions(c context.Context, ctx *hertzapp.Reques
 		return
 	}
 
-	// 返回权限服务状态
-	skipRequests := appInstance.Permissions.SkipRequests()
-
+	// 返回权限服务状态和待回复的请求
+	sessionID := string(ctx.Query("sessionID"))
 	response := models.PermissionsResponse{
-		SkipRequests: skipRequests,
+		SkipRequests: appInstance.Permissions.SkipRequests(),
+	}
+	for _, req := range appInstance.Permissions.PendingRequests() {
+		if sessionID != "" && req.SessionID != sessionID {
+			continue
+		}
+		response.Pending = append(response.Pending, models.PermissionRequestFromInternal(req))
 	}
 
 	WriteJSON(c, ctx, consts.StatusOK, response)
@@ -57,12 +68,12 @@ func (h *Handlers) HandleListPermissions(c context.Context, ctx *hertzapp.Reques
 //	@Accept			json
 //	@Produce		json
 //	@Param			directory	query		string							true	"项目路径"
-//	@Param			id			path		string							true	"权限请求ID"
+//	@Param			requestID	path		string							true	"权限请求ID"
 //	@Param			request		body		models.PermissionReplyRequest	true	"权限回复请求"
 //	@Success		200			{object}	map[string]string
 //	@Failure		400			{object}	map[string]interface{}
 //	@Failure		404			{object}	map[string]interface{}
-//	@Router			/project/permissions/{id}/reply [post]
+//	@Router			/project/permissions/{requestID}/reply [post]
 func (h *Handlers) HandleReplyPermission(c context.Context, ctx *hertzapp.RequestContext) {
 	projectPath := string(ctx.Query("directory"))
 	if projectPath == "" {
@@ -70,7 +81,7 @@ func (h *Handlers) HandleReplyPermission(c context.Context, ctx *hertzapp.Reques
 		return
 	}
 
-	requestID := ctx.Param("id")
+	requestID := ctx.Param("requestID")
 	if requestID == "" {
 		WriteError(c, ctx, "MISSING_REQUEST_ID", "Request ID path parameter is required", consts.StatusBadRequest)
 		return
@@ -93,30 +104,51 @@ func (h *Handlers) HandleReplyPermission(c context.Context, ctx *hertzapp.Reques
 		return
 	}
 
-	// 注意：这里需要找到对应的 PermissionRequest 才能调用 Grant/Deny
-	// 由于 internal 层的限制，我们需要通过订阅事件来获取待处理的请求
-	// 这是一个简化的实现，实际中可能需要在 Handlers 中维护待处理请求的映射
+	if !replyPermission(appInstance, requestID, req, requestActor(ctx)) {
+		WriteError(c, ctx, "PERMISSION_NOT_FOUND", "Permission request not found or already answered", consts.StatusNotFound)
+		return
+	}
+
+	WriteJSON(c, ctx, consts.StatusOK, map[string]string{
+		"status":     "replied",
+		"request_id": requestID,
+		"granted":    boolToString(req.Granted),
+	})
+}
 
-	// 创建一个模拟的 PermissionRequest（实际应该从待处理队列中获取）
-	permReq := models.PermissionRequest{
-		ID: requestID,
+// replyPermission 回复等待中的权限请求，请求不存在或已被回复时返回 false
+// decider 为审计日志中记录的回复方
+func replyPermission(appInstance *internalapp.App, requestID string, req models.PermissionReplyRequest, decider string) bool {
+	pending := appInstance.Permissions.PendingRequests()
+	idx := slices.IndexFunc(pending, func(p permission.PermissionRequest) bool {
+		return p.ID == requestID
+	})
+	if idx < 0 {
+		return false
 	}
+	permReq := pending[idx]
+	permReq.Decider = decider
 
 	if req.Granted {
 		if req.Persistent {
-			appInstance.Permissions.GrantPersistent(permReq.ToInternal())
+			appInstance.Permissions.GrantPersistent(permReq)
 		} else {
-			appInstance.Permissions.Grant(permReq.ToInternal())
+			appInstance.Permissions.Grant(permReq)
 		}
 	} else {
-		appInstance.Permissions.Deny(permReq.ToInternal())
+		appInstance.Permissions.Deny(permReq)
 	}
+	return true
+}
 
-	WriteJSON(c, ctx, consts.StatusOK, map[string]string{
-		"status":     "replied",
-		"request_id": requestID,
-		"granted":    boolToString(req.Granted),
-	})
+// requestActor 返回审计日志中记录的调用方（发起操作的 actor 和回复权限请求的 decider）：
+// API Key 或 Token 的标识，未启用认证时为 "api"
+func requestActor(ctx *hertzapp.RequestContext) string {
+	principal, _ := middleware.PrincipalFrom(ctx)
+	if id := principal.ID(); id != "" {
+		return id
+	}
+	return "api"
 }
 
 func boolToString(b bool) string {
@@ -125,3 +157,21 @@ func boolToString(b bool) string {
 	}
 	return "false"
 }
+
+// permissionModeFor 返回会话创建时保存的权限模式，未设置时使用服务器默认模式
+func (h *Handlers) permissionModeFor(c context.Context, appInstance *internalapp.App, sessionID string) permission.SessionMode {
+	sess, err := appInstance.Sessions.Get(c, sessionID)
+	if err != nil {
+		slog.Warn("Failed to load session permission mode", "session_id", sessionID, "error", err)
+		return h.opts.PermissionMode
+	}
+	if mode := permission.SessionMode(sess.PermissionMode); mode.Valid() {
+		return mode
+	}
+	return h.opts.PermissionMode
+}
+
+// applyPermissionMode 在运行 agent 前将会话的权限模式应用到项目的权限服务
+func (h *Handlers) applyPermissionMode(c context.Context, appInstance *internalapp.App, sessionID string) {
+	appInstance.Permissions.SetSessionMode(sessionID, h.permissionModeFor(c, appInstance, sessionID))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"path/filepath"
 
+	"github.com/charmbracelet/crush/api/middleware"
 	"github.com/charmbracelet/crush/api/models"
 	"github.com/charmbracelet/crush/internal/projects"
 	hertzapp "github.com/cloudwego/hertz/pkg/app"
@@ -27,11 +28,17 @@ func (h *Handlers) HandleListProjects(c context.Context, ctx *hertzapp.RequestCo
 		return
 	}
 
+	// 受限的 API Key 只能看到其授权范围内的项目
+	principal, _ := middleware.PrincipalFrom(ctx)
+
 	response := models.ProjectsResponse{
-		Projects: make([]models.ProjectResponse, len(projectList)),
+		Projects: make([]models.ProjectResponse, 0, len(projectList)),
 	}
-	for i, p := range projectList {
-		response.Projects[i] = models.ProjectToResponse(p)
+	for _, p := range projectList {
+		if !principal.AllowsProject(p.Path) {
+			continue
+		}
+		response.Projects = append(response.Projects, models.ProjectToResponse(p))
 	}
 
 	WriteJSON(c, ctx, consts.StatusOK, response)
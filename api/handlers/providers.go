@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"cmp"
+	"context"
+	"regexp"
+	"slices"
+	"time"
+
+	"charm.land/catwalk/pkg/catwalk"
+	"github.com/charmbracelet/crush/api/models"
+	"github.com/charmbracelet/crush/internal/config"
+	hertzapp "github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// envRefRe $VAR 或 ${VAR} 形式的环境变量引用
+var envRefRe = regexp.MustCompile(`^\$\{?([A-Za-z_][A-Za-z0-9_]*)\}?$`)
+
+// testableProviderTypes ProviderConfig.TestConnection 支持的 provider 类型
+var testableProviderTypes = []catwalk.Type{
+	catwalk.TypeOpenAI,
+	catwalk.TypeOpenAICompat,
+	catwalk.TypeOpenRouter,
+	catwalk.TypeAnthropic,
+	catwalk.TypeGoogle,
+}
+
+// HandleListProviders 获取 provider 和模型目录 (参考 OpenCode: /config/providers)
+//
+//	@Summary		获取 provider 和模型目录
+//	@Description	返回 catwalk 已知的 provider 和配置文件中添加的 provider，包括模型的上下文窗口、价格、附件和推理支持，以及 provider 是否已配置。
+//	@Description	default 为每个 provider 的默认模型：当前选择的大模型所属的 provider 为该模型，其余为 catwalk 的默认大模型或第一个模型。
+//	@Tags			Config
+//	@Produce		json
+//	@Param			directory	query		string	true	"项目路径"
+//	@Success		200			{object}	models.ProvidersResponse
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		500			{object}	map[string]interface{}
+//	@Router			/config/providers [get]
+func (h *Handlers) HandleListProviders(c context.Context, ctx *hertzapp.RequestContext) {
+	appInstance, ok := h.projectApp(c, ctx)
+	if !ok {
+		return
+	}
+	cfg := appInstance.Config()
+
+	response := models.ProvidersResponse{
+		Providers: []models.Provider{},
+		Default:   map[string]string{},
+	}
+	known := make(map[string]bool)
+	for _, p := range cfg.KnownProviders() {
+		id := string(p.ID)
+		known[id] = true
+		provider := models.Provider{
+			ID:   id,
+			Name: p.Name,
+			Type: string(p.Type),
+			Env:  envRefs(p.APIKey),
+			API:  p.APIEndpoint,
+		}
+		defaultModel := p.DefaultLargeModelID
+		modelList := p.Models
+		// 已配置的 provider 使用合并了配置文件中模型的列表
+		if pc, ok := cfg.Providers.Get(id); ok && !pc.Disable {
+			provider.Configured = true
+			provider.API = cmp.Or(pc.BaseURL, provider.API)
+			modelList = pc.Models
+		}
+		provider.Models = toProviderModels(modelList)
+		response.Providers = append(response.Providers, provider)
+		if defaultModel == "" && len(modelList) > 0 {
+			defaultModel = modelList[0].ID
+		}
+		if defaultModel != "" {
+			response.Default[id] = defaultModel
+		}
+	}
+
+	var custom []models.Provider
+	for id, pc := range cfg.Providers.Seq2() {
+		if known[id] {
+			continue
+		}
+		custom = append(custom, models.Provider{
+			ID:         id,
+			Name:       cmp.Or(pc.Name, id),
+			Type:       string(pc.Type),
+			Env:        envRefs(pc.APIKeyTemplate),
+			API:        pc.BaseURL,
+			Configured: !pc.Disable,
+			Custom:     true,
+			Models:     toProviderModels(pc.Models),
+		})
+		if len(pc.Models) > 0 {
+			response.Default[id] = pc.Models[0].ID
+		}
+	}
+	slices.SortFunc(custom, func(a, b models.Provider) int { return cmp.Compare(a.ID, b.ID) })
+	response.Providers = append(response.Providers, custom...)
+
+	if selected, ok := cfg.Models[config.SelectedModelTypeLarge]; ok && selected.Provider != "" {
+		response.Default[selected.Provider] = selected.Model
+	}
+
+	WriteJSON(c, ctx, consts.StatusOK, response)
+}
+
+// HandleTestProvider 测试 provider 连接
+//
+//	@Summary		测试 provider 连接
+//	@Description	使用 provider 的配置（或请求体中的 api_key、base_url）请求其模型列表等接口，检查凭据和地址是否可用，超时时间 5 秒。
+//	@Description	未配置的 catwalk provider 也可以测试，用于保存 API Key 之前的校验。连接失败时仍返回 200，ok 为 false。
+//	@Description	支持 openai、openai-compat、openrouter、anthropic 和 google 类型，其他类型返回 400 UNSUPPORTED_PROVIDER_TYPE。
+//	@Tags			Config
+//	@Accept			json
+//	@Produce		json
+//	@Param			directory	query		string						true	"项目路径"
+//	@Param			id			path		string						true	"Provider ID"
+//	@Param			request		body		models.ProviderTestRequest	false	"要测试的凭据"
+//	@Success		200			{object}	models.ProviderTestResponse
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		500			{object}	map[string]interface{}
+//	@Router			/config/providers/{id}/test [post]
+func (h *Handlers) HandleTestProvider(c context.Context, ctx *hertzapp.RequestContext) {
+	var req models.ProviderTestRequest
+	if len(ctx.Request.Body()) > 0 {
+		if err := ctx.BindJSON(&req); err != nil {
+			WriteError(c, ctx, "INVALID_REQUEST", "Invalid request body: "+err.Error(), consts.StatusBadRequest)
+			return
+		}
+	}
+
+	appInstance, ok := h.projectApp(c, ctx)
+	if !ok {
+		return
+	}
+	cfg := appInstance.Config()
+	id := ctx.Param("id")
+
+	provider, ok := cfg.Providers.Get(id)
+	if !ok {
+		idx := slices.IndexFunc(cfg.KnownProviders(), func(p catwalk.Provider) bool { return string(p.ID) == id })
+		if idx < 0 {
+			WriteError(c, ctx, "PROVIDER_NOT_FOUND", "Provider not found: "+id, consts.StatusNotFound)
+			return
+		}
+		// 与 TUI 中输入 API Key 后的校验一致
+		known := cfg.KnownProviders()[idx]
+		provider = config.ProviderConfig{
+			ID:      id,
+			Name:    known.Name,
+			APIKey:  known.APIKey,
+			Type:    known.Type,
+			BaseURL: known.APIEndpoint,
+		}
+	}
+	if !slices.Contains(testableProviderTypes, provider.Type) {
+		WriteError(c, ctx, "UNSUPPORTED_PROVIDER_TYPE", "Connection test is not supported for provider type "+string(provider.Type), consts.StatusBadRequest)
+		return
+	}
+	if req.APIKey != "" {
+		provider.APIKey = req.APIKey
+	}
+	if req.BaseURL != "" {
+		provider.BaseURL = req.BaseURL
+	}
+
+	start := time.Now()
+	err := provider.TestConnection(cfg.Resolver())
+	response := models.ProviderTestResponse{
+		ProviderID: id,
+		OK:         err == nil,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		response.Error = err.Error()
+	}
+	WriteJSON(c, ctx, consts.StatusOK, response)
+}
+
+// envRefs 返回 API Key 引用的环境变量
+func envRefs(apiKey string) []string {
+	if m := envRefRe.FindStringSubmatch(apiKey); m != nil {
+		return []string{m[1]}
+	}
+	return []string{}
+}
+
+// toProviderModels 转换为 API 的模型，以模型 ID 为键
+func toProviderModels(list []catwalk.Model) map[string]models.ProviderModel {
+	out := make(map[string]models.ProviderModel, len(list))
+	for _, m := range list {
+		out[m.ID] = models.ProviderModel{
+			ID:              m.ID,
+			Name:            cmp.Or(m.Name, m.ID),
+			Attachment:      m.SupportsImages,
+			Reasoning:       m.CanReason,
+			ReasoningLevels: m.ReasoningLevels,
+			Cost: models.ModelCost{
+				Input:      m.CostPer1MIn,
+				Output:     m.CostPer1MOut,
+				CacheRead:  m.CostPer1MOutCached,
+				CacheWrite: m.CostPer1MInCached,
+			},
+			Limit: models.ModelLimit{
+				Context: m.ContextWindow,
+				Output:  m.DefaultMaxTokens,
+			},
+		}
+	}
+	return out
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	internalapp "github.com/charmbracelet/crush/internal/app"
+	hertzapp "github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// 并发运行限制的默认值
+const (
+	defaultMaxConcurrentRuns = 16 // 全局同时进行的 agent 运行数
+	defaultMaxProjectRuns    = 4  // 单个项目同时进行的 agent 运行数
+	runLimitRetryAfter       = 5 * time.Second
+)
+
+// runLimiter 限制同时进行的 agent 运行数量（全局和每个项目）
+// 限制值小于等于 0 表示不限制
+type runLimiter struct {
+	mu         sync.Mutex
+	maxGlobal  int
+	maxProject int
+	active     int
+	projects   map[string]int
+}
+
+func newRunLimiter(maxGlobal, maxProject int) *runLimiter {
+	return &runLimiter{
+		maxGlobal:  maxGlobal,
+		maxProject: maxProject,
+		projects:   make(map[string]int),
+	}
+}
+
+// acquire 为项目占用一个运行名额，成功时返回释放函数（可重复调用）
+func (l *runLimiter) acquire(project string) (release func(), ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.maxGlobal > 0 && l.active >= l.maxGlobal {
+		return nil, false
+	}
+	if l.maxProject > 0 && l.projects[project] >= l.maxProject {
+		return nil, false
+	}
+	l.active++
+	l.projects[project]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.active--
+			if l.projects[project]--; l.projects[project] <= 0 {
+				delete(l.projects, project)
+			}
+		})
+	}, true
+}
+
+// acquireRunSlot 为即将开始的 agent 运行占用名额
+// 超出限制时写入 429 响应并返回 false
+func (h *Handlers) acquireRunSlot(c context.Context, ctx *hertzapp.RequestContext, appInstance *internalapp.App) (release func(), ok bool) {
+	project := appInstance.Config().WorkingDir()
+	release, ok = h.runs.acquire(project)
+	if !ok {
+		slog.Warn("Concurrent run limit reached", "project", project)
+		ctx.Response.Header.Set("Retry-After", strconv.Itoa(int(runLimitRetryAfter.Seconds())))
+		WriteError(c, ctx, "TOO_MANY_RUNS", "Too many concurrent agent runs, please try again later", consts.StatusTooManyRequests)
+		return nil, false
+	}
+	return release, true
+}
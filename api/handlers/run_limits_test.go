@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunLimiter(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxGlobal  int
+		maxProject int
+		acquire    []string
+		want       []bool
+	}{
+		{"global limit", 2, 0, []string{"a", "b", "c"}, []bool{true, true, false}},
+		{"project limit", 0, 2, []string{"a", "a", "a", "b"}, []bool{true, true, false, true}},
+		{"both limits", 3, 2, []string{"a", "a", "a", "b", "c"}, []bool{true, true, false, true, false}},
+		{"unlimited", 0, 0, []string{"a", "a", "a", "a"}, []bool{true, true, true, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newRunLimiter(tt.maxGlobal, tt.maxProject)
+			for i, project := range tt.acquire {
+				_, ok := l.acquire(project)
+				require.Equal(t, tt.want[i], ok, "acquire %d for %s", i, project)
+			}
+		})
+	}
+}
+
+func TestRunLimiterRelease(t *testing.T) {
+	l := newRunLimiter(2, 1)
+
+	releaseA, ok := l.acquire("a")
+	require.True(t, ok)
+	_, ok = l.acquire("a")
+	require.False(t, ok)
+	releaseB, ok := l.acquire("b")
+	require.True(t, ok)
+	_, ok = l.acquire("c")
+	require.False(t, ok)
+
+	// 释放可以重复调用，只归还一次名额
+	releaseA()
+	releaseA()
+	require.Equal(t, 1, l.active)
+	require.NotContains(t, l.projects, "a")
+
+	_, ok = l.acquire("a")
+	require.True(t, ok)
+	_, ok = l.acquire("c")
+	require.False(t, ok)
+
+	releaseB()
+	_, ok = l.acquire("c")
+	require.True(t, ok)
+}
+
+func TestRunLimiterConcurrent(t *testing.T) {
+	l := newRunLimiter(4, 0)
+
+	var mu sync.Mutex
+	var granted int
+	var releases []func()
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Go(func() {
+			if release, ok := l.acquire("a"); ok {
+				mu.Lock()
+				granted++
+				releases = append(releases, release)
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+	require.Equal(t, 4, granted)
+
+	for _, release := range releases {
+		wg.Go(release)
+	}
+	wg.Wait()
+	require.Zero(t, l.active)
+	require.Empty(t, l.projects)
+}
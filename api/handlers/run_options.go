@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/charmbracelet/crush/api/models"
+	"github.com/charmbracelet/crush/internal/agent"
+	"github.com/charmbracelet/crush/internal/config"
+)
+
+// runOptionsFromRequest 将 prompt 请求中的模型和参数覆盖转换为单次运行的选项
+// 覆盖只作用于本次运行，不会修改项目配置中的 Models
+func runOptionsFromRequest(cfg *config.Config, req models.PromptRequest) (agent.RunOptions, error) {
+	var opts agent.RunOptions
+
+	large, err := selectedModelFor(cfg, req.Model, config.SelectedModelTypeLarge)
+	if err != nil {
+		return opts, err
+	}
+	small, err := selectedModelFor(cfg, req.SmallModel, config.SelectedModelTypeSmall)
+	if err != nil {
+		return opts, err
+	}
+	opts.LargeModel = large
+	opts.SmallModel = small
+
+	if req.ReasoningEffort != "" {
+		// 校验推理强度是否被本次使用的模型支持
+		effective := large
+		if effective == nil {
+			if current, ok := cfg.Models[config.SelectedModelTypeLarge]; ok {
+				effective = &current
+			}
+		}
+		if effective != nil {
+			if m := cfg.GetModel(effective.Provider, effective.Model); m != nil && len(m.ReasoningLevels) > 0 && !slices.Contains(m.ReasoningLevels, req.ReasoningEffort) {
+				return opts, fmt.Errorf("reasoning effort %q is not supported by model %s/%s", req.ReasoningEffort, effective.Provider, effective.Model)
+			}
+		}
+		opts.ReasoningEffort = req.ReasoningEffort
+	}
+
+	if req.Temperature != nil && (*req.Temperature < 0 || *req.Temperature > 2) {
+		return opts, errors.New("temperature must be between 0 and 2")
+	}
+	opts.Temperature = req.Temperature
+
+	if req.MaxOutputTokens < 0 {
+		return opts, errors.New("maxOutputTokens must not be negative")
+	}
+	opts.MaxOutputTokens = req.MaxOutputTokens
+
+	return opts, nil
+}
+
+// selectedModelFor 根据请求中的模型规格生成模型选择，未指定时返回 nil
+// 与当前配置相同的模型沿用配置中的参数，其他模型使用提供商的默认参数
+func selectedModelFor(cfg *config.Config, spec *models.ModelSpec, modelType config.SelectedModelType) (*config.SelectedModel, error) {
+	if spec == nil || (spec.ProviderID == "" && spec.ModelID == "") {
+		return nil, nil
+	}
+	if spec.ProviderID == "" || spec.ModelID == "" {
+		return nil, errors.New("providerID and modelID must be specified together")
+	}
+
+	if current, ok := cfg.Models[modelType]; ok && current.Provider == spec.ProviderID && current.Model == spec.ModelID {
+		return &current, nil
+	}
+
+	providerCfg, ok := cfg.Providers.Get(spec.ProviderID)
+	if !ok || providerCfg.Disable {
+		return nil, fmt.Errorf("provider %q is not configured", spec.ProviderID)
+	}
+	m := cfg.GetModel(spec.ProviderID, spec.ModelID)
+	if m == nil {
+		return nil, fmt.Errorf("model %q not found in provider %q", spec.ModelID, spec.ProviderID)
+	}
+
+	return &config.SelectedModel{
+		Provider:        spec.ProviderID,
+		Model:           spec.ModelID,
+		ReasoningEffort: m.DefaultReasoningEffort,
+	}, nil
+}
@@ -0,0 +1,367 @@
+package handlers
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/crush/api/models"
+	"github.com/charmbracelet/crush/internal/agent"
+	internalapp "github.com/charmbracelet/crush/internal/app"
+	"github.com/charmbracelet/crush/internal/audit"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	hertzapp "github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/google/uuid"
+)
+
+// runRetention 已结束的运行保留时长，超时后不再可查询
+const runRetention = time.Hour
+
+// promptRun 一次异步 prompt 运行
+// 运行使用独立于 HTTP 请求的 context，客户端断开后仍会继续，可通过运行 ID 重新查询
+type promptRun struct {
+	mu sync.Mutex
+
+	id        string
+	sessionID string
+	directory string
+	status    string
+	err       string
+	createdAt time.Time
+	finished  time.Time
+
+	messageIDs []string
+	messages   map[string]message.Message
+
+	cancel    context.CancelFunc // 运行结束时释放消息订阅
+	cancelled bool
+}
+
+// promptRuns 保存所有异步运行（运行 ID -> 运行）
+var promptRuns = csync.NewMap[string, *promptRun]()
+
+// startPromptRun 在后台启动一次 agent 运行，运行结束后调用 release
+// directory 为项目实例的工作目录，运行中的工具执行在审计日志中记录为 actor 发起
+func startPromptRun(appInstance *internalapp.App, actor, directory, sessionID, prompt string, attachments []message.Attachment, opts agent.RunOptions, release func()) *promptRun {
+	pruneRuns()
+
+	runCtx, cancel := context.WithCancel(audit.WithActor(context.Background(), actor))
+	run := &promptRun{
+		id:        uuid.New().String(),
+		sessionID: sessionID,
+		directory: directory,
+		status:    models.RunStatusRunning,
+		createdAt: time.Now(),
+		messages:  make(map[string]message.Message),
+		cancel:    cancel,
+	}
+	promptRuns.Set(run.id, run)
+
+	// 先订阅消息事件，避免错过运行开始时创建的消息
+	events := appInstance.Messages.Subscribe(runCtx)
+	done := make(chan error, 1)
+	go func() {
+		defer release()
+		_, err := appInstance.AgentCoordinator.RunWithOptions(runCtx, sessionID, prompt, opts, attachments...)
+		done <- err
+	}()
+
+	handle := func(event pubsub.Event[message.Message]) {
+		msg := event.Payload
+		if msg.SessionID == sessionID && msg.Role == message.Assistant {
+			run.updateMessage(msg)
+		}
+	}
+	go func() {
+		defer cancel()
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					events = nil
+					continue
+				}
+				handle(event)
+			case err := <-done:
+				// 运行结束前发布的消息事件已在缓冲中，先处理完再结束，避免遗漏最后的消息
+				for drained := false; !drained; {
+					select {
+					case event, ok := <-events:
+						if !ok {
+							drained = true
+							continue
+						}
+						handle(event)
+					default:
+						drained = true
+					}
+				}
+				run.finish(appInstance, err)
+				return
+			}
+		}
+	}()
+
+	slog.Info("Prompt run started", "run_id", run.id, "session_id", sessionID)
+	return run
+}
+
+// updateMessage 记录运行中 assistant 消息的最新内容
+func (r *promptRun) updateMessage(msg message.Message) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.messages[msg.ID]; !ok {
+		r.messageIDs = append(r.messageIDs, msg.ID)
+	}
+	r.messages[msg.ID] = msg
+}
+
+// finish 标记运行结束，并从数据库刷新消息的最终内容
+func (r *promptRun) finish(appInstance *internalapp.App, runErr error) {
+	r.mu.Lock()
+	ids := slices.Clone(r.messageIDs)
+	r.mu.Unlock()
+
+	latest := make(map[string]message.Message, len(ids))
+	for _, id := range ids {
+		if msg, err := appInstance.Messages.Get(context.Background(), id); err == nil {
+			latest[id] = msg
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, msg := range latest {
+		r.messages[id] = msg
+	}
+	r.finished = time.Now()
+
+	var last message.Message
+	if len(r.messageIDs) > 0 {
+		last = r.messages[r.messageIDs[len(r.messageIDs)-1]]
+	}
+
+	switch {
+	case r.cancelled || errors.Is(runErr, context.Canceled) || last.FinishReason() == message.FinishReasonCanceled:
+		r.status = models.RunStatusCancelled
+	case runErr != nil:
+		r.status = models.RunStatusFailed
+		r.err = runErr.Error()
+	case last.FinishReason() == message.FinishReasonError:
+		r.status = models.RunStatusFailed
+		if finish := last.FinishPart(); finish != nil {
+			r.err = cmp.Or(finish.Message, finish.Details)
+		}
+	default:
+		r.status = models.RunStatusCompleted
+	}
+
+	slog.Info("Prompt run finished", "run_id", r.id, "session_id", r.sessionID, "status", r.status)
+}
+
+// toResponse 转换为 API 响应
+func (r *promptRun) toResponse() models.RunResponse {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	response := models.RunResponse{
+		ID:         r.id,
+		SessionID:  r.sessionID,
+		Directory:  r.directory,
+		Status:     r.status,
+		Error:      r.err,
+		MessageIDs: slices.Clone(r.messageIDs),
+		CreatedAt:  r.createdAt.Unix(),
+	}
+	if response.MessageIDs == nil {
+		response.MessageIDs = []string{}
+	}
+	if !r.finished.IsZero() {
+		response.FinishedAt = r.finished.Unix()
+	}
+
+	var output []string
+	for _, id := range r.messageIDs {
+		msg := r.messages[id]
+		if text := msg.Content().Text; text != "" {
+			output = append(output, text)
+		}
+	}
+	response.Output = strings.Join(output, "\n\n")
+
+	if len(r.messageIDs) > 0 {
+		last := models.MessageToPromptResponse(r.messages[r.messageIDs[len(r.messageIDs)-1]])
+		response.Message = &last
+	}
+	return response
+}
+
+// isRunning 判断运行是否仍在进行
+func (r *promptRun) isRunning() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.status == models.RunStatusRunning
+}
+
+// stop 取消正在进行的运行，已结束的运行不做任何操作
+// 只取消 agent 的生成，运行的 context 保持有效，agent 才能把消息标记为已取消
+func (r *promptRun) stop(appInstance *internalapp.App) {
+	r.mu.Lock()
+	if r.status != models.RunStatusRunning {
+		r.mu.Unlock()
+		return
+	}
+	r.cancelled = true
+	r.mu.Unlock()
+
+	if appInstance.AgentCoordinator != nil {
+		appInstance.AgentCoordinator.Cancel(r.sessionID)
+	}
+	slog.Info("Prompt run cancelled", "run_id", r.id, "session_id", r.sessionID)
+}
+
+// listRuns 返回项目目录中的运行，sessionID 不为空时只返回该会话的运行，按创建时间倒序
+func listRuns(directory, sessionID string) []models.RunResponse {
+	pruneRuns()
+
+	runs := []models.RunResponse{}
+	for run := range promptRuns.Seq() {
+		if run.directory != directory || (sessionID != "" && run.sessionID != sessionID) {
+			continue
+		}
+		runs = append(runs, run.toResponse())
+	}
+	slices.SortFunc(runs, func(a, b models.RunResponse) int {
+		return cmp.Compare(b.CreatedAt, a.CreatedAt)
+	})
+	return runs
+}
+
+// pruneRuns 清理超过保留时长的已结束运行
+func pruneRuns() {
+	for id, run := range promptRuns.Seq2() {
+		run.mu.Lock()
+		expired := !run.finished.IsZero() && time.Since(run.finished) > runRetention
+		run.mu.Unlock()
+		if expired {
+			promptRuns.Del(id)
+		}
+	}
+}
+
+// handleAsyncPrompt 确认会话之前的回退后启动异步运行，并立即返回运行 ID
+// release 释放运行名额，在运行结束（或未能启动）时调用
+func (h *Handlers) handleAsyncPrompt(c context.Context, ctx *hertzapp.RequestContext, sessionID, prompt string, attachments []message.Attachment, opts agent.RunOptions, appInstance *internalapp.App, release func()) {
+	if appInstance.AgentCoordinator == nil {
+		release()
+		WriteError(c, ctx, "INTERNAL_ERROR", "Agent coordinator not initialized", consts.StatusInternalServerError)
+		return
+	}
+	// 会话忙时 Run 只会把 prompt 加入队列，无法追踪其结果
+	if isSessionBusy(appInstance, sessionID) {
+		release()
+		WriteError(c, ctx, "SESSION_BUSY", "Session is busy", consts.StatusConflict)
+		return
+	}
+	// 新消息会确认之前的回退，删除被回退的消息
+	if err := commitSessionRevert(c, appInstance, sessionID); err != nil {
+		release()
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to apply session revert: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+
+	run := startPromptRun(appInstance, requestActor(ctx), appInstance.Config().WorkingDir(), sessionID, prompt, attachments, opts, release)
+	WriteJSON(c, ctx, consts.StatusAccepted, run.toResponse())
+}
+
+// HandleListRuns 获取项目的异步运行列表
+//
+//	@Summary		获取运行列表
+//	@Description	获取项目中正在进行和最近结束的异步 prompt 运行，可用于断线后重新找回运行
+//	@Tags			Run
+//	@Accept			json
+//	@Produce		json
+//	@Param			directory	query		string	true	"项目路径"
+//	@Param			sessionID	query		string	false	"按会话 ID 过滤"
+//	@Success		200			{object}	models.RunsResponse
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Router			/run [get]
+func (h *Handlers) HandleListRuns(c context.Context, ctx *hertzapp.RequestContext) {
+	appInstance, ok := h.projectApp(c, ctx)
+	if !ok {
+		return
+	}
+	runs := listRuns(appInstance.Config().WorkingDir(), string(ctx.Query("sessionID")))
+	WriteJSON(c, ctx, consts.StatusOK, models.RunsResponse{Runs: runs, Total: len(runs)})
+}
+
+// HandleGetRun 获取异步运行状态
+//
+//	@Summary		获取运行状态
+//	@Description	获取异步 prompt 运行的状态、部分输出和最终消息
+//	@Tags			Run
+//	@Accept			json
+//	@Produce		json
+//	@Param			directory	query		string	true	"项目路径"
+//	@Param			id			path		string	true	"运行 ID"
+//	@Success		200			{object}	models.RunResponse
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Router			/run/{id} [get]
+func (h *Handlers) HandleGetRun(c context.Context, ctx *hertzapp.RequestContext) {
+	_, run, ok := h.lookupRun(c, ctx)
+	if !ok {
+		return
+	}
+	WriteJSON(c, ctx, consts.StatusOK, run.toResponse())
+}
+
+// HandleCancelRun 取消异步运行
+//
+//	@Summary		取消运行
+//	@Description	取消正在进行的异步 prompt 运行。已结束的运行直接返回当前状态。
+//	@Tags			Run
+//	@Accept			json
+//	@Produce		json
+//	@Param			directory	query		string	true	"项目路径"
+//	@Param			id			path		string	true	"运行 ID"
+//	@Success		200			{object}	models.RunResponse
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Router			/run/{id} [delete]
+func (h *Handlers) HandleCancelRun(c context.Context, ctx *hertzapp.RequestContext) {
+	appInstance, run, ok := h.lookupRun(c, ctx)
+	if !ok {
+		return
+	}
+	run.stop(appInstance)
+	WriteJSON(c, ctx, consts.StatusOK, run.toResponse())
+}
+
+// lookupRun 根据路径中的运行 ID 和 directory 参数查找运行，返回项目的 app 实例和运行
+// 运行记录的是项目实例的工作目录，directory 参数同样通过项目实例规范化后再比较
+// 出错时已写入错误响应，ok 为 false
+func (h *Handlers) lookupRun(c context.Context, ctx *hertzapp.RequestContext) (*internalapp.App, *promptRun, bool) {
+	appInstance, ok := h.projectApp(c, ctx)
+	if !ok {
+		return nil, nil, false
+	}
+
+	run, ok := promptRuns.Get(ctx.Param("id"))
+	if !ok || run.directory != appInstance.Config().WorkingDir() {
+		WriteError(c, ctx, "RUN_NOT_FOUND", "Run not found", consts.StatusNotFound)
+		return nil, nil, false
+	}
+	return appInstance, run, true
+}
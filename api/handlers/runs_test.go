@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/api/models"
+	"github.com/charmbracelet/crush/internal/agent"
+	internalapp "github.com/charmbracelet/crush/internal/app"
+	"github.com/charmbracelet/crush/internal/audit"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCoordinator 用 run 代替 agent 的运行，并记录取消的会话
+type fakeCoordinator struct {
+	agent.Coordinator
+	run func(ctx context.Context, sessionID string) error
+
+	mu        sync.Mutex
+	cancelled []string
+}
+
+func (f *fakeCoordinator) RunWithOptions(ctx context.Context, sessionID, _ string, _ agent.RunOptions, _ ...message.Attachment) (*fantasy.AgentResult, error) {
+	return nil, f.run(ctx, sessionID)
+}
+
+func (f *fakeCoordinator) Cancel(sessionID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cancelled = append(f.cancelled, sessionID)
+}
+
+func (f *fakeCoordinator) cancelledSessions() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.cancelled...)
+}
+
+func newTestRunApp(t *testing.T, coordinator agent.Coordinator) *internalapp.App {
+	t.Helper()
+	r := newTestReverter(t)
+	return &internalapp.App{Sessions: r.sessions, Messages: r.messages, AgentCoordinator: coordinator}
+}
+
+func createAssistantMessage(t *testing.T, appInstance *internalapp.App, sessionID, text string, finish *message.Finish) message.Message {
+	t.Helper()
+	parts := []message.ContentPart{message.TextContent{Text: text}}
+	if finish != nil {
+		parts = append(parts, *finish)
+	}
+	msg, err := appInstance.Messages.Create(t.Context(), sessionID, message.CreateMessageParams{Role: message.Assistant, Parts: parts})
+	require.NoError(t, err)
+	return msg
+}
+
+// addTestRun 登记运行，测试结束时移除
+func addTestRun(t *testing.T, run *promptRun) {
+	t.Helper()
+	promptRuns.Set(run.id, run)
+	t.Cleanup(func() { promptRuns.Del(run.id) })
+}
+
+func waitRunFinished(t *testing.T, run *promptRun) {
+	t.Helper()
+	require.Eventually(t, func() bool { return !run.isRunning() }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestPromptRunFinish(t *testing.T) {
+	appInstance := newTestRunApp(t, nil)
+	sess, err := appInstance.Sessions.Create(t.Context(), "test")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		finish     *message.Finish
+		runErr     error
+		cancelled  bool
+		wantStatus string
+		wantErr    string
+	}{
+		{name: "no messages", wantStatus: models.RunStatusCompleted},
+		{name: "end turn", finish: &message.Finish{Reason: message.FinishReasonEndTurn}, wantStatus: models.RunStatusCompleted},
+		{name: "run error", runErr: errors.New("provider unavailable"), wantStatus: models.RunStatusFailed, wantErr: "provider unavailable"},
+		{name: "context canceled", runErr: context.Canceled, wantStatus: models.RunStatusCancelled},
+		{name: "cancelled with error", runErr: errors.New("interrupted"), cancelled: true, wantStatus: models.RunStatusCancelled},
+		{name: "message canceled", finish: &message.Finish{Reason: message.FinishReasonCanceled}, wantStatus: models.RunStatusCancelled},
+		{name: "message error", finish: &message.Finish{Reason: message.FinishReasonError, Message: "rate limited", Details: "retry later"}, wantStatus: models.RunStatusFailed, wantErr: "rate limited"},
+		{name: "message error details", finish: &message.Finish{Reason: message.FinishReasonError, Details: "retry later"}, wantStatus: models.RunStatusFailed, wantErr: "retry later"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := &promptRun{
+				id:        tt.name,
+				sessionID: sess.ID,
+				status:    models.RunStatusRunning,
+				createdAt: time.Now(),
+				messages:  make(map[string]message.Message),
+				cancelled: tt.cancelled,
+			}
+			if tt.finish != nil {
+				// 运行中记录的是生成过程中的内容，结束时从数据库读取最终内容
+				msg := createAssistantMessage(t, appInstance, sess.ID, "output", tt.finish)
+				run.updateMessage(message.Message{ID: msg.ID, SessionID: sess.ID, Role: message.Assistant})
+			}
+
+			run.finish(appInstance, tt.runErr)
+
+			response := run.toResponse()
+			require.Equal(t, tt.wantStatus, response.Status)
+			require.Equal(t, tt.wantErr, response.Error)
+			require.NotZero(t, response.FinishedAt)
+			if tt.finish != nil {
+				require.Equal(t, "output", response.Output)
+			}
+		})
+	}
+}
+
+func TestPromptRunLifecycle(t *testing.T) {
+	var appInstance *internalapp.App
+	var actor string
+	var otherID string
+	coordinator := &fakeCoordinator{run: func(ctx context.Context, sessionID string) error {
+		actor = audit.ActorFromContext(ctx)
+		// 其他会话和用户的消息不属于运行
+		finish := message.Finish{Reason: message.FinishReasonEndTurn}
+		for _, params := range []struct {
+			sessionID string
+			role      message.MessageRole
+			text      string
+		}{
+			{otherID, message.Assistant, "other session"},
+			{sessionID, message.User, "user"},
+			{sessionID, message.Assistant, "hello"},
+		} {
+			_, err := appInstance.Messages.Create(ctx, params.sessionID, message.CreateMessageParams{
+				Role:  params.role,
+				Parts: []message.ContentPart{message.TextContent{Text: params.text}, finish},
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}}
+	appInstance = newTestRunApp(t, coordinator)
+	sess, err := appInstance.Sessions.Create(t.Context(), "test")
+	require.NoError(t, err)
+	other, err := appInstance.Sessions.Create(t.Context(), "other")
+	require.NoError(t, err)
+	otherID = other.ID
+	directory := t.TempDir()
+
+	released := make(chan struct{}, 2)
+	run := startPromptRun(appInstance, "tester", directory, sess.ID, "hi", nil, agent.RunOptions{}, func() { released <- struct{}{} })
+	t.Cleanup(func() { promptRuns.Del(run.id) })
+
+	stored, ok := promptRuns.Get(run.id)
+	require.True(t, ok)
+	require.Same(t, run, stored)
+
+	waitRunFinished(t, run)
+	<-released
+	require.Empty(t, released)
+
+	response := run.toResponse()
+	require.Equal(t, models.RunStatusCompleted, response.Status)
+	require.Equal(t, directory, response.Directory)
+	require.Equal(t, sess.ID, response.SessionID)
+	require.Len(t, response.MessageIDs, 1)
+	require.Equal(t, "hello", response.Output)
+	require.NotNil(t, response.Message)
+	require.NotZero(t, response.FinishedAt)
+	require.Equal(t, "tester", actor)
+}
+
+func TestPromptRunStop(t *testing.T) {
+	stopped := make(chan struct{})
+	var runCtxErr error
+	coordinator := &fakeCoordinator{run: func(ctx context.Context, _ string) error {
+		<-stopped
+		runCtxErr = ctx.Err()
+		return nil
+	}}
+	appInstance := newTestRunApp(t, coordinator)
+	sess, err := appInstance.Sessions.Create(t.Context(), "test")
+	require.NoError(t, err)
+
+	run := startPromptRun(appInstance, "tester", t.TempDir(), sess.ID, "hi", nil, agent.RunOptions{}, func() {})
+	t.Cleanup(func() { promptRuns.Del(run.id) })
+	require.True(t, run.isRunning())
+
+	run.stop(appInstance)
+	require.Equal(t, []string{sess.ID}, coordinator.cancelledSessions())
+	close(stopped)
+	waitRunFinished(t, run)
+
+	require.Equal(t, models.RunStatusCancelled, run.toResponse().Status)
+	// 取消不会结束运行的 context，agent 需要它来保存被取消的消息
+	require.NoError(t, runCtxErr)
+
+	// 已结束的运行不再取消 agent
+	run.stop(appInstance)
+	require.Len(t, coordinator.cancelledSessions(), 1)
+}
+
+func TestPruneRuns(t *testing.T) {
+	now := time.Now()
+	expired := &promptRun{id: "expired", status: models.RunStatusCompleted, createdAt: now.Add(-2 * runRetention), finished: now.Add(-runRetention - time.Minute)}
+	recent := &promptRun{id: "recent", status: models.RunStatusFailed, createdAt: now.Add(-2 * runRetention), finished: now.Add(-time.Minute)}
+	running := &promptRun{id: "running", status: models.RunStatusRunning, createdAt: now.Add(-2 * runRetention)}
+	for _, run := range []*promptRun{expired, recent, running} {
+		addTestRun(t, run)
+	}
+
+	pruneRuns()
+
+	_, ok := promptRuns.Get(expired.id)
+	require.False(t, ok)
+	_, ok = promptRuns.Get(recent.id)
+	require.True(t, ok)
+	_, ok = promptRuns.Get(running.id)
+	require.True(t, ok)
+}
+
+func TestListRuns(t *testing.T) {
+	project, other := t.TempDir(), t.TempDir()
+	now := time.Now()
+	newRun := func(id, directory, sessionID string, age time.Duration) *promptRun {
+		run := &promptRun{id: id, directory: directory, sessionID: sessionID, status: models.RunStatusRunning, createdAt: now.Add(-age)}
+		addTestRun(t, run)
+		return run
+	}
+	newRun("oldest", project, "s1", 3*time.Minute)
+	newRun("newest", project, "s2", time.Minute)
+	newRun("middle", project, "s1", 2*time.Minute)
+	newRun("other", other, "s1", 0)
+
+	ids := func(runs []models.RunResponse) []string {
+		result := []string{}
+		for _, run := range runs {
+			result = append(result, run.ID)
+		}
+		return result
+	}
+
+	require.Equal(t, []string{"newest", "middle", "oldest"}, ids(listRuns(project, "")))
+	require.Equal(t, []string{"middle", "oldest"}, ids(listRuns(project, "s1")))
+	require.Equal(t, []string{"other"}, ids(listRuns(other, "")))
+	require.Equal(t, []models.RunResponse{}, listRuns(t.TempDir(), ""))
+}
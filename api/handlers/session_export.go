@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/charmbracelet/crush/api/models"
+	"github.com/charmbracelet/crush/internal/transcript"
+	hertzapp "github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// HandleExportSession 导出会话
+//
+//	@Summary		导出会话
+//	@Description	导出会话及其消息（包括工具调用和结果）、待办事项、子任务会话和文件历史版本。json 格式可通过 POST /session/import 导入到其他项目，markdown 格式便于阅读，省略标题生成会话。
+//	@Tags			Session
+//	@Produce		json
+//	@Produce		text/markdown
+//	@Param			directory	query		string	true	"项目路径"
+//	@Param			id			path		string	true	"会话ID"
+//	@Param			format		query		string	false	"导出格式"	Enums(json, markdown)	default(json)
+//	@Success		200			{object}	transcript.Transcript
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		500			{object}	map[string]interface{}
+//	@Router			/session/{id}/export [get]
+func (h *Handlers) HandleExportSession(c context.Context, ctx *hertzapp.RequestContext) {
+	format := string(ctx.Query("format"))
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "markdown" {
+		WriteError(c, ctx, "INVALID_REQUEST", "format must be json or markdown", consts.StatusBadRequest)
+		return
+	}
+
+	appInstance, sessionID, ok := h.resolveSessionApp(c, ctx)
+	if !ok {
+		return
+	}
+
+	t, err := appInstance.Transcripts.Export(c, sessionID)
+	if err != nil {
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to export session: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+
+	if format == "markdown" {
+		ctx.Response.Header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="session-%s.md"`, sessionID))
+		ctx.Response.Header.Set("Content-Type", "text/markdown; charset=utf-8")
+		ctx.SetStatusCode(consts.StatusOK)
+		ctx.Response.SetBodyString(t.Markdown())
+		return
+	}
+	ctx.Response.Header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="session-%s.json"`, sessionID))
+	WriteJSON(c, ctx, consts.StatusOK, t)
+}
+
+// HandleImportSession 导入会话
+//
+//	@Summary		导入会话
+//	@Description	导入 GET /session/{id}/export 导出的 json，以新的 ID 重建会话、消息、子任务会话和文件历史。导出项目目录下的文件路径会映射到当前项目目录，文件历史中有导出项目目录之外的路径（包括解析符号链接后）时拒绝导入。
+//	@Tags			Session
+//	@Accept			json
+//	@Produce		json
+//	@Param			directory	query		string					true	"项目路径"
+//	@Param			request		body		transcript.Transcript	true	"导出的会话"
+//	@Success		201			{object}	models.SessionResponse
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		500			{object}	map[string]interface{}
+//	@Router			/session/import [post]
+func (h *Handlers) HandleImportSession(c context.Context, ctx *hertzapp.RequestContext) {
+	projectPath := string(ctx.Query("directory"))
+	if projectPath == "" {
+		WriteError(c, ctx, "MISSING_DIRECTORY_PARAM", "Directory query parameter is required", consts.StatusBadRequest)
+		return
+	}
+
+	t, err := transcript.Parse(ctx.Request.Body())
+	if err != nil {
+		WriteError(c, ctx, "INVALID_REQUEST", "Invalid session export: "+err.Error(), consts.StatusBadRequest)
+		return
+	}
+
+	// 获取项目的 app 实例
+	appInstance, err := h.GetAppForProject(c, projectPath)
+	if err != nil {
+		if strings.Contains(err.Error(), "project not found") {
+			WriteError(c, ctx, "PROJECT_NOT_FOUND", err.Error(), consts.StatusNotFound)
+			return
+		}
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to get or create app for project: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+
+	// 文件历史会在回退时写回磁盘，映射后的路径必须位于当前项目内
+	workingDir := appInstance.Config().WorkingDir()
+	for _, path := range t.FilePaths(workingDir) {
+		if _, err := resolveProjectPath(workingDir, path); err != nil {
+			WriteError(c, ctx, "INVALID_PATH", "Invalid session export: "+err.Error(), consts.StatusBadRequest)
+			return
+		}
+	}
+
+	session, err := appInstance.Transcripts.Import(c, t)
+	if err != nil {
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to import session: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+
+	slog.Info("Session imported", "project", projectPath, "session_id", session.ID, "source_session_id", t.Session.ID)
+
+	WriteJSON(c, ctx, consts.StatusCreated, models.SessionToResponse(session))
+}
@@ -0,0 +1,593 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/crush/api/models"
+	"github.com/charmbracelet/crush/internal/agent"
+	"github.com/charmbracelet/crush/internal/agent/tools"
+	internalapp "github.com/charmbracelet/crush/internal/app"
+	"github.com/charmbracelet/crush/internal/audit"
+	"github.com/charmbracelet/crush/internal/commands"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/shell"
+	hertzapp "github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/google/uuid"
+)
+
+const (
+	shellOutputLimit = 30000           // 单次 shell 命令保存到会话中的最大输出长度
+	shellTimeout     = 5 * time.Minute // 单次 shell 命令的超时时间，超时后中止命令
+)
+
+// HandleListChildSessions 获取会话的子会话 (参考 OpenCode: /session/{id}/children)
+//
+//	@Summary		获取子会话
+//	@Description	获取由指定会话派生的子会话（如 agent 工具和任务会话）
+//	@Tags			Session
+//	@Accept			json
+//	@Produce		json
+//	@Param			directory	query		string	true	"项目路径"
+//	@Param			id			path		string	true	"会话ID"
+//	@Success		200			{array}		models.Session
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Router			/session/{id}/children [get]
+func (h *Handlers) HandleListChildSessions(c context.Context, ctx *hertzapp.RequestContext) {
+	appInstance, sessionID, ok := h.resolveSessionApp(c, ctx)
+	if !ok {
+		return
+	}
+
+	children, err := appInstance.Sessions.ListChildren(c, sessionID)
+	if err != nil {
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to list child sessions: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+
+	directory := appInstance.Config().WorkingDir()
+	response := make([]models.Session, 0, len(children))
+	for _, child := range children {
+		// 标题生成会话仅供内部使用
+		if strings.HasPrefix(child.ID, "title-") {
+			continue
+		}
+		response = append(response, models.SessionToOpencode(child, directory))
+	}
+
+	WriteJSON(c, ctx, consts.StatusOK, response)
+}
+
+// HandleSummarizeSession 总结会话 (参考 OpenCode: /session/{id}/summarize)
+//
+//	@Summary		总结会话
+//	@Description	总结会话内容，后续对话将从总结继续。指定 providerID 和 modelID 时使用该模型总结（不修改配置），否则使用当前配置的大模型；模型不存在时返回 400。
+//	@Tags			Session
+//	@Accept			json
+//	@Produce		json
+//	@Param			directory	query		string							true	"项目路径"
+//	@Param			id			path		string							true	"会话ID"
+//	@Param			request		body		models.SessionSummarizeRequest	false	"总结请求"
+//	@Success		200			{boolean}	bool
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		409			{object}	map[string]interface{}
+//	@Failure		429			{object}	map[string]interface{}
+//	@Router			/session/{id}/summarize [post]
+func (h *Handlers) HandleSummarizeSession(c context.Context, ctx *hertzapp.RequestContext) {
+	var req models.SessionSummarizeRequest
+	if len(ctx.Request.Body()) > 0 {
+		if err := ctx.BindJSON(&req); err != nil {
+			WriteError(c, ctx, "INVALID_REQUEST", "Invalid request body: "+err.Error(), consts.StatusBadRequest)
+			return
+		}
+	}
+
+	appInstance, sessionID, ok := h.resolveSessionApp(c, ctx)
+	if !ok {
+		return
+	}
+	if !h.ensureAgentIdle(c, ctx, appInstance, sessionID) {
+		return
+	}
+
+	runOpts, err := runOptionsFromRequest(appInstance.Config(), models.PromptRequest{
+		Model: &models.ModelSpec{ProviderID: req.ProviderID, ModelID: req.ModelID},
+	})
+	if err != nil {
+		WriteError(c, ctx, "INVALID_REQUEST", err.Error(), consts.StatusBadRequest)
+		return
+	}
+
+	release, ok := h.acquireRunSlot(c, ctx, appInstance)
+	if !ok {
+		return
+	}
+	defer release()
+
+	if err := commitSessionRevert(c, appInstance, sessionID); err != nil {
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to apply session revert: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+
+	if err := appInstance.AgentCoordinator.SummarizeWithOptions(c, sessionID, runOpts); err != nil {
+		if errors.Is(err, agent.ErrModelNotFound) {
+			WriteError(c, ctx, "INVALID_REQUEST", err.Error(), consts.StatusBadRequest)
+			return
+		}
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to summarize session: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+
+	slog.Info("Session summarized", "session_id", sessionID)
+	WriteJSON(c, ctx, consts.StatusOK, true)
+}
+
+// HandleInitSession 初始化项目 (参考 OpenCode: /session/{id}/init)
+//
+//	@Summary		初始化项目
+//	@Description	在会话中运行项目初始化提示词，分析项目并生成 AGENTS.md 等上下文文件，完成后将项目标记为已初始化
+//	@Tags			Session
+//	@Accept			json
+//	@Produce		json
+//	@Param			directory	query		string						true	"项目路径"
+//	@Param			id			path		string						true	"会话ID"
+//	@Param			request		body		models.SessionInitRequest	false	"初始化请求"
+//	@Success		200			{boolean}	bool
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		409			{object}	map[string]interface{}
+//	@Failure		429			{object}	map[string]interface{}
+//	@Router			/session/{id}/init [post]
+func (h *Handlers) HandleInitSession(c context.Context, ctx *hertzapp.RequestContext) {
+	var req models.SessionInitRequest
+	if len(ctx.Request.Body()) > 0 {
+		if err := ctx.BindJSON(&req); err != nil {
+			WriteError(c, ctx, "INVALID_REQUEST", "Invalid request body: "+err.Error(), consts.StatusBadRequest)
+			return
+		}
+	}
+
+	appInstance, sessionID, ok := h.resolveSessionApp(c, ctx)
+	if !ok {
+		return
+	}
+	if !h.ensureAgentIdle(c, ctx, appInstance, sessionID) {
+		return
+	}
+
+	cfg := appInstance.Config()
+	runOpts, err := runOptionsFromRequest(cfg, models.PromptRequest{
+		Model: &models.ModelSpec{ProviderID: req.ProviderID, ModelID: req.ModelID},
+	})
+	if err != nil {
+		WriteError(c, ctx, "INVALID_REQUEST", err.Error(), consts.StatusBadRequest)
+		return
+	}
+	initPrompt, err := agent.InitializePrompt(*cfg)
+	if err != nil {
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to build initialize prompt: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+
+	// 占用运行名额，agent 运行结束时释放
+	release, ok := h.acquireRunSlot(c, ctx, appInstance)
+	if !ok {
+		return
+	}
+	if err := commitSessionRevert(c, appInstance, sessionID); err != nil {
+		release()
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to apply session revert: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+
+	// 按会话的权限模式处理权限请求
+	h.applyPermissionMode(c, appInstance, sessionID)
+
+	if _, err := h.waitForAIResponse(audit.WithActor(c, requestActor(ctx)), sessionID, initPrompt, nil, runOpts, appInstance, release); err != nil {
+		writePromptError(c, ctx, err)
+		return
+	}
+
+	// 标记项目已初始化（项目实例的配置不一定是全局配置，因此不使用 config.MarkProjectInitialized）
+	flagFile := filepath.Join(cfg.Options.DataDirectory, config.InitFlagFilename)
+	if err := os.WriteFile(flagFile, nil, 0o644); err != nil {
+		slog.Error("Failed to mark project initialized", "project", cfg.WorkingDir(), "error", err)
+	}
+
+	WriteJSON(c, ctx, consts.StatusOK, true)
+}
+
+// HandleSessionShell 在会话中执行 shell 命令 (参考 OpenCode: /session/{id}/shell)
+//
+//	@Summary		执行 shell 命令
+//	@Description	在项目目录中执行 shell 命令，并将命令和输出作为 bash 工具调用记录到会话中，供后续对话使用。agent 仅为兼容 OpenCode SDK 而接受。
+//	@Description	执行前按会话的权限模式请求权限：auto 直接执行，deny-dangerous 拒绝，ask 等待权限回复，被拒绝时返回 403。命令占用一个运行名额，超过 5 分钟会被中止，执行结果记录到审计日志。
+//	@Tags			Session
+//	@Accept			json
+//	@Produce		json
+//	@Param			directory	query		string						true	"项目路径"
+//	@Param			id			path		string						true	"会话ID"
+//	@Param			request		body		models.SessionShellRequest	true	"shell 请求"
+//	@Success		200			{object}	models.AssistantMessage
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		403			{object}	map[string]interface{}
+//	@Failure		409			{object}	map[string]interface{}
+//	@Failure		429			{object}	map[string]interface{}
+//	@Router			/session/{id}/shell [post]
+func (h *Handlers) HandleSessionShell(c context.Context, ctx *hertzapp.RequestContext) {
+	var req models.SessionShellRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		WriteError(c, ctx, "INVALID_REQUEST", "Invalid request body: "+err.Error(), consts.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Command) == "" {
+		WriteError(c, ctx, "INVALID_REQUEST", "command is required", consts.StatusBadRequest)
+		return
+	}
+
+	appInstance, sessionID, ok := h.resolveSessionApp(c, ctx)
+	if !ok {
+		return
+	}
+	if isSessionBusy(appInstance, sessionID) {
+		WriteError(c, ctx, "SESSION_BUSY", "Session is busy", consts.StatusConflict)
+		return
+	}
+
+	release, ok := h.acquireRunSlot(c, ctx, appInstance)
+	if !ok {
+		return
+	}
+	defer release()
+
+	if err := commitSessionRevert(c, appInstance, sessionID); err != nil {
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to apply session revert: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+
+	// 按会话的权限模式请求执行权限，与 agent 的 bash 工具相同
+	workingDir := appInstance.Config().WorkingDir()
+	toolCallID := uuid.New().String()
+	h.applyPermissionMode(c, appInstance, sessionID)
+	granted, err := appInstance.Permissions.Request(c, permission.CreatePermissionRequest{
+		SessionID:   sessionID,
+		ToolCallID:  toolCallID,
+		ToolName:    tools.BashToolName,
+		Action:      "execute",
+		Description: "Execute command: " + req.Command,
+		Params:      tools.BashPermissionsParams{Command: req.Command, WorkingDir: workingDir},
+		Path:        workingDir,
+	})
+	if err != nil {
+		WriteError(c, ctx, "REQUEST_CANCELLED", "Permission request cancelled: "+err.Error(), consts.StatusRequestTimeout)
+		return
+	}
+	if !granted {
+		WriteError(c, ctx, "FORBIDDEN", "Permission to execute the command was denied", consts.StatusForbidden)
+		return
+	}
+
+	// 执行命令
+	input, _ := json.Marshal(tools.BashParams{Command: req.Command})
+	execCtx, cancel := context.WithTimeout(c, shellTimeout)
+	defer cancel()
+	start := time.Now()
+	sh := shell.NewShell(&shell.Options{WorkingDir: workingDir})
+	stdout, stderr, execErr := sh.Exec(execCtx, req.Command)
+	exitCode := shell.ExitCode(execErr)
+	output := formatShellOutput(stdout, stderr, exitCode, execErr)
+
+	entry := audit.Entry{
+		Kind:       audit.KindTool,
+		SessionID:  sessionID,
+		ToolCallID: toolCallID,
+		ToolName:   tools.BashToolName,
+		Params:     string(input),
+		Status:     audit.StatusSuccess,
+		ExitCode:   &exitCode,
+		Actor:      requestActor(ctx),
+		CreatedAt:  start,
+		FinishedAt: time.Now(),
+	}
+	if exitCode != 0 {
+		entry.Status = audit.StatusError
+		entry.Error = output
+	}
+	appInstance.Audit.Record(c, entry)
+
+	// 记录到会话：用户消息 + bash 工具调用 + 工具结果
+	if _, err := appInstance.Messages.Create(c, sessionID, message.CreateMessageParams{
+		Role:  message.User,
+		Parts: []message.ContentPart{message.TextContent{Text: "The following tool was executed by the user"}},
+	}); err != nil {
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to create message: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+
+	toolCall := message.ToolCall{
+		ID:       toolCallID,
+		Name:     tools.BashToolName,
+		Input:    string(input),
+		Finished: true,
+	}
+	params := message.CreateMessageParams{
+		Role: message.Assistant,
+		Parts: []message.ContentPart{
+			toolCall,
+			message.Finish{Reason: message.FinishReasonToolUse, Time: time.Now().Unix()},
+		},
+	}
+	if appInstance.AgentCoordinator != nil {
+		model := appInstance.AgentCoordinator.Model()
+		params.Model = model.ModelCfg.Model
+		params.Provider = model.ModelCfg.Provider
+	}
+	assistantMsg, err := appInstance.Messages.Create(c, sessionID, params)
+	if err != nil {
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to create message: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+
+	if _, err := appInstance.Messages.Create(c, sessionID, message.CreateMessageParams{
+		Role: message.Tool,
+		Parts: []message.ContentPart{message.ToolResult{
+			ToolCallID: toolCall.ID,
+			Name:       toolCall.Name,
+			Content:    output,
+			IsError:    exitCode != 0,
+		}},
+	}); err != nil {
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to create message: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+
+	slog.Info("Shell command executed", "session_id", sessionID, "exit_code", exitCode)
+	WriteJSON(c, ctx, consts.StatusOK, models.MessageToPromptResponse(assistantMsg).Info)
+}
+
+// HandleSessionCommand 在会话中执行自定义命令 (参考 OpenCode: /session/{id}/command)
+//
+//	@Summary		执行自定义命令
+//	@Description	渲染用户或项目的自定义命令，或从 MCP 服务器获取 prompt（命令名为 "服务器:prompt"），并发送到会话。
+//	@Description	args 按参数名填充；未提供 args 时，arguments 中的内容填充命令中的 $ARGUMENTS，其余参数按顺序以空白分隔填充。agent 和 model 仅为兼容 OpenCode SDK 而接受。
+//	@Description	缺少必填参数时返回 400，获取 MCP prompt 失败时返回 502 MCP_PROMPT_FAILED。
+//	@Tags			Session
+//	@Accept			json
+//	@Produce		json
+//	@Param			directory	query		string							true	"项目路径"
+//	@Param			id			path		string							true	"会话ID"
+//	@Param			request		body		models.SessionCommandRequest	true	"命令请求"
+//	@Success		200			{object}	models.PromptResponse
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		409			{object}	map[string]interface{}
+//	@Failure		429			{object}	map[string]interface{}
+//	@Failure		502			{object}	map[string]interface{}
+//	@Router			/session/{id}/command [post]
+func (h *Handlers) HandleSessionCommand(c context.Context, ctx *hertzapp.RequestContext) {
+	var req models.SessionCommandRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		WriteError(c, ctx, "INVALID_REQUEST", "Invalid request body: "+err.Error(), consts.StatusBadRequest)
+		return
+	}
+	if req.Command == "" {
+		WriteError(c, ctx, "INVALID_REQUEST", "command is required", consts.StatusBadRequest)
+		return
+	}
+
+	appInstance, sessionID, ok := h.resolveSessionApp(c, ctx)
+	if !ok {
+		return
+	}
+	if !h.ensureAgentIdle(c, ctx, appInstance, sessionID) {
+		return
+	}
+
+	customCommands, err := commands.LoadCustomCommands(appInstance.Config())
+	if err != nil {
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to load commands: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+	var prompt string
+	if cmd, found := findCustomCommand(customCommands, req.Command); found {
+		if req.Args != nil {
+			prompt, err = cmd.ExpandArgs(req.Args)
+		} else {
+			prompt, err = cmd.Expand(req.Arguments)
+		}
+		if err != nil {
+			WriteError(c, ctx, "INVALID_REQUEST", err.Error(), consts.StatusBadRequest)
+			return
+		}
+	} else if mcpPrompt, found := findMCPPrompt(req.Command); found {
+		if req.Args != nil {
+			prompt, err = mcpPrompt.ExpandArgs(c, req.Args)
+		} else {
+			prompt, err = mcpPrompt.Expand(c, req.Arguments)
+		}
+		if errors.Is(err, commands.ErrMissingArgument) {
+			WriteError(c, ctx, "INVALID_REQUEST", err.Error(), consts.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			WriteError(c, ctx, "MCP_PROMPT_FAILED", "Failed to get MCP prompt: "+err.Error(), consts.StatusBadGateway)
+			return
+		}
+	} else {
+		WriteError(c, ctx, "COMMAND_NOT_FOUND", "Command not found: "+req.Command, consts.StatusNotFound)
+		return
+	}
+	if strings.TrimSpace(prompt) == "" {
+		WriteError(c, ctx, "INVALID_REQUEST", "Command rendered an empty prompt", consts.StatusBadRequest)
+		return
+	}
+	// model 格式为 "providerID/modelID"，模型 ID 本身可能包含 "/"
+	var modelSpec *models.ModelSpec
+	if req.Model != "" {
+		providerID, modelID, _ := strings.Cut(req.Model, "/")
+		modelSpec = &models.ModelSpec{ProviderID: providerID, ModelID: modelID}
+	}
+	runOpts, err := runOptionsFromRequest(appInstance.Config(), models.PromptRequest{Model: modelSpec})
+	if err != nil {
+		WriteError(c, ctx, "INVALID_REQUEST", err.Error(), consts.StatusBadRequest)
+		return
+	}
+
+	// 占用运行名额，agent 运行结束时释放
+	release, ok := h.acquireRunSlot(c, ctx, appInstance)
+	if !ok {
+		return
+	}
+
+	// 按会话的权限模式处理权限请求
+	h.applyPermissionMode(c, appInstance, sessionID)
+
+	h.handleSyncPrompt(c, ctx, sessionID, prompt, nil, runOpts, appInstance, release)
+}
+
+// findCustomCommand 按 ID 查找自定义命令，允许省略 "user:" / "project:" 前缀
+// 同名时项目命令优先
+func findCustomCommand(customCommands []commands.CustomCommand, name string) (commands.CustomCommand, bool) {
+	name = strings.TrimPrefix(name, "/")
+	var match *commands.CustomCommand
+	for i, cmd := range customCommands {
+		if cmd.ID == name {
+			return cmd, true
+		}
+		_, short, _ := strings.Cut(cmd.ID, ":")
+		if short == name && (match == nil || strings.HasPrefix(cmd.ID, "project:")) {
+			match = &customCommands[i]
+		}
+	}
+	if match == nil {
+		return commands.CustomCommand{}, false
+	}
+	return *match, true
+}
+
+// resolveSessionApp 解析 directory 参数和路径中的会话 ID，返回项目 app 实例
+// 出错时已写入错误响应，ok 为 false
+func (h *Handlers) resolveSessionApp(c context.Context, ctx *hertzapp.RequestContext) (appInstance *internalapp.App, sessionID string, ok bool) {
+	projectPath := string(ctx.Query("directory"))
+	if projectPath == "" {
+		WriteError(c, ctx, "MISSING_DIRECTORY_PARAM", "Directory query parameter is required", consts.StatusBadRequest)
+		return nil, "", false
+	}
+
+	sessionID = ctx.Param("id")
+	if sessionID == "" {
+		WriteError(c, ctx, "INVALID_REQUEST", "Session ID is required", consts.StatusBadRequest)
+		return nil, "", false
+	}
+
+	// 获取项目的 app 实例
+	appInstance, err := h.GetAppForProject(c, projectPath)
+	if err != nil {
+		if strings.Contains(err.Error(), "project not found") {
+			WriteError(c, ctx, "PROJECT_NOT_FOUND", err.Error(), consts.StatusNotFound)
+			return nil, "", false
+		}
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to get or create app for project: "+err.Error(), consts.StatusInternalServerError)
+		return nil, "", false
+	}
+
+	// 验证会话存在
+	if _, err := appInstance.Sessions.Get(c, sessionID); err != nil {
+		WriteError(c, ctx, "SESSION_NOT_FOUND", "Session not found: "+err.Error(), consts.StatusNotFound)
+		return nil, "", false
+	}
+
+	return appInstance, sessionID, true
+}
+
+// ensureAgentIdle 检查 agent 已初始化且会话空闲，否则写入错误响应
+func (h *Handlers) ensureAgentIdle(c context.Context, ctx *hertzapp.RequestContext, appInstance *internalapp.App, sessionID string) bool {
+	if appInstance.AgentCoordinator == nil {
+		WriteError(c, ctx, "INTERNAL_ERROR", "Agent coordinator not initialized", consts.StatusInternalServerError)
+		return false
+	}
+	if isSessionBusy(appInstance, sessionID) {
+		WriteError(c, ctx, "SESSION_BUSY", "Session is busy", consts.StatusConflict)
+		return false
+	}
+	return true
+}
+
+// isSessionBusy 判断会话是否正在运行 agent
+func isSessionBusy(appInstance *internalapp.App, sessionID string) bool {
+	return appInstance.AgentCoordinator != nil && appInstance.AgentCoordinator.IsSessionBusy(sessionID)
+}
+
+// writeOpencodeSession 以 OpenCode 格式返回会话（包含待定回退信息）
+func (h *Handlers) writeOpencodeSession(c context.Context, ctx *hertzapp.RequestContext, appInstance *internalapp.App, sessionID string) {
+	sess, err := appInstance.Sessions.Get(c, sessionID)
+	if err != nil {
+		WriteError(c, ctx, "SESSION_NOT_FOUND", "Session not found: "+err.Error(), consts.StatusNotFound)
+		return
+	}
+
+	response := models.SessionToOpencode(sess, appInstance.Config().WorkingDir())
+	revert, ok, err := newSessionReverter(appInstance).get(c, sessionID)
+	if err != nil {
+		slog.Warn("Failed to get session revert", "session_id", sessionID, "error", err)
+	} else if ok {
+		response.Revert = &models.SessionRevert{MessageID: revert.MessageID, PartID: revert.PartID, Diff: revert.Diff}
+	}
+
+	WriteJSON(c, ctx, consts.StatusOK, response)
+}
+
+// writePromptError 将 waitForAIResponse 的错误写入响应
+func writePromptError(c context.Context, ctx *hertzapp.RequestContext, err error) {
+	if errors.Is(err, agent.ErrModelNotFound) {
+		WriteError(c, ctx, "INVALID_REQUEST", err.Error(), consts.StatusBadRequest)
+		return
+	}
+	switch err.Error() {
+	case "request_cancelled":
+		WriteError(c, ctx, "REQUEST_CANCELLED", "Request cancelled", consts.StatusRequestTimeout)
+	case "timeout":
+		WriteError(c, ctx, "TIMEOUT", "Request timeout", consts.StatusRequestTimeout)
+	default:
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to run agent: "+err.Error(), consts.StatusInternalServerError)
+	}
+}
+
+// formatShellOutput 合并命令输出，并附加退出码
+func formatShellOutput(stdout, stderr string, exitCode int, execErr error) string {
+	var out strings.Builder
+	out.WriteString(strings.TrimRight(stdout, "\n"))
+	if stderr = strings.TrimRight(stderr, "\n"); stderr != "" {
+		if out.Len() > 0 {
+			out.WriteString("\n")
+		}
+		out.WriteString(stderr)
+	}
+
+	result := out.String()
+	if len(result) > shellOutputLimit {
+		result = result[:shellOutputLimit] + "\n... (output truncated)"
+	}
+	if execErr != nil && shell.IsInterrupt(execErr) {
+		result += "\nCommand was aborted"
+	} else if exitCode != 0 {
+		result += "\nExit code " + strconv.Itoa(exitCode)
+	}
+	if result == "" {
+		result = "no output"
+	}
+	return result
+}
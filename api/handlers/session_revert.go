@@ -0,0 +1,424 @@
+package handlers
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/crush/api/models"
+	internalapp "github.com/charmbracelet/crush/internal/app"
+	"github.com/charmbracelet/crush/internal/diff"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/session"
+	hertzapp "github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// 会话的待定回退保存在数据库中（session_reverts 表），服务重启后仍可撤销或确认。
+// 回退只恢复文件并记录回退点，被回退的消息在下一次向会话发送消息时才真正删除，
+// 在此之前可以通过 unrevert 撤销
+
+// revertMu 串行化回退、撤销回退和确认回退，避免同时读写文件快照
+var revertMu sync.Mutex
+
+// HandleRevertSession 回退会话到指定消息之前 (参考 OpenCode: /session/{id}/revert)
+//
+//	@Summary		回退会话
+//	@Description	将会话修改过的文件恢复到指定消息之前的状态，并标记该消息及之后的消息为已回退。被回退的消息会在下一次发送消息时删除，在此之前可以调用 unrevert 撤销。
+//	@Tags			Session
+//	@Accept			json
+//	@Produce		json
+//	@Param			directory	query		string						true	"项目路径"
+//	@Param			id			path		string						true	"会话ID"
+//	@Param			request		body		models.SessionRevertRequest	true	"回退请求"
+//	@Success		200			{object}	models.Session
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		409			{object}	map[string]interface{}
+//	@Router			/session/{id}/revert [post]
+func (h *Handlers) HandleRevertSession(c context.Context, ctx *hertzapp.RequestContext) {
+	var req models.SessionRevertRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		WriteError(c, ctx, "INVALID_REQUEST", "Invalid request body: "+err.Error(), consts.StatusBadRequest)
+		return
+	}
+	if req.MessageID == "" {
+		WriteError(c, ctx, "INVALID_REQUEST", "messageID is required", consts.StatusBadRequest)
+		return
+	}
+
+	appInstance, sessionID, ok := h.resolveSessionApp(c, ctx)
+	if !ok {
+		return
+	}
+
+	msg, err := appInstance.Messages.Get(c, req.MessageID)
+	if err != nil || msg.SessionID != sessionID {
+		WriteError(c, ctx, "MESSAGE_NOT_FOUND", "Message not found in session", consts.StatusNotFound)
+		return
+	}
+
+	revertMu.Lock()
+	defer revertMu.Unlock()
+
+	// 持有锁后再检查，新的运行在开始前需要获取同一把锁确认回退
+	if isSessionBusy(appInstance, sessionID) {
+		WriteError(c, ctx, "SESSION_BUSY", "Session is busy", consts.StatusConflict)
+		return
+	}
+
+	reverter := newSessionReverter(appInstance)
+	// 已有回退时先撤销，保证文件快照始终对应回退前的真实状态
+	if err := reverter.unrevert(c, sessionID); err != nil {
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to undo previous revert: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+
+	revert, err := reverter.revert(c, sessionID, msg)
+	if err != nil {
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to revert session: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+	revert.PartID = req.PartID
+	if err := appInstance.Sessions.SetRevert(c, revert); err != nil {
+		// 回退状态无法保存时恢复文件，否则之后无法撤销
+		if restoreErr := reverter.restore(revert.Files); restoreErr != nil {
+			err = errors.Join(err, restoreErr)
+		}
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to save revert: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+
+	slog.Info("Session reverted", "session_id", sessionID, "message_id", msg.ID, "files", len(revert.Files))
+	h.writeOpencodeSession(c, ctx, appInstance, sessionID)
+}
+
+// HandleUnrevertSession 撤销会话的回退 (参考 OpenCode: /session/{id}/unrevert)
+//
+//	@Summary		撤销回退
+//	@Description	恢复回退前的文件内容并取消会话的待定回退
+//	@Tags			Session
+//	@Accept			json
+//	@Produce		json
+//	@Param			directory	query		string	true	"项目路径"
+//	@Param			id			path		string	true	"会话ID"
+//	@Success		200			{object}	models.Session
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Router			/session/{id}/unrevert [post]
+func (h *Handlers) HandleUnrevertSession(c context.Context, ctx *hertzapp.RequestContext) {
+	appInstance, sessionID, ok := h.resolveSessionApp(c, ctx)
+	if !ok {
+		return
+	}
+
+	revertMu.Lock()
+	err := newSessionReverter(appInstance).unrevert(c, sessionID)
+	revertMu.Unlock()
+	if err != nil {
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to unrevert session: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+
+	h.writeOpencodeSession(c, ctx, appInstance, sessionID)
+}
+
+// commitSessionRevert 确认会话的待定回退：删除被回退的消息和文件版本
+// 在向会话发送新消息之前调用
+func commitSessionRevert(c context.Context, appInstance *internalapp.App, sessionID string) error {
+	revertMu.Lock()
+	defer revertMu.Unlock()
+	return newSessionReverter(appInstance).commit(c, sessionID)
+}
+
+// sessionReverter 回退、撤销和确认会话的文件修改
+// 只处理项目目录内的文件（包括符号链接指向的位置），调用方需持有 revertMu
+type sessionReverter struct {
+	sessions   session.Service
+	messages   message.Service
+	history    history.Service
+	workingDir string
+}
+
+// newSessionReverter 创建处理项目实例中会话的 sessionReverter
+func newSessionReverter(appInstance *internalapp.App) *sessionReverter {
+	return &sessionReverter{
+		sessions:   appInstance.Sessions,
+		messages:   appInstance.Messages,
+		history:    appInstance.History,
+		workingDir: appInstance.Config().WorkingDir(),
+	}
+}
+
+// revert 将会话（含子会话）修改过的文件恢复到消息 msg 之前的版本
+// 项目目录外的文件会被跳过；任一文件恢复失败时，已修改的文件会还原为回退前的内容
+func (r *sessionReverter) revert(c context.Context, sessionID string, msg message.Message) (session.Revert, error) {
+	isReverted, err := r.classifier(c, sessionID, msg)
+	if err != nil {
+		return session.Revert{}, err
+	}
+	sessionIDs, err := r.treeIDs(c, sessionID)
+	if err != nil {
+		return session.Revert{}, err
+	}
+
+	// 按路径汇总所有文件版本（每个会话内按版本号升序）
+	versions := make(map[string][]history.File)
+	var paths []string
+	for _, id := range sessionIDs {
+		files, err := r.history.ListBySession(c, id)
+		if err != nil {
+			return session.Revert{}, fmt.Errorf("failed to list file history: %w", err)
+		}
+		for _, f := range files {
+			if _, ok := versions[f.Path]; !ok {
+				paths = append(paths, f.Path)
+			}
+			versions[f.Path] = append(versions[f.Path], f)
+		}
+	}
+
+	revert := session.Revert{SessionID: sessionID, MessageID: msg.ID}
+
+	var diffs strings.Builder
+	for _, path := range paths {
+		fileVersions := versions[path]
+		slices.SortStableFunc(fileVersions, func(a, b history.File) int {
+			return cmp.Or(cmp.Compare(a.CreatedAt, b.CreatedAt), cmp.Compare(a.Version, b.Version))
+		})
+
+		// 回退点之后没有修改的文件无需处理
+		if !slices.ContainsFunc(fileVersions, isReverted) {
+			continue
+		}
+
+		fullPath, err := resolveProjectPath(r.workingDir, path)
+		if err != nil {
+			slog.Warn("Skipping file outside the project in revert", "session_id", sessionID, "path", path, "error", err)
+			continue
+		}
+
+		// 回退点之前的最新版本；没有时使用初始版本（会话首次修改前的内容）
+		target := fileVersions[0]
+		for _, f := range fileVersions {
+			if !isReverted(f) {
+				target = f
+			}
+		}
+		// 初始内容为空说明文件由会话创建，回退时删除
+		removeFile := target.Version == history.InitialVersion && target.Content == "" && isReverted(target)
+
+		current, existed, err := readFileState(fullPath)
+		if err != nil {
+			return session.Revert{}, errors.Join(err, r.restore(revert.Files))
+		}
+		revert.Files = append(revert.Files, session.RevertedFile{Path: fullPath, Content: current, Existed: existed})
+
+		if removeFile {
+			if existed {
+				err = os.Remove(fullPath)
+				if err != nil {
+					err = fmt.Errorf("failed to remove %s: %w", fullPath, err)
+				}
+			}
+		} else {
+			err = writeFileContent(fullPath, target.Content)
+		}
+		if err != nil {
+			return session.Revert{}, errors.Join(err, r.restore(revert.Files))
+		}
+
+		displayPath, _ := filepath.Rel(r.workingDir, fullPath)
+		unified, _, _ := diff.GenerateDiff(current, target.Content, displayPath)
+		diffs.WriteString(unified)
+	}
+	revert.Diff = diffs.String()
+
+	return revert, nil
+}
+
+// classifier 返回判断文件版本是否在回退点（消息 msg）之后的函数
+// 由 agent 创建的版本按其所属消息在会话中的顺序判断；没有所属消息的版本
+// （如通过 API 编辑或导入的版本）按创建时间判断
+func (r *sessionReverter) classifier(c context.Context, sessionID string, msg message.Message) (func(history.File) bool, error) {
+	messages, err := r.messages.List(c, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list messages: %w", err)
+	}
+	idx := slices.IndexFunc(messages, func(m message.Message) bool { return m.ID == msg.ID })
+	if idx < 0 {
+		return nil, fmt.Errorf("message %s not found in session", msg.ID)
+	}
+	// 消息 ID -> 是否被回退
+	reverted := make(map[string]bool, len(messages))
+	for i, m := range messages {
+		reverted[m.ID] = i >= idx
+	}
+	return func(f history.File) bool {
+		if isReverted, ok := reverted[f.MessageID]; ok {
+			return isReverted
+		}
+		return f.CreatedAt >= msg.CreatedAt
+	}, nil
+}
+
+// treeIDs 返回会话及其子会话的 ID
+func (r *sessionReverter) treeIDs(c context.Context, sessionID string) ([]string, error) {
+	sessionIDs := []string{sessionID}
+	children, err := r.sessions.ListChildren(c, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list child sessions: %w", err)
+	}
+	for _, child := range children {
+		sessionIDs = append(sessionIDs, child.ID)
+	}
+	return sessionIDs, nil
+}
+
+// restore 将文件恢复为回退前的状态，项目目录外的文件会被跳过
+func (r *sessionReverter) restore(files []session.RevertedFile) error {
+	var errs []error
+	for _, f := range files {
+		fullPath, err := resolveProjectPath(r.workingDir, f.Path)
+		if err != nil {
+			slog.Warn("Skipping file outside the project in restore", "path", f.Path, "error", err)
+			continue
+		}
+		if !f.Existed {
+			if err := os.Remove(fullPath); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if err := writeFileContent(fullPath, f.Content); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// get 读取会话的待定回退，没有回退时 ok 为 false
+func (r *sessionReverter) get(c context.Context, sessionID string) (revert session.Revert, ok bool, err error) {
+	revert, err = r.sessions.GetRevert(c, sessionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return session.Revert{}, false, nil
+	}
+	if err != nil {
+		return session.Revert{}, false, fmt.Errorf("failed to get session revert: %w", err)
+	}
+	return revert, true, nil
+}
+
+// unrevert 撤销会话的待定回退，没有回退时不做任何操作
+func (r *sessionReverter) unrevert(c context.Context, sessionID string) error {
+	revert, ok, err := r.get(c, sessionID)
+	if err != nil || !ok {
+		return err
+	}
+
+	// 恢复失败时保留回退状态，便于重试
+	if err := r.restore(revert.Files); err != nil {
+		return err
+	}
+	if err := r.sessions.DeleteRevert(c, sessionID); err != nil {
+		return fmt.Errorf("failed to delete session revert: %w", err)
+	}
+
+	slog.Info("Session unreverted", "session_id", sessionID, "project", r.workingDir)
+	return nil
+}
+
+// commit 确认会话的待定回退：删除被回退的消息和文件版本，没有回退时不做任何操作
+func (r *sessionReverter) commit(c context.Context, sessionID string) error {
+	revert, ok, err := r.get(c, sessionID)
+	if err != nil || !ok {
+		return err
+	}
+
+	msg, err := r.messages.Get(c, revert.MessageID)
+	if err != nil || msg.SessionID != sessionID {
+		// 回退点消息已不存在，没有可删除的内容
+		slog.Warn("Reverted message not found, dropping session revert", "session_id", sessionID, "message_id", revert.MessageID)
+		return r.sessions.DeleteRevert(c, sessionID)
+	}
+	isReverted, err := r.classifier(c, sessionID, msg)
+	if err != nil {
+		return err
+	}
+	sessionIDs, err := r.treeIDs(c, sessionID)
+	if err != nil {
+		return err
+	}
+
+	// 先删除文件版本，再删除消息，文件版本的归属依赖消息顺序
+	for _, id := range sessionIDs {
+		files, err := r.history.ListBySession(c, id)
+		if err != nil {
+			return fmt.Errorf("failed to list file history: %w", err)
+		}
+		for _, f := range files {
+			if !isReverted(f) {
+				continue
+			}
+			if err := r.history.Delete(c, f.ID); err != nil {
+				return fmt.Errorf("failed to delete reverted file version: %w", err)
+			}
+		}
+	}
+
+	messages, err := r.messages.List(c, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to list messages: %w", err)
+	}
+	idx := slices.IndexFunc(messages, func(m message.Message) bool { return m.ID == revert.MessageID })
+	if idx >= 0 {
+		for _, m := range messages[idx:] {
+			if err := r.messages.Delete(c, m.ID); err != nil {
+				return fmt.Errorf("failed to delete reverted message: %w", err)
+			}
+		}
+	}
+
+	if err := r.sessions.DeleteRevert(c, sessionID); err != nil {
+		return fmt.Errorf("failed to delete session revert: %w", err)
+	}
+
+	slog.Info("Session revert committed", "session_id", sessionID, "message_id", revert.MessageID)
+	return nil
+}
+
+// readFileState 读取文件当前内容，文件不存在时 existed 为 false
+func readFileState(path string) (content string, existed bool, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return string(data), true, nil
+}
+
+// writeFileContent 写入文件内容，保留已有文件的权限
+func writeFileContent(path, content string) error {
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, []byte(content), mode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestReverter(t *testing.T) *sessionReverter {
+	t.Helper()
+	conn, err := db.Connect(t.Context(), t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	q := db.New(conn)
+	return &sessionReverter{
+		sessions:   session.NewService(q, conn),
+		messages:   message.NewService(q),
+		history:    history.NewService(q, conn),
+		workingDir: t.TempDir(),
+	}
+}
+
+func createTestMessage(t *testing.T, r *sessionReverter, sessionID, text string) message.Message {
+	t.Helper()
+	msg, err := r.messages.Create(t.Context(), sessionID, message.CreateMessageParams{
+		Role:  message.User,
+		Parts: []message.ContentPart{message.TextContent{Text: text}},
+	})
+	require.NoError(t, err)
+	return msg
+}
+
+// recordEdit 像编辑工具一样记录消息 msg 对 path 的修改：首次修改前的内容和修改后的内容
+func recordEdit(t *testing.T, r *sessionReverter, sessionID string, msg message.Message, path, before, after string) {
+	t.Helper()
+	ctx := history.WithMessageID(t.Context(), msg.ID)
+	if _, err := r.history.GetByPathAndSession(ctx, path, sessionID); err != nil {
+		_, err := r.history.Create(ctx, sessionID, path, before)
+		require.NoError(t, err)
+	}
+	_, err := r.history.CreateVersion(ctx, sessionID, path, after)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(after), 0o644))
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestSessionReverterClassify(t *testing.T) {
+	r := newTestReverter(t)
+	sess, err := r.sessions.Create(t.Context(), "test")
+	require.NoError(t, err)
+	first := createTestMessage(t, r, sess.ID, "first")
+	point := createTestMessage(t, r, sess.ID, "point")
+	last := createTestMessage(t, r, sess.ID, "last")
+
+	isReverted, err := r.classifier(t.Context(), sess.ID, point)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		file history.File
+		want bool
+	}{
+		{"before revert point", history.File{MessageID: first.ID, CreatedAt: point.CreatedAt + 10}, false},
+		{"revert point", history.File{MessageID: point.ID}, true},
+		{"after revert point", history.File{MessageID: last.ID}, true},
+		{"no message, created before", history.File{CreatedAt: point.CreatedAt - 1}, false},
+		{"no message, created at", history.File{CreatedAt: point.CreatedAt}, true},
+		{"no message, created after", history.File{CreatedAt: point.CreatedAt + 1}, true},
+		{"unknown message, created before", history.File{MessageID: "other", CreatedAt: point.CreatedAt - 1}, false},
+		{"unknown message, created after", history.File{MessageID: "other", CreatedAt: point.CreatedAt + 1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, isReverted(tt.file))
+		})
+	}
+
+	_, err = r.classifier(t.Context(), sess.ID, message.Message{ID: "missing"})
+	require.Error(t, err)
+}
+
+func TestSessionReverterRevertAndUnrevert(t *testing.T) {
+	r := newTestReverter(t)
+	ctx := t.Context()
+	sess, err := r.sessions.Create(ctx, "test")
+	require.NoError(t, err)
+
+	edited := filepath.Join(r.workingDir, "edited.txt")
+	created := filepath.Join(r.workingDir, "dir", "created.txt")
+	untouched := filepath.Join(r.workingDir, "untouched.txt")
+
+	// 项目外的文件，直接给出或经由项目内的符号链接
+	outsideDir := t.TempDir()
+	outside := filepath.Join(outsideDir, "outside.txt")
+	require.NoError(t, os.Symlink(outsideDir, filepath.Join(r.workingDir, "link")))
+	linked := filepath.Join(r.workingDir, "link", "linked.txt")
+
+	first := createTestMessage(t, r, sess.ID, "first")
+	recordEdit(t, r, sess.ID, first, edited, "v0", "v1")
+	recordEdit(t, r, sess.ID, first, untouched, "u0", "u1")
+
+	point := createTestMessage(t, r, sess.ID, "point")
+	recordEdit(t, r, sess.ID, point, edited, "", "v2")
+	recordEdit(t, r, sess.ID, point, created, "", "new")
+	recordEdit(t, r, sess.ID, point, outside, "o0", "o1")
+	recordEdit(t, r, sess.ID, point, linked, "l0", "l1")
+
+	revert, err := r.revert(ctx, sess.ID, point)
+	require.NoError(t, err)
+	require.Equal(t, sess.ID, revert.SessionID)
+	require.Equal(t, point.ID, revert.MessageID)
+	require.ElementsMatch(t, []session.RevertedFile{
+		{Path: edited, Content: "v2", Existed: true},
+		{Path: created, Content: "new", Existed: true},
+	}, revert.Files)
+	require.Contains(t, revert.Diff, "edited.txt")
+
+	require.Equal(t, "v1", readTestFile(t, edited))
+	require.NoFileExists(t, created)
+	require.Equal(t, "u1", readTestFile(t, untouched))
+	require.Equal(t, "o1", readTestFile(t, outside))
+	require.Equal(t, "l1", readTestFile(t, linked))
+
+	require.NoError(t, r.sessions.SetRevert(ctx, revert))
+	require.NoError(t, r.unrevert(ctx, sess.ID))
+
+	require.Equal(t, "v2", readTestFile(t, edited))
+	require.Equal(t, "new", readTestFile(t, created))
+	_, ok, err := r.get(ctx, sess.ID)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// 没有回退时不做任何操作
+	require.NoError(t, r.unrevert(ctx, sess.ID))
+}
+
+func TestSessionReverterRevertWithoutMessageID(t *testing.T) {
+	r := newTestReverter(t)
+	ctx := t.Context()
+	sess, err := r.sessions.Create(ctx, "test")
+	require.NoError(t, err)
+	point := createTestMessage(t, r, sess.ID, "point")
+
+	// 通过 API 编辑的版本没有所属消息，按创建时间判断
+	path := filepath.Join(r.workingDir, "api.txt")
+	_, err = r.history.Create(ctx, sess.ID, path, "before")
+	require.NoError(t, err)
+	_, err = r.history.CreateVersion(ctx, sess.ID, path, "after")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte("after"), 0o644))
+
+	revert, err := r.revert(ctx, sess.ID, point)
+	require.NoError(t, err)
+	require.Len(t, revert.Files, 1)
+	require.Equal(t, "before", readTestFile(t, path))
+}
+
+func TestSessionReverterRestoreSkipsPathsOutsideProject(t *testing.T) {
+	r := newTestReverter(t)
+	outside := filepath.Join(t.TempDir(), "outside.txt")
+	require.NoError(t, os.WriteFile(outside, []byte("keep"), 0o644))
+	inside := filepath.Join(r.workingDir, "inside.txt")
+
+	err := r.restore([]session.RevertedFile{
+		{Path: outside, Existed: false},
+		{Path: filepath.Join(r.workingDir, "..", filepath.Base(filepath.Dir(outside)), "outside.txt"), Content: "changed", Existed: true},
+		{Path: inside, Content: "restored", Existed: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "keep", readTestFile(t, outside))
+	require.Equal(t, "restored", readTestFile(t, inside))
+}
+
+func TestSessionReverterCommit(t *testing.T) {
+	r := newTestReverter(t)
+	ctx := t.Context()
+	sess, err := r.sessions.Create(ctx, "test")
+	require.NoError(t, err)
+	child, err := r.sessions.CreateTaskSession(ctx, "call", sess.ID, "child")
+	require.NoError(t, err)
+
+	path := filepath.Join(r.workingDir, "file.txt")
+	first := createTestMessage(t, r, sess.ID, "first")
+	recordEdit(t, r, sess.ID, first, path, "v0", "v1")
+	point := createTestMessage(t, r, sess.ID, "point")
+	recordEdit(t, r, sess.ID, point, path, "", "v2")
+	last := createTestMessage(t, r, sess.ID, "last")
+	recordEdit(t, r, child.ID, last, filepath.Join(r.workingDir, "child.txt"), "", "c1")
+
+	// 没有回退时不做任何操作
+	require.NoError(t, r.commit(ctx, sess.ID))
+	messages, err := r.messages.List(ctx, sess.ID)
+	require.NoError(t, err)
+	require.Len(t, messages, 3)
+
+	revert, err := r.revert(ctx, sess.ID, point)
+	require.NoError(t, err)
+	require.NoError(t, r.sessions.SetRevert(ctx, revert))
+	require.NoError(t, r.commit(ctx, sess.ID))
+
+	messages, err = r.messages.List(ctx, sess.ID)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	require.Equal(t, first.ID, messages[0].ID)
+
+	files, err := r.history.ListBySession(ctx, sess.ID)
+	require.NoError(t, err)
+	for _, f := range files {
+		require.Equal(t, first.ID, f.MessageID)
+	}
+	childFiles, err := r.history.ListBySession(ctx, child.ID)
+	require.NoError(t, err)
+	require.Empty(t, childFiles)
+
+	_, err = r.sessions.GetRevert(ctx, sess.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	require.Equal(t, "v1", readTestFile(t, path))
+}
+
+func TestSessionReverterCommitDropsRevertOfDeletedMessage(t *testing.T) {
+	r := newTestReverter(t)
+	ctx := context.Background()
+	sess, err := r.sessions.Create(ctx, "test")
+	require.NoError(t, err)
+	msg := createTestMessage(t, r, sess.ID, "point")
+
+	require.NoError(t, r.sessions.SetRevert(ctx, session.Revert{SessionID: sess.ID, MessageID: msg.ID}))
+	require.NoError(t, r.messages.Delete(ctx, msg.ID))
+	require.NoError(t, r.commit(ctx, sess.ID))
+
+	_, ok, err := r.get(ctx, sess.ID)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/crush/api/models"
+	"github.com/charmbracelet/crush/internal/permission"
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
 )
@@ -13,11 +14,13 @@ import (
 // HandleListSessions 处理获取项目下所有会话的请求
 //
 //	@Summary		获取会话列表
-//	@Description	获取指定项目的所有会话
+//	@Description	获取指定项目的会话，可通过 limit/offset 分页，total 为会话总数
 //	@Tags			Session
 //	@Accept			json
 //	@Produce		json
 //	@Param			directory	query		string	true	"项目路径"
+//	@Param			limit		query		int		false	"单页条数，最大 1000，不指定时返回全部"
+//	@Param			offset		query		int		false	"跳过的条数"
 //	@Success		200			{object}	models.SessionsResponse
 //	@Failure		400			{object}	map[string]interface{}
 //	@Failure		404			{object}	map[string]interface{}
@@ -51,11 +54,12 @@ func (h *Handlers) HandleListSessions(c context.Context, ctx *app.RequestContext
 		return
 	}
 
+	page := paginate(ctx, sessions)
 	response := models.SessionsResponse{
-		Sessions: make([]models.SessionResponse, len(sessions)),
+		Sessions: make([]models.SessionResponse, len(page)),
 		Total:    len(sessions),
 	}
-	for i, s := range sessions {
+	for i, s := range page {
 		response.Sessions[i] = models.SessionToResponse(s)
 	}
 
@@ -65,7 +69,7 @@ func (h *Handlers) HandleListSessions(c context.Context, ctx *app.RequestContext
 // HandleCreateSession 处理创建会话的请求
 //
 //	@Summary		创建会话
-//	@Description	在指定项目中创建新会话
+//	@Description	在指定项目中创建新会话。permission_mode 决定工具权限请求的处理方式：auto 自动批准，ask 通过 permission.updated 事件等待回复（超时拒绝），deny-dangerous 拒绝执行命令、下载、MCP 工具和项目外路径的请求，其余自动批准。
 //	@Tags			Session
 //	@Accept			json
 //	@Produce		json
@@ -92,6 +96,14 @@ func (h *Handlers) HandleCreateSession(c context.Context, ctx *app.RequestContex
 		WriteError(c, ctx, "INVALID_REQUEST", "Title is required", consts.StatusBadRequest)
 		return
 	}
+	mode := h.opts.PermissionMode
+	if req.PermissionMode != "" {
+		mode = permission.SessionMode(req.PermissionMode)
+		if !mode.Valid() {
+			WriteError(c, ctx, "INVALID_REQUEST", "permission_mode must be one of auto, ask, deny-dangerous", consts.StatusBadRequest)
+			return
+		}
+	}
 
 	// 获取项目的 app 实例
 	appInstance, err := h.GetAppForProject(c, projectPath)
@@ -111,10 +123,19 @@ func (h *Handlers) HandleCreateSession(c context.Context, ctx *app.RequestContex
 		return
 	}
 
-	slog.Info("Session created", "project", projectPath, "session_id", session.ID)
+	// 权限模式保存在会话中，服务重启或项目实例重建后仍然有效
+	if err := appInstance.Sessions.SetPermissionMode(c, session.ID, string(mode)); err != nil {
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to save session permission mode: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+	session.PermissionMode = string(mode)
+	appInstance.Permissions.SetSessionMode(session.ID, mode)
+
+	slog.Info("Session created", "project", projectPath, "session_id", session.ID, "permission_mode", mode)
 
 	response := models.CreateSessionResponse{
-		Session: models.SessionToResponse(session),
+		Session:        models.SessionToResponse(session),
+		PermissionMode: string(mode),
 	}
 
 	WriteJSON(c, ctx, consts.StatusCreated, response)
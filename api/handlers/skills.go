@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"slices"
+
+	"github.com/charmbracelet/crush/api/models"
+	"github.com/charmbracelet/crush/internal/skills"
+	hertzapp "github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// HandleListSkills 获取 Agent Skills
+//
+//	@Summary		获取 skill 列表
+//	@Description	在 options.skills_paths 和全局 skills 目录中查找 SKILL.md，返回每个 skill 的名称、描述、路径和校验结果。
+//	@Description	valid 为 false 的 skill 不会提供给 agent，errors 为原因。
+//	@Tags			Skills
+//	@Produce		json
+//	@Param			directory	query		string	true	"项目路径"
+//	@Success		200			{object}	models.SkillsResponse
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		500			{object}	map[string]interface{}
+//	@Router			/skill [get]
+func (h *Handlers) HandleListSkills(c context.Context, ctx *hertzapp.RequestContext) {
+	appInstance, ok := h.projectApp(c, ctx)
+	if !ok {
+		return
+	}
+
+	paths := appInstance.Config().SkillsDirs()
+	results := skills.Scan(paths)
+	response := models.SkillsResponse{
+		Paths:  paths,
+		Skills: make([]models.Skill, 0, len(results)),
+	}
+	for _, result := range results {
+		response.Skills = append(response.Skills, models.SkillFromResult(result))
+	}
+	WriteJSON(c, ctx, consts.StatusOK, response)
+}
+
+// HandleGetSkill 获取 skill 的内容
+//
+//	@Summary		获取 skill
+//	@Description	按名称获取 skill，包括 SKILL.md 的正文。同名 skill 有多个时返回第一个通过校验的。
+//	@Tags			Skills
+//	@Produce		json
+//	@Param			directory	query		string	true	"项目路径"
+//	@Param			name		path		string	true	"skill 名称"
+//	@Success		200			{object}	models.Skill
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		500			{object}	map[string]interface{}
+//	@Router			/skill/{name} [get]
+func (h *Handlers) HandleGetSkill(c context.Context, ctx *hertzapp.RequestContext) {
+	appInstance, ok := h.projectApp(c, ctx)
+	if !ok {
+		return
+	}
+	name := ctx.Param("name")
+
+	var matches []skills.Result
+	for _, result := range skills.Scan(appInstance.Config().SkillsDirs()) {
+		if result.Skill != nil && result.Skill.Name == name {
+			matches = append(matches, result)
+		}
+	}
+	if len(matches) == 0 {
+		WriteError(c, ctx, "SKILL_NOT_FOUND", "Skill not found: "+name, consts.StatusNotFound)
+		return
+	}
+	// 优先返回 agent 能看到的 skill
+	idx := max(slices.IndexFunc(matches, func(r skills.Result) bool { return r.Err == nil }), 0)
+
+	skill := models.SkillFromResult(matches[idx])
+	skill.Instructions = matches[idx].Skill.Instructions
+	WriteJSON(c, ctx, consts.StatusOK, skill)
+}
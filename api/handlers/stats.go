@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/charmbracelet/crush/api/middleware"
+	"github.com/charmbracelet/crush/api/models"
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/projects"
+	"github.com/charmbracelet/crush/internal/stats"
+	hertzapp "github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// maxStatsSessionLimit session_limit 的上限
+const maxStatsSessionLimit = 1000
+
+// HandleGetStats 获取项目的用量和费用统计
+//
+//	@Summary		获取项目的用量和费用统计
+//	@Description	返回项目中顶层会话的 token 用量和费用，包括按天、模型、小时和星期的分布、平均响应时间、工具调用次数，以及费用最高的会话。
+//	@Description	数据与 crush stats 生成的页面一致。日期按 UTC 计算；指定 model 时只统计有该模型回复的会话，按模型和工具的分布只统计该模型的消息。
+//	@Tags			Stats
+//	@Produce		json
+//	@Param			directory		query		string	true	"项目路径"
+//	@Param			from			query		string	false	"开始日期（YYYY-MM-DD，包含）"
+//	@Param			to				query		string	false	"结束日期（YYYY-MM-DD，包含）"
+//	@Param			model			query		string	false	"模型 ID"
+//	@Param			session_limit	query		int		false	"返回的费用最高的会话数，默认 20，最大 1000"
+//	@Success		200				{object}	models.StatsResponse
+//	@Failure		400				{object}	map[string]interface{}
+//	@Failure		404				{object}	map[string]interface{}
+//	@Failure		500				{object}	map[string]interface{}
+//	@Router			/stats [get]
+func (h *Handlers) HandleGetStats(c context.Context, ctx *hertzapp.RequestContext) {
+	filter, ok := statsFilter(c, ctx)
+	if !ok {
+		return
+	}
+	appInstance, ok := h.projectApp(c, ctx)
+	if !ok {
+		return
+	}
+
+	result, err := appInstance.Stats(c, filter)
+	if err != nil {
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to gather stats: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+	WriteJSON(c, ctx, consts.StatusOK, models.StatsResponse{Stats: *result})
+}
+
+// HandleGetGlobalStats 获取所有项目的用量和费用统计
+//
+//	@Summary		获取所有项目的用量和费用统计
+//	@Description	合计所有已注册项目（受限的 API Key 只包括其授权的项目）的统计，格式同 /stats，另外返回每个项目的合计。
+//	@Description	未加载的项目以只读方式读取其数据库，不会创建 app 实例，也不会迁移数据库；还没有数据库的项目不计入。读取失败或数据库版本低于当前程序的项目在 projects 中返回 error，不影响其他项目。
+//	@Tags			Stats
+//	@Produce		json
+//	@Param			from			query		string	false	"开始日期（YYYY-MM-DD，包含）"
+//	@Param			to				query		string	false	"结束日期（YYYY-MM-DD，包含）"
+//	@Param			model			query		string	false	"模型 ID"
+//	@Param			session_limit	query		int		false	"返回的费用最高的会话数，默认 20，最大 1000"
+//	@Success		200				{object}	models.GlobalStatsResponse
+//	@Failure		400				{object}	map[string]interface{}
+//	@Failure		500				{object}	map[string]interface{}
+//	@Router			/global/stats [get]
+func (h *Handlers) HandleGetGlobalStats(c context.Context, ctx *hertzapp.RequestContext) {
+	filter, ok := statsFilter(c, ctx)
+	if !ok {
+		return
+	}
+	projectList, err := projects.List()
+	if err != nil {
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to list projects: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+	principal, _ := middleware.PrincipalFrom(ctx)
+
+	var all []*stats.Stats
+	projectStats := []models.ProjectStats{}
+	for _, p := range projectList {
+		if !principal.AllowsProject(p.Path) {
+			continue
+		}
+		result, err := projectStatsFor(c, p, filter)
+		if err != nil {
+			slog.Warn("Failed to gather project stats", "project", p.Path, "error", err)
+			projectStats = append(projectStats, models.ProjectStats{Path: p.Path, Error: err.Error()})
+			continue
+		}
+		if result == nil {
+			continue
+		}
+		for i := range result.SessionCosts {
+			result.SessionCosts[i].Project = p.Path
+		}
+		all = append(all, result)
+		projectStats = append(projectStats, models.ProjectStats{
+			Path:          p.Path,
+			TotalSessions: result.Total.TotalSessions,
+			TotalTokens:   result.Total.TotalTokens,
+			TotalCost:     result.Total.TotalCost,
+		})
+	}
+	slices.SortStableFunc(projectStats, func(a, b models.ProjectStats) int {
+		return cmp.Compare(b.TotalCost, a.TotalCost)
+	})
+
+	WriteJSON(c, ctx, consts.StatusOK, models.GlobalStatsResponse{
+		Stats:    *stats.Merge(filter.SessionLimit, all...),
+		Projects: projectStats,
+	})
+}
+
+// projectStatsFor 获取项目的统计，已加载的项目使用其实例的数据库连接，未加载的项目只读打开其数据库，
+// 没有数据库的项目返回 nil
+func projectStatsFor(c context.Context, p projects.Project, filter stats.Filter) (*stats.Stats, error) {
+	globalAppManager.mu.RLock()
+	appInstance, ok := globalAppManager.apps[p.Path]
+	globalAppManager.mu.RUnlock()
+	if ok {
+		return appInstance.Stats(c, filter)
+	}
+
+	if _, err := os.Stat(filepath.Join(p.DataDir, "crush.db")); os.IsNotExist(err) {
+		return nil, nil
+	}
+	// 只读打开且不执行迁移：项目可能正被其他进程使用，数据库版本较旧时返回错误
+	conn, err := db.OpenReadOnly(c, p.DataDir)
+	if errors.Is(err, db.ErrOutdatedSchema) {
+		return nil, errors.New("database schema is outdated, open the project to migrate it")
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return stats.Gather(c, conn, filter)
+}
+
+// statsFilter 解析 from、to、model 和 session_limit 参数
+func statsFilter(c context.Context, ctx *hertzapp.RequestContext) (stats.Filter, bool) {
+	from, to, ok := parseDateRange(c, ctx)
+	if !ok {
+		return stats.Filter{}, false
+	}
+	filter := stats.Filter{From: from, To: to, Model: string(ctx.Query("model"))}
+
+	if raw := string(ctx.Query("session_limit")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxStatsSessionLimit {
+			WriteError(c, ctx, "INVALID_REQUEST", "session_limit must be between 1 and "+strconv.Itoa(maxStatsSessionLimit), consts.StatusBadRequest)
+			return stats.Filter{}, false
+		}
+		filter.SessionLimit = n
+	}
+	return filter, true
+}
@@ -46,13 +46,7 @@ func (h *Handlers) HandleGetLSPStatus(c context.Context, ctx *hertzapp.RequestCo
 	// 遍历所有 LSP 客户端
 	// csync.Map 使用 Seq2() 方法遍历
 	for name, _ := range lspClients.Seq2() {
-		status := models.LSPStatus{
-			ID:     name,
-			Name:   name,
-			Root:   directory,
-			Status: "connected", // 如果在 Map 中，说明已连接
-		}
-		lspStatusList = append(lspStatusList, status)
+		lspStatusList = append(lspStatusList, lspStatus(name, directory))
 	}
 
 	WriteJSON(c, ctx, consts.StatusOK, lspStatusList)
@@ -61,7 +55,7 @@ func (h *Handlers) HandleGetLSPStatus(c context.Context, ctx *hertzapp.RequestCo
 // HandleGetMCPStatus 处理获取 MCP 状态的请求 (参考 OpenCode: /mcp)
 //
 //	@Summary		获取 MCP 状态
-//	@Description	获取所有 Model Context Protocol (MCP) 服务器的状态
+//	@Description	获取项目配置的 Model Context Protocol (MCP) 服务器的状态：disabled、starting、connected 或 failed（message 为原因），以及工具和 prompt 数
 //	@Tags			MCP
 //	@Accept			json
 //	@Produce		json
@@ -88,24 +82,19 @@ func (h *Handlers) HandleGetMCPStatus(c context.Context, ctx *hertzapp.RequestCo
 		return
 	}
 
-	// 获取配置的 MCP 列表
-	cfg := appInstance.Config()
-	mcpConfigs := cfg.MCP
-
-	// 构建 MCP 状态响应
+	// MCP 客户端由所有项目共用，只返回该项目配置的服务器
 	mcpStatusMap := make(map[string]models.MCPStatus)
-
-	for name := range mcpConfigs {
-		// 简单判断 MCP 状态
-		// 实际实现中可能需要检查 MCP 连接状态
-		status := models.MCPStatusConnected{
-			Status: "connected",
+	for name, m := range appInstance.Config().MCP {
+		status := mcpServerStatus(name, m)
+		entry := models.MCPStatus{
+			"status":  status.Status,
+			"tools":   status.Tools,
+			"prompts": status.Prompts,
 		}
-
-		// 将状态转换为通用格式
-		mcpStatusMap[name] = models.MCPStatus{
-			"status": status.Status,
+		if status.Message != "" {
+			entry["message"] = status.Message
 		}
+		mcpStatusMap[name] = entry
 	}
 
 	WriteJSON(c, ctx, consts.StatusOK, mcpStatusMap)
@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/crush/api/models"
+	internalapp "github.com/charmbracelet/crush/internal/app"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/x/powernap/pkg/lsp/protocol"
+	hertzapp "github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+const (
+	// defaultSymbolLimit /find/symbol 默认返回的符号数量
+	defaultSymbolLimit = 50
+	// maxSymbolLimit /find/symbol 最多返回的符号数量
+	maxSymbolLimit = 200
+	// symbolSearchTimeout 符号搜索的超时时间，需要对每个候选符号请求 LSP
+	symbolSearchTimeout = 15 * time.Second
+)
+
+// HandleFindSymbol 搜索工作区符号 (参考 OpenCode: /find/symbol)
+//
+//	@Summary		搜索工作区符号
+//	@Description	通过项目中已就绪的 LSP 服务器搜索名称包含 query 的符号（不区分大小写），合并所有服务器的结果，完全匹配的符号排在前面。没有就绪的 LSP 服务器时返回空数组。
+//	@Tags			File
+//	@Produce		json
+//	@Param			directory	query	string	true	"项目路径"
+//	@Param			query		query	string	true	"符号名称"
+//	@Param			limit		query	int		false	"最多返回的符号数量（1-200，默认 50）"
+//	@Success		200			{array}		models.Symbol
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		500			{object}	map[string]interface{}
+//	@Router			/find/symbol [get]
+func (h *Handlers) HandleFindSymbol(c context.Context, ctx *hertzapp.RequestContext) {
+	query := strings.TrimSpace(string(ctx.Query("query")))
+	if query == "" {
+		WriteError(c, ctx, "MISSING_QUERY", "Query parameter is required", consts.StatusBadRequest)
+		return
+	}
+	if err := validateSearchInput(query); err != nil {
+		WriteError(c, ctx, "INVALID_QUERY", err.Error(), consts.StatusBadRequest)
+		return
+	}
+	limit := defaultSymbolLimit
+	if raw := string(ctx.Query("limit")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxSymbolLimit {
+			WriteError(c, ctx, "INVALID_REQUEST", "limit must be between 1 and 200", consts.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	appInstance, ok := h.projectApp(c, ctx)
+	if !ok {
+		return
+	}
+	root := appInstance.Config().WorkingDir()
+
+	searchCtx, cancel := context.WithTimeout(c, symbolSearchTimeout)
+	defer cancel()
+	symbols, err := lsp.SearchSymbols(searchCtx, readyLSPClients(appInstance), root, query, limit)
+	if err != nil {
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to search symbols: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+
+	WriteJSON(c, ctx, consts.StatusOK, toSymbolModels(root, symbols))
+}
+
+// HandleFindDefinition 跳转到定义
+//
+//	@Summary		查找定义
+//	@Description	通过处理该文件的 LSP 服务器查找指定位置符号的声明，合并所有服务器的结果。没有就绪的 LSP 服务器时返回空数组。
+//	@Tags			File
+//	@Produce		json
+//	@Param			directory	query	string	true	"项目路径"
+//	@Param			path		query	string	true	"文件路径（相对于项目根目录）"
+//	@Param			line		query	int		true	"行号（从 1 开始）"
+//	@Param			column		query	int		true	"列号（从 1 开始，以 UTF-16 码元计）"
+//	@Success		200			{array}		models.Symbol
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		500			{object}	map[string]interface{}
+//	@Router			/find/definition [get]
+func (h *Handlers) HandleFindDefinition(c context.Context, ctx *hertzapp.RequestContext) {
+	h.findAtPosition(c, ctx, func(client *lsp.Client, path string, line, column int) ([]protocol.Location, error) {
+		return client.FindDefinition(c, path, line, column)
+	}, func(root string, locs []protocol.Location) any {
+		return toSymbolModels(root, lsp.Symbols(locs))
+	})
+}
+
+// HandleFindReferences 查找引用
+//
+//	@Summary		查找引用
+//	@Description	通过处理该文件的 LSP 服务器查找指定位置符号的所有引用，合并所有服务器的结果。没有就绪的 LSP 服务器时返回空数组。
+//	@Tags			File
+//	@Produce		json
+//	@Param			directory			query	string	true	"项目路径"
+//	@Param			path				query	string	true	"文件路径（相对于项目根目录）"
+//	@Param			line				query	int		true	"行号（从 1 开始）"
+//	@Param			column				query	int		true	"列号（从 1 开始，以 UTF-16 码元计）"
+//	@Param			include_declaration	query	bool	false	"是否包含声明，默认 true"
+//	@Success		200					{array}		models.Location
+//	@Failure		400					{object}	map[string]interface{}
+//	@Failure		404					{object}	map[string]interface{}
+//	@Failure		500					{object}	map[string]interface{}
+//	@Router			/find/references [get]
+func (h *Handlers) HandleFindReferences(c context.Context, ctx *hertzapp.RequestContext) {
+	includeDeclaration := string(ctx.Query("include_declaration")) != "false"
+	h.findAtPosition(c, ctx, func(client *lsp.Client, path string, line, column int) ([]protocol.Location, error) {
+		return client.FindReferences(c, path, line, column, includeDeclaration)
+	}, func(root string, locs []protocol.Location) any {
+		out := make([]models.Location, 0, len(locs))
+		for _, loc := range locs {
+			out = append(out, toLocationModel(root, loc))
+		}
+		return out
+	})
+}
+
+// findAtPosition 解析 path、line、column 参数，向所有处理该文件的 LSP 服务器查询并合并结果
+func (h *Handlers) findAtPosition(
+	c context.Context,
+	ctx *hertzapp.RequestContext,
+	find func(client *lsp.Client, path string, line, column int) ([]protocol.Location, error),
+	respond func(root string, locs []protocol.Location) any,
+) {
+	line, err := strconv.Atoi(string(ctx.Query("line")))
+	if err != nil || line < 1 {
+		WriteError(c, ctx, "INVALID_REQUEST", "line must be a positive integer", consts.StatusBadRequest)
+		return
+	}
+	column, err := strconv.Atoi(string(ctx.Query("column")))
+	if err != nil || column < 1 {
+		WriteError(c, ctx, "INVALID_REQUEST", "column must be a positive integer", consts.StatusBadRequest)
+		return
+	}
+
+	appInstance, ok := h.projectApp(c, ctx)
+	if !ok {
+		return
+	}
+	root := appInstance.Config().WorkingDir()
+	path := string(ctx.Query("path"))
+	fullPath, err := resolveProjectPath(root, path)
+	if err != nil {
+		WriteError(c, ctx, "INVALID_PATH", err.Error(), consts.StatusBadRequest)
+		return
+	}
+
+	var locs []protocol.Location
+	for _, client := range readyLSPClients(appInstance) {
+		if !client.HandlesFile(fullPath) {
+			continue
+		}
+		found, err := find(client, fullPath, line, column)
+		if err != nil {
+			// 其他服务器可能仍有结果
+			slog.Warn("LSP lookup failed", "lsp", client.GetName(), "path", path, "error", err)
+			continue
+		}
+		locs = append(locs, found...)
+	}
+
+	WriteJSON(c, ctx, consts.StatusOK, respond(root, lsp.SortLocations(locs)))
+}
+
+// projectApp 校验 directory 参数并获取项目的 app 实例
+func (h *Handlers) projectApp(c context.Context, ctx *hertzapp.RequestContext) (*internalapp.App, bool) {
+	directory := string(ctx.Query("directory"))
+	if directory == "" {
+		WriteError(c, ctx, "MISSING_DIRECTORY_PARAM", "Directory query parameter is required", consts.StatusBadRequest)
+		return nil, false
+	}
+
+	appInstance, err := h.GetAppForProject(c, directory)
+	if err != nil {
+		if strings.Contains(err.Error(), "project not found") {
+			WriteError(c, ctx, "PROJECT_NOT_FOUND", err.Error(), consts.StatusNotFound)
+			return nil, false
+		}
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to get app for project: "+err.Error(), consts.StatusInternalServerError)
+		return nil, false
+	}
+	return appInstance, true
+}
+
+// readyLSPClients 返回项目中已就绪的 LSP 客户端
+func readyLSPClients(appInstance *internalapp.App) []*lsp.Client {
+	var clients []*lsp.Client
+	for client := range appInstance.LSPClients.Seq() {
+		if client.GetServerState() == lsp.StateReady {
+			clients = append(clients, client)
+		}
+	}
+	return clients
+}
+
+// toSymbolModels 转换为 API 的符号
+func toSymbolModels(root string, symbols []lsp.Symbol) []models.Symbol {
+	out := make([]models.Symbol, 0, len(symbols))
+	for _, sym := range symbols {
+		out = append(out, models.Symbol{
+			Name:     sym.Name,
+			Kind:     int(sym.Kind),
+			Location: toLocationModel(root, sym.Location),
+		})
+	}
+	return out
+}
+
+// toLocationModel 转换为 API 的位置，项目内的文件路径转换为相对路径
+func toLocationModel(root string, loc protocol.Location) models.Location {
+	path, err := loc.URI.Path()
+	if err == nil && isWithin(root, path) {
+		if rel, err := filepath.Rel(root, path); err == nil {
+			path = filepath.ToSlash(rel)
+		}
+	}
+	return models.Location{
+		URI:  string(loc.URI),
+		Path: path,
+		Range: models.Range{
+			Start: models.Position{Line: int(loc.Range.Start.Line), Character: int(loc.Range.Start.Character)},
+			End:   models.Position{Line: int(loc.Range.End.Line), Character: int(loc.Range.End.Character)},
+		},
+	}
+}
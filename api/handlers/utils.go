@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"log/slog"
 	"strconv"
+	"time"
 
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
@@ -35,6 +36,18 @@ func WriteError(c context.Context, ctx *app.RequestContext, code string, message
 	ctx.Response.SetBody(buf.Bytes())
 }
 
+// apiError 处理器内部返回的错误，由调用方按所在的传输方式返回给客户端
+type apiError struct {
+	code    string
+	message string
+	status  int
+}
+
+// write 以 HTTP 错误响应返回
+func (e *apiError) write(c context.Context, ctx *app.RequestContext) {
+	WriteError(c, ctx, e.code, e.message, e.status)
+}
+
 // WriteJSON 写入 JSON 响应
 func WriteJSON(c context.Context, ctx *app.RequestContext, statusCode int, data interface{}) {
 	// 先编码到 buffer，避免部分写入问题
@@ -49,6 +62,18 @@ func WriteJSON(c context.Context, ctx *app.RequestContext, statusCode int, data
 	ctx.Response.SetBody(buf.Bytes())
 }
 
+// maxPageLimit 分页查询单页最多返回的条数
+const maxPageLimit = 1000
+
+// paginate 按 limit/offset 查询参数截取列表，未指定 limit 时返回 offset 之后的全部数据
+func paginate[T any](ctx *app.RequestContext, items []T) []T {
+	limit, offset := ParsePaginationParams(ctx, len(items), maxPageLimit)
+	if offset >= len(items) {
+		return nil
+	}
+	return items[offset:min(offset+limit, len(items))]
+}
+
 // ParsePaginationParams 解析分页参数
 func ParsePaginationParams(ctx *app.RequestContext, defaultLimit, maxLimit int) (limit, offset int) {
 	limit = defaultLimit
@@ -71,3 +96,32 @@ func ParsePaginationParams(ctx *app.RequestContext, defaultLimit, maxLimit int)
 
 	return limit, offset
 }
+
+// parseDateRange 解析 from 和 to 查询参数（YYYY-MM-DD，UTC，包含两端），返回 [from, to) 范围
+// 未指定的一端为零值，参数不合法时写入 400 错误并返回 false
+func parseDateRange(c context.Context, ctx *app.RequestContext) (from, to time.Time, ok bool) {
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		raw := string(ctx.Query(p.name))
+		if raw == "" {
+			continue
+		}
+		day, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			WriteError(c, ctx, "INVALID_REQUEST", p.name+" must be a date in YYYY-MM-DD format", consts.StatusBadRequest)
+			return time.Time{}, time.Time{}, false
+		}
+		*p.dst = day
+	}
+	if !to.IsZero() {
+		// to 包含当天
+		to = to.AddDate(0, 0, 1)
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		WriteError(c, ctx, "INVALID_REQUEST", "from must not be after to", consts.StatusBadRequest)
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
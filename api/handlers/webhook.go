@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/charmbracelet/crush/api/models"
+	hertzapp "github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// HandleGetWebhookStatus 获取 webhook 的投递状态
+//
+//	@Summary		获取 webhook 的投递状态
+//	@Description	返回配置文件 webhooks 中每个 webhook 的状态：订阅的事件、排队数、成功/失败/丢弃的投递数、最后一次错误，以及最近 50 次投递（按时间倒序）。
+//	@Description	URL 不包含用户信息和查询参数。配置有误的 webhook 不会启动，错误在 error 中返回。投递记录只保存在内存中，项目实例释放后清空。
+//	@Tags			Webhook
+//	@Produce		json
+//	@Param			directory	query		string	true	"项目路径"
+//	@Success		200			{object}	models.WebhookStatusResponse
+//	@Failure		400			{object}	map[string]interface{}
+//	@Failure		404			{object}	map[string]interface{}
+//	@Failure		500			{object}	map[string]interface{}
+//	@Router			/webhook [get]
+func (h *Handlers) HandleGetWebhookStatus(c context.Context, ctx *hertzapp.RequestContext) {
+	appInstance, ok := h.projectApp(c, ctx)
+	if !ok {
+		return
+	}
+	WriteJSON(c, ctx, consts.StatusOK, models.WebhookStatusResponse{Webhooks: appInstance.Webhooks.Status()})
+}
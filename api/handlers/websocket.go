@@ -0,0 +1,469 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/crush/api/middleware"
+	"github.com/charmbracelet/crush/api/models"
+	internalapp "github.com/charmbracelet/crush/internal/app"
+	hertzapp "github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/adaptor"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// wsWriteWait 单条消息的写超时
+	wsWriteWait = 10 * time.Second
+	// wsPongWait 等待客户端 pong 或任意消息的时间，超时后断开连接
+	wsPongWait = 60 * time.Second
+	// wsPingInterval 发送 ping 的间隔，必须小于 wsPongWait
+	wsPingInterval = 30 * time.Second
+	// wsMaxMessageSize 客户端消息的大小上限，prompt 可能带有内联附件
+	wsMaxMessageSize = 32 << 20
+	// wsResultBufferSize 每个连接待发送的命令结果缓冲
+	wsResultBufferSize = 64
+)
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+	// 与 CORS 配置一致允许任意来源，访问控制由认证中间件完成
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+// HandleWebSocket 处理 WebSocket 连接
+//
+//	@Summary		WebSocket 连接
+//	@Description	双向连接：服务器发送与 /event 相同的事件（models.WSEvent，id 与 /event 属于同一序列），
+//	@Description	客户端发送 prompt、abort、permission.reply、subscribe、unsubscribe、ping 命令（models.WSCommand），
+//	@Description	命令结果以 command.result 事件返回（models.WSCommandResult）。prompt 命令以异步运行方式执行，与 HTTP 请求一样计入客户端的限流。
+//	@Description	订阅了会话时只发送这些会话的事件，与会话无关的事件总是发送。
+//	@Description	服务器每 30 秒发送一次 ping，60 秒内没有收到 pong 或消息时断开连接
+//	@Tags			Event
+//	@Param			directory		query		string	true	"项目路径"
+//	@Param			session_id		query		string	false	"初始订阅的会话，多个会话用逗号分隔，不指定时接收所有会话的事件"
+//	@Param			last_event_id	query		string	false	"最后收到的事件 ID，用于断线重连后补发"
+//	@Success		101				{object}	models.WSEvent	"Switching Protocols"
+//	@Failure		400				{object}	map[string]interface{}
+//	@Failure		404				{object}	map[string]interface{}
+//	@Router			/ws [get]
+func (h *Handlers) HandleWebSocket(c context.Context, ctx *hertzapp.RequestContext) {
+	projectPath := string(ctx.Query("directory"))
+	if projectPath == "" {
+		WriteError(c, ctx, "MISSING_DIRECTORY_PARAM", "Directory query parameter is required", consts.StatusBadRequest)
+		return
+	}
+
+	// 升级前获取 app 实例，项目不存在时返回普通的错误响应
+	appInstance, err := h.GetAppForProject(c, projectPath)
+	if err != nil {
+		if strings.Contains(err.Error(), "project not found") {
+			WriteError(c, ctx, "PROJECT_NOT_FOUND", err.Error(), consts.StatusNotFound)
+			return
+		}
+		WriteError(c, ctx, "INTERNAL_ERROR", "Failed to get or create app for project: "+err.Error(), consts.StatusInternalServerError)
+		return
+	}
+
+	// 只读的 API Key 只能接收事件，不能发送会修改状态的命令
+	readOnly := false
+	if principal, ok := middleware.PrincipalFrom(ctx); ok {
+		readOnly = principal.ReadOnly
+	}
+	// 连接只在建立时经过限流中间件，prompt 命令与 HTTP 请求共用客户端的限流器
+	limiter, _ := middleware.RateLimiterFrom(ctx)
+
+	// 浏览器无法为 WebSocket 设置请求头，因此 Last-Event-ID 也可以通过查询参数传递
+	lastEventHeader := string(ctx.Query("last_event_id"))
+	if lastEventHeader == "" {
+		lastEventHeader = string(ctx.GetHeader("Last-Event-ID"))
+	}
+	lastEventID, resume := parseLastEventID(lastEventHeader)
+
+	ws := &wsConn{
+		h:        h,
+		app:      appInstance,
+		readOnly: readOnly,
+		limiter:  limiter,
+		actor:    requestActor(ctx),
+		results:  make(chan models.SSEEvent, wsResultBufferSize),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		sessions: make(map[string]struct{}),
+	}
+	for id := range strings.SplitSeq(string(ctx.Query("session_id")), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ws.sessions[id] = struct{}{}
+		}
+	}
+
+	// 连接在 handler 返回前一直保持，返回后 Hertz 关闭被接管的连接
+	adaptor.HertzHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := wsUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// Upgrade 已经写入了错误响应
+			slog.Warn("WebSocket upgrade failed", "error", err, "remote_addr", r.RemoteAddr)
+			return
+		}
+		ws.conn = conn
+		ws.remoteAddr = r.RemoteAddr
+		// netpoll 连接不支持 SetWriteDeadline，通过连接的写超时避免客户端不读取时写操作一直阻塞
+		if tc, ok := conn.NetConn().(interface{ SetWriteTimeout(time.Duration) error }); ok {
+			_ = tc.SetWriteTimeout(wsWriteWait)
+		}
+
+		slog.Info("WebSocket connection established", "remote_addr", ws.remoteAddr, "project", projectPath)
+		ws.serve(c, h.eventHubFor(projectPath, appInstance), lastEventID, resume)
+		slog.Info("WebSocket connection closed", "remote_addr", ws.remoteAddr, "project", projectPath)
+	}))(c, ctx)
+}
+
+// wsConn 一个 WebSocket 连接
+// 读 goroutine 处理客户端命令，写 goroutine 负责发送事件、命令结果和 ping，
+// 除 WriteControl 外所有写操作都在写 goroutine 中进行
+type wsConn struct {
+	h          *Handlers
+	conn       *websocket.Conn
+	app        *internalapp.App
+	remoteAddr string
+	readOnly   bool
+	// limiter 客户端的限流器，未启用限流时为 nil
+	limiter *middleware.RateLimiter
+	// actor 审计日志中记录的调用方，用于连接发起的运行和权限回复
+	actor string
+
+	// results 待发送的命令结果
+	results chan models.SSEEvent
+	// stop 读循环结束时关闭，通知写 goroutine 退出
+	stop chan struct{}
+	// done 写 goroutine 退出时关闭
+	done chan struct{}
+	// lastSeen 最后一次收到客户端消息或 pong 的时间（Unix 纳秒）
+	// netpoll 连接不支持读超时，由写 goroutine 在发送 ping 时检查
+	lastSeen atomic.Int64
+
+	mu sync.Mutex
+	// sessions 订阅的会话，为空表示接收所有会话的事件
+	sessions map[string]struct{}
+}
+
+// serve 处理连接直到任意一方断开
+func (ws *wsConn) serve(c context.Context, hub *eventHub, lastEventID uint64, resume bool) {
+	eventCh, replay, complete, latestID := hub.subscribe(lastEventID, resume)
+	defer hub.unsubscribe(eventCh)
+
+	go ws.writeLoop(eventCh, replay, complete, latestID)
+	ws.readLoop(c)
+
+	close(ws.stop)
+	<-ws.done
+}
+
+// readLoop 读取并执行客户端命令，连接出错或关闭时返回
+func (ws *wsConn) readLoop(c context.Context) {
+	ws.conn.SetReadLimit(wsMaxMessageSize)
+	ws.touch()
+	ws.conn.SetPongHandler(func(string) error {
+		ws.touch()
+		return nil
+	})
+	ws.conn.SetPingHandler(func(data string) error {
+		ws.touch()
+		err := ws.conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(wsWriteWait))
+		if err == websocket.ErrCloseSent {
+			return nil
+		}
+		return err
+	})
+
+	for {
+		_, data, err := ws.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				slog.Info("WebSocket read failed", "error", err, "remote_addr", ws.remoteAddr)
+			}
+			return
+		}
+		ws.touch()
+
+		var cmd models.WSCommand
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			ws.reply(models.WSCommandResult{
+				Error: &models.WSError{Code: "INVALID_REQUEST", Message: "Invalid command: " + err.Error()},
+			})
+			continue
+		}
+		ws.reply(ws.handleCommand(c, cmd))
+	}
+}
+
+// writeLoop 发送事件、命令结果和 ping，写入失败或事件流关闭时关闭连接
+func (ws *wsConn) writeLoop(eventCh <-chan hubEntry, replay []hubEntry, complete bool, latestID uint64) {
+	defer close(ws.done)
+	// 关闭连接使读循环返回
+	defer ws.conn.Close()
+
+	if err := ws.writeMessage(encodeEvent(models.SSEEvent{
+		Type: "server.connected",
+		Properties: map[string]string{
+			"status": "connected",
+		},
+	})); err != nil {
+		return
+	}
+
+	// 缺失的事件已不在缓冲中，通知客户端重新获取状态
+	if !complete {
+		if err := ws.writeEntry(resetEntry(latestID)); err != nil {
+			return
+		}
+	}
+	for _, entry := range replay {
+		if !ws.wants(entry.Value.sessionID) {
+			continue
+		}
+		if err := ws.writeEntry(entry); err != nil {
+			return
+		}
+	}
+
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ws.stop:
+			return
+
+		case entry, ok := <-eventCh:
+			if !ok {
+				// 实例被释放或连接跟不上事件速度，客户端应携带 last_event_id 重连
+				_ = ws.conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseGoingAway, "event stream closed"),
+					time.Now().Add(wsWriteWait))
+				return
+			}
+			if !ws.wants(entry.Value.sessionID) {
+				continue
+			}
+			if err := ws.writeEntry(entry); err != nil {
+				slog.Info("WebSocket client disconnected", "remote_addr", ws.remoteAddr)
+				return
+			}
+
+		case result := <-ws.results:
+			if err := ws.writeMessage(encodeEvent(result)); err != nil {
+				return
+			}
+
+		case <-ping.C:
+			if time.Since(time.Unix(0, ws.lastSeen.Load())) > wsPongWait {
+				slog.Info("WebSocket client timed out", "remote_addr", ws.remoteAddr)
+				return
+			}
+			if err := ws.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// touch 记录收到客户端消息的时间
+func (ws *wsConn) touch() {
+	ws.lastSeen.Store(time.Now().UnixNano())
+}
+
+// writeMessage 发送一条文本消息
+func (ws *wsConn) writeMessage(data []byte) error {
+	_ = ws.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	return ws.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// writeEntry 发送带 ID 的事件，在事件 JSON 中加入 id 字段
+func (ws *wsConn) writeEntry(entry hubEntry) error {
+	data := entry.Value.data
+	buf := make([]byte, 0, len(data)+24)
+	buf = fmt.Appendf(buf, `{"id":"%d",`, entry.ID)
+	buf = append(buf, data[1:]...)
+	return ws.writeMessage(buf)
+}
+
+// reply 发送命令结果，写 goroutine 已退出时丢弃
+func (ws *wsConn) reply(result models.WSCommandResult) {
+	select {
+	case ws.results <- models.SSEEvent{Type: models.WSEventCommandResult, Properties: result}:
+	case <-ws.done:
+	}
+}
+
+// wants 判断是否发送属于 sessionID 的事件
+func (ws *wsConn) wants(sessionID string) bool {
+	if sessionID == "" {
+		return true
+	}
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if len(ws.sessions) == 0 {
+		return true
+	}
+	_, ok := ws.sessions[sessionID]
+	return ok
+}
+
+// subscription 返回当前订阅的会话
+func (ws *wsConn) subscription() models.WSSubscription {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ids := make([]string, 0, len(ws.sessions))
+	for id := range ws.sessions {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return models.WSSubscription{SessionIDs: ids}
+}
+
+// handleCommand 执行客户端命令
+func (ws *wsConn) handleCommand(c context.Context, cmd models.WSCommand) models.WSCommandResult {
+	result := models.WSCommandResult{ID: cmd.ID, Command: cmd.Type}
+
+	var (
+		data   interface{}
+		cmdErr *apiError
+	)
+	switch cmd.Type {
+	case models.WSCommandPrompt:
+		data, cmdErr = ws.prompt(c, cmd)
+	case models.WSCommandAbort:
+		cmdErr = ws.abort(cmd)
+	case models.WSCommandPermissionReply:
+		cmdErr = ws.replyPermission(cmd)
+	case models.WSCommandSubscribe, models.WSCommandUnsubscribe:
+		if len(cmd.SessionIDs) == 0 {
+			cmdErr = &apiError{"INVALID_REQUEST", "session_ids is required", consts.StatusBadRequest}
+			break
+		}
+		ws.mu.Lock()
+		for _, id := range cmd.SessionIDs {
+			if cmd.Type == models.WSCommandSubscribe {
+				ws.sessions[id] = struct{}{}
+			} else {
+				delete(ws.sessions, id)
+			}
+		}
+		ws.mu.Unlock()
+		data = ws.subscription()
+	case models.WSCommandPing:
+	default:
+		cmdErr = &apiError{"UNKNOWN_COMMAND", "Unknown command type: " + cmd.Type, consts.StatusBadRequest}
+	}
+
+	if cmdErr != nil {
+		result.Error = &models.WSError{Code: cmdErr.code, Message: cmdErr.message}
+		return result
+	}
+	result.OK = true
+	result.Data = data
+	return result
+}
+
+// checkWritable 只读连接不能执行修改状态的命令
+func (ws *wsConn) checkWritable() *apiError {
+	if ws.readOnly {
+		return &apiError{"FORBIDDEN", "API key is read-only", consts.StatusForbidden}
+	}
+	return nil
+}
+
+// prompt 以异步运行方式发送消息，返回运行状态
+// 连接订阅了会话时自动订阅 prompt 的目标会话
+func (ws *wsConn) prompt(c context.Context, cmd models.WSCommand) (interface{}, *apiError) {
+	if err := ws.checkWritable(); err != nil {
+		return nil, err
+	}
+	if ws.limiter != nil {
+		if ok, wait := ws.limiter.Take(); !ok {
+			slog.Warn("Rate limit exceeded", "path", "/ws", "command", cmd.Type, "remote_addr", ws.remoteAddr)
+			msg := fmt.Sprintf("Too many requests, please try again in %d seconds", middleware.RetryAfterSeconds(wait))
+			return nil, &apiError{"RATE_LIMIT_EXCEEDED", msg, consts.StatusTooManyRequests}
+		}
+	}
+	if cmd.SessionID == "" {
+		return nil, &apiError{"INVALID_REQUEST", "session_id is required", consts.StatusBadRequest}
+	}
+	if cmd.Prompt == nil {
+		return nil, &apiError{"INVALID_REQUEST", "prompt is required", consts.StatusBadRequest}
+	}
+	if cmd.Prompt.NoReply {
+		return nil, &apiError{"INVALID_REQUEST", "noReply is not supported over WebSocket", consts.StatusBadRequest}
+	}
+	if ws.app.AgentCoordinator == nil {
+		return nil, &apiError{"INTERNAL_ERROR", "Agent coordinator not initialized", consts.StatusInternalServerError}
+	}
+
+	prepared, apiErr := ws.h.preparePrompt(c, ws.app, cmd.SessionID, *cmd.Prompt)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	release, ok := ws.h.runs.acquire(ws.app.Config().WorkingDir())
+	if !ok {
+		return nil, &apiError{"TOO_MANY_RUNS", "Too many concurrent agent runs, please try again later", consts.StatusTooManyRequests}
+	}
+	// 会话忙时 Run 只会把 prompt 加入队列，无法追踪其结果
+	if isSessionBusy(ws.app, cmd.SessionID) {
+		release()
+		return nil, &apiError{"SESSION_BUSY", "Session is busy", consts.StatusConflict}
+	}
+	// 新消息会确认之前的回退，删除被回退的消息
+	if err := commitSessionRevert(c, ws.app, cmd.SessionID); err != nil {
+		release()
+		return nil, &apiError{"INTERNAL_ERROR", "Failed to apply session revert: " + err.Error(), consts.StatusInternalServerError}
+	}
+
+	ws.mu.Lock()
+	if len(ws.sessions) > 0 {
+		ws.sessions[cmd.SessionID] = struct{}{}
+	}
+	ws.mu.Unlock()
+
+	run := startPromptRun(ws.app, ws.actor, ws.app.Config().WorkingDir(), cmd.SessionID, prepared.text, prepared.attachments, prepared.opts, release)
+	return run.toResponse(), nil
+}
+
+// abort 取消会话中正在进行的 agent 运行
+func (ws *wsConn) abort(cmd models.WSCommand) *apiError {
+	if err := ws.checkWritable(); err != nil {
+		return err
+	}
+	if cmd.SessionID == "" {
+		return &apiError{"INVALID_REQUEST", "session_id is required", consts.StatusBadRequest}
+	}
+	if ws.app.AgentCoordinator != nil {
+		ws.app.AgentCoordinator.Cancel(cmd.SessionID)
+	}
+	return nil
+}
+
+// replyPermission 回复权限请求
+func (ws *wsConn) replyPermission(cmd models.WSCommand) *apiError {
+	if err := ws.checkWritable(); err != nil {
+		return err
+	}
+	if cmd.RequestID == "" || cmd.Reply == nil {
+		return &apiError{"INVALID_REQUEST", "request_id and reply are required", consts.StatusBadRequest}
+	}
+	if !replyPermission(ws.app, cmd.RequestID, *cmd.Reply, ws.actor) {
+		return &apiError{"PERMISSION_NOT_FOUND", "Permission request not found or already answered", consts.StatusNotFound}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/crush/api/middleware"
+	"github.com/charmbracelet/crush/api/models"
+	internalapp "github.com/charmbracelet/crush/internal/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWSPromptChargesRateLimiter(t *testing.T) {
+	ws := &wsConn{limiter: middleware.NewRateLimiter(0, 2)}
+	cmd := models.WSCommand{Type: "prompt"}
+
+	// 无效的命令同样计数，与 HTTP 请求一致
+	for range 2 {
+		_, apiErr := ws.prompt(t.Context(), cmd)
+		require.NotNil(t, apiErr)
+		require.Equal(t, "INVALID_REQUEST", apiErr.code)
+	}
+
+	_, apiErr := ws.prompt(t.Context(), cmd)
+	require.NotNil(t, apiErr)
+	require.Equal(t, "RATE_LIMIT_EXCEEDED", apiErr.code)
+	require.Equal(t, consts.StatusTooManyRequests, apiErr.status)
+
+	// 只读连接在计数前被拒绝
+	readOnly := &wsConn{readOnly: true, limiter: middleware.NewRateLimiter(0, 1)}
+	for range 2 {
+		_, apiErr = readOnly.prompt(t.Context(), cmd)
+		require.Equal(t, "FORBIDDEN", apiErr.code)
+	}
+}
+
+func TestPreparePromptSessionNotFound(t *testing.T) {
+	r := newTestReverter(t)
+	appInstance := &internalapp.App{Sessions: r.sessions}
+	sess, err := r.sessions.Create(t.Context(), "test")
+	require.NoError(t, err)
+
+	h := &Handlers{}
+	_, apiErr := h.preparePrompt(t.Context(), appInstance, "missing", models.PromptRequest{
+		Parts: []models.PartInput{models.TextPartInput{Text: "hello"}},
+	})
+	require.NotNil(t, apiErr)
+	require.Equal(t, "SESSION_NOT_FOUND", apiErr.code)
+	require.Equal(t, consts.StatusNotFound, apiErr.status)
+
+	_, apiErr = h.preparePrompt(t.Context(), appInstance, sess.ID, models.PromptRequest{})
+	require.NotNil(t, apiErr)
+	require.Equal(t, "INVALID_REQUEST", apiErr.code)
+}
@@ -0,0 +1,262 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// 认证相关常量
+const (
+	principalKey = "auth.principal" // RequestContext 中保存调用方身份的 key
+	apiKeyPrefix = "zk_"            // 生成的 API Key 前缀
+	apiKeyBytes  = 32               // API Key 随机字节数
+)
+
+// PrincipalKind 调用方身份类型
+type PrincipalKind string
+
+const (
+	PrincipalAnonymous PrincipalKind = "anonymous" // 未启用认证
+	PrincipalToken     PrincipalKind = "token"     // 静态 Bearer Token
+	PrincipalAPIKey    PrincipalKind = "api_key"   // 哈希存储的 API Key
+)
+
+// Principal 表示通过认证的调用方
+type Principal struct {
+	Kind     PrincipalKind
+	Name     string
+	Projects []string // 允许访问的项目目录，为空表示全部
+	ReadOnly bool
+}
+
+// AllowsProject 检查调用方是否可以访问指定项目目录
+// 比较前解析符号链接，指向授权目录之外的符号链接不算在授权范围内
+func (p *Principal) AllowsProject(dir string) bool {
+	if p == nil || len(p.Projects) == 0 {
+		return true
+	}
+	dir = realPath(dir)
+	for _, project := range p.Projects {
+		project = realPath(project)
+		if dir == project || strings.HasPrefix(dir, project+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// realPath 返回路径的绝对形式并解析符号链接
+// 路径不存在时解析最近的已存在的上级目录，再拼接剩余部分
+func realPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	path = filepath.Clean(path)
+	var rest []string
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if real, err := filepath.EvalSymlinks(dir); err == nil {
+			slices.Reverse(rest)
+			return filepath.Join(append([]string{real}, rest...)...)
+		}
+		if dir == filepath.Dir(dir) {
+			return path
+		}
+		rest = append(rest, filepath.Base(dir))
+	}
+}
+
+// Scoped 表示调用方是否被限制在部分项目中
+func (p *Principal) Scoped() bool {
+	return p != nil && len(p.Projects) > 0
+}
+
+// ID 返回用于日志和限流的调用方标识
+func (p *Principal) ID() string {
+	if p == nil || p.Kind == PrincipalAnonymous {
+		return ""
+	}
+	return string(p.Kind) + ":" + p.Name
+}
+
+// PrincipalFrom 从请求上下文中获取调用方身份
+func PrincipalFrom(ctx *app.RequestContext) (*Principal, bool) {
+	v, ok := ctx.Get(principalKey)
+	if !ok {
+		return nil, false
+	}
+	p, ok := v.(*Principal)
+	return p, ok
+}
+
+// AuthConfig 认证中间件配置
+type AuthConfig struct {
+	// Tokens 静态 Bearer Token（已解析环境变量），拥有全部权限
+	Tokens []string
+	// APIKeys 哈希存储的 API Key
+	APIKeys []config.ServerAPIKey
+	// PublicPaths 无需认证即可访问的路径
+	PublicPaths []string
+}
+
+// Enabled 表示是否配置了任何凭据
+func (a AuthConfig) Enabled() bool {
+	return len(a.Tokens) > 0 || len(a.APIKeys) > 0
+}
+
+// AuthMiddleware 校验 Bearer Token 或 API Key，并检查项目范围和读写权限
+// 未配置任何凭据时所有请求以匿名身份放行
+func AuthMiddleware(cfg AuthConfig) app.HandlerFunc {
+	tokenHashes := make([][]byte, 0, len(cfg.Tokens))
+	for _, token := range cfg.Tokens {
+		if token == "" {
+			continue
+		}
+		sum := sha256.Sum256([]byte(token))
+		tokenHashes = append(tokenHashes, sum[:])
+	}
+	enabled := len(tokenHashes) > 0 || len(cfg.APIKeys) > 0
+
+	return func(c context.Context, ctx *app.RequestContext) {
+		if !enabled {
+			ctx.Set(principalKey, &Principal{Kind: PrincipalAnonymous})
+			ctx.Next(c)
+			return
+		}
+
+		path := string(ctx.Path())
+		if slices.Contains(cfg.PublicPaths, path) {
+			ctx.Next(c)
+			return
+		}
+
+		credential := extractCredential(ctx)
+		if credential == "" {
+			abortUnauthorized(ctx, "Missing API credentials")
+			return
+		}
+
+		principal := authenticate(credential, tokenHashes, cfg.APIKeys)
+		if principal == nil {
+			slog.Warn("Rejected API credentials",
+				"path", path,
+				"remote_addr", ctx.RemoteAddr().String(),
+			)
+			abortUnauthorized(ctx, "Invalid API credentials")
+			return
+		}
+
+		if principal.ReadOnly && !isReadMethod(string(ctx.Method())) {
+			abortForbidden(ctx, "API key is read-only")
+			return
+		}
+
+		if principal.Scoped() {
+			directory := string(ctx.Query("directory"))
+			switch {
+			case directory != "" && !principal.AllowsProject(directory):
+				abortForbidden(ctx, "API key is not allowed to access this project")
+				return
+			case directory == "" && !isReadMethod(string(ctx.Method())):
+				// 不带 directory 的写操作作用于全局（如注册项目、释放全部实例）
+				abortForbidden(ctx, "API key is restricted to specific projects")
+				return
+			}
+		}
+
+		ctx.Set(principalKey, principal)
+		ctx.Next(c)
+	}
+}
+
+// authenticate 根据凭据查找匹配的调用方
+func authenticate(credential string, tokenHashes [][]byte, keys []config.ServerAPIKey) *Principal {
+	sum := sha256.Sum256([]byte(credential))
+
+	for _, hash := range tokenHashes {
+		if subtle.ConstantTimeCompare(sum[:], hash) == 1 {
+			return &Principal{Kind: PrincipalToken, Name: "token"}
+		}
+	}
+
+	hexSum := []byte(hex.EncodeToString(sum[:]))
+	for _, key := range keys {
+		if subtle.ConstantTimeCompare(hexSum, []byte(strings.ToLower(key.Hash))) == 1 {
+			return &Principal{
+				Kind:     PrincipalAPIKey,
+				Name:     key.Name,
+				Projects: key.Projects,
+				ReadOnly: key.ReadOnly,
+			}
+		}
+	}
+	return nil
+}
+
+// extractCredential 从请求中提取凭据
+// 支持 Authorization: Bearer、X-API-Key 头；/event 和 /ws 额外支持 token 查询参数（EventSource 和 WebSocket 无法设置请求头）
+func extractCredential(ctx *app.RequestContext) string {
+	if auth := string(ctx.GetHeader("Authorization")); auth != "" {
+		if token, ok := strings.CutPrefix(auth, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+	if key := string(ctx.GetHeader("X-API-Key")); key != "" {
+		return strings.TrimSpace(key)
+	}
+	if path := string(ctx.Path()); path == "/event" || path == "/ws" {
+		return string(ctx.Query("token"))
+	}
+	return ""
+}
+
+func isReadMethod(method string) bool {
+	switch method {
+	case consts.MethodGet, consts.MethodHead, consts.MethodOptions:
+		return true
+	}
+	return false
+}
+
+func abortUnauthorized(ctx *app.RequestContext, message string) {
+	ctx.Response.Header.Set("WWW-Authenticate", `Bearer realm="zorkagent"`)
+	abortWithError(ctx, consts.StatusUnauthorized, "UNAUTHORIZED", message)
+}
+
+func abortForbidden(ctx *app.RequestContext, message string) {
+	abortWithError(ctx, consts.StatusForbidden, "FORBIDDEN", message)
+}
+
+func abortWithError(ctx *app.RequestContext, statusCode int, code, message string) {
+	ctx.SetStatusCode(statusCode)
+	ctx.SetContentType("application/json; charset=utf-8")
+	ctx.Response.SetBody([]byte(`{"error":{"code":"` + code + `","message":"` + message + `"}}`))
+	ctx.Abort()
+}
+
+// GenerateAPIKey 生成新的随机 API Key，返回明文和用于存储的哈希
+func GenerateAPIKey() (key, hash string, err error) {
+	buf := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	key = apiKeyPrefix + base64.RawURLEncoding.EncodeToString(buf)
+	return key, HashAPIKey(key), nil
+}
+
+// HashAPIKey 计算 API Key 的 SHA-256 哈希（十六进制）
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/cloudwego/hertz/pkg/app"
+	hertzconfig "github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/stretchr/testify/require"
+)
+
+func newAuthEngine(cfg AuthConfig) *route.Engine {
+	engine := route.NewEngine(hertzconfig.NewOptions(nil))
+	engine.Use(AuthMiddleware(cfg))
+	return engine
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	t.Parallel()
+
+	engine := newAuthEngine(AuthConfig{
+		Tokens: []string{"admin-token"},
+		APIKeys: []config.ServerAPIKey{
+			{Name: "ci", Hash: HashAPIKey("ci-key")},
+			{Name: "viewer", Hash: HashAPIKey("viewer-key"), ReadOnly: true},
+			{Name: "app", Hash: HashAPIKey("app-key"), Projects: []string{"/srv/app"}},
+		},
+		PublicPaths: []string{"/health"},
+	})
+	handler := func(c context.Context, ctx *app.RequestContext) {
+		var id string
+		if p, ok := PrincipalFrom(ctx); ok {
+			id = p.ID()
+		}
+		ctx.String(consts.StatusOK, id)
+	}
+	for _, path := range []string{"/health", "/session", "/event"} {
+		engine.GET(path, handler)
+		engine.POST(path, handler)
+		engine.DELETE(path, handler)
+	}
+
+	for _, tt := range []struct {
+		name      string
+		method    string
+		url       string
+		header    ut.Header
+		status    int
+		principal string
+	}{
+		{"missing credentials", "GET", "/session", ut.Header{}, consts.StatusUnauthorized, ""},
+		{"invalid token", "GET", "/session", ut.Header{Key: "Authorization", Value: "Bearer wrong"}, consts.StatusUnauthorized, ""},
+		{"token", "POST", "/session", ut.Header{Key: "Authorization", Value: "Bearer admin-token"}, consts.StatusOK, "token:token"},
+		{"api key header", "POST", "/session", ut.Header{Key: "X-API-Key", Value: "ci-key"}, consts.StatusOK, "api_key:ci"},
+		{"token query on event stream", "GET", "/event?token=ci-key", ut.Header{}, consts.StatusOK, "api_key:ci"},
+		{"token query elsewhere", "GET", "/session?token=ci-key", ut.Header{}, consts.StatusUnauthorized, ""},
+
+		{"read-only key read", "GET", "/session", ut.Header{Key: "X-API-Key", Value: "viewer-key"}, consts.StatusOK, "api_key:viewer"},
+		{"read-only key write", "POST", "/session", ut.Header{Key: "X-API-Key", Value: "viewer-key"}, consts.StatusForbidden, ""},
+		{"read-only key delete", "DELETE", "/session", ut.Header{Key: "X-API-Key", Value: "viewer-key"}, consts.StatusForbidden, ""},
+
+		{"scoped key own project", "POST", "/session?directory=/srv/app", ut.Header{Key: "X-API-Key", Value: "app-key"}, consts.StatusOK, "api_key:app"},
+		{"scoped key subdirectory", "GET", "/session?directory=/srv/app/web", ut.Header{Key: "X-API-Key", Value: "app-key"}, consts.StatusOK, "api_key:app"},
+		{"scoped key other project", "GET", "/session?directory=/srv/other", ut.Header{Key: "X-API-Key", Value: "app-key"}, consts.StatusForbidden, ""},
+		{"scoped key sibling prefix", "GET", "/session?directory=/srv/app-old", ut.Header{Key: "X-API-Key", Value: "app-key"}, consts.StatusForbidden, ""},
+		{"scoped key escaping project", "GET", "/session?directory=/srv/app/../other", ut.Header{Key: "X-API-Key", Value: "app-key"}, consts.StatusForbidden, ""},
+		{"scoped key read without directory", "GET", "/session", ut.Header{Key: "X-API-Key", Value: "app-key"}, consts.StatusOK, "api_key:app"},
+		{"scoped key write without directory", "POST", "/session", ut.Header{Key: "X-API-Key", Value: "app-key"}, consts.StatusForbidden, ""},
+
+		{"public path without credentials", "GET", "/health", ut.Header{}, consts.StatusOK, ""},
+		{"public path with invalid credentials", "POST", "/health", ut.Header{Key: "Authorization", Value: "Bearer wrong"}, consts.StatusOK, ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var headers []ut.Header
+			if tt.header.Key != "" {
+				headers = append(headers, tt.header)
+			}
+			resp := ut.PerformRequest(engine, tt.method, tt.url, nil, headers...).Result()
+			require.Equal(t, tt.status, resp.StatusCode(), string(resp.Body()))
+			if tt.status == consts.StatusOK {
+				require.Equal(t, tt.principal, string(resp.Body()))
+			}
+			if tt.status == consts.StatusUnauthorized {
+				require.NotEmpty(t, resp.Header.Peek("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestAuthMiddlewareDisabled(t *testing.T) {
+	t.Parallel()
+
+	engine := newAuthEngine(AuthConfig{PublicPaths: []string{"/health"}})
+	engine.DELETE("/session", func(c context.Context, ctx *app.RequestContext) {
+		p, ok := PrincipalFrom(ctx)
+		require.True(t, ok)
+		require.Equal(t, PrincipalAnonymous, p.Kind)
+		require.False(t, p.Scoped())
+		ctx.SetStatusCode(consts.StatusNoContent)
+	})
+
+	resp := ut.PerformRequest(engine, "DELETE", "/session", nil).Result()
+	require.Equal(t, consts.StatusNoContent, resp.StatusCode())
+}
+
+func TestPrincipalAllowsProject(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	allowed := filepath.Join(root, "allowed")
+	other := filepath.Join(root, "other")
+	require.NoError(t, os.MkdirAll(filepath.Join(allowed, "sub"), 0o755))
+	require.NoError(t, os.MkdirAll(other, 0o755))
+	require.NoError(t, os.Symlink(other, filepath.Join(allowed, "escape")))
+	require.NoError(t, os.Symlink(filepath.Join(allowed, "sub"), filepath.Join(root, "alias")))
+
+	p := &Principal{Kind: PrincipalAPIKey, Name: "app", Projects: []string{allowed}}
+	for _, tt := range []struct {
+		dir  string
+		want bool
+	}{
+		{allowed, true},
+		{allowed + "/", true},
+		{filepath.Join(allowed, "sub"), true},
+		{filepath.Join(allowed, "missing", "dir"), true},
+		{filepath.Join(root, "alias"), true},
+		{other, false},
+		{filepath.Join(allowed, "..", "other"), false},
+		{filepath.Join(allowed, "escape"), false},
+		{filepath.Join(allowed, "escape", "missing"), false},
+	} {
+		require.Equal(t, tt.want, p.AllowsProject(tt.dir), tt.dir)
+	}
+
+	// A project configured through a symlink is compared by its target.
+	link := filepath.Join(root, "link")
+	require.NoError(t, os.Symlink(allowed, link))
+	p.Projects = []string{link}
+	require.True(t, p.AllowsProject(filepath.Join(allowed, "sub")))
+	require.False(t, p.AllowsProject(other))
+
+	require.True(t, (*Principal)(nil).AllowsProject(other))
+}
@@ -2,12 +2,18 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math"
+	"net"
 	"runtime/debug"
+	"slices"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/charmbracelet/crush/internal/metrics"
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
 )
@@ -19,7 +25,7 @@ const (
 	DefaultRateBurst      = 200              // 默认突发请求数限制
 )
 
-// LoggingMiddleware 记录 HTTP 请求日志
+// LoggingMiddleware 记录 HTTP 请求日志和请求指标
 func LoggingMiddleware() app.HandlerFunc {
 	return func(c context.Context, ctx *app.RequestContext) {
 		start := time.Now()
@@ -35,12 +41,24 @@ func LoggingMiddleware() app.HandlerFunc {
 		// 记录请求完成
 		duration := time.Since(start)
 		statusCode := ctx.Response.StatusCode()
+		var principal string
+		if p, ok := PrincipalFrom(ctx); ok {
+			principal = p.ID()
+		}
+		route := ctx.FullPath()
+		if route == "" {
+			// 未匹配路由时不使用原始路径，避免指标标签无限增长
+			route = "unmatched"
+		}
+		metrics.ObserveHTTPRequest(method, route, statusCode, duration)
+
 		slog.Info("HTTP request",
 			"method", method,
 			"path", path,
 			"status", statusCode,
 			"duration", duration,
 			"remote_addr", remoteAddr,
+			"principal", principal,
 		)
 	}
 }
@@ -49,8 +67,8 @@ func LoggingMiddleware() app.HandlerFunc {
 func CORSMiddleware() app.HandlerFunc {
 	return func(c context.Context, ctx *app.RequestContext) {
 		ctx.Response.Header.Set("Access-Control-Allow-Origin", "*")
-		ctx.Response.Header.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		ctx.Response.Header.Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		ctx.Response.Header.Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		ctx.Response.Header.Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, Last-Event-ID")
 
 		if string(ctx.Method()) == "OPTIONS" {
 			ctx.SetStatusCode(consts.StatusNoContent)
@@ -100,12 +118,14 @@ func RecoveryMiddleware() app.HandlerFunc {
 }
 
 // TimeoutMiddleware 添加请求超时控制
-// 注意：SSE 和 WebSocket 等长连接请求应该跳过此中间件
-func TimeoutMiddleware(timeout time.Duration) app.HandlerFunc {
+// handler 使用带超时的 context 同步执行，超时后由 handler 感知 context 取消并尽快返回，
+// 再将响应替换为 408。不在其他 goroutine 中执行 handler，避免请求结束后继续访问已回收的 RequestContext
+// skipRoutes 为跳过超时控制的路由（如 /session/:sessionID/prompt），SSE 等长连接始终跳过
+func TimeoutMiddleware(timeout time.Duration, skipRoutes ...string) app.HandlerFunc {
 	return func(c context.Context, ctx *app.RequestContext) {
 		// SSE 和 event 路径跳过超时控制
 		path := string(ctx.Path())
-		if path == "/event" || path == "/events" {
+		if timeout <= 0 || path == "/event" || path == "/events" || slices.Contains(skipRoutes, ctx.FullPath()) {
 			ctx.Next(c)
 			return
 		}
@@ -114,44 +134,31 @@ func TimeoutMiddleware(timeout time.Duration) app.HandlerFunc {
 		timeoutCtx, cancel := context.WithTimeout(c, timeout)
 		defer cancel()
 
-		// 使用 channel 来检测是否完成
-		done := make(chan struct{})
-
-		go func() {
-			ctx.Next(timeoutCtx)
-			close(done)
-		}()
+		ctx.Next(timeoutCtx)
 
-		select {
-		case <-done:
-			// 正常完成
-			return
-		case <-timeoutCtx.Done():
-			// 超时
-			if timeoutCtx.Err() == context.DeadlineExceeded {
-				slog.Warn("Request timeout",
-					"path", path,
-					"method", string(ctx.Method()),
-					"timeout", timeout,
-				)
-				ctx.SetStatusCode(consts.StatusRequestTimeout)
-				ctx.SetContentType("application/json; charset=utf-8")
-				ctx.Response.SetBody([]byte(
-					`{"error":{"code":"REQUEST_TIMEOUT","message":"Request timeout"}}`,
-				))
-				ctx.Abort()
-			}
+		if errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) {
+			slog.Warn("Request timeout",
+				"path", path,
+				"method", string(ctx.Method()),
+				"timeout", timeout,
+			)
+			ctx.SetStatusCode(consts.StatusRequestTimeout)
+			ctx.SetContentType("application/json; charset=utf-8")
+			ctx.Response.SetBody([]byte(
+				`{"error":{"code":"REQUEST_TIMEOUT","message":"Request timeout"}}`,
+			))
+			ctx.Abort()
 		}
 	}
 }
 
-// RateLimiter 简易令牌桶限流器
+// RateLimiter 令牌桶限流器
 type RateLimiter struct {
 	mu        sync.Mutex
-	tokens    int
-	maxTokens int
-	refillMs  int64 // 每毫秒补充的令牌数（乘以1000）
-	lastTime  int64 // 上次补充时间（毫秒）
+	tokens    float64
+	maxTokens float64
+	rate      float64 // 每秒补充的令牌数
+	lastTime  time.Time
 }
 
 // NewRateLimiter 创建限流器
@@ -159,58 +166,179 @@ type RateLimiter struct {
 // burst: 最大突发请求数
 func NewRateLimiter(rate, burst int) *RateLimiter {
 	return &RateLimiter{
-		tokens:    burst,
-		maxTokens: burst,
-		refillMs:  int64(rate), // rate tokens per second = rate/1000 per ms
-		lastTime:  time.Now().UnixMilli(),
+		tokens:    float64(burst),
+		maxTokens: float64(burst),
+		rate:      float64(rate),
+		lastTime:  time.Now(),
 	}
 }
 
 // Allow 检查是否允许请求
 func (r *RateLimiter) Allow() bool {
+	ok, _ := r.Take()
+	return ok
+}
+
+// Take 尝试取出一个令牌，失败时返回需要等待多久才会有可用令牌
+func (r *RateLimiter) Take() (bool, time.Duration) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	now := time.Now().UnixMilli()
-	elapsed := now - r.lastTime
+	now := time.Now()
+	elapsed := now.Sub(r.lastTime).Seconds()
 	r.lastTime = now
 
-	// 补充令牌（每秒 refillMs 个）
-	tokensToAdd := int(elapsed * r.refillMs / 1000)
-	r.tokens += tokensToAdd
-	if r.tokens > r.maxTokens {
-		r.tokens = r.maxTokens
-	}
+	// 按经过的时间补充令牌
+	r.tokens = min(r.maxTokens, r.tokens+elapsed*r.rate)
 
 	// 检查是否有可用令牌
-	if r.tokens > 0 {
+	if r.tokens >= 1 {
 		r.tokens--
-		return true
+		return true, 0
+	}
+
+	if r.rate <= 0 {
+		return false, time.Second
 	}
+	wait := time.Duration((1 - r.tokens) / r.rate * float64(time.Second))
+	return false, wait
+}
+
+// RateLimitConfig 限流中间件配置
+type RateLimitConfig struct {
+	// Rate 每个客户端每秒允许的请求数，0 使用 DefaultRateLimit，负数表示不限流
+	Rate int
+	// Burst 每个客户端的最大突发请求数，0 使用 DefaultRateBurst
+	Burst int
+	// SkipPaths 不限流的路径（如健康检查）
+	SkipPaths []string
+}
+
+// rateLimiterIdleTTL 客户端限流器闲置多久后回收
+const rateLimiterIdleTTL = 10 * time.Minute
 
-	return false
+// rateLimiterKey RequestContext 中保存客户端限流器的 key
+const rateLimiterKey = "ratelimit.limiter"
+
+// clientLimiter 单个客户端的限流器
+type clientLimiter struct {
+	limiter  *RateLimiter
+	lastSeen time.Time
+}
+
+// clientLimiters 按客户端区分的限流器
+type clientLimiters struct {
+	mu        sync.Mutex
+	rate      int
+	burst     int
+	limiters  map[string]*clientLimiter
+	lastSweep time.Time
 }
 
-// RateLimitMiddleware 限流中间件
-// 使用简易的令牌桶算法
-func RateLimitMiddleware(rate, burst int) app.HandlerFunc {
-	limiter := NewRateLimiter(rate, burst)
+func newClientLimiters(rate, burst int) *clientLimiters {
+	return &clientLimiters{
+		rate:      rate,
+		burst:     burst,
+		limiters:  make(map[string]*clientLimiter),
+		lastSweep: time.Now(),
+	}
+}
 
+// get 返回客户端的限流器，不存在时创建
+func (l *clientLimiters) get(key string, now time.Time) *RateLimiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// 定期回收闲置的限流器，避免按地址区分的客户端无限增长
+	if now.Sub(l.lastSweep) > rateLimiterIdleTTL {
+		for k, c := range l.limiters {
+			if now.Sub(c.lastSeen) > rateLimiterIdleTTL {
+				delete(l.limiters, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	c, ok := l.limiters[key]
+	if !ok {
+		c = &clientLimiter{limiter: NewRateLimiter(l.rate, l.burst)}
+		l.limiters[key] = c
+	}
+	c.lastSeen = now
+	return c.limiter
+}
+
+// RateLimitMiddleware 按客户端限流的中间件
+// 使用令牌桶算法，已认证的请求按 API Key / Token 区分，匿名请求按远端地址区分，
+// 因此需要放在 AuthMiddleware 之后。超出限制时返回 429 并设置 Retry-After。
+// 客户端的限流器保存在请求上下文中，长连接（如 WebSocket）可通过 RateLimiterFrom 为其中的命令计数
+func RateLimitMiddleware(cfg RateLimitConfig) app.HandlerFunc {
+	if cfg.Rate == 0 {
+		cfg.Rate = DefaultRateLimit
+	}
+	if cfg.Burst <= 0 {
+		cfg.Burst = DefaultRateBurst
+	}
+	if cfg.Rate < 0 {
+		return func(c context.Context, ctx *app.RequestContext) {
+			ctx.Next(c)
+		}
+	}
+
+	limiters := newClientLimiters(cfg.Rate, cfg.Burst)
 	return func(c context.Context, ctx *app.RequestContext) {
-		if !limiter.Allow() {
+		if slices.Contains(cfg.SkipPaths, string(ctx.Path())) {
+			ctx.Next(c)
+			return
+		}
+
+		key := rateLimitKey(ctx)
+		limiter := limiters.get(key, time.Now())
+		if ok, wait := limiter.Take(); !ok {
 			slog.Warn("Rate limit exceeded",
 				"path", string(ctx.Path()),
-				"remote_addr", ctx.RemoteAddr().String(),
+				"client", key,
 			)
-			ctx.SetStatusCode(consts.StatusTooManyRequests)
-			ctx.SetContentType("application/json; charset=utf-8")
-			ctx.Response.SetBody([]byte(
-				`{"error":{"code":"RATE_LIMIT_EXCEEDED","message":"Too many requests, please try again later"}}`,
-			))
-			ctx.Abort()
+			abortTooManyRequests(ctx, wait, "RATE_LIMIT_EXCEEDED", "Too many requests, please try again later")
 			return
 		}
+		ctx.Set(rateLimiterKey, limiter)
 
 		ctx.Next(c)
 	}
 }
+
+// RateLimiterFrom 从请求上下文中获取客户端的限流器，未启用限流时返回 false
+func RateLimiterFrom(ctx *app.RequestContext) (*RateLimiter, bool) {
+	v, ok := ctx.Get(rateLimiterKey)
+	if !ok {
+		return nil, false
+	}
+	l, ok := v.(*RateLimiter)
+	return l, ok
+}
+
+// rateLimitKey 返回限流使用的客户端标识
+func rateLimitKey(ctx *app.RequestContext) string {
+	if p, ok := PrincipalFrom(ctx); ok {
+		if id := p.ID(); id != "" {
+			return id
+		}
+	}
+	addr := ctx.RemoteAddr().String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return "addr:" + addr
+}
+
+// abortTooManyRequests 返回 429 响应
+func abortTooManyRequests(ctx *app.RequestContext, retryAfter time.Duration, code, message string) {
+	ctx.Response.Header.Set("Retry-After", strconv.Itoa(RetryAfterSeconds(retryAfter)))
+	abortWithError(ctx, consts.StatusTooManyRequests, code, message)
+}
+
+// RetryAfterSeconds 返回 Retry-After 使用的秒数，向上取整且至少为 1
+func RetryAfterSeconds(retryAfter time.Duration) int {
+	return max(1, int(math.Ceil(retryAfter.Seconds())))
+}
@@ -0,0 +1,183 @@
+package middleware
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEngine(middlewares ...app.HandlerFunc) *route.Engine {
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.Use(middlewares...)
+	return engine
+}
+
+func TestCORSPreflight(t *testing.T) {
+	t.Parallel()
+
+	engine := newTestEngine(CORSMiddleware())
+	engine.PATCH("/config", func(c context.Context, ctx *app.RequestContext) {
+		ctx.SetStatusCode(consts.StatusOK)
+	})
+
+	for _, method := range []string{"GET", "POST", "PUT", "PATCH", "DELETE"} {
+		w := ut.PerformRequest(engine, consts.MethodOptions, "/config", nil,
+			ut.Header{Key: "Origin", Value: "http://localhost:3000"},
+			ut.Header{Key: "Access-Control-Request-Method", Value: method},
+		)
+		resp := w.Result()
+		require.Equal(t, consts.StatusNoContent, resp.StatusCode(), method)
+		require.Equal(t, "*", string(resp.Header.Peek("Access-Control-Allow-Origin")))
+		allowed := strings.Split(string(resp.Header.Peek("Access-Control-Allow-Methods")), ", ")
+		require.Contains(t, allowed, method)
+	}
+
+	w := ut.PerformRequest(engine, consts.MethodPatch, "/config", nil)
+	require.Equal(t, consts.StatusOK, w.Result().StatusCode())
+	require.NotEmpty(t, w.Result().Header.Peek("Access-Control-Allow-Methods"))
+}
+
+func TestRateLimiterRefill(t *testing.T) {
+	t.Parallel()
+
+	limiter := NewRateLimiter(2, 2)
+	require.True(t, limiter.Allow())
+	require.True(t, limiter.Allow())
+
+	ok, wait := limiter.Take()
+	require.False(t, ok)
+	require.Greater(t, wait, time.Duration(0))
+	require.LessOrEqual(t, wait, 500*time.Millisecond)
+
+	// 一秒补充 rate 个令牌
+	limiter.lastTime = limiter.lastTime.Add(-time.Second)
+	require.True(t, limiter.Allow())
+	require.True(t, limiter.Allow())
+	require.False(t, limiter.Allow())
+
+	// 补充的令牌不超过 burst
+	limiter.lastTime = limiter.lastTime.Add(-time.Minute)
+	require.True(t, limiter.Allow())
+	require.True(t, limiter.Allow())
+	require.False(t, limiter.Allow())
+
+	// rate 为 0 时不补充令牌
+	empty := NewRateLimiter(0, 1)
+	require.True(t, empty.Allow())
+	ok, wait = empty.Take()
+	require.False(t, ok)
+	require.Equal(t, time.Second, wait)
+}
+
+func TestClientLimitersSweep(t *testing.T) {
+	t.Parallel()
+
+	limiters := newClientLimiters(1, 1)
+	now := limiters.lastSweep
+	idle := limiters.get("idle", now)
+	active := limiters.get("active", now)
+	require.Same(t, idle, limiters.get("idle", now))
+
+	// 闲置超过 TTL 的限流器在下一次回收时删除，期间使用过的保留
+	limiters.get("active", now.Add(rateLimiterIdleTTL/2))
+	now = now.Add(rateLimiterIdleTTL + time.Second)
+	limiters.get("other", now)
+	require.Len(t, limiters.limiters, 2)
+	require.Same(t, active, limiters.get("active", now))
+	require.NotSame(t, idle, limiters.get("idle", now))
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	t.Parallel()
+
+	// 按请求头模拟认证结果，不同调用方使用不同的限流器
+	setPrincipal := func(c context.Context, ctx *app.RequestContext) {
+		if name := string(ctx.GetHeader("X-Client")); name != "" {
+			ctx.Set(principalKey, &Principal{Kind: PrincipalAPIKey, Name: name})
+		}
+		ctx.Next(c)
+	}
+	engine := newTestEngine(setPrincipal, RateLimitMiddleware(RateLimitConfig{Rate: 1, Burst: 2, SkipPaths: []string{"/health"}}))
+	handler := func(c context.Context, ctx *app.RequestContext) {
+		_, ok := RateLimiterFrom(ctx)
+		require.True(t, ok)
+		ctx.SetStatusCode(consts.StatusOK)
+	}
+	engine.GET("/session", handler)
+	engine.GET("/health", func(c context.Context, ctx *app.RequestContext) {
+		ctx.SetStatusCode(consts.StatusOK)
+	})
+
+	get := func(path, client string) (int, string) {
+		w := ut.PerformRequest(engine, consts.MethodGet, path, nil, ut.Header{Key: "X-Client", Value: client})
+		resp := w.Result()
+		return resp.StatusCode(), string(resp.Header.Peek("Retry-After"))
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		client     string
+		wantStatus int
+	}{
+		{"first request", "/session", "a", consts.StatusOK},
+		{"burst", "/session", "a", consts.StatusOK},
+		{"over burst", "/session", "a", consts.StatusTooManyRequests},
+		{"other client", "/session", "b", consts.StatusOK},
+		{"anonymous client", "/session", "", consts.StatusOK},
+		{"skipped path", "/health", "a", consts.StatusOK},
+	}
+	for _, tt := range tests {
+		status, retryAfter := get(tt.path, tt.client)
+		require.Equal(t, tt.wantStatus, status, tt.name)
+		if tt.wantStatus == consts.StatusTooManyRequests {
+			seconds, err := strconv.Atoi(retryAfter)
+			require.NoError(t, err, tt.name)
+			require.Equal(t, 1, seconds, tt.name)
+		} else {
+			require.Empty(t, retryAfter, tt.name)
+		}
+	}
+}
+
+func TestRateLimitMiddlewareDisabled(t *testing.T) {
+	t.Parallel()
+
+	engine := newTestEngine(RateLimitMiddleware(RateLimitConfig{Rate: -1}))
+	engine.GET("/session", func(c context.Context, ctx *app.RequestContext) {
+		_, ok := RateLimiterFrom(ctx)
+		require.False(t, ok)
+		ctx.SetStatusCode(consts.StatusOK)
+	})
+	for range DefaultRateBurst + 1 {
+		w := ut.PerformRequest(engine, consts.MethodGet, "/session", nil)
+		require.Equal(t, consts.StatusOK, w.Result().StatusCode())
+	}
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wait time.Duration
+		want int
+	}{
+		{0, 1},
+		{time.Millisecond, 1},
+		{time.Second, 1},
+		{1500 * time.Millisecond, 2},
+		{5 * time.Second, 5},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, RetryAfterSeconds(tt.wait), tt.wait.String())
+	}
+}
@@ -0,0 +1,12 @@
+package models
+
+import "github.com/charmbracelet/crush/internal/audit"
+
+// AuditEntry 审计日志条目：一次工具执行或一次权限决定
+type AuditEntry = audit.Entry
+
+// AuditResponse 审计日志，按时间倒序，Total 为符合条件的条目总数
+type AuditResponse struct {
+	Entries []AuditEntry `json:"entries"`
+	Total   int          `json:"total"`
+}
@@ -0,0 +1,38 @@
+package models
+
+// 命令来源
+const (
+	CommandSourceUser    = "user"
+	CommandSourceProject = "project"
+	CommandSourceMCP     = "mcp"
+)
+
+// Command 可以通过 /session/{id}/command 执行的命令
+type Command struct {
+	// Name 命令 ID，例如 user:review、project:deploy:staging 或 MCP 的 server:prompt
+	Name string `json:"name"`
+
+	// Source 命令来源：user、project 或 mcp
+	Source string `json:"source"`
+
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	// MCP 提供该 prompt 的 MCP 服务器，仅 mcp 命令有值
+	MCP string `json:"mcp,omitempty"`
+
+	// Template 命令的 Markdown 内容，仅 user 和 project 命令有值
+	Template string `json:"template,omitempty"`
+
+	// Arguments 命令的参数，按 raw arguments 填充的顺序排列
+	Arguments []CommandArgument `json:"arguments"`
+}
+
+// CommandArgument 命令参数
+type CommandArgument struct {
+	// Name 参数名，自定义命令中为 $NAME 占位符的 NAME
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required"`
+}
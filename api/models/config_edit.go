@@ -0,0 +1,25 @@
+package models
+
+// 可编辑的配置段
+const (
+	ConfigSectionProviders   = "providers"
+	ConfigSectionModels      = "models"
+	ConfigSectionMCP         = "mcp"
+	ConfigSectionLSP         = "lsp"
+	ConfigSectionOptions     = "options"
+	ConfigSectionPermissions = "permissions"
+)
+
+// ConfigSectionResponse 配置段的内容
+type ConfigSectionResponse struct {
+	// Section 配置段名称：providers、models、mcp、lsp、options、permissions
+	Section string `json:"section"`
+
+	// Value 当前生效的配置（全局、数据目录和项目配置文件合并后的结果），
+	// providers、mcp、lsp、models 为以名称为键的对象。
+	// 密钥已脱敏：$VAR 形式的引用原样返回，其余显示为 "****" 加末 4 位
+	Value any `json:"value" swaggertype:"object"`
+
+	// Path 修改写入的配置文件（数据目录下的 crush.json，所有项目共用）
+	Path string `json:"path"`
+}
@@ -5,8 +5,27 @@ import (
 	"strings"
 
 	internalmsg "github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/charmbracelet/crush/internal/version"
 )
 
+// SessionToOpencode converts a Zorkagent internal Session to an Opencode-compatible Session
+func SessionToOpencode(s session.Session, directory string) Session {
+	return Session{
+		ID:        s.ID,
+		Directory: directory,
+		ProjectID: directory,
+		Time: SessionTime{
+			// Internal timestamps are in seconds, Opencode uses milliseconds
+			Created: s.CreatedAt * 1000,
+			Updated: s.UpdatedAt * 1000,
+		},
+		Title:    s.Title,
+		Version:  version.Version,
+		ParentID: s.ParentSessionID,
+	}
+}
+
 // MessageToPromptResponse converts a Zorkagent internal Message to Opencode-compatible PromptResponse
 func MessageToPromptResponse(msg internalmsg.Message) PromptResponse {
 	info := AssistantMessage{
@@ -116,7 +135,8 @@ func ExtractPromptTextFromParts(parts []PartInput) string {
 	return text.String()
 }
 
-// PartsToMessage converts Opencode PartInput array to internal message parts
+// PartsToMessage converts Opencode PartInput array to internal message parts.
+// File parts are skipped; they are resolved to attachments by the handlers.
 func PartsToMessageParts(sessionID string, parts []PartInput) []internalmsg.ContentPart {
 	contentParts := make([]internalmsg.ContentPart, 0, len(parts))
 
@@ -127,18 +147,6 @@ func PartsToMessageParts(sessionID string, parts []PartInput) []internalmsg.Cont
 				Text: p.Text,
 			})
 
-		case FilePartInput:
-			data, err := base64.StdEncoding.DecodeString(p.Data)
-			if err != nil {
-				// Skip invalid base64 data
-				continue
-			}
-			contentParts = append(contentParts, internalmsg.BinaryContent{
-				Path:     p.Name,
-				MIMEType: detectMIMEType(p.Name),
-				Data:     data,
-			})
-
 		case AgentPartInput:
 			// Agent parts are handled as text with special formatting
 			text := p.Prompt
@@ -164,8 +172,8 @@ func PartsToMessageParts(sessionID string, parts []PartInput) []internalmsg.Cont
 	return contentParts
 }
 
-// detectMIMEType detects MIME type based on file extension
-func detectMIMEType(filename string) string {
+// DetectMIMEType detects MIME type based on file extension
+func DetectMIMEType(filename string) string {
 	if strings.HasSuffix(filename, ".txt") {
 		return "text/plain"
 	} else if strings.HasSuffix(filename, ".json") {
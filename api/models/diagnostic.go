@@ -0,0 +1,40 @@
+package models
+
+// Diagnostic LSP 诊断，与 agent 的 diagnostics 工具看到的一致
+type Diagnostic struct {
+	// Path 文件路径，位于项目内时为相对于项目根目录的路径
+	Path string `json:"path"`
+
+	Range Range `json:"range"`
+
+	// Severity error、warning、information 或 hint
+	Severity string `json:"severity"`
+
+	// Source 产生诊断的工具，例如 compiler、staticcheck
+	Source string `json:"source,omitempty"`
+
+	// Code 诊断代码
+	Code string `json:"code,omitempty"`
+
+	Message string `json:"message"`
+
+	// Server 报告诊断的 LSP 服务器
+	Server string `json:"server"`
+}
+
+// DiagnosticCounts 各严重程度的诊断数
+type DiagnosticCounts struct {
+	Error       int `json:"error"`
+	Warning     int `json:"warning"`
+	Information int `json:"information"`
+	Hint        int `json:"hint"`
+}
+
+// DiagnosticsResponse 诊断列表
+type DiagnosticsResponse struct {
+	// Diagnostics 按路径和位置排序
+	Diagnostics []Diagnostic `json:"diagnostics"`
+
+	// Counts 返回的诊断中各严重程度的数量
+	Counts DiagnosticCounts `json:"counts"`
+}
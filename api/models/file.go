@@ -89,7 +89,7 @@ type LSPStatus struct {
 	ID     string `json:"id"`
 	Name   string `json:"name"`
 	Root   string `json:"root"`
-	Status string `json:"status"` // "connected" or "error"
+	Status string `json:"status"` // "starting"、"connected"、"error" 或 "disabled"
 }
 
 // MCP 状态（通用格式，使用 map[string]interface{} 来支持多种状态类型）
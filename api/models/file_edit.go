@@ -0,0 +1,87 @@
+package models
+
+// 文件修改的操作类型，随 file.edited 事件和 FileEditResponse 返回
+const (
+	FileEditWrite  = "write"
+	FileEditPatch  = "patch"
+	FileEditRename = "rename"
+	FileEditDelete = "delete"
+)
+
+// FileWriteRequest 写入文件请求
+type FileWriteRequest struct {
+	// Path 文件路径（相对于项目根目录），不存在时创建，包括上级目录
+	Path string `json:"path"`
+
+	// Content 文件内容
+	Content string `json:"content"`
+
+	// Encoding 内容编码：utf8（默认）或 base64
+	Encoding string `json:"encoding,omitempty"`
+
+	// SessionID 记录文件历史版本的会话
+	SessionID string `json:"session_id"`
+}
+
+// FilePatchRequest 应用 unified diff 请求
+type FilePatchRequest struct {
+	// Path 文件路径（相对于项目根目录），不存在时视为空文件
+	Path string `json:"path"`
+
+	// Diff 单个文件的 unified diff，上下文位置有偏移时会在文件中查找
+	Diff string `json:"diff"`
+
+	// SessionID 记录文件历史版本的会话
+	SessionID string `json:"session_id"`
+}
+
+// FileRenameRequest 重命名文件请求
+type FileRenameRequest struct {
+	// From 原路径（相对于项目根目录）
+	From string `json:"from"`
+
+	// To 新路径（相对于项目根目录），已存在时返回 409
+	To string `json:"to"`
+
+	// SessionID 记录文件历史版本的会话
+	SessionID string `json:"session_id"`
+}
+
+// FileEditResponse 文件修改结果
+type FileEditResponse struct {
+	// Path 修改后的文件路径（相对于项目根目录）
+	Path string `json:"path"`
+
+	// From 重命名前的路径
+	From string `json:"from,omitempty"`
+
+	// Action 操作类型：write、patch、rename、delete
+	Action string `json:"action"`
+
+	// SessionID 记录文件历史版本的会话
+	SessionID string `json:"session_id"`
+
+	// Version 文件历史中新增的版本号，删除时为记录空内容的版本
+	Version int64 `json:"version"`
+
+	// Additions 新增的行数
+	Additions int `json:"additions"`
+
+	// Removals 删除的行数
+	Removals int `json:"removals"`
+}
+
+// FileEditedEvent file.edited 事件的 properties
+type FileEditedEvent struct {
+	// File 修改后的文件路径（相对于项目根目录）
+	File string `json:"file"`
+
+	// From 重命名前的路径
+	From string `json:"from,omitempty"`
+
+	// Action 操作类型：write、patch、rename、delete
+	Action string `json:"action"`
+
+	// SessionID 记录文件历史版本的会话
+	SessionID string `json:"sessionID"`
+}
@@ -0,0 +1,71 @@
+package models
+
+import "github.com/charmbracelet/crush/internal/shell"
+
+// 后台任务的状态
+const (
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+)
+
+// Job agent 通过 bash 工具的 run_in_background 启动的后台任务
+type Job struct {
+	// ID 任务 ID，与 agent 的 job_output、job_kill 工具使用的 shell_id 一致
+	ID string `json:"id"`
+
+	Command     string `json:"command"`
+	Description string `json:"description,omitempty"`
+
+	// WorkingDir 工作目录
+	WorkingDir string `json:"working_dir"`
+
+	// Status running、completed 或 failed（退出码不为 0，包括被终止）
+	Status string `json:"status"`
+
+	// ExitCode 退出码，仅结束后有值
+	ExitCode *int `json:"exit_code,omitempty"`
+
+	// StartedAt 启动时间（毫秒时间戳）
+	StartedAt int64 `json:"started_at"`
+
+	// CompletedAt 结束时间（毫秒时间戳，精确到秒），运行中为 0
+	CompletedAt int64 `json:"completed_at,omitempty"`
+}
+
+// JobOutput 后台任务在指定偏移量之后的输出
+type JobOutput struct {
+	Stdout string `json:"stdout"`
+	Stderr string `json:"stderr"`
+
+	// StdoutOffset 下次读取 stdout 的偏移量（字节）
+	StdoutOffset int `json:"stdout_offset"`
+
+	// StderrOffset 下次读取 stderr 的偏移量（字节）
+	StderrOffset int `json:"stderr_offset"`
+
+	// Done 任务是否已结束，结束后不会再有新的输出
+	Done bool `json:"done"`
+}
+
+// JobFromInfo 转换后台任务的信息
+func JobFromInfo(info shell.BackgroundShellInfo) Job {
+	job := Job{
+		ID:          info.ID,
+		Command:     info.Command,
+		Description: info.Description,
+		WorkingDir:  info.WorkingDir,
+		Status:      JobStatusRunning,
+		StartedAt:   info.StartedAt.UnixMilli(),
+	}
+	if info.Done {
+		exitCode := info.ExitCode
+		job.ExitCode = &exitCode
+		job.CompletedAt = info.CompletedAt.UnixMilli()
+		job.Status = JobStatusCompleted
+		if exitCode != 0 {
+			job.Status = JobStatusFailed
+		}
+	}
+	return job
+}
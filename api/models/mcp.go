@@ -0,0 +1,87 @@
+package models
+
+// MCPServerStatus MCP 服务器的状态
+type MCPServerStatus struct {
+	Name string `json:"name"`
+
+	// Status disabled、starting、connected 或 failed
+	Status string `json:"status"`
+
+	// Message 连接失败的原因
+	Message string `json:"message,omitempty"`
+
+	// Tools 提供给 agent 的工具数（不包括 disabled_tools）
+	Tools int `json:"tools"`
+
+	// Prompts prompt 数
+	Prompts int `json:"prompts"`
+
+	// ConnectedAt 连接时间（毫秒时间戳），未连接时为 0
+	ConnectedAt int64 `json:"connected_at,omitempty"`
+}
+
+// MCPTool MCP 服务器提供的工具
+type MCPTool struct {
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	// InputSchema 参数的 JSON Schema
+	InputSchema any `json:"input_schema,omitempty" swaggertype:"object"`
+
+	// OutputSchema 结构化输出的 JSON Schema
+	OutputSchema any `json:"output_schema,omitempty" swaggertype:"object"`
+
+	// Disabled 是否在配置的 disabled_tools 中，禁用的工具不提供给 agent
+	Disabled bool `json:"disabled"`
+}
+
+// MCPPromptArgument MCP prompt 的参数
+type MCPPromptArgument struct {
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required"`
+}
+
+// MCPPrompt MCP 服务器提供的 prompt，可以通过 /session/{id}/command 以 "服务器:prompt" 执行
+type MCPPrompt struct {
+	Name        string              `json:"name"`
+	Title       string              `json:"title,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Arguments   []MCPPromptArgument `json:"arguments"`
+}
+
+// MCPResource MCP 服务器提供的资源
+type MCPResource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	MIMEType    string `json:"mime_type,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+}
+
+// MCPToolCallRequest 直接调用 MCP 工具的请求体
+type MCPToolCallRequest struct {
+	// Arguments 工具参数，按工具的 input_schema 填写
+	Arguments map[string]any `json:"arguments"`
+}
+
+// MCPToolCallResponse MCP 工具的调用结果
+type MCPToolCallResponse struct {
+	// Type text、image 或 media
+	Type string `json:"type"`
+
+	// Content 文本内容
+	Content string `json:"content"`
+
+	// Data 图片或音频数据（base64），仅 image 和 media 有值
+	Data []byte `json:"data,omitempty" swaggertype:"string" format:"base64"`
+
+	// MediaType 图片或音频的 MIME 类型
+	MediaType string `json:"media_type,omitempty"`
+
+	// DurationMs 调用耗时（毫秒）
+	DurationMs int64 `json:"duration_ms"`
+}
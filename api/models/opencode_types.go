@@ -13,6 +13,13 @@ type PromptRequest struct {
 	Agent     string      `json:"agent,omitempty"`
 	NoReply   bool        `json:"noReply,omitempty"`
 	Parts     []PartInput `json:"parts"`
+
+	// Per-prompt overrides. They only apply to this prompt and leave the
+	// project configuration untouched.
+	SmallModel      *ModelSpec `json:"smallModel,omitempty"`
+	ReasoningEffort string     `json:"reasoningEffort,omitempty"`
+	Temperature     *float64   `json:"temperature,omitempty"`
+	MaxOutputTokens int64      `json:"maxOutputTokens,omitempty"`
 }
 
 // UnmarshalJSON implements custom JSON unmarshaling for PromptRequest
@@ -61,6 +68,14 @@ func unmarshalPartInput(data []byte) (PartInput, error) {
 		return part, nil
 	}
 
+	if partType, _ := checkMap["type"].(string); partType == "file" {
+		var part FilePartInput
+		if err := json.Unmarshal(data, &part); err != nil {
+			return nil, err
+		}
+		return part, nil
+	}
+
 	if _, hasName := checkMap["name"]; hasName {
 		if _, hasData := checkMap["data"]; hasData {
 			var part FilePartInput
@@ -117,10 +132,14 @@ type TextPartInput struct {
 
 func (TextPartInput) isPartInput() {}
 
-// FilePartInput represents a file attachment in the request
+// FilePartInput represents a file attachment in the request.
+// The content is either inline base64 data or a path relative to the project.
 type FilePartInput struct {
-	Name string `json:"name"`
-	Data string `json:"data"` // base64 encoded
+	Type string `json:"type,omitempty"` // "file"
+	Name string `json:"name,omitempty"`
+	Data string `json:"data,omitempty"` // base64 encoded
+	Path string `json:"path,omitempty"` // relative to the project directory
+	Mime string `json:"mime,omitempty"` // used when the type can't be sniffed
 }
 
 func (FilePartInput) isPartInput() {}
@@ -345,3 +364,64 @@ type CompactionPart struct {
 
 func (CompactionPart) isPart()               {}
 func (CompactionPart) GetPartType() string   { return "compaction" }
+
+// Session is the Opencode-compatible session representation
+type Session struct {
+	ID        string         `json:"id"`
+	Directory string         `json:"directory"`
+	ProjectID string         `json:"projectID"`
+	Time      SessionTime    `json:"time"`
+	Title     string         `json:"title"`
+	Version   string         `json:"version"`
+	ParentID  string         `json:"parentID,omitempty"`
+	Revert    *SessionRevert `json:"revert,omitempty"`
+}
+
+// SessionTime contains session timestamps in milliseconds
+type SessionTime struct {
+	Created int64 `json:"created"`
+	Updated int64 `json:"updated"`
+}
+
+// SessionRevert describes a pending revert of a session
+type SessionRevert struct {
+	MessageID string `json:"messageID"`
+	PartID    string `json:"partID,omitempty"`
+	Snapshot  string `json:"snapshot,omitempty"`
+	Diff      string `json:"diff,omitempty"`
+}
+
+// SessionRevertRequest represents the request body for the /revert endpoint
+type SessionRevertRequest struct {
+	MessageID string `json:"messageID"`
+	PartID    string `json:"partID,omitempty"`
+}
+
+// SessionSummarizeRequest represents the request body for the /summarize endpoint
+type SessionSummarizeRequest struct {
+	ProviderID string `json:"providerID"`
+	ModelID    string `json:"modelID"`
+}
+
+// SessionInitRequest represents the request body for the /init endpoint
+type SessionInitRequest struct {
+	MessageID  string `json:"messageID"`
+	ProviderID string `json:"providerID"`
+	ModelID    string `json:"modelID"`
+}
+
+// SessionShellRequest represents the request body for the /shell endpoint
+type SessionShellRequest struct {
+	Agent   string `json:"agent"`
+	Command string `json:"command"`
+}
+
+// SessionCommandRequest represents the request body for the /command endpoint
+type SessionCommandRequest struct {
+	Command   string            `json:"command"`
+	Arguments string            `json:"arguments"`
+	Args      map[string]string `json:"args,omitempty"` // named arguments, take precedence over arguments
+	Agent     string            `json:"agent,omitempty"`
+	MessageID string            `json:"messageID,omitempty"`
+	Model     string            `json:"model,omitempty"` // "providerID/modelID"
+}
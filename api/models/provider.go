@@ -0,0 +1,92 @@
+package models
+
+// ProvidersResponse provider 和模型目录，与 opencode SDK 的 AppService.Providers 一致
+type ProvidersResponse struct {
+	// Providers catwalk 已知的 provider 和配置文件中添加的 provider
+	Providers []Provider `json:"providers"`
+
+	// Default 每个 provider 的默认模型 ID
+	Default map[string]string `json:"default"`
+}
+
+// Provider provider 及其模型
+type Provider struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+
+	// Type API 格式，例如 openai、openai-compat、anthropic
+	Type string `json:"type"`
+
+	// Env 读取 API Key 的环境变量
+	Env []string `json:"env"`
+
+	// API 接口地址，可能为 $VAR 形式的引用
+	API string `json:"api,omitempty"`
+
+	// Configured 是否已配置凭据并启用，未配置的 provider 不能使用
+	Configured bool `json:"configured"`
+
+	// Custom 是否为配置文件中添加的 provider（不在 catwalk 目录中）
+	Custom bool `json:"custom"`
+
+	// Models 以模型 ID 为键
+	Models map[string]ProviderModel `json:"models"`
+}
+
+// ProviderModel 模型的能力和价格
+type ProviderModel struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+
+	// Attachment 是否支持图片等附件
+	Attachment bool `json:"attachment"`
+
+	// Reasoning 是否支持推理
+	Reasoning bool `json:"reasoning"`
+
+	// ReasoningLevels 支持的推理强度，例如 low、medium、high
+	ReasoningLevels []string `json:"reasoning_levels,omitempty"`
+
+	Cost  ModelCost  `json:"cost"`
+	Limit ModelLimit `json:"limit"`
+}
+
+// ModelCost 每百万 token 的价格（美元）
+type ModelCost struct {
+	Input      float64 `json:"input"`
+	Output     float64 `json:"output"`
+	CacheRead  float64 `json:"cache_read"`
+	CacheWrite float64 `json:"cache_write"`
+}
+
+// ModelLimit 模型的 token 限制
+type ModelLimit struct {
+	// Context 上下文窗口
+	Context int64 `json:"context"`
+
+	// Output 默认的最大输出 token 数
+	Output int64 `json:"output"`
+}
+
+// ProviderTestRequest 测试 provider 连接的请求体，字段为空时使用当前配置
+type ProviderTestRequest struct {
+	// APIKey 要测试的 API Key，可以是 $VAR 形式的引用
+	APIKey string `json:"api_key,omitempty"`
+
+	// BaseURL 要测试的接口地址
+	BaseURL string `json:"base_url,omitempty"`
+}
+
+// ProviderTestResponse 测试 provider 连接的结果
+type ProviderTestResponse struct {
+	ProviderID string `json:"provider_id"`
+
+	// OK 是否连接成功
+	OK bool `json:"ok"`
+
+	// Error 连接失败的原因
+	Error string `json:"error,omitempty"`
+
+	// DurationMs 测试耗时（毫秒）
+	DurationMs int64 `json:"duration_ms"`
+}
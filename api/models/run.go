@@ -0,0 +1,48 @@
+package models
+
+// 异步 prompt 运行状态
+const (
+	RunStatusRunning   = "running"
+	RunStatusCompleted = "completed"
+	RunStatusFailed    = "failed"
+	RunStatusCancelled = "cancelled"
+)
+
+// RunResponse 异步 prompt 运行的状态
+type RunResponse struct {
+	// ID 运行 ID
+	ID string `json:"id"`
+
+	// SessionID 所属会话 ID
+	SessionID string `json:"session_id"`
+
+	// Directory 所属项目路径
+	Directory string `json:"directory"`
+
+	// Status 运行状态：running、completed、failed、cancelled
+	Status string `json:"status"`
+
+	// Error 运行失败时的错误信息
+	Error string `json:"error,omitempty"`
+
+	// Output 本次运行中 assistant 已输出的文本（运行中为部分输出）
+	Output string `json:"output"`
+
+	// Message 本次运行最新的 assistant 消息，运行结束后即为最终消息
+	Message *PromptResponse `json:"message,omitempty"`
+
+	// MessageIDs 本次运行产生的 assistant 消息 ID
+	MessageIDs []string `json:"message_ids"`
+
+	// CreatedAt 创建时间（Unix 秒）
+	CreatedAt int64 `json:"created_at"`
+
+	// FinishedAt 结束时间（Unix 秒），运行中为空
+	FinishedAt int64 `json:"finished_at,omitempty"`
+}
+
+// RunsResponse 异步运行列表
+type RunsResponse struct {
+	Runs  []RunResponse `json:"runs"`
+	Total int           `json:"total"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/skills"
+)
+
+// SkillsResponse 项目可用的 Agent Skills
+type SkillsResponse struct {
+	// Paths 查找 SKILL.md 的目录（options.skills_paths 和全局 skills 目录，已展开）
+	Paths []string `json:"paths"`
+
+	// Skills 找到的所有 SKILL.md，按路径排序，包括未通过校验的
+	Skills []Skill `json:"skills"`
+}
+
+// Skill SKILL.md 的内容和校验结果
+type Skill struct {
+	// Name frontmatter 中的名称，SKILL.md 无法解析时为空
+	Name          string            `json:"name"`
+	Description   string            `json:"description"`
+	License       string            `json:"license,omitempty"`
+	Compatibility string            `json:"compatibility,omitempty"`
+	Metadata      map[string]string `json:"metadata,omitempty"`
+
+	// Path skill 所在目录
+	Path string `json:"path"`
+
+	// File SKILL.md 的路径，agent 通过它读取 skill
+	File string `json:"file"`
+
+	// Valid 是否通过校验，只有通过校验的 skill 会提供给 agent
+	Valid bool `json:"valid"`
+
+	// Errors 解析或校验失败的原因
+	Errors []string `json:"errors"`
+
+	// Instructions SKILL.md 正文，仅 /skill/{name} 返回
+	Instructions string `json:"instructions,omitempty"`
+}
+
+// SkillFromResult 将 skills.Scan 的结果转换为 API 的 skill，不包括正文
+func SkillFromResult(result skills.Result) Skill {
+	skill := Skill{
+		Path:   filepath.Dir(result.Path),
+		File:   result.Path,
+		Valid:  result.Err == nil,
+		Errors: []string{},
+	}
+	if s := result.Skill; s != nil {
+		skill.Name = s.Name
+		skill.Description = s.Description
+		skill.License = s.License
+		skill.Compatibility = s.Compatibility
+		skill.Metadata = s.Metadata
+	}
+	if result.Err != nil {
+		// Validate 用 errors.Join 合并多个错误，每行一个
+		skill.Errors = strings.Split(result.Err.Error(), "\n")
+	}
+	return skill
+}
@@ -0,0 +1,27 @@
+package models
+
+import "github.com/charmbracelet/crush/internal/stats"
+
+// StatsResponse 项目的用量和费用统计，与 crush stats 生成的页面数据一致
+type StatsResponse struct {
+	stats.Stats
+}
+
+// GlobalStatsResponse 所有项目的用量和费用统计，字段与单个项目的统计相同，另外包括每个项目的合计
+type GlobalStatsResponse struct {
+	stats.Stats
+
+	// Projects 每个项目的合计，按费用降序
+	Projects []ProjectStats `json:"projects"`
+}
+
+// ProjectStats 单个项目的用量和费用合计
+type ProjectStats struct {
+	Path          string  `json:"path"`
+	TotalSessions int64   `json:"total_sessions"`
+	TotalTokens   int64   `json:"total_tokens"`
+	TotalCost     float64 `json:"total_cost"`
+
+	// Error 读取项目数据库失败的原因，失败的项目不计入合计
+	Error string `json:"error,omitempty"`
+}
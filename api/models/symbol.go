@@ -0,0 +1,35 @@
+package models
+
+// Position 文件中的位置，与 LSP 一致，行和列都从 0 开始，列以 UTF-16 码元计
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range 文件中的范围
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Location 文件中的位置，uri 为 file:// URI
+type Location struct {
+	URI string `json:"uri"`
+
+	// Path 文件路径，位于项目内时为相对于项目根目录的路径
+	Path string `json:"path"`
+
+	Range Range `json:"range"`
+}
+
+// Symbol 符号，与 opencode SDK 的 Symbol 一致
+type Symbol struct {
+	// Name 符号名称
+	Name string `json:"name"`
+
+	// Kind LSP SymbolKind，例如 5 Class、6 Method、12 Function、13 Variable
+	Kind int `json:"kind"`
+
+	// Location 符号声明的位置
+	Location Location `json:"location"`
+}
@@ -90,10 +90,13 @@ type TodoResponse struct {
 
 type CreateSessionRequest struct {
 	Title string `json:"title"`
+	// PermissionMode 会话的权限模式：auto、ask、deny-dangerous，为空时使用服务器默认值
+	PermissionMode string `json:"permission_mode,omitempty"`
 }
 
 type CreateSessionResponse struct {
-	Session SessionResponse `json:"session"`
+	Session        SessionResponse `json:"session"`
+	PermissionMode string          `json:"permission_mode"`
 }
 
 type UpdateSessionRequest struct {
@@ -193,6 +196,20 @@ type PermissionReplyRequest struct {
 	Persistent bool `json:"persistent,omitempty"`
 }
 
+// PermissionRequestFromInternal 从内部 permission.PermissionRequest 类型转换
+func PermissionRequestFromInternal(p permission.PermissionRequest) PermissionRequest {
+	return PermissionRequest{
+		ID:          p.ID,
+		SessionID:   p.SessionID,
+		ToolCallID:  p.ToolCallID,
+		ToolName:    p.ToolName,
+		Description: p.Description,
+		Action:      p.Action,
+		Params:      p.Params,
+		Path:        p.Path,
+	}
+}
+
 // ToInternal 转换为内部 permission.PermissionRequest 类型
 func (p PermissionRequest) ToInternal() permission.PermissionRequest {
 	return permission.PermissionRequest{
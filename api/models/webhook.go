@@ -0,0 +1,14 @@
+package models
+
+import "github.com/charmbracelet/crush/internal/webhook"
+
+// WebhookStatus webhook 的配置、投递统计和最近的投递
+type WebhookStatus = webhook.TargetStatus
+
+// WebhookDelivery 一个事件到一个 webhook 的投递
+type WebhookDelivery = webhook.Delivery
+
+// WebhookStatusResponse 项目中所有 webhook 的状态，按名称排序
+type WebhookStatusResponse struct {
+	Webhooks []WebhookStatus `json:"webhooks"`
+}
@@ -0,0 +1,75 @@
+package models
+
+// WebSocket 客户端命令类型
+const (
+	WSCommandPrompt          = "prompt"
+	WSCommandAbort           = "abort"
+	WSCommandPermissionReply = "permission.reply"
+	WSCommandSubscribe       = "subscribe"
+	WSCommandUnsubscribe     = "unsubscribe"
+	WSCommandPing            = "ping"
+)
+
+// WSEventCommandResult 命令结果的事件类型
+const WSEventCommandResult = "command.result"
+
+// WSEvent 服务器通过 WebSocket 发送的消息，即带 ID 的 SSEEvent
+// ID 与 /event 的事件 ID 属于同一序列，重连时可作为 last_event_id 补发；server.connected 和 command.result 没有 ID
+type WSEvent struct {
+	ID         string      `json:"id,omitempty"`
+	Type       string      `json:"type"`
+	Properties interface{} `json:"properties"`
+}
+
+// WSCommand 客户端通过 WebSocket 发送的命令
+type WSCommand struct {
+	// ID 客户端生成的命令 ID，原样带回到命令结果中
+	ID string `json:"id,omitempty"`
+
+	// Type 命令类型：prompt、abort、permission.reply、subscribe、unsubscribe、ping
+	Type string `json:"type"`
+
+	// SessionID prompt 和 abort 的目标会话
+	SessionID string `json:"session_id,omitempty"`
+
+	// Prompt prompt 命令的内容，与 POST /session/{sessionID}/prompt 的请求体相同，不支持 noReply
+	Prompt *PromptRequest `json:"prompt,omitempty"`
+
+	// RequestID permission.reply 回复的权限请求 ID
+	RequestID string `json:"request_id,omitempty"`
+
+	// Reply permission.reply 的回复内容
+	Reply *PermissionReplyRequest `json:"reply,omitempty"`
+
+	// SessionIDs subscribe 和 unsubscribe 的会话列表
+	SessionIDs []string `json:"session_ids,omitempty"`
+}
+
+// WSCommandResult 命令的执行结果，以 command.result 事件的 properties 发送
+type WSCommandResult struct {
+	// ID 对应命令的 ID
+	ID string `json:"id,omitempty"`
+
+	// Command 命令类型
+	Command string `json:"command"`
+
+	// OK 命令是否成功
+	OK bool `json:"ok"`
+
+	// Error 失败时的错误，code 与 HTTP 接口的错误码一致
+	Error *WSError `json:"error,omitempty"`
+
+	// Data 命令的返回数据：prompt 为 RunResponse，subscribe/unsubscribe 为当前订阅的会话
+	Data interface{} `json:"data,omitempty"`
+}
+
+// WSError 命令失败的原因
+type WSError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// WSSubscription 连接当前订阅的会话，为空表示接收所有会话的事件
+type WSSubscription struct {
+	SessionIDs []string `json:"session_ids"`
+}
@@ -17,10 +17,48 @@ import (
 type Server struct {
 	*hertzserver.Hertz
 	handlers *handlers.Handlers
+	opts     Options
 }
 
+// Options 服务器可选配置
+type Options struct {
+	// Auth 认证配置，未配置凭据时不启用认证
+	Auth middleware.AuthConfig
+	// DisableSwagger 不注册 Swagger / Redoc 文档路由
+	DisableSwagger bool
+	// PublicSwagger 文档路由无需认证
+	PublicSwagger bool
+	// Handlers 处理器配置（权限模式、并发运行限制等）
+	Handlers handlers.Options
+	// RateLimit 按客户端限流配置
+	RateLimit middleware.RateLimitConfig
+	// RequestTimeout 普通请求的超时时间，0 使用默认值，负数表示不限制
+	RequestTimeout time.Duration
+}
+
+// 无需认证的路径
+var publicPaths = []string{"/health", "/global/health"}
+
+// maxRequestBodySize 请求体大小上限，需容纳 base64 编码后的附件
+const maxRequestBodySize = 32 * 1024 * 1024
+
+// 不受请求超时限制的路由，agent 运行由各自的超时控制，WebSocket 为长连接
+var longRunningRoutes = []string{
+	"/session/:sessionID/prompt",
+	"/session/:id/summarize",
+	"/session/:id/init",
+	"/session/:id/shell",
+	"/session/:id/command",
+	"/lsp/:name/restart",
+	"/job/:id/stream",
+	"/ws",
+}
+
+// 文档相关路径
+var swaggerPaths = []string{"/", "/swagger", "/swagger/doc.json", "/swagger/openapi3.json", "/redoc"}
+
 // NewServer 创建新的 Hertz API 服务器实例
-func NewServer(host string, port int) *Server {
+func NewServer(host string, port int, opts Options) *Server {
 	addr := fmt.Sprintf("%s:%d", host, port)
 
 	// 创建 Hertz 服务器
@@ -29,16 +67,18 @@ func NewServer(host string, port int) *Server {
 		hertzserver.WithReadTimeout(30*time.Second),
 		// hertzserver.WithWriteTimeout(30*time.Second),
 		hertzserver.WithIdleTimeout(120*time.Second),
+		hertzserver.WithMaxRequestBodySize(maxRequestBodySize),
 	)
 
 	// 创建 handlers
-	handlersInstance := handlers.New()
+	handlersInstance := handlers.New(opts.Handlers)
 
 	slog.Info("Hertz server created", "addr", addr)
 
 	return &Server{
 		Hertz:    h,
 		handlers: handlersInstance,
+		opts:     opts,
 	}
 }
 
@@ -46,19 +86,40 @@ func NewServer(host string, port int) *Server {
 func (s *Server) Start() error {
 	// 全局中间件
 	// 注意：中间件按顺序执行，Recovery 放最前确保捕获所有 panic
+	authCfg := s.opts.Auth
+	authCfg.PublicPaths = append(authCfg.PublicPaths, publicPaths...)
+	if s.opts.PublicSwagger {
+		authCfg.PublicPaths = append(authCfg.PublicPaths, swaggerPaths...)
+	}
+	if !authCfg.Enabled() {
+		slog.Warn("API server authentication disabled: no tokens or API keys configured")
+	}
+
+	rateLimitCfg := s.opts.RateLimit
+	rateLimitCfg.SkipPaths = append(rateLimitCfg.SkipPaths, publicPaths...)
+	requestTimeout := s.opts.RequestTimeout
+	if requestTimeout == 0 {
+		requestTimeout = middleware.DefaultRequestTimeout
+	}
+
 	s.Use(
-		middleware.RecoveryMiddleware(), // panic 恢复
-		middleware.LoggingMiddleware(),  // 日志记录
-		middleware.CORSMiddleware(),     // CORS 处理
-		middleware.JSONMiddleware(),     // JSON Content-Type
+		middleware.RecoveryMiddleware(),                                    // panic 恢复
+		middleware.LoggingMiddleware(),                                     // 日志记录
+		middleware.CORSMiddleware(),                                        // CORS 处理
+		middleware.AuthMiddleware(authCfg),                                 // 认证与授权
+		middleware.RateLimitMiddleware(rateLimitCfg),                       // 按客户端限流（依赖认证结果）
+		middleware.TimeoutMiddleware(requestTimeout, longRunningRoutes...), // 请求超时
+		middleware.JSONMiddleware(),                                        // JSON Content-Type
 	)
 
 	// Swagger 路由
-	s.GET("/", handlers.HandleIndexRedirect)
-	s.GET("/swagger", handlers.HandleSwaggerUI)
-	s.GET("/swagger/doc.json", handlers.HandleSwaggerJSON)
-	s.GET("/swagger/openapi3.json", handlers.HandleOpenAPI3JSON) // OpenAPI 3.0
-	s.GET("/redoc", handlers.HandleRedoc)                        // Redoc UI with OpenAPI 3.0
+	if !s.opts.DisableSwagger {
+		s.GET("/", handlers.HandleIndexRedirect)
+		s.GET("/swagger", handlers.HandleSwaggerUI)
+		s.GET("/swagger/doc.json", handlers.HandleSwaggerJSON)
+		s.GET("/swagger/openapi3.json", handlers.HandleOpenAPI3JSON) // OpenAPI 3.0
+		s.GET("/redoc", handlers.HandleRedoc)                        // Redoc UI with OpenAPI 3.0
+	}
 
 	// API 路由
 	{
@@ -74,6 +135,16 @@ func (s *Server) Start() error {
 		s.POST("/instance/dispose", s.handlers.HandleDisposeProject)
 		s.GET("/project/config", s.handlers.HandleGetConfig)
 
+		// 配置管理 - providers、models、mcp、lsp、options、permissions
+		s.GET("/project/config/:section", s.handlers.HandleGetConfigSection)
+		s.PATCH("/project/config/:section", s.handlers.HandlePatchConfigSection)
+		s.PUT("/project/config/:section/:name", s.handlers.HandlePutConfigEntry)
+		s.DELETE("/project/config/:section/:name", s.handlers.HandleDeleteConfigEntry)
+
+		// Provider 和模型目录
+		s.GET("/config/providers", s.handlers.HandleListProviders)
+		s.POST("/config/providers/:id/test", s.handlers.HandleTestProvider)
+
 		// 系统提示词管理
 		s.GET("/system-prompt", s.handlers.HandleGetSystemPrompt)
 		s.PUT("/system-prompt", s.handlers.HandleUpdateSystemPrompt)
@@ -89,32 +160,91 @@ func (s *Server) Start() error {
 			})
 		})
 		s.POST("/global/dispose", s.handlers.HandleDisposeAll)
+		s.GET("/global/stats", s.handlers.HandleGetGlobalStats)
+
+		// Prometheus 指标
+		s.GET("/metrics", s.handlers.HandleMetrics)
 
 		// 文件系统操作
-		s.GET("/find", s.handlers.HandleSearchContent)          // 搜索文本内容
-		s.GET("/find/file", s.handlers.HandleSearchFile)        // 搜索文件名
-		s.GET("/file", s.handlers.HandleListFiles)              // 列出目录内容
-		s.GET("/file/content", s.handlers.HandleGetFileContent) // 读取文件内容
-		s.GET("/file/status", s.handlers.HandleGetGitStatus)    // 获取 Git 状态
+		s.GET("/find", s.handlers.HandleSearchContent)             // 搜索文本内容
+		s.GET("/find/file", s.handlers.HandleSearchFile)           // 搜索文件名
+		s.GET("/find/symbol", s.handlers.HandleFindSymbol)         // 搜索工作区符号
+		s.GET("/find/definition", s.handlers.HandleFindDefinition) // 查找定义
+		s.GET("/find/references", s.handlers.HandleFindReferences) // 查找引用
+		s.GET("/file", s.handlers.HandleListFiles)                 // 列出目录内容
+		s.GET("/file/content", s.handlers.HandleGetFileContent)    // 读取文件内容
+		s.GET("/file/status", s.handlers.HandleGetGitStatus)       // 获取 Git 状态
+		s.PUT("/file/content", s.handlers.HandleWriteFile)         // 写入文件
+		s.POST("/file/patch", s.handlers.HandlePatchFile)          // 应用 unified diff
+		s.POST("/file/rename", s.handlers.HandleRenameFile)        // 重命名文件
+		s.DELETE("/file", s.handlers.HandleDeleteFile)             // 删除文件
 
 		// LSP 和 MCP 状态
 		s.GET("/lsp", s.handlers.HandleGetLSPStatus) // 获取 LSP 状态
 		s.GET("/mcp", s.handlers.HandleGetMCPStatus) // 获取 MCP 状态
 
+		// LSP 诊断和管理
+		s.GET("/lsp/diagnostics", s.handlers.HandleGetDiagnostics)
+		s.POST("/lsp/:name/restart", s.handlers.HandleRestartLSP)
+
+		// MCP 服务器管理
+		s.POST("/mcp/:name/restart", s.handlers.HandleRestartMCP)
+		s.POST("/mcp/:name/enable", s.handlers.HandleEnableMCP)
+		s.POST("/mcp/:name/disable", s.handlers.HandleDisableMCP)
+		s.GET("/mcp/:name/tools", s.handlers.HandleListMCPTools)
+		s.GET("/mcp/:name/prompts", s.handlers.HandleListMCPPrompts)
+		s.GET("/mcp/:name/resources", s.handlers.HandleListMCPResources)
+		s.POST("/mcp/:name/tools/:tool/call", s.handlers.HandleCallMCPTool)
+
+		// 用量和费用统计
+		s.GET("/stats", s.handlers.HandleGetStats)
+
+		// 审计日志 - 工具执行和权限决定
+		s.GET("/audit", s.handlers.HandleListAudit)
+
+		// Webhook - 投递状态
+		s.GET("/webhook", s.handlers.HandleGetWebhookStatus)
+
+		// 后台任务 - agent 通过 bash 工具启动的任务
+		s.GET("/job", s.handlers.HandleListJobs)
+		s.GET("/job/:id", s.handlers.HandleGetJob)
+		s.GET("/job/:id/output", s.handlers.HandleGetJobOutput)
+		s.GET("/job/:id/stream", s.handlers.HandleStreamJobOutput)
+		s.POST("/job/:id/kill", s.handlers.HandleKillJob)
+
 		// 会话管理 - 使用查询参数指定项目
 		s.GET("/session", s.handlers.HandleListSessions)
 		s.POST("/session", s.handlers.HandleCreateSession)
+		s.POST("/session/import", s.handlers.HandleImportSession)
 		s.GET("/session/:id", s.handlers.HandleGetSession)
 		s.PUT("/session/:id", s.handlers.HandleUpdateSession)
 		s.DELETE("/session/:id", s.handlers.HandleDeleteSession)
 		s.POST("/session/:id/abort", s.handlers.HandleAbortSession)
 		s.GET("/session/status", s.handlers.HandleGetSessionStatus)
+		s.GET("/session/:id/children", s.handlers.HandleListChildSessions)
+		s.GET("/session/:id/export", s.handlers.HandleExportSession)
+		s.POST("/session/:id/revert", s.handlers.HandleRevertSession)
+		s.POST("/session/:id/unrevert", s.handlers.HandleUnrevertSession)
+		s.POST("/session/:id/summarize", s.handlers.HandleSummarizeSession)
+		s.POST("/session/:id/init", s.handlers.HandleInitSession)
+		s.POST("/session/:id/shell", s.handlers.HandleSessionShell)
+		s.POST("/session/:id/command", s.handlers.HandleSessionCommand)
+		s.GET("/command", s.handlers.HandleListCommands)
+
+		// Agent Skills
+		s.GET("/skill", s.handlers.HandleListSkills)
+		s.GET("/skill/:name", s.handlers.HandleGetSkill)
 
 		// 消息管理 - 使用查询参数指定项目
 		s.GET("/session/:sessionID/message", s.handlers.HandleListMessages)
 		s.POST("/session/:sessionID/prompt", s.handlers.HandlePrompt)
 		s.GET("/message/:id", s.handlers.HandleGetMessage)
 
+		// 异步运行 - 通过 prompt?async=true 创建
+		s.GET("/run", s.handlers.HandleListRuns)
+		s.GET("/run/:id", s.handlers.HandleGetRun)
+		s.DELETE("/run/:id", s.handlers.HandleCancelRun)
+
 		// SSE 事件流 - 需要单独处理，跳过 JSON 中间件
 		s.GET("/event", func(c context.Context, ctx *hertzapp.RequestContext) {
 			// 临时清除 Content-Type，让 HandleSSE 自己设置
@@ -122,6 +252,9 @@ func (s *Server) Start() error {
 			s.handlers.HandleSSE(c, ctx)
 		})
 
+		// WebSocket - 双向传输事件和命令
+		s.GET("/ws", s.handlers.HandleWebSocket)
+
 		// 健康检查 (兼容旧路径)
 		s.GET("/health", func(c context.Context, ctx *hertzapp.RequestContext) {
 			ctx.JSON(consts.StatusOK, map[string]string{"status": "ok"})
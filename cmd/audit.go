@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"charm.land/lipgloss/v2"
+	"charm.land/lipgloss/v2/table"
+	"github.com/charmbracelet/crush/internal/audit"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+// auditParamsWidth 表格中参数列的最大宽度
+const auditParamsWidth = 60
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "查询审计日志",
+	Long: `按时间倒序显示当前项目审计日志中的工具执行和权限决定。
+工具执行记录参数、状态、退出码和耗时；权限决定记录结果和决定方：
+tui、回复请求的 API Key 或 Token（未启用认证时为 api），或自动决定的 auto。
+通过 API 发起的操作记录发起的调用方，格式同决定方。`,
+	Example: `
+# 显示最近 50 条记录
+zorkagent audit
+
+# 显示会话的权限决定
+zorkagent audit --session 3f2b... --kind permission
+
+# 以 JSON 格式输出 3 月执行的 bash 命令
+zorkagent audit --tool bash --from 2025-03-01 --to 2025-03-31 --json
+  `,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sessionID, _ := cmd.Flags().GetString("session")
+		kind, _ := cmd.Flags().GetString("kind")
+		tool, _ := cmd.Flags().GetString("tool")
+		decider, _ := cmd.Flags().GetString("decider")
+		actor, _ := cmd.Flags().GetString("actor")
+		from, _ := cmd.Flags().GetString("from")
+		to, _ := cmd.Flags().GetString("to")
+		limit, _ := cmd.Flags().GetInt("limit")
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+
+		filter := audit.Filter{
+			SessionID: sessionID,
+			Kind:      audit.Kind(kind),
+			ToolName:  tool,
+			Decider:   decider,
+			Actor:     actor,
+			Limit:     limit,
+		}
+		if kind != "" && filter.Kind != audit.KindTool && filter.Kind != audit.KindPermission {
+			return fmt.Errorf("invalid kind %q: must be tool or permission", kind)
+		}
+		var err error
+		if filter.From, err = parseDate(from); err != nil {
+			return fmt.Errorf("invalid --from: %w", err)
+		}
+		if filter.To, err = parseDate(to); err != nil {
+			return fmt.Errorf("invalid --to: %w", err)
+		}
+		if !filter.To.IsZero() {
+			// --to 包含当天
+			filter.To = filter.To.AddDate(0, 0, 1)
+		}
+
+		auditLog, closeDB, err := openAudit(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeDB() //nolint:errcheck
+
+		entries, total, err := auditLog.List(cmd.Context(), filter)
+		if err != nil {
+			return fmt.Errorf("failed to read audit log: %w", err)
+		}
+
+		if jsonOutput {
+			data, err := json.Marshal(struct {
+				Entries []audit.Entry `json:"entries"`
+				Total   int           `json:"total"`
+			}{Entries: entries, Total: total})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), string(data))
+			return nil
+		}
+
+		if len(entries) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "没有符合条件的审计记录。")
+			return nil
+		}
+
+		if term.IsTerminal(os.Stdout.Fd()) {
+			t := table.New().
+				Border(lipgloss.RoundedBorder()).
+				StyleFunc(func(row, col int) lipgloss.Style {
+					return lipgloss.NewStyle().Padding(0, 1)
+				}).
+				Headers("时间", "会话", "调用方", "工具", "事件", "参数")
+			for _, e := range entries {
+				t.Row(
+					e.CreatedAt.Local().Format(time.DateTime),
+					shortSessionID(e.SessionID),
+					e.Actor,
+					e.ToolName,
+					auditEvent(e),
+					ansi.Truncate(strings.Join(strings.Fields(e.Params), " "), auditParamsWidth, "…"),
+				)
+			}
+			lipgloss.Println(t)
+			if total > len(entries) {
+				fmt.Fprintf(cmd.OutOrStdout(), "显示 %d / %d 条记录，使用 --limit 显示更多。\n", len(entries), total)
+			}
+			return nil
+		}
+
+		// 非终端：每行一条记录，字段以制表符分隔
+		for _, e := range entries {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%s\t%s\t%s\t%s\n", e.CreatedAt.Format(time.RFC3339), e.SessionID, e.Actor, e.ToolName, auditEvent(e), e.Params)
+		}
+		return nil
+	},
+}
+
+// openAudit 打开当前项目的数据库，不启动 LSP、MCP 等 app 服务
+func openAudit(cmd *cobra.Command) (audit.Service, func() error, error) {
+	cwd, err := ResolveCwd(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+	dataDir, _ := cmd.Flags().GetString("data-dir")
+	debug, _ := cmd.Flags().GetBool("debug")
+
+	cfg, err := config.Load(cwd, dataDir, debug)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load configuration: %v", err)
+	}
+	if err := createDotZorkAgentDir(cfg.Options.DataDirectory); err != nil {
+		return nil, nil, err
+	}
+
+	conn, err := db.Connect(cmd.Context(), cfg.Options.DataDirectory)
+	if err != nil {
+		return nil, nil, err
+	}
+	return audit.NewService(db.New(conn)), conn.Close, nil
+}
+
+// parseDate 解析 YYYY-MM-DD 格式的日期，空字符串返回零值
+func parseDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.DateOnly, s)
+}
+
+// auditEvent 描述审计记录的内容，如 "denied by tui" 或 "error, exit 1, 1.2s"
+func auditEvent(e audit.Entry) string {
+	if e.Kind == audit.KindPermission {
+		event := e.Decision + " by " + e.Decider
+		if e.Reason != "" {
+			event += " (" + e.Reason + ")"
+		}
+		return event
+	}
+
+	parts := []string{e.Status}
+	if e.ExitCode != nil {
+		parts = append(parts, fmt.Sprintf("exit %d", *e.ExitCode))
+	}
+	if !e.FinishedAt.IsZero() {
+		parts = append(parts, e.FinishedAt.Sub(e.CreatedAt).Round(100*time.Millisecond).String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// shortSessionID 缩短会话 ID 用于表格显示
+func shortSessionID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+func init() {
+	auditCmd.Flags().String("session", "", "只显示该会话的记录")
+	auditCmd.Flags().String("kind", "", "只显示该类型的记录：tool 或 permission")
+	auditCmd.Flags().String("tool", "", "只显示该工具的记录")
+	auditCmd.Flags().String("decider", "", "只显示该决定方的权限决定，如 tui、auto 或 api_key:<名称>")
+	auditCmd.Flags().String("actor", "", "只显示该调用方发起的记录，如 api 或 api_key:<名称>")
+	auditCmd.Flags().String("from", "", "开始日期（YYYY-MM-DD，UTC）")
+	auditCmd.Flags().String("to", "", "结束日期（YYYY-MM-DD，UTC，包含）")
+	auditCmd.Flags().Int("limit", 50, "最多显示的记录数，0 表示全部")
+	auditCmd.Flags().Bool("json", false, "以 JSON 格式输出")
+	rootCmd.AddCommand(auditCmd)
+}
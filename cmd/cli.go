@@ -478,7 +478,10 @@ var serveCmd = &cobra.Command{
 	Long: `启动无头 API 服务器以编程方式访问 ZorkAgent。
 
 服务器提供用于管理项目、会话和消息的 REST API。
-当您想将 ZorkAgent 集成到其他应用程序或脚本时使用此功能。`,
+当您想将 ZorkAgent 集成到其他应用程序或脚本时使用此功能。
+
+在配置的 server.tokens 或 server.api_keys 中设置凭据后，所有请求都需要携带
+Authorization: Bearer <key> 或 X-API-Key 头。`,
 	Example: `
 # 在默认端口（8080）启动服务器
 zorkagent serve
@@ -491,6 +494,18 @@ zorkagent serve --host 0.0.0.0
 
 # 启用调试日志启动服务器
 zorkagent serve --debug
+
+# 会话默认需要通过 API 审批工具权限，1 分钟未回复则拒绝
+zorkagent serve --permission-mode ask --permission-timeout 1m
+
+# 限制每个客户端的请求速率和并发运行数
+zorkagent serve --rate-limit 20 --max-project-runs 2
+
+# 最多保留 4 个项目实例，闲置 10 分钟后释放
+zorkagent serve --max-instances 4 --instance-idle-ttl 10m
+
+# 创建 API Key（配置任意 Key 或 Token 后即启用认证）
+zorkagent serve keys create my-bot
   `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		port, _ := cmd.Flags().GetInt("port")
@@ -517,6 +532,15 @@ func init() {
 	updateProvidersCmd.Flags().StringVar(&updateProvidersSource, "source", "catwalk", "要更新的提供者源（catwalk 或 hyper）")
 	serveCmd.Flags().IntP("port", "p", 8080, "API 服务器端口")
 	serveCmd.Flags().String("host", "localhost", "API 服务器主机")
+	serveCmd.Flags().String("permission-mode", "", "会话默认权限模式：auto、ask 或 deny-dangerous（默认 ask）")
+	serveCmd.Flags().Duration("permission-timeout", 0, "权限请求等待回复的超时时间，超时后拒绝，0 表示一直等待（默认 2m）")
+	serveCmd.Flags().Int("rate-limit", 0, "每个客户端每秒允许的请求数，负数表示不限流（默认 100）")
+	serveCmd.Flags().Int("rate-burst", 0, "每个客户端的最大突发请求数（默认 200）")
+	serveCmd.Flags().Int("max-runs", 0, "全局同时进行的 agent 运行数上限，负数表示不限制（默认 16）")
+	serveCmd.Flags().Int("max-project-runs", 0, "单个项目同时进行的 agent 运行数上限，负数表示不限制（默认 4）")
+	serveCmd.Flags().Duration("request-timeout", 0, "普通请求的超时时间，不影响 agent 运行和事件流，负数表示不限制（默认 30s）")
+	serveCmd.Flags().Duration("instance-idle-ttl", 0, "项目实例闲置多久后释放，负数表示不释放（默认 30m）")
+	serveCmd.Flags().Int("max-instances", 0, "同时保留的项目实例数上限，超出时淘汰最久未使用的空闲实例，负数表示不限制（默认 16）")
 
 	rootCmd.AddCommand(
 		runCmd,
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"charm.land/lipgloss/v2"
+	"charm.land/lipgloss/v2/table"
+	"github.com/charmbracelet/crush/api/middleware"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+var serveKeysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "管理 API 服务器的 API Key",
+	Long: `管理 API 服务器使用的 API Key。
+Key 仅以 SHA-256 哈希形式保存在数据目录的配置文件中，明文只在创建时显示一次。`,
+	Example: `
+# 创建一个可读写所有项目的 Key
+zorkagent serve keys create ci-bot
+
+# 创建一个只读、仅能访问指定项目的 Key
+zorkagent serve keys create dashboard --project /srv/repo --read-only
+
+# 列出所有 Key
+zorkagent serve keys list
+
+# 吊销 Key
+zorkagent serve keys revoke ci-bot
+  `,
+}
+
+var serveKeysCreateCmd = &cobra.Command{
+	Use:   "create <名称>",
+	Short: "创建新的 API Key",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectPaths, _ := cmd.Flags().GetStringSlice("project")
+		readOnly, _ := cmd.Flags().GetBool("read-only")
+
+		cfg, err := loadServeKeysConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		for i, p := range projectPaths {
+			abs, err := filepath.Abs(p)
+			if err != nil {
+				return fmt.Errorf("invalid project path %q: %w", p, err)
+			}
+			projectPaths[i] = abs
+		}
+
+		key, hash, err := middleware.GenerateAPIKey()
+		if err != nil {
+			return fmt.Errorf("failed to generate api key: %w", err)
+		}
+
+		if err := cfg.AddServerAPIKey(config.ServerAPIKey{
+			Name:      args[0],
+			Hash:      hash,
+			Projects:  projectPaths,
+			ReadOnly:  readOnly,
+			CreatedAt: time.Now().UTC(),
+		}); err != nil {
+			return err
+		}
+
+		fmt.Fprintln(cmd.OutOrStdout(), key)
+		if term.IsTerminal(os.Stderr.Fd()) {
+			fmt.Fprintln(os.Stderr, "请妥善保存此 Key，它不会再次显示。")
+		}
+		return nil
+	},
+}
+
+var serveKeysListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "列出 API Key",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+
+		cfg, err := loadServeKeysConfig(cmd)
+		if err != nil {
+			return err
+		}
+		keys := cfg.Server.APIKeys
+
+		if jsonOutput {
+			output := struct {
+				Keys []config.ServerAPIKey `json:"keys"`
+			}{Keys: keys}
+
+			data, err := json.Marshal(output)
+			if err != nil {
+				return err
+			}
+			cmd.Println(string(data))
+			return nil
+		}
+
+		if len(keys) == 0 {
+			cmd.Println("No API keys configured.")
+			return nil
+		}
+
+		access := func(k config.ServerAPIKey) string {
+			if k.ReadOnly {
+				return "read-only"
+			}
+			return "read-write"
+		}
+		scope := func(k config.ServerAPIKey) string {
+			if len(k.Projects) == 0 {
+				return "*"
+			}
+			return strings.Join(k.Projects, ", ")
+		}
+
+		if term.IsTerminal(os.Stdout.Fd()) {
+			t := table.New().
+				Border(lipgloss.RoundedBorder()).
+				StyleFunc(func(row, col int) lipgloss.Style {
+					return lipgloss.NewStyle().Padding(0, 2)
+				}).
+				Headers("Name", "Access", "Projects", "Created")
+
+			for _, k := range keys {
+				t.Row(k.Name, access(k), scope(k), k.CreatedAt.Local().Format("2006-01-02 15:04"))
+			}
+			lipgloss.Println(t)
+			return nil
+		}
+
+		for _, k := range keys {
+			cmd.Printf("%s\t%s\t%s\t%s\n", k.Name, access(k), scope(k), k.CreatedAt.Format(time.RFC3339))
+		}
+		return nil
+	},
+}
+
+var serveKeysRevokeCmd = &cobra.Command{
+	Use:     "revoke <名称>",
+	Aliases: []string{"rm", "delete"},
+	Short:   "吊销 API Key",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadServeKeysConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		removed, err := cfg.RemoveServerAPIKey(args[0])
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return fmt.Errorf("api key %q not found in %s", args[0], config.GlobalConfigData())
+		}
+		cmd.Printf("API key %q revoked. Restart the server for the change to take effect.\n", args[0])
+		return nil
+	},
+}
+
+// loadServeKeysConfig 加载用于管理 API Key 的配置
+func loadServeKeysConfig(cmd *cobra.Command) (*config.Config, error) {
+	cwd, err := ResolveCwd(cmd)
+	if err != nil {
+		return nil, err
+	}
+	dataDir, _ := cmd.Flags().GetString("data-dir")
+
+	cfg, err := config.Load(cwd, dataDir, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %v", err)
+	}
+	return cfg, nil
+}
+
+func init() {
+	serveKeysCreateCmd.Flags().StringSlice("project", nil, "限制 Key 只能访问的项目目录（可重复）")
+	serveKeysCreateCmd.Flags().Bool("read-only", false, "只允许读取请求")
+	serveKeysListCmd.Flags().Bool("json", false, "以 JSON 格式输出")
+
+	serveKeysCmd.AddCommand(serveKeysCreateCmd, serveKeysListCmd, serveKeysRevokeCmd)
+	serveCmd.AddCommand(serveKeysCmd)
+}
@@ -14,7 +14,9 @@ import (
 	"time"
 
 	"github.com/charmbracelet/crush/api"
+	"github.com/charmbracelet/crush/api/middleware"
 	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/permission"
 	"github.com/charmbracelet/crush/internal/projects"
 	"github.com/spf13/cobra"
 )
@@ -183,7 +185,45 @@ func StartServer(cmd *cobra.Command, port int, host string) {
 	}
 
 	// 创建 API 服务器（不再需要默认 app 实例）
-	server := api.NewServer(host, port)
+	opts := serverOptions(cfg, host)
+	if cmd.Flags().Changed("permission-mode") {
+		mode, _ := cmd.Flags().GetString("permission-mode")
+		opts.Handlers.PermissionMode = permission.SessionMode(mode)
+	}
+	if cmd.Flags().Changed("permission-timeout") {
+		timeout, _ := cmd.Flags().GetDuration("permission-timeout")
+		opts.Handlers.PermissionTimeout = &timeout
+	}
+	if cmd.Flags().Changed("rate-limit") {
+		opts.RateLimit.Rate, _ = cmd.Flags().GetInt("rate-limit")
+	}
+	if cmd.Flags().Changed("rate-burst") {
+		opts.RateLimit.Burst, _ = cmd.Flags().GetInt("rate-burst")
+	}
+	if cmd.Flags().Changed("max-runs") {
+		opts.Handlers.MaxConcurrentRuns, _ = cmd.Flags().GetInt("max-runs")
+	}
+	if cmd.Flags().Changed("max-project-runs") {
+		opts.Handlers.MaxProjectRuns, _ = cmd.Flags().GetInt("max-project-runs")
+	}
+	if cmd.Flags().Changed("request-timeout") {
+		opts.RequestTimeout, _ = cmd.Flags().GetDuration("request-timeout")
+	}
+	if cmd.Flags().Changed("instance-idle-ttl") {
+		opts.Handlers.InstanceIdleTTL, _ = cmd.Flags().GetDuration("instance-idle-ttl")
+	}
+	if cmd.Flags().Changed("max-instances") {
+		opts.Handlers.MaxInstances, _ = cmd.Flags().GetInt("max-instances")
+	}
+	if mode := opts.Handlers.PermissionMode; mode != "" && !mode.Valid() {
+		slog.Error("Invalid permission mode, expected auto, ask or deny-dangerous", "mode", mode)
+		os.Exit(1)
+	}
+	if timeout := opts.Handlers.PermissionTimeout; timeout != nil && *timeout < 0 {
+		slog.Error("Invalid permission timeout, expected 0 or a positive duration", "timeout", *timeout)
+		os.Exit(1)
+	}
+	server := api.NewServer(host, port, opts)
 
 	// 设置信号处理
 	sigChan := make(chan os.Signal, 1)
@@ -220,3 +260,59 @@ func StartServer(cmd *cobra.Command, port int, host string) {
 	}
 	slog.Info("API server shutdown complete")
 }
+
+// serverOptions 根据配置构建 API 服务器选项
+func serverOptions(cfg *config.Config, host string) api.Options {
+	opts := api.Options{
+		DisableSwagger: cfg.Server.DisableSwagger,
+		PublicSwagger:  cfg.Server.PublicSwagger,
+		Auth: middleware.AuthConfig{
+			APIKeys: cfg.Server.APIKeys,
+		},
+	}
+
+	for _, token := range cfg.Server.Tokens {
+		resolved, err := cfg.Resolve(token)
+		if err != nil {
+			slog.Error("Failed to resolve server token", "error", err)
+			continue
+		}
+		if resolved != "" {
+			opts.Auth.Tokens = append(opts.Auth.Tokens, resolved)
+		}
+	}
+
+	opts.Handlers.PermissionMode = permission.SessionMode(cfg.Server.PermissionMode)
+	if cfg.Server.PermissionTimeout != nil {
+		timeout := time.Duration(*cfg.Server.PermissionTimeout) * time.Second
+		opts.Handlers.PermissionTimeout = &timeout
+	}
+
+	opts.RateLimit.Rate = cfg.Server.RateLimit
+	opts.RateLimit.Burst = cfg.Server.RateBurst
+	opts.Handlers.MaxConcurrentRuns = cfg.Server.MaxConcurrentRuns
+	opts.Handlers.MaxProjectRuns = cfg.Server.MaxProjectRuns
+	if cfg.Server.RequestTimeout != 0 {
+		opts.RequestTimeout = time.Duration(cfg.Server.RequestTimeout) * time.Second
+	}
+	if cfg.Server.InstanceIdleTTL != 0 {
+		opts.Handlers.InstanceIdleTTL = time.Duration(cfg.Server.InstanceIdleTTL) * time.Second
+	}
+	opts.Handlers.MaxInstances = cfg.Server.MaxInstances
+
+	if !opts.Auth.Enabled() && !isLoopbackHost(host) {
+		slog.Warn("API server is listening on a non-loopback address without authentication; anyone who can reach it can run commands",
+			"host", host,
+		)
+	}
+	return opts
+}
+
+// isLoopbackHost 判断监听地址是否只对本机开放
+func isLoopbackHost(host string) bool {
+	switch host {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/charmbracelet/crush/internal/transcript"
+	"github.com/spf13/cobra"
+)
+
+var sessionCmd = &cobra.Command{
+	Use:   "session",
+	Short: "导出和导入会话",
+	Long: `导出和导入当前项目的会话。
+导出内容包括消息（含工具调用和结果）、待办事项、子任务会话和文件历史版本。
+json 格式可以导入到其他项目，导入时所有记录使用新的 ID。`,
+	Example: `
+# 以 JSON 格式导出会话到文件
+zorkagent session export 3f2b... -o session.json
+
+# 以 Markdown 格式导出会话
+zorkagent session export 3f2b... --format markdown > session.md
+
+# 在另一个项目中导入会话
+zorkagent session import session.json --cwd /path/to/other/project
+  `,
+}
+
+var sessionExportCmd = &cobra.Command{
+	Use:   "export <会话ID>",
+	Short: "导出会话",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		if format != "json" && format != "markdown" {
+			return fmt.Errorf("invalid format %q: must be json or markdown", format)
+		}
+
+		transcripts, closeDB, err := openTranscripts(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeDB() //nolint:errcheck
+
+		t, err := transcripts.Export(cmd.Context(), args[0])
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("session %s not found", args[0])
+			}
+			return fmt.Errorf("failed to export session: %w", err)
+		}
+
+		var data []byte
+		if format == "markdown" {
+			data = []byte(t.Markdown())
+		} else if data, err = json.MarshalIndent(t, "", "  "); err != nil {
+			return err
+		}
+
+		if output == "" || output == "-" {
+			_, err = cmd.OutOrStdout().Write(data)
+			return err
+		}
+		return os.WriteFile(output, data, 0o644)
+	},
+}
+
+var sessionImportCmd = &cobra.Command{
+	Use:   "import <文件>",
+	Short: "导入 JSON 格式导出的会话",
+	Long:  "将 session export 导出的 JSON 导入当前项目，文件为 - 时从标准输入读取。导出项目目录下的文件历史路径会映射到当前项目目录。",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var data []byte
+		var err error
+		if args[0] == "-" {
+			data, err = io.ReadAll(cmd.InOrStdin())
+		} else {
+			data, err = os.ReadFile(args[0])
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read session export: %w", err)
+		}
+
+		t, err := transcript.Parse(data)
+		if err != nil {
+			return err
+		}
+
+		transcripts, closeDB, err := openTranscripts(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeDB() //nolint:errcheck
+
+		sess, err := transcripts.Import(cmd.Context(), t)
+		if err != nil {
+			return fmt.Errorf("failed to import session: %w", err)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), sess.ID)
+		return nil
+	},
+}
+
+// openTranscripts 打开当前项目的数据库，不启动 LSP、MCP 等 app 服务
+func openTranscripts(cmd *cobra.Command) (transcript.Service, func() error, error) {
+	cwd, err := ResolveCwd(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+	dataDir, _ := cmd.Flags().GetString("data-dir")
+	debug, _ := cmd.Flags().GetBool("debug")
+
+	cfg, err := config.Load(cwd, dataDir, debug)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load configuration: %v", err)
+	}
+	if err := createDotZorkAgentDir(cfg.Options.DataDirectory); err != nil {
+		return nil, nil, err
+	}
+
+	conn, err := db.Connect(cmd.Context(), cfg.Options.DataDirectory)
+	if err != nil {
+		return nil, nil, err
+	}
+	q := db.New(conn)
+	svc := transcript.NewService(
+		q,
+		conn,
+		session.NewService(q, conn),
+		message.NewService(q),
+		history.NewService(q, conn),
+		cfg.WorkingDir(),
+	)
+	return svc, conn.Close, nil
+}
+
+func init() {
+	sessionExportCmd.Flags().String("format", "json", "导出格式：json 或 markdown")
+	sessionExportCmd.Flags().StringP("output", "o", "", "输出文件，默认输出到标准输出")
+
+	sessionCmd.AddCommand(sessionExportCmd, sessionImportCmd)
+	rootCmd.AddCommand(sessionCmd)
+}
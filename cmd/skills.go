@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+	"charm.land/lipgloss/v2/table"
+	"github.com/charmbracelet/crush/api/models"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/skills"
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+var skillsCmd = &cobra.Command{
+	Use:   "skills",
+	Short: "管理 Agent Skills",
+	Long: `列出、校验和创建 Agent Skills。
+Skill 是包含 SKILL.md 的目录，在配置的 options.skills_paths 和全局 skills 目录中查找，
+只有通过校验的 skill 会提供给 agent。`,
+	Example: `
+# 列出 agent 可以使用的 skill
+zorkagent skills list
+
+# 校验所有 skill，有未通过校验的 skill 时以非零状态退出
+zorkagent skills validate
+
+# 校验指定目录或 SKILL.md
+zorkagent skills validate ./skills/deploy-service
+
+# 在第一个 skills 目录中创建 skill
+zorkagent skills new deploy-service --description "Deploy a service to staging or production."
+  `,
+}
+
+var skillsListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "列出 agent 可以使用的 skill",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+
+		cfg, err := loadSkillsConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		var list []models.Skill
+		for _, result := range skills.Scan(cfg.SkillsDirs()) {
+			if result.Err == nil {
+				list = append(list, models.SkillFromResult(result))
+			}
+		}
+
+		if jsonOutput {
+			output := struct {
+				Skills []models.Skill `json:"skills"`
+			}{Skills: list}
+			if output.Skills == nil {
+				output.Skills = []models.Skill{}
+			}
+
+			data, err := json.Marshal(output)
+			if err != nil {
+				return err
+			}
+			cmd.Println(string(data))
+			return nil
+		}
+
+		if len(list) == 0 {
+			cmd.Println("No skills found.")
+			return nil
+		}
+
+		if term.IsTerminal(os.Stdout.Fd()) {
+			t := table.New().
+				Border(lipgloss.RoundedBorder()).
+				StyleFunc(func(row, col int) lipgloss.Style {
+					return lipgloss.NewStyle().Padding(0, 2)
+				}).
+				Headers("Name", "Description", "Path")
+
+			for _, s := range list {
+				t.Row(s.Name, truncateSkillDescription(s.Description), s.Path)
+			}
+			lipgloss.Println(t)
+			return nil
+		}
+
+		for _, s := range list {
+			cmd.Printf("%s\t%s\t%s\n", s.Name, s.Path, s.Description)
+		}
+		return nil
+	},
+}
+
+var skillsValidateCmd = &cobra.Command{
+	Use:   "validate [路径...]",
+	Short: "校验 skill",
+	Long:  "校验 skills 目录中的所有 SKILL.md，或指定的目录和 SKILL.md 文件。有未通过校验的 skill 时以非零状态退出。",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+
+		var results []skills.Result
+		if len(args) == 0 {
+			cfg, err := loadSkillsConfig(cmd)
+			if err != nil {
+				return err
+			}
+			results = skills.Scan(cfg.SkillsDirs())
+		} else {
+			for _, arg := range args {
+				info, err := os.Stat(arg)
+				if err != nil {
+					return err
+				}
+				if info.IsDir() {
+					results = append(results, skills.Scan([]string{arg})...)
+					continue
+				}
+				result := skills.Result{Path: arg}
+				if result.Skill, result.Err = skills.Parse(arg); result.Err != nil {
+					result.Skill = nil
+				} else {
+					result.Err = result.Skill.Validate()
+				}
+				results = append(results, result)
+			}
+		}
+
+		invalid := 0
+		list := make([]models.Skill, 0, len(results))
+		for _, result := range results {
+			if result.Err != nil {
+				invalid++
+			}
+			list = append(list, models.SkillFromResult(result))
+		}
+
+		if jsonOutput {
+			output := struct {
+				Skills []models.Skill `json:"skills"`
+			}{Skills: list}
+
+			data, err := json.Marshal(output)
+			if err != nil {
+				return err
+			}
+			cmd.Println(string(data))
+		} else {
+			if len(list) == 0 {
+				cmd.Println("No skills found.")
+			}
+			for _, s := range list {
+				if s.Valid {
+					cmd.Printf("ok    %s (%s)\n", s.File, s.Name)
+					continue
+				}
+				cmd.Printf("FAIL  %s\n", s.File)
+				for _, e := range s.Errors {
+					cmd.Printf("      %s\n", e)
+				}
+			}
+		}
+
+		if invalid > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d of %d skills failed validation", invalid, len(results))
+		}
+		return nil
+	},
+}
+
+var skillsNewCmd = &cobra.Command{
+	Use:   "new <名称>",
+	Short: "创建 skill",
+	Long:  "创建 <目录>/<名称>/SKILL.md，包含 frontmatter 和说明的模板。名称只能包含字母、数字和单个 -。",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, _ := cmd.Flags().GetString("dir")
+		description, _ := cmd.Flags().GetString("description")
+
+		if dir == "" {
+			cfg, err := loadSkillsConfig(cmd)
+			if err != nil {
+				return err
+			}
+			dirs := cfg.SkillsDirs()
+			if len(dirs) == 0 {
+				return errors.New("no skills directory configured, use --dir")
+			}
+			dir = dirs[0]
+		}
+		dir, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+
+		path, err := skills.Create(dir, args[0], description)
+		if err != nil {
+			return err
+		}
+		cmd.Println(path)
+		return nil
+	},
+}
+
+// loadSkillsConfig 加载用于查找 skill 的配置
+func loadSkillsConfig(cmd *cobra.Command) (*config.Config, error) {
+	cwd, err := ResolveCwd(cmd)
+	if err != nil {
+		return nil, err
+	}
+	dataDir, _ := cmd.Flags().GetString("data-dir")
+
+	cfg, err := config.Load(cwd, dataDir, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %v", err)
+	}
+	return cfg, nil
+}
+
+// truncateSkillDescription 截断表格中过长的描述
+func truncateSkillDescription(description string) string {
+	const maxLen = 60
+	description = strings.Join(strings.Fields(description), " ")
+	if runes := []rune(description); len(runes) > maxLen {
+		return string(runes[:maxLen-1]) + "…"
+	}
+	return description
+}
+
+func init() {
+	skillsListCmd.Flags().Bool("json", false, "以 JSON 格式输出")
+	skillsValidateCmd.Flags().Bool("json", false, "以 JSON 格式输出")
+	skillsNewCmd.Flags().String("dir", "", "创建 skill 的目录，默认为第一个 skills 目录")
+	skillsNewCmd.Flags().String("description", "Describe what this skill does and when the agent should use it.", "skill 的描述")
+
+	skillsCmd.AddCommand(skillsListCmd, skillsValidateCmd, skillsNewCmd)
+	rootCmd.AddCommand(skillsCmd)
+}
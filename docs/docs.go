@@ -9,25 +9,31 @@ const docTemplate = `{
     "info": {
         "description": "{{escape .Description}}",
         "title": "{{.Title}}",
-        "contact": {},
+        "termsOfService": "http://swagger.io/terms/",
+        "contact": {
+            "name": "API Support",
+            "url": "http://www.swagger.io/support",
+            "email": "support@swagger.io"
+        },
+        "license": {
+            "name": "Apache 2.0",
+            "url": "http://www.apache.org/licenses/LICENSE-2.0.html"
+        },
         "version": "{{.Version}}"
     },
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/event": {
+        "/audit": {
             "get": {
-                "description": "订阅项目的实时事件流",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "返回项目中工具执行和权限决定的审计日志，按时间倒序，可通过 limit/offset 分页，total 为符合条件的条目总数。\n工具执行（kind 为 tool）记录参数、状态、退出码和起止时间；权限决定（kind 为 permission）记录结果（granted、granted_session 或 denied）和决定方：\ntui、API Key 或 Token 的标识（如 api_key:ci）、未启用认证时的 api，或自动决定的 auto，自动决定的原因记录在 reason 中。\n通过 API 发起的操作在 actor 中记录调用方（格式同 decider），包括 agent 运行中的工具执行，以及直接通过 API 执行的 shell 命令（bash）、文件修改（file_edit）和 MCP 工具调用。",
                 "produces": [
-                    "text/event-stream"
+                    "application/json"
                 ],
                 "tags": [
-                    "Event"
+                    "Audit"
                 ],
-                "summary": "订阅服务器事件",
+                "summary": "查询审计日志",
                 "parameters": [
                     {
                         "type": "string",
@@ -35,13 +41,71 @@ const docTemplate = `{
                         "name": "directory",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "按会话 ID 过滤",
+                        "name": "sessionID",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "tool",
+                            "permission"
+                        ],
+                        "type": "string",
+                        "description": "条目类型",
+                        "name": "kind",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "工具名称",
+                        "name": "tool",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "权限的决定方",
+                        "name": "decider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "发起操作的调用方",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "开始日期（YYYY-MM-DD，包含）",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "结束日期（YYYY-MM-DD，包含）",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "单页条数，默认 100，最大 1000",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "跳过的条数",
+                        "name": "offset",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Event stream",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SSEEvent"
+                            "$ref": "#/definitions/models.AuditResponse"
                         }
                     },
                     "400": {
@@ -57,23 +121,27 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
             }
         },
-        "/file": {
+        "/command": {
             "get": {
-                "description": "列出指定目录的文件和子目录",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "返回用户命令（~/.config/crush/commands 和 ~/.crush/commands）、项目命令（数据目录下的 commands）和已连接 MCP 服务器提供的 prompt，以及它们的参数。\nMCP prompt 的名称为 \"服务器:prompt\"，列表只包含已连接的 MCP 服务器。",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "File"
+                    "Session"
                 ],
-                "summary": "列出目录内容",
+                "summary": "获取命令列表",
                 "parameters": [
                     {
                         "type": "string",
@@ -81,25 +149,66 @@ const docTemplate = `{
                         "name": "directory",
                         "in": "query",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Command"
+                            }
+                        }
                     },
-                    {
-                        "type": "string",
-                        "description": "子目录路径（相对于项目根目录）",
-                        "name": "path",
-                        "in": "query"
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/config/providers": {
+            "get": {
+                "description": "返回 catwalk 已知的 provider 和配置文件中添加的 provider，包括模型的上下文窗口、价格、附件和推理支持，以及 provider 是否已配置。\ndefault 为每个 provider 的默认模型：当前选择的大模型所属的 provider 为该模型，其余为 catwalk 的默认大模型或第一个模型。",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Config"
+                ],
+                "summary": "获取 provider 和模型目录",
+                "parameters": [
                     {
-                        "type": "boolean",
-                        "description": "是否递归列出（默认 false）",
-                        "name": "recursive",
-                        "in": "query"
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.FileListResponse"
+                            "$ref": "#/definitions/models.ProvidersResponse"
                         }
                     },
                     "400": {
@@ -115,13 +224,20 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
             }
         },
-        "/file/content": {
-            "get": {
-                "description": "读取指定文件的内容",
+        "/config/providers/{id}/test": {
+            "post": {
+                "description": "使用 provider 的配置（或请求体中的 api_key、base_url）请求其模型列表等接口，检查凭据和地址是否可用，超时时间 5 秒。\n未配置的 catwalk provider 也可以测试，用于保存 API Key 之前的校验。连接失败时仍返回 200，ok 为 false。\n支持 openai、openai-compat、openrouter、anthropic 和 google 类型，其他类型返回 400 UNSUPPORTED_PROVIDER_TYPE。",
                 "consumes": [
                     "application/json"
                 ],
@@ -129,9 +245,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "File"
+                    "Config"
                 ],
-                "summary": "读取文件内容",
+                "summary": "测试 provider 连接",
                 "parameters": [
                     {
                         "type": "string",
@@ -142,29 +258,25 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "文件路径（相对于项目根目录）",
-                        "name": "path",
-                        "in": "query",
+                        "description": "Provider ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "起始行号（可选）",
-                        "name": "offset",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "读取行数限制（可选）",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "要测试的凭据",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProviderTestRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.FileContentResponse"
+                            "$ref": "#/definitions/models.ProviderTestResponse"
                         }
                     },
                     "400": {
@@ -180,23 +292,30 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
             }
         },
-        "/file/status": {
+        "/event": {
             "get": {
-                "description": "获取项目的 Git 状态信息",
+                "description": "订阅项目的实时事件流。每个事件带有递增的 id，断线重连时通过 Last-Event-ID 请求头补发期间的事件；\n缺失的事件已超出缓冲范围时发送 reset 事件，客户端应重新获取会话和消息",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "text/event-stream"
                 ],
                 "tags": [
-                    "File"
+                    "Event"
                 ],
-                "summary": "获取 Git 状态",
+                "summary": "订阅服务器事件",
                 "parameters": [
                     {
                         "type": "string",
@@ -204,13 +323,19 @@ const docTemplate = `{
                         "name": "directory",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "最后收到的事件 ID",
+                        "name": "Last-Event-ID",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Event stream",
                         "schema": {
-                            "$ref": "#/definitions/models.GitStatusResponse"
+                            "$ref": "#/definitions/models.SSEEvent"
                         }
                     },
                     "400": {
@@ -230,9 +355,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/find": {
+        "/file": {
             "get": {
-                "description": "在项目中搜索文本内容，支持正则表达式",
+                "description": "列出指定目录的文件和子目录",
                 "consumes": [
                     "application/json"
                 ],
@@ -242,7 +367,7 @@ const docTemplate = `{
                 "tags": [
                     "File"
                 ],
-                "summary": "搜索文本内容",
+                "summary": "列出目录内容",
                 "parameters": [
                     {
                         "type": "string",
@@ -253,21 +378,14 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "搜索内容",
-                        "name": "query",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "boolean",
-                        "description": "是否使用正则表达式（默认 false）",
-                        "name": "regex",
+                        "description": "子目录路径（相对于项目根目录）",
+                        "name": "path",
                         "in": "query"
                     },
                     {
                         "type": "boolean",
-                        "description": "是否忽略大小写（默认 false）",
-                        "name": "case_insensitive",
+                        "description": "是否递归列出（默认 false）",
+                        "name": "recursive",
                         "in": "query"
                     }
                 ],
@@ -275,7 +393,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SearchResponse"
+                            "$ref": "#/definitions/models.FileListResponse"
                         }
                     },
                     "400": {
@@ -293,21 +411,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/find/file": {
-            "get": {
-                "description": "使用通配符模式搜索文件",
-                "consumes": [
-                    "application/json"
-                ],
+            },
+            "delete": {
+                "description": "删除项目内的文件，删除前的内容和空内容记录到会话的文件历史，可通过历史恢复。通知 LSP 并发送 file.edited 事件。",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "File"
                 ],
-                "summary": "搜索文件名",
+                "summary": "删除文件",
                 "parameters": [
                     {
                         "type": "string",
@@ -318,8 +431,15 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "文件名模式（支持通配符）",
-                        "name": "pattern",
+                        "description": "文件路径（相对于项目根目录）",
+                        "name": "path",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "记录文件历史版本的会话",
+                        "name": "session_id",
                         "in": "query",
                         "required": true
                     }
@@ -328,7 +448,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.FileListResponse"
+                            "$ref": "#/definitions/models.FileEditResponse"
                         }
                     },
                     "400": {
@@ -344,29 +464,6 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
-                    }
-                }
-            }
-        },
-        "/global/dispose": {
-            "post": {
-                "description": "释放所有项目的 app 实例以释放资源",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Global"
-                ],
-                "summary": "释放所有项目",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.DisposeAllResponse"
-                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
@@ -378,9 +475,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/instance/dispose": {
-            "post": {
-                "description": "释放指定项目的 app 实例以释放资源",
+        "/file/content": {
+            "get": {
+                "description": "读取指定文件的内容",
                 "consumes": [
                     "application/json"
                 ],
@@ -388,9 +485,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "File"
                 ],
-                "summary": "释放实例",
+                "summary": "读取文件内容",
                 "parameters": [
                     {
                         "type": "string",
@@ -398,13 +495,32 @@ const docTemplate = `{
                         "name": "directory",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "文件路径（相对于项目根目录）",
+                        "name": "path",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "起始行号（可选）",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "读取行数限制（可选）",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.DisposeProjectResponse"
+                            "$ref": "#/definitions/models.FileContentResponse"
                         }
                     },
                     "400": {
@@ -420,20 +536,11 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
                     }
                 }
-            }
-        },
-        "/lsp": {
-            "get": {
-                "description": "获取所有 LSP 服务器的状态",
+            },
+            "put": {
+                "description": "写入项目内的文件，不存在时创建。修改前后的内容记录到会话的文件历史，通知 LSP 并发送 file.edited 事件。",
                 "consumes": [
                     "application/json"
                 ],
@@ -441,9 +548,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "LSP"
+                    "File"
                 ],
-                "summary": "获取 LSP 状态",
+                "summary": "写入文件",
                 "parameters": [
                     {
                         "type": "string",
@@ -451,16 +558,22 @@ const docTemplate = `{
                         "name": "directory",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "description": "写入请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.FileWriteRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.LSPStatus"
-                            }
+                            "$ref": "#/definitions/models.FileEditResponse"
                         }
                     },
                     "400": {
@@ -476,13 +589,20 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
             }
         },
-        "/mcp": {
-            "get": {
-                "description": "获取所有 Model Context Protocol (MCP) 服务器的状态",
+        "/file/patch": {
+            "post": {
+                "description": "将单个文件的 unified diff 应用到项目内的文件，任一 hunk 无法应用时不修改文件并返回 409。修改前后的内容记录到会话的文件历史，通知 LSP 并发送 file.edited 事件。",
                 "consumes": [
                     "application/json"
                 ],
@@ -490,9 +610,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "MCP"
+                    "File"
                 ],
-                "summary": "获取 MCP 状态",
+                "summary": "应用 unified diff",
                 "parameters": [
                     {
                         "type": "string",
@@ -500,16 +620,22 @@ const docTemplate = `{
                         "name": "directory",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "description": "补丁请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.FilePatchRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "$ref": "#/definitions/models.MCPStatus"
-                            }
+                            "$ref": "#/definitions/models.FileEditResponse"
                         }
                     },
                     "400": {
@@ -525,13 +651,27 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
             }
         },
-        "/message/{id}": {
-            "get": {
-                "description": "获取指定消息的详细信息",
+        "/file/rename": {
+            "post": {
+                "description": "重命名或移动项目内的文件，目标已存在时返回 409。原路径记录为空内容的版本，新路径记录文件内容，通知 LSP 并发送 file.edited 事件。",
                 "consumes": [
                     "application/json"
                 ],
@@ -539,9 +679,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Message"
+                    "File"
                 ],
-                "summary": "获取消息详情",
+                "summary": "重命名文件",
                 "parameters": [
                     {
                         "type": "string",
@@ -551,18 +691,20 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "消息ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "重命名请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.FileRenameRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.MessageDetailResponse"
+                            "$ref": "#/definitions/models.FileEditResponse"
                         }
                     },
                     "400": {
@@ -578,13 +720,27 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
             }
         },
-        "/path": {
+        "/file/status": {
             "get": {
-                "description": "获取当前工作目录和相关路径信息",
+                "description": "获取项目的 Git 状态信息",
                 "consumes": [
                     "application/json"
                 ],
@@ -592,9 +748,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "File"
                 ],
-                "summary": "获取路径信息",
+                "summary": "获取 Git 状态",
                 "parameters": [
                     {
                         "type": "string",
@@ -608,8 +764,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.GitStatusResponse"
                         }
                     },
                     "400": {
@@ -629,9 +784,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/project": {
+        "/find": {
             "get": {
-                "description": "获取所有已注册的项目",
+                "description": "在项目中搜索文本内容，支持正则表达式",
                 "consumes": [
                     "application/json"
                 ],
@@ -639,53 +794,109 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "File"
+                ],
+                "summary": "搜索文本内容",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "搜索内容",
+                        "name": "query",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否使用正则表达式（默认 false）",
+                        "name": "regex",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否忽略大小写（默认 false）",
+                        "name": "case_insensitive",
+                        "in": "query"
+                    }
                 ],
-                "summary": "获取项目列表",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.ProjectsResponse"
+                            "$ref": "#/definitions/models.SearchResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "注册一个新项目",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/find/definition": {
+            "get": {
+                "description": "通过处理该文件的 LSP 服务器查找指定位置符号的声明，合并所有服务器的结果。没有就绪的 LSP 服务器时返回空数组。",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "File"
                 ],
-                "summary": "创建项目",
+                "summary": "查找定义",
                 "parameters": [
                     {
-                        "description": "创建项目请求",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.CreateProjectRequest"
-                        }
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "文件路径（相对于项目根目录）",
+                        "name": "path",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "行号（从 1 开始）",
+                        "name": "line",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "列号（从 1 开始，以 UTF-16 码元计）",
+                        "name": "column",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.CreateProjectResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Symbol"
+                            }
                         }
                     },
                     "400": {
@@ -695,6 +906,13 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -705,9 +923,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/project/config": {
+        "/find/file": {
             "get": {
-                "description": "获取指定项目的配置信息",
+                "description": "使用通配符模式搜索文件",
                 "consumes": [
                     "application/json"
                 ],
@@ -715,9 +933,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Config"
+                    "File"
                 ],
-                "summary": "获取项目配置",
+                "summary": "搜索文件名",
                 "parameters": [
                     {
                         "type": "string",
@@ -725,13 +943,20 @@ const docTemplate = `{
                         "name": "directory",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "文件名模式（支持通配符）",
+                        "name": "pattern",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.ConfigResponse"
+                            "$ref": "#/definitions/models.FileListResponse"
                         }
                     },
                     "400": {
@@ -751,24 +976,49 @@ const docTemplate = `{
                 }
             }
         },
-        "/project/current": {
+        "/find/references": {
             "get": {
-                "description": "获取当前活跃的项目。提供 directory 参数时返回该目录的项目，否则返回最近访问的项目",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "通过处理该文件的 LSP 服务器查找指定位置符号的所有引用，合并所有服务器的结果。没有就绪的 LSP 服务器时返回空数组。",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "File"
                 ],
-                "summary": "获取当前项目",
+                "summary": "查找引用",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "项目路径（可选，不传则返回最近访问的项目）",
+                        "description": "项目路径",
                         "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "文件路径（相对于项目根目录）",
+                        "name": "path",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "行号（从 1 开始）",
+                        "name": "line",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "列号（从 1 开始，以 UTF-16 码元计）",
+                        "name": "column",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否包含声明，默认 true",
+                        "name": "include_declaration",
                         "in": "query"
                     }
                 ],
@@ -776,18 +1026,28 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.CurrentProjectResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Location"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "404": {
-                        "description": "没有可用的项目",
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "500": {
-                        "description": "服务器内部错误",
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -796,19 +1056,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/project/permissions": {
+        "/find/symbol": {
             "get": {
-                "description": "获取指定项目的权限请求列表",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "通过项目中已就绪的 LSP 服务器搜索名称包含 query 的符号（不区分大小写），合并所有服务器的结果，完全匹配的符号排在前面。没有就绪的 LSP 服务器时返回空数组。",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Permission"
+                    "File"
                 ],
-                "summary": "获取权限请求列表",
+                "summary": "搜索工作区符号",
                 "parameters": [
                     {
                         "type": "string",
@@ -816,13 +1073,29 @@ const docTemplate = `{
                         "name": "directory",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "符号名称",
+                        "name": "query",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "最多返回的符号数量（1-200，默认 50）",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.PermissionsResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Symbol"
+                            }
                         }
                     },
                     "400": {
@@ -838,13 +1111,20 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
             }
         },
-        "/project/permissions/{id}/reply": {
+        "/global/dispose": {
             "post": {
-                "description": "批准或拒绝特定的权限请求",
+                "description": "释放所有项目的 app 实例以释放资源",
                 "consumes": [
                     "application/json"
                 ],
@@ -852,42 +1132,67 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Permission"
+                    "Global"
                 ],
-                "summary": "回复权限请求",
+                "summary": "释放所有项目",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DisposeAllResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/global/stats": {
+            "get": {
+                "description": "合计所有已注册项目（受限的 API Key 只包括其授权的项目）的统计，格式同 /stats，另外返回每个项目的合计。\n未加载的项目以只读方式读取其数据库，不会创建 app 实例，也不会迁移数据库；还没有数据库的项目不计入。读取失败或数据库版本低于当前程序的项目在 projects 中返回 error，不影响其他项目。",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stats"
+                ],
+                "summary": "获取所有项目的用量和费用统计",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "项目路径",
-                        "name": "directory",
-                        "in": "query",
-                        "required": true
+                        "description": "开始日期（YYYY-MM-DD，包含）",
+                        "name": "from",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "权限请求ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "结束日期（YYYY-MM-DD，包含）",
+                        "name": "to",
+                        "in": "query"
                     },
                     {
-                        "description": "权限回复请求",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.PermissionReplyRequest"
-                        }
+                        "type": "string",
+                        "description": "模型 ID",
+                        "name": "model",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "返回的费用最高的会话数，默认 20，最大 1000",
+                        "name": "session_limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.GlobalStatsResponse"
                         }
                     },
                     "400": {
@@ -897,8 +1202,8 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -907,9 +1212,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/session": {
-            "get": {
-                "description": "获取指定项目的所有会话",
+        "/instance/dispose": {
+            "post": {
+                "description": "释放指定项目的 app 实例以释放资源",
                 "consumes": [
                     "application/json"
                 ],
@@ -917,9 +1222,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Session"
+                    "Project"
                 ],
-                "summary": "获取会话列表",
+                "summary": "释放实例",
                 "parameters": [
                     {
                         "type": "string",
@@ -933,7 +1238,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SessionsResponse"
+                            "$ref": "#/definitions/models.DisposeProjectResponse"
                         }
                     },
                     "400": {
@@ -949,21 +1254,27 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
-            },
-            "post": {
-                "description": "在指定项目中创建新会话",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/job": {
+            "get": {
+                "description": "返回 agent 通过 bash 工具的 run_in_background 在项目目录中启动的后台任务，按启动时间排序。\n已结束的任务在被 agent 或 API 终止、或结束 8 小时后不再返回。",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Session"
+                    "Job"
                 ],
-                "summary": "创建会话",
+                "summary": "获取后台任务列表",
                 "parameters": [
                     {
                         "type": "string",
@@ -971,22 +1282,16 @@ const docTemplate = `{
                         "name": "directory",
                         "in": "query",
                         "required": true
-                    },
-                    {
-                        "description": "创建会话请求",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.CreateSessionRequest"
-                        }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.CreateSessionResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Job"
+                            }
                         }
                     },
                     "400": {
@@ -1002,23 +1307,26 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
             }
         },
-        "/session/status": {
+        "/job/{id}": {
             "get": {
-                "description": "获取项目的会话状态信息",
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Session"
+                    "Job"
                 ],
-                "summary": "获取会话状态",
+                "summary": "获取后台任务",
                 "parameters": [
                     {
                         "type": "string",
@@ -1026,14 +1334,20 @@ const docTemplate = `{
                         "name": "directory",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "任务 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.Job"
                         }
                     },
                     "400": {
@@ -1049,23 +1363,27 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
             }
         },
-        "/session/{id}": {
-            "get": {
-                "description": "获取指定会话的详细信息",
-                "consumes": [
-                    "application/json"
-                ],
+        "/job/{id}/kill": {
+            "post": {
+                "description": "终止后台任务并停止跟踪，与 agent 的 job_kill 工具相同。返回任务的最终状态，终止后任务不再出现在列表中。",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Session"
+                    "Job"
                 ],
-                "summary": "获取会话详情",
+                "summary": "终止后台任务",
                 "parameters": [
                     {
                         "type": "string",
@@ -1076,7 +1394,7 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "会话ID",
+                        "description": "任务 ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1086,7 +1404,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SessionDetailResponse"
+                            "$ref": "#/definitions/models.Job"
                         }
                     },
                     "400": {
@@ -1102,21 +1420,27 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
-            },
-            "put": {
-                "description": "更新指定会话的信息",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/job/{id}/output": {
+            "get": {
+                "description": "返回 stdout 和 stderr 在指定偏移量（字节）之后的输出，以及下次读取的偏移量。轮询时传入上次返回的偏移量即可只获取新的输出。",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Session"
+                    "Job"
                 ],
-                "summary": "更新会话",
+                "summary": "获取后台任务的输出",
                 "parameters": [
                     {
                         "type": "string",
@@ -1127,26 +1451,29 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "会话ID",
+                        "description": "任务 ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "更新会话请求",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UpdateSessionRequest"
-                        }
+                        "type": "integer",
+                        "description": "stdout 偏移量，默认 0",
+                        "name": "stdout_offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "stderr 偏移量，默认 0",
+                        "name": "stderr_offset",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateSessionResponse"
+                            "$ref": "#/definitions/models.JobOutput"
                         }
                     },
                     "400": {
@@ -1162,21 +1489,27 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
-            },
-            "delete": {
-                "description": "删除指定的会话及其所有消息",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/job/{id}/stream": {
+            "get": {
+                "description": "以 SSE 发送指定偏移量之后的输出，有新的输出时发送 job.output 事件（properties 格式同 /job/{id}/output），\n任务结束并发送完所有输出后发送 job.finished 事件（properties 为任务）并关闭连接。断线后可以传入最后收到的偏移量继续读取。",
                 "produces": [
-                    "application/json"
+                    "text/event-stream"
                 ],
                 "tags": [
-                    "Session"
+                    "Job"
                 ],
-                "summary": "删除会话",
+                "summary": "流式获取后台任务的输出",
                 "parameters": [
                     {
                         "type": "string",
@@ -1187,20 +1520,29 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "会话ID",
+                        "description": "任务 ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "stdout 偏移量，默认 0",
+                        "name": "stdout_offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "stderr 偏移量，默认 0",
+                        "name": "stderr_offset",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Event stream",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.SSEEvent"
                         }
                     },
                     "400": {
@@ -1220,9 +1562,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/session/{id}/abort": {
-            "post": {
-                "description": "中止指定会话的 AI 处理。注意：当前实现会取消该项目下所有会话的 AI 处理。",
+        "/lsp": {
+            "get": {
+                "description": "获取所有 LSP 服务器的状态",
                 "consumes": [
                     "application/json"
                 ],
@@ -1230,9 +1572,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Session"
+                    "LSP"
                 ],
-                "summary": "中止会话",
+                "summary": "获取 LSP 状态",
                 "parameters": [
                     {
                         "type": "string",
@@ -1240,23 +1582,65 @@ const docTemplate = `{
                         "name": "directory",
                         "in": "query",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LSPStatus"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/lsp/diagnostics": {
+            "get": {
+                "description": "返回项目中已就绪的 LSP 服务器报告的诊断，与 agent 通过 diagnostics 工具看到的一致。\n指定 path 时只返回该文件的诊断，文件未打开时会先在处理它的 LSP 服务器中打开；不指定时返回 LSP 服务器已报告的所有诊断。",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "LSP"
+                ],
+                "summary": "获取 LSP 诊断",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "会话ID",
-                        "name": "id",
-                        "in": "path",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "文件路径（相对于项目根目录）",
+                        "name": "path",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.DiagnosticsResponse"
                         }
                     },
                     "400": {
@@ -1272,53 +1656,48 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
             }
         },
-        "/session/{sessionID}/prompt": {
+        "/lsp/{name}/restart": {
             "post": {
-                "description": "Create and send a new message to a session using Opencode SDK compatible API",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "关闭 LSP 服务器并按相同配置重新启动，重新打开之前打开的文件，用于服务器崩溃或卡住时恢复，无需释放项目实例。等待服务器就绪后返回状态。\n配置文件中的 LSP 服务器未运行时（例如启动失败）在后台启动，返回的 status 为 starting，可以通过 SSE 的 lsp.server.state_changed 事件获取结果。",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Session"
+                    "LSP"
                 ],
-                "summary": "Send message (Opencode compatible)",
+                "summary": "重启 LSP 服务器",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Project path",
+                        "description": "项目路径",
                         "name": "directory",
                         "in": "query",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Session ID",
-                        "name": "sessionID",
+                        "description": "LSP 服务器名称",
+                        "name": "name",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Prompt request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.PromptRequest"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.PromptResponse"
+                            "$ref": "#/definitions/models.LSPStatus"
                         }
                     },
                     "400": {
@@ -1334,13 +1713,27 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
             }
         },
-        "/session/{session_id}/message": {
+        "/mcp": {
             "get": {
-                "description": "获取指定会话的所有消息",
+                "description": "获取项目配置的 Model Context Protocol (MCP) 服务器的状态：disabled、starting、connected 或 failed（message 为原因），以及工具和 prompt 数",
                 "consumes": [
                     "application/json"
                 ],
@@ -1348,9 +1741,55 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Message"
+                    "MCP"
                 ],
-                "summary": "获取消息列表",
+                "summary": "获取 MCP 状态",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/models.MCPStatus"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/mcp/{name}/disable": {
+            "post": {
+                "description": "在运行时断开并禁用 MCP 服务器，所有已加载项目的 agent 不再使用它的工具。修改不写入配置文件，持久禁用请使用 PUT /project/config/mcp/{name}。调用方不能是受项目范围限制的 API Key。",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MCP"
+                ],
+                "summary": "禁用 MCP 服务器",
                 "parameters": [
                     {
                         "type": "string",
@@ -1361,8 +1800,8 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "会话ID",
-                        "name": "session_id",
+                        "description": "MCP 服务器名称",
+                        "name": "name",
                         "in": "path",
                         "required": true
                     }
@@ -1371,7 +1810,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.MessagesResponse"
+                            "$ref": "#/definitions/models.MCPServerStatus"
                         }
                     },
                     "400": {
@@ -1381,6 +1820,13 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -1391,19 +1837,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/system-prompt": {
-            "get": {
-                "description": "获取指定项目当前的系统提示词内容",
-                "consumes": [
-                    "application/json"
-                ],
+        "/mcp/{name}/enable": {
+            "post": {
+                "description": "在运行时启用 MCP 服务器并连接，对所有已加载的项目生效。修改不写入配置文件，重新加载配置后恢复为配置文件中的设置。调用方不能是受项目范围限制的 API Key。",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Prompt"
+                    "MCP"
                 ],
-                "summary": "获取系统提示词",
+                "summary": "启用 MCP 服务器",
                 "parameters": [
                     {
                         "type": "string",
@@ -1411,13 +1854,20 @@ const docTemplate = `{
                         "name": "directory",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "MCP 服务器名称",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.GetSystemPromptResponse"
+                            "$ref": "#/definitions/models.MCPServerStatus"
                         }
                     },
                     "400": {
@@ -1427,34 +1877,33 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "动态修改指定项目的系统提示词，无需重启服务。更新后立即对后续对话生效。",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/mcp/{name}/prompts": {
+            "get": {
+                "description": "从 MCP 服务器获取 prompt 列表。prompt 可以通过 /session/{id}/command 以 \"服务器:prompt\" 执行。",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Prompt"
+                    "MCP"
                 ],
-                "summary": "更新系统提示词",
+                "summary": "获取 MCP prompt",
                 "parameters": [
                     {
                         "type": "string",
@@ -1464,20 +1913,21 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "系统提示词",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UpdateSystemPromptRequest"
-                        }
+                        "type": "string",
+                        "description": "MCP 服务器名称",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateSystemPromptResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.MCPPrompt"
+                            }
                         }
                     },
                     "400": {
@@ -1494,8 +1944,15 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1503,110 +1960,4275 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.AssistantMessage": {
-            "type": "object",
-            "properties": {
-                "agent": {
-                    "type": "string"
-                },
-                "cost": {
-                    "type": "number"
-                },
-                "finish": {
+        },
+        "/mcp/{name}/resources": {
+            "get": {
+                "description": "从 MCP 服务器获取资源列表，不支持资源的服务器返回空列表。",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MCP"
+                ],
+                "summary": "获取 MCP 资源",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "MCP 服务器名称",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.MCPResource"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/mcp/{name}/restart": {
+            "post": {
+                "description": "关闭 MCP 服务器的连接（stdio 服务器会重启进程）并按当前配置重新连接，重新加载工具和 prompt，用于服务器崩溃或卡住时恢复，无需释放项目实例。\nMCP 客户端由所有项目共用，所有已加载项目的 agent 工具都会更新，调用方不能是受项目范围限制的 API Key。连接完成后返回状态，连接失败时 status 为 failed。",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MCP"
+                ],
+                "summary": "重连 MCP 服务器",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "MCP 服务器名称",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MCPServerStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/mcp/{name}/tools": {
+            "get": {
+                "description": "从 MCP 服务器获取工具列表及其参数的 JSON Schema，包括配置的 disabled_tools 中的工具（disabled 为 true）。\n服务器未连接时返回 409 MCP_NOT_CONNECTED，请求失败时返回 502 MCP_REQUEST_FAILED。",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MCP"
+                ],
+                "summary": "获取 MCP 工具",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "MCP 服务器名称",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.MCPTool"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/mcp/{name}/tools/{tool}/call": {
+            "post": {
+                "description": "不经过 agent 和权限确认直接调用 MCP 工具，用于调试。disabled_tools 中的工具也可以调用。调用方不能是受项目范围限制的 API Key。\n工具返回的错误内容在 content 中，调用本身失败时返回 502 MCP_REQUEST_FAILED。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MCP"
+                ],
+                "summary": "调用 MCP 工具",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "MCP 服务器名称",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "工具名称",
+                        "name": "tool",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "工具参数",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.MCPToolCallRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MCPToolCallResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/message/{id}": {
+            "get": {
+                "description": "获取指定消息的详细信息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Message"
+                ],
+                "summary": "获取消息详情",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "消息ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageDetailResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/metrics": {
+            "get": {
+                "description": "以 Prometheus 文本格式输出 HTTP 请求、app 实例、agent 运行、token 用量与费用、工具调用以及 MCP/LSP 客户端状态等指标\n指标包含所有项目的路径和活动，受项目范围限制的 API Key 无权访问",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Global"
+                ],
+                "summary": "获取服务器指标",
+                "responses": {
+                    "200": {
+                        "description": "Prometheus metrics",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/path": {
+            "get": {
+                "description": "获取当前工作目录和相关路径信息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Project"
+                ],
+                "summary": "获取路径信息",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/project": {
+            "get": {
+                "description": "获取所有已注册的项目",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Project"
+                ],
+                "summary": "获取项目列表",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProjectsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "注册一个新项目",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Project"
+                ],
+                "summary": "创建项目",
+                "parameters": [
+                    {
+                        "description": "创建项目请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateProjectRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateProjectResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/project/config": {
+            "get": {
+                "description": "获取指定项目的配置信息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Config"
+                ],
+                "summary": "获取项目配置",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ConfigResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/project/config/{section}": {
+            "get": {
+                "description": "获取项目当前生效的 providers、models、mcp、lsp、options 或 permissions 配置，密钥已脱敏",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Config"
+                ],
+                "summary": "获取配置段",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "providers",
+                            "models",
+                            "mcp",
+                            "lsp",
+                            "options",
+                            "permissions"
+                        ],
+                        "type": "string",
+                        "description": "配置段",
+                        "name": "section",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ConfigSectionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "按字段修改 options 或 permissions 配置段：请求体中的每个字段写入数据目录下的 crush.json，值为 null 时删除该字段。\n修改前按 schema.json 校验，修改后重新加载配置并更新 Agent，不会重启项目实例。部分选项（如 data_directory）需要重启后生效。\n配置文件为所有项目共用，受项目范围限制的 API Key 不能修改。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Config"
+                ],
+                "summary": "修改配置段字段",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "options",
+                            "permissions"
+                        ],
+                        "type": "string",
+                        "description": "配置段",
+                        "name": "section",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "要修改的字段",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ConfigSectionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/project/config/{section}/{name}": {
+            "put": {
+                "description": "将 providers、models、mcp 或 lsp 配置段中名为 name 的条目写入数据目录下的 crush.json，整体替换该文件中的原有条目。\nmodels 的 name 为 large 或 small，模型必须存在。provider 的 api_key 通过 SetProviderAPIKey 保存，请求体中的 oauth 会被忽略。\n值为 \"****\" 开头的脱敏密钥表示保留原值。修改前按 schema.json 校验，修改后重新加载配置，更新 Agent 模型、重连变更的 MCP 服务器、重启变更的 LSP 服务器，不会重启项目实例。\n配置文件为所有项目共用，受项目范围限制的 API Key 不能修改。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Config"
+                ],
+                "summary": "添加或替换配置条目",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "providers",
+                            "models",
+                            "mcp",
+                            "lsp"
+                        ],
+                        "type": "string",
+                        "description": "配置段",
+                        "name": "section",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "条目名称",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "条目内容",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ConfigSectionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "从数据目录下的 crush.json 删除 providers、models、mcp 或 lsp 配置段中名为 name 的条目，随后重新加载配置并更新受影响的子系统。\n只定义在其他配置文件（如项目的 crush.json）中的条目不能删除，返回 409。删除 models 条目后恢复默认模型。",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Config"
+                ],
+                "summary": "删除配置条目",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "providers",
+                            "models",
+                            "mcp",
+                            "lsp"
+                        ],
+                        "type": "string",
+                        "description": "配置段",
+                        "name": "section",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "条目名称",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ConfigSectionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/project/current": {
+            "get": {
+                "description": "获取当前活跃的项目。提供 directory 参数时返回该目录的项目，否则返回最近访问的项目",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Project"
+                ],
+                "summary": "获取当前项目",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径（可选，不传则返回最近访问的项目）",
+                        "name": "directory",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.CurrentProjectResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "没有可用的项目",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/project/permissions": {
+            "get": {
+                "description": "获取指定项目中等待回复的权限请求列表",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Permission"
+                ],
+                "summary": "获取权限请求列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "按会话 ID 过滤",
+                        "name": "sessionID",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PermissionsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/project/permissions/{requestID}/reply": {
+            "post": {
+                "description": "批准或拒绝特定的权限请求",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Permission"
+                ],
+                "summary": "回复权限请求",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "权限请求ID",
+                        "name": "requestID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "权限回复请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PermissionReplyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/run": {
+            "get": {
+                "description": "获取项目中正在进行和最近结束的异步 prompt 运行，可用于断线后重新找回运行",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Run"
+                ],
+                "summary": "获取运行列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "按会话 ID 过滤",
+                        "name": "sessionID",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RunsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/run/{id}": {
+            "get": {
+                "description": "获取异步 prompt 运行的状态、部分输出和最终消息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Run"
+                ],
+                "summary": "获取运行状态",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "运行 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RunResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "取消正在进行的异步 prompt 运行。已结束的运行直接返回当前状态。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Run"
+                ],
+                "summary": "取消运行",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "运行 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RunResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/session": {
+            "get": {
+                "description": "获取指定项目的会话，可通过 limit/offset 分页，total 为会话总数",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session"
+                ],
+                "summary": "获取会话列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "单页条数，最大 1000，不指定时返回全部",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "跳过的条数",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SessionsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "在指定项目中创建新会话。permission_mode 决定工具权限请求的处理方式：auto 自动批准，ask 通过 permission.updated 事件等待回复（超时拒绝），deny-dangerous 拒绝执行命令、下载、MCP 工具和项目外路径的请求，其余自动批准。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session"
+                ],
+                "summary": "创建会话",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "创建会话请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateSessionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateSessionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/session/import": {
+            "post": {
+                "description": "导入 GET /session/{id}/export 导出的 json，以新的 ID 重建会话、消息、子任务会话和文件历史。导出项目目录下的文件路径会映射到当前项目目录，文件历史中有导出项目目录之外的路径（包括解析符号链接后）时拒绝导入。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session"
+                ],
+                "summary": "导入会话",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "导出的会话",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/transcript.Transcript"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.SessionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/session/status": {
+            "get": {
+                "description": "获取项目的会话状态信息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session"
+                ],
+                "summary": "获取会话状态",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/session/{id}": {
+            "get": {
+                "description": "获取指定会话的详细信息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session"
+                ],
+                "summary": "获取会话详情",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "会话ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SessionDetailResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "更新指定会话的信息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session"
+                ],
+                "summary": "更新会话",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "会话ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "更新会话请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateSessionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateSessionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "删除指定的会话及其所有消息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session"
+                ],
+                "summary": "删除会话",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "会话ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/session/{id}/abort": {
+            "post": {
+                "description": "中止指定会话的 AI 处理。注意：当前实现会取消该项目下所有会话的 AI 处理。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session"
+                ],
+                "summary": "中止会话",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "会话ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/session/{id}/children": {
+            "get": {
+                "description": "获取由指定会话派生的子会话（如 agent 工具和任务会话）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session"
+                ],
+                "summary": "获取子会话",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "会话ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Session"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/session/{id}/command": {
+            "post": {
+                "description": "渲染用户或项目的自定义命令，或从 MCP 服务器获取 prompt（命令名为 \"服务器:prompt\"），并发送到会话。\nargs 按参数名填充；未提供 args 时，arguments 中的内容填充命令中的 $ARGUMENTS，其余参数按顺序以空白分隔填充。agent 和 model 仅为兼容 OpenCode SDK 而接受。\n缺少必填参数时返回 400，获取 MCP prompt 失败时返回 502 MCP_PROMPT_FAILED。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session"
+                ],
+                "summary": "执行自定义命令",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "会话ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "命令请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SessionCommandRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PromptResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/session/{id}/export": {
+            "get": {
+                "description": "导出会话及其消息（包括工具调用和结果）、待办事项、子任务会话和文件历史版本。json 格式可通过 POST /session/import 导入到其他项目，markdown 格式便于阅读，省略标题生成会话。",
+                "produces": [
+                    "application/json",
+                    "text/markdown"
+                ],
+                "tags": [
+                    "Session"
+                ],
+                "summary": "导出会话",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "会话ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "markdown"
+                        ],
+                        "type": "string",
+                        "default": "json",
+                        "description": "导出格式",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/transcript.Transcript"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/session/{id}/init": {
+            "post": {
+                "description": "在会话中运行项目初始化提示词，分析项目并生成 AGENTS.md 等上下文文件，完成后将项目标记为已初始化",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session"
+                ],
+                "summary": "初始化项目",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "会话ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "初始化请求",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.SessionInitRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "boolean"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/session/{id}/revert": {
+            "post": {
+                "description": "将会话修改过的文件恢复到指定消息之前的状态，并标记该消息及之后的消息为已回退。被回退的消息会在下一次发送消息时删除，在此之前可以调用 unrevert 撤销。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session"
+                ],
+                "summary": "回退会话",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "会话ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "回退请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SessionRevertRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Session"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/session/{id}/shell": {
+            "post": {
+                "description": "在项目目录中执行 shell 命令，并将命令和输出作为 bash 工具调用记录到会话中，供后续对话使用。agent 仅为兼容 OpenCode SDK 而接受。\n执行前按会话的权限模式请求权限：auto 直接执行，deny-dangerous 拒绝，ask 等待权限回复，被拒绝时返回 403。命令占用一个运行名额，超过 5 分钟会被中止，执行结果记录到审计日志。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session"
+                ],
+                "summary": "执行 shell 命令",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "会话ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "shell 请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SessionShellRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AssistantMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/session/{id}/summarize": {
+            "post": {
+                "description": "总结会话内容，后续对话将从总结继续。指定 providerID 和 modelID 时使用该模型总结（不修改配置），否则使用当前配置的大模型；模型不存在时返回 400。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session"
+                ],
+                "summary": "总结会话",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "会话ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "总结请求",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.SessionSummarizeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "boolean"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/session/{id}/unrevert": {
+            "post": {
+                "description": "恢复回退前的文件内容并取消会话的待定回退",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session"
+                ],
+                "summary": "撤销回退",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "会话ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Session"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/session/{sessionID}/message": {
+            "get": {
+                "description": "获取指定会话的消息，可通过 limit/offset 分页，total 为消息总数",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Message"
+                ],
+                "summary": "获取消息列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "会话ID",
+                        "name": "sessionID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "单页条数，最大 1000，不指定时返回全部",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "跳过的条数",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessagesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/session/{sessionID}/prompt": {
+            "post": {
+                "description": "Create and send a new message to a session using Opencode SDK compatible API",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session"
+                ],
+                "summary": "Send message (Opencode compatible)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project path",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Run in the background and return a run ID immediately",
+                        "name": "async",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Prompt request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PromptRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PromptResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.RunResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/skill": {
+            "get": {
+                "description": "在 options.skills_paths 和全局 skills 目录中查找 SKILL.md，返回每个 skill 的名称、描述、路径和校验结果。\nvalid 为 false 的 skill 不会提供给 agent，errors 为原因。",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Skills"
+                ],
+                "summary": "获取 skill 列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SkillsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/skill/{name}": {
+            "get": {
+                "description": "按名称获取 skill，包括 SKILL.md 的正文。同名 skill 有多个时返回第一个通过校验的。",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Skills"
+                ],
+                "summary": "获取 skill",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "skill 名称",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Skill"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/stats": {
+            "get": {
+                "description": "返回项目中顶层会话的 token 用量和费用，包括按天、模型、小时和星期的分布、平均响应时间、工具调用次数，以及费用最高的会话。\n数据与 crush stats 生成的页面一致。日期按 UTC 计算；指定 model 时只统计有该模型回复的会话，按模型和工具的分布只统计该模型的消息。",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stats"
+                ],
+                "summary": "获取项目的用量和费用统计",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "开始日期（YYYY-MM-DD，包含）",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "结束日期（YYYY-MM-DD，包含）",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "模型 ID",
+                        "name": "model",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "返回的费用最高的会话数，默认 20，最大 1000",
+                        "name": "session_limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StatsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/system-prompt": {
+            "get": {
+                "description": "获取指定项目当前的系统提示词内容",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Prompt"
+                ],
+                "summary": "获取系统提示词",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetSystemPromptResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "动态修改指定项目的系统提示词，无需重启服务。更新后立即对后续对话生效。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Prompt"
+                ],
+                "summary": "更新系统提示词",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "系统提示词",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateSystemPromptRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateSystemPromptResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/webhook": {
+            "get": {
+                "description": "返回配置文件 webhooks 中每个 webhook 的状态：订阅的事件、排队数、成功/失败/丢弃的投递数、最后一次错误，以及最近 50 次投递（按时间倒序）。\nURL 不包含用户信息和查询参数。配置有误的 webhook 不会启动，错误在 error 中返回。投递记录只保存在内存中，项目实例释放后清空。",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "获取 webhook 的投递状态",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/ws": {
+            "get": {
+                "description": "双向连接：服务器发送与 /event 相同的事件（models.WSEvent，id 与 /event 属于同一序列），\n客户端发送 prompt、abort、permission.reply、subscribe、unsubscribe、ping 命令（models.WSCommand），\n命令结果以 command.result 事件返回（models.WSCommandResult）。prompt 命令以异步运行方式执行，与 HTTP 请求一样计入客户端的限流。\n订阅了会话时只发送这些会话的事件，与会话无关的事件总是发送。\n服务器每 30 秒发送一次 ping，60 秒内没有收到 pong 或消息时断开连接",
+                "tags": [
+                    "Event"
+                ],
+                "summary": "WebSocket 连接",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目路径",
+                        "name": "directory",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "初始订阅的会话，多个会话用逗号分隔，不指定时接收所有会话的事件",
+                        "name": "session_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "最后收到的事件 ID，用于断线重连后补发",
+                        "name": "last_event_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "$ref": "#/definitions/models.WSEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "audit.Kind": {
+            "type": "string",
+            "enum": [
+                "tool",
+                "permission"
+            ],
+            "x-enum-varnames": [
+                "KindTool",
+                "KindPermission"
+            ]
+        },
+        "message.MessageRole": {
+            "type": "string",
+            "enum": [
+                "assistant",
+                "user",
+                "system",
+                "tool"
+            ],
+            "x-enum-varnames": [
+                "Assistant",
+                "User",
+                "System",
+                "Tool"
+            ]
+        },
+        "models.AssistantMessage": {
+            "type": "object",
+            "properties": {
+                "agent": {
+                    "type": "string"
+                },
+                "cost": {
+                    "type": "number"
+                },
+                "finish": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "modelID": {
+                    "type": "string"
+                },
+                "parentID": {
+                    "type": "string"
+                },
+                "providerID": {
+                    "type": "string"
+                },
+                "role": {
+                    "description": "\"assistant\"",
+                    "type": "string"
+                },
+                "sessionID": {
+                    "type": "string"
+                },
+                "time": {
+                    "$ref": "#/definitions/models.MessageTime"
+                },
+                "tokens": {
+                    "$ref": "#/definitions/models.TokenInfo"
+                }
+            }
+        },
+        "models.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "Action and Path are those of the permission request.",
+                    "type": "string"
+                },
+                "actor": {
+                    "description": "Actor is the API caller that started the audited action, see\n[WithActor]. It is empty for actions started from the TUI.",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "decider": {
+                    "type": "string"
+                },
+                "decision": {
+                    "description": "Decision, Decider and Reason are set for permission entries, see\n[permission.Decision].",
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "exit_code": {
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "$ref": "#/definitions/audit.Kind"
+                },
+                "params": {
+                    "description": "Params are the JSON encoded parameters of the tool call, truncated if\nthey are too large.",
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status, ExitCode and Error are set for tool entries. ExitCode is set\nfor commands that finished before the tool returned.",
+                    "type": "string"
+                },
+                "tool_call_id": {
+                    "type": "string"
+                },
+                "tool_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AuditResponse": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AuditEntry"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CacheTokenInfo": {
+            "type": "object",
+            "properties": {
+                "read": {
+                    "type": "integer"
+                },
+                "write": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Command": {
+            "type": "object",
+            "properties": {
+                "arguments": {
+                    "description": "Arguments 命令的参数，按 raw arguments 填充的顺序排列",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CommandArgument"
+                    }
+                },
+                "description": {
+                    "type": "string"
+                },
+                "mcp": {
+                    "description": "MCP 提供该 prompt 的 MCP 服务器，仅 mcp 命令有值",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Name 命令 ID，例如 user:review、project:deploy:staging 或 MCP 的 server:prompt",
+                    "type": "string"
+                },
+                "source": {
+                    "description": "Source 命令来源：user、project 或 mcp",
+                    "type": "string"
+                },
+                "template": {
+                    "description": "Template 命令的 Markdown 内容，仅 user 和 project 命令有值",
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CommandArgument": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Name 参数名，自定义命令中为 $NAME 占位符的 NAME",
+                    "type": "string"
+                },
+                "required": {
+                    "type": "boolean"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ConfigResponse": {
+            "type": "object",
+            "properties": {
+                "configured": {
+                    "type": "boolean"
+                },
+                "data_dir": {
+                    "type": "string"
+                },
+                "debug": {
+                    "type": "boolean"
+                },
+                "providers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProviderInfo"
+                    }
+                },
+                "working_dir": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ConfigSectionResponse": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "description": "Path 修改写入的配置文件（数据目录下的 crush.json，所有项目共用）",
+                    "type": "string"
+                },
+                "section": {
+                    "description": "Section 配置段名称：providers、models、mcp、lsp、options、permissions",
+                    "type": "string"
+                },
+                "value": {
+                    "description": "Value 当前生效的配置（全局、数据目录和项目配置文件合并后的结果），\nproviders、mcp、lsp、models 为以名称为键的对象。\n密钥已脱敏：$VAR 形式的引用原样返回，其余显示为 \"****\" 加末 4 位",
+                    "type": "object"
+                }
+            }
+        },
+        "models.CreateProjectRequest": {
+            "type": "object",
+            "properties": {
+                "data_dir": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateProjectResponse": {
+            "type": "object",
+            "properties": {
+                "project": {
+                    "$ref": "#/definitions/models.ProjectResponse"
+                }
+            }
+        },
+        "models.CreateSessionRequest": {
+            "type": "object",
+            "properties": {
+                "permission_mode": {
+                    "description": "PermissionMode 会话的权限模式：auto、ask、deny-dangerous，为空时使用服务器默认值",
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateSessionResponse": {
+            "type": "object",
+            "properties": {
+                "permission_mode": {
+                    "type": "string"
+                },
+                "session": {
+                    "$ref": "#/definitions/models.SessionResponse"
+                }
+            }
+        },
+        "models.CurrentProjectResponse": {
+            "type": "object",
+            "properties": {
+                "project": {
+                    "$ref": "#/definitions/models.ProjectResponse"
+                }
+            }
+        },
+        "models.Diagnostic": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code 诊断代码",
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "path": {
+                    "description": "Path 文件路径，位于项目内时为相对于项目根目录的路径",
+                    "type": "string"
+                },
+                "range": {
+                    "$ref": "#/definitions/models.Range"
+                },
+                "server": {
+                    "description": "Server 报告诊断的 LSP 服务器",
+                    "type": "string"
+                },
+                "severity": {
+                    "description": "Severity error、warning、information 或 hint",
+                    "type": "string"
+                },
+                "source": {
+                    "description": "Source 产生诊断的工具，例如 compiler、staticcheck",
+                    "type": "string"
+                }
+            }
+        },
+        "models.DiagnosticCounts": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "integer"
+                },
+                "hint": {
+                    "type": "integer"
+                },
+                "information": {
+                    "type": "integer"
+                },
+                "warning": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.DiagnosticsResponse": {
+            "type": "object",
+            "properties": {
+                "counts": {
+                    "description": "Counts 返回的诊断中各严重程度的数量",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.DiagnosticCounts"
+                        }
+                    ]
+                },
+                "diagnostics": {
+                    "description": "Diagnostics 按路径和位置排序",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Diagnostic"
+                    }
+                }
+            }
+        },
+        "models.DisposeAllResponse": {
+            "type": "object",
+            "properties": {
+                "disposed_count": {
+                    "type": "integer"
+                },
+                "projects": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DisposeProjectResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "project_path": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.FileContentResponse": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "encoding": {
+                    "description": "\"utf8\", \"base64\" for images",
+                    "type": "string"
+                },
+                "is_binary": {
+                    "type": "boolean"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "lines": {
+                    "description": "行数（如果是文本文件）",
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.FileEditResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "Action 操作类型：write、patch、rename、delete",
+                    "type": "string"
+                },
+                "additions": {
+                    "description": "Additions 新增的行数",
+                    "type": "integer"
+                },
+                "from": {
+                    "description": "From 重命名前的路径",
+                    "type": "string"
+                },
+                "path": {
+                    "description": "Path 修改后的文件路径（相对于项目根目录）",
+                    "type": "string"
+                },
+                "removals": {
+                    "description": "Removals 删除的行数",
+                    "type": "integer"
+                },
+                "session_id": {
+                    "description": "SessionID 记录文件历史版本的会话",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version 文件历史中新增的版本号，删除时为记录空内容的版本",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.FileInfo": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FileInfo"
+                    }
+                },
+                "mod_time": {
+                    "type": "string"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "\"file\" or \"directory\"",
+                    "type": "string"
+                }
+            }
+        },
+        "models.FileListResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FileInfo"
+                    }
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.FilePatchRequest": {
+            "type": "object",
+            "properties": {
+                "diff": {
+                    "description": "Diff 单个文件的 unified diff，上下文位置有偏移时会在文件中查找",
+                    "type": "string"
+                },
+                "path": {
+                    "description": "Path 文件路径（相对于项目根目录），不存在时视为空文件",
+                    "type": "string"
+                },
+                "session_id": {
+                    "description": "SessionID 记录文件历史版本的会话",
+                    "type": "string"
+                }
+            }
+        },
+        "models.FileRenameRequest": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "description": "From 原路径（相对于项目根目录）",
+                    "type": "string"
+                },
+                "session_id": {
+                    "description": "SessionID 记录文件历史版本的会话",
+                    "type": "string"
+                },
+                "to": {
+                    "description": "To 新路径（相对于项目根目录），已存在时返回 409",
+                    "type": "string"
+                }
+            }
+        },
+        "models.FileWriteRequest": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "description": "Content 文件内容",
+                    "type": "string"
+                },
+                "encoding": {
+                    "description": "Encoding 内容编码：utf8（默认）或 base64",
+                    "type": "string"
+                },
+                "path": {
+                    "description": "Path 文件路径（相对于项目根目录），不存在时创建，包括上级目录",
+                    "type": "string"
+                },
+                "session_id": {
+                    "description": "SessionID 记录文件历史版本的会话",
+                    "type": "string"
+                }
+            }
+        },
+        "models.GetSystemPromptResponse": {
+            "type": "object",
+            "properties": {
+                "is_custom": {
+                    "description": "IsCustom 是否为自定义提示词（非空表示已自定义）",
+                    "type": "boolean"
+                },
+                "length": {
+                    "description": "Length 系统提示词的字符数",
+                    "type": "integer"
+                },
+                "system_prompt": {
+                    "description": "SystemPrompt 当前的系统提示词内容",
+                    "type": "string"
+                }
+            }
+        },
+        "models.GitFileStatus": {
+            "type": "object",
+            "properties": {
+                "old_path": {
+                    "description": "for renamed files",
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "\"modified\", \"added\", \"deleted\", \"renamed\", \"untracked\"",
+                    "type": "string"
+                }
+            }
+        },
+        "models.GitStatusResponse": {
+            "type": "object",
+            "properties": {
+                "branch": {
+                    "type": "string"
+                },
+                "deleted": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GitFileStatus"
+                    }
+                },
+                "head": {
+                    "type": "string"
+                },
+                "is_clean": {
+                    "type": "boolean"
+                },
+                "modified": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GitFileStatus"
+                    }
+                },
+                "staged": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GitFileStatus"
+                    }
+                },
+                "untracked": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GitFileStatus"
+                    }
+                }
+            }
+        },
+        "models.GlobalStatsResponse": {
+            "type": "object",
+            "properties": {
+                "avg_response_time_ms": {
+                    "type": "number"
+                },
+                "generated_at": {
+                    "type": "string"
+                },
+                "hour_day_heatmap": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stats.HourDayHeatmapPt"
+                    }
+                },
+                "projects": {
+                    "description": "Projects 每个项目的合计，按费用降序",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProjectStats"
+                    }
+                },
+                "recent_activity": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stats.DailyActivity"
+                    }
+                },
+                "session_costs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stats.SessionCost"
+                    }
+                },
+                "tool_usage": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stats.ToolUsage"
+                    }
+                },
+                "total": {
+                    "$ref": "#/definitions/stats.TotalStats"
+                },
+                "usage_by_day": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stats.DailyUsage"
+                    }
+                },
+                "usage_by_day_of_week": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stats.DayOfWeekUsage"
+                    }
+                },
+                "usage_by_hour": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stats.HourlyUsage"
+                    }
+                },
+                "usage_by_model": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stats.ModelUsage"
+                    }
+                }
+            }
+        },
+        "models.Job": {
+            "type": "object",
+            "properties": {
+                "command": {
+                    "type": "string"
+                },
+                "completed_at": {
+                    "description": "CompletedAt 结束时间（毫秒时间戳，精确到秒），运行中为 0",
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "exit_code": {
+                    "description": "ExitCode 退出码，仅结束后有值",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "ID 任务 ID，与 agent 的 job_output、job_kill 工具使用的 shell_id 一致",
+                    "type": "string"
+                },
+                "started_at": {
+                    "description": "StartedAt 启动时间（毫秒时间戳）",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "Status running、completed 或 failed（退出码不为 0，包括被终止）",
+                    "type": "string"
+                },
+                "working_dir": {
+                    "description": "WorkingDir 工作目录",
+                    "type": "string"
+                }
+            }
+        },
+        "models.JobOutput": {
+            "type": "object",
+            "properties": {
+                "done": {
+                    "description": "Done 任务是否已结束，结束后不会再有新的输出",
+                    "type": "boolean"
+                },
+                "stderr": {
+                    "type": "string"
+                },
+                "stderr_offset": {
+                    "description": "StderrOffset 下次读取 stderr 的偏移量（字节）",
+                    "type": "integer"
+                },
+                "stdout": {
+                    "type": "string"
+                },
+                "stdout_offset": {
+                    "description": "StdoutOffset 下次读取 stdout 的偏移量（字节）",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LSPStatus": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "root": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "\"starting\"、\"connected\"、\"error\" 或 \"disabled\"",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Location": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "description": "Path 文件路径，位于项目内时为相对于项目根目录的路径",
+                    "type": "string"
+                },
+                "range": {
+                    "$ref": "#/definitions/models.Range"
+                },
+                "uri": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MCPPrompt": {
+            "type": "object",
+            "properties": {
+                "arguments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MCPPromptArgument"
+                    }
+                },
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MCPPromptArgument": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "required": {
+                    "type": "boolean"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MCPResource": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "mime_type": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "uri": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MCPServerStatus": {
+            "type": "object",
+            "properties": {
+                "connected_at": {
+                    "description": "ConnectedAt 连接时间（毫秒时间戳），未连接时为 0",
+                    "type": "integer"
+                },
+                "message": {
+                    "description": "Message 连接失败的原因",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prompts": {
+                    "description": "Prompts prompt 数",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "Status disabled、starting、connected 或 failed",
+                    "type": "string"
+                },
+                "tools": {
+                    "description": "Tools 提供给 agent 的工具数（不包括 disabled_tools）",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.MCPStatus": {
+            "type": "object",
+            "additionalProperties": true
+        },
+        "models.MCPTool": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "disabled": {
+                    "description": "Disabled 是否在配置的 disabled_tools 中，禁用的工具不提供给 agent",
+                    "type": "boolean"
+                },
+                "input_schema": {
+                    "description": "InputSchema 参数的 JSON Schema",
+                    "type": "object"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "output_schema": {
+                    "description": "OutputSchema 结构化输出的 JSON Schema",
+                    "type": "object"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MCPToolCallRequest": {
+            "type": "object",
+            "properties": {
+                "arguments": {
+                    "description": "Arguments 工具参数，按工具的 input_schema 填写",
+                    "type": "object",
+                    "additionalProperties": {}
+                }
+            }
+        },
+        "models.MCPToolCallResponse": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "description": "Content 文本内容",
+                    "type": "string"
+                },
+                "data": {
+                    "description": "Data 图片或音频数据（base64），仅 image 和 media 有值",
+                    "type": "string",
+                    "format": "base64"
+                },
+                "duration_ms": {
+                    "description": "DurationMs 调用耗时（毫秒）",
+                    "type": "integer"
+                },
+                "media_type": {
+                    "description": "MediaType 图片或音频的 MIME 类型",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Type text、image 或 media",
+                    "type": "string"
+                }
+            }
+        },
+        "models.MessageDetailResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "$ref": "#/definitions/models.MessageResponse"
+                }
+            }
+        },
+        "models.MessageResponse": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "integer"
+                },
+                "finish_reason": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_summary_message": {
+                    "type": "boolean"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "parts": {
+                    "type": "array",
+                    "items": {
+                        "type": "object",
+                        "additionalProperties": true
+                    }
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.MessageTime": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "type": "integer"
+                },
+                "created": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.MessagesResponse": {
+            "type": "object",
+            "properties": {
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MessageResponse"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ModelCost": {
+            "type": "object",
+            "properties": {
+                "cache_read": {
+                    "type": "number"
+                },
+                "cache_write": {
+                    "type": "number"
+                },
+                "input": {
+                    "type": "number"
+                },
+                "output": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.ModelLimit": {
+            "type": "object",
+            "properties": {
+                "context": {
+                    "description": "Context 上下文窗口",
+                    "type": "integer"
+                },
+                "output": {
+                    "description": "Output 默认的最大输出 token 数",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ModelSpec": {
+            "type": "object",
+            "properties": {
+                "modelID": {
+                    "type": "string"
+                },
+                "providerID": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PermissionReplyRequest": {
+            "type": "object",
+            "properties": {
+                "granted": {
+                    "type": "boolean"
+                },
+                "persistent": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.PermissionRequest": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "params": {},
+                "path": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "tool_call_id": {
+                    "type": "string"
+                },
+                "tool_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PermissionsResponse": {
+            "type": "object",
+            "properties": {
+                "pending": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PermissionRequest"
+                    }
+                },
+                "skip_requests": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.Position": {
+            "type": "object",
+            "properties": {
+                "character": {
+                    "type": "integer"
+                },
+                "line": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ProjectResponse": {
+            "type": "object",
+            "properties": {
+                "data_dir": {
+                    "type": "string"
+                },
+                "last_accessed": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ProjectStats": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Error 读取项目数据库失败的原因，失败的项目不计入合计",
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "total_cost": {
+                    "type": "number"
+                },
+                "total_sessions": {
+                    "type": "integer"
+                },
+                "total_tokens": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ProjectsResponse": {
+            "type": "object",
+            "properties": {
+                "projects": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProjectResponse"
+                    }
+                }
+            }
+        },
+        "models.PromptRequest": {
+            "type": "object",
+            "properties": {
+                "agent": {
+                    "type": "string"
+                },
+                "maxOutputTokens": {
+                    "type": "integer"
+                },
+                "messageID": {
+                    "type": "string"
+                },
+                "model": {
+                    "$ref": "#/definitions/models.ModelSpec"
+                },
+                "noReply": {
+                    "type": "boolean"
+                },
+                "parts": {
+                    "type": "array",
+                    "items": {}
+                },
+                "reasoningEffort": {
+                    "type": "string"
+                },
+                "smallModel": {
+                    "description": "Per-prompt overrides. They only apply to this prompt and leave the\nproject configuration untouched.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ModelSpec"
+                        }
+                    ]
+                },
+                "temperature": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.PromptResponse": {
+            "type": "object",
+            "properties": {
+                "info": {
+                    "$ref": "#/definitions/models.AssistantMessage"
+                },
+                "parts": {
+                    "type": "array",
+                    "items": {}
+                }
+            }
+        },
+        "models.Provider": {
+            "type": "object",
+            "properties": {
+                "api": {
+                    "description": "API 接口地址，可能为 $VAR 形式的引用",
+                    "type": "string"
+                },
+                "configured": {
+                    "description": "Configured 是否已配置凭据并启用，未配置的 provider 不能使用",
+                    "type": "boolean"
+                },
+                "custom": {
+                    "description": "Custom 是否为配置文件中添加的 provider（不在 catwalk 目录中）",
+                    "type": "boolean"
+                },
+                "env": {
+                    "description": "Env 读取 API Key 的环境变量",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "models": {
+                    "description": "Models 以模型 ID 为键",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.ProviderModel"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Type API 格式，例如 openai、openai-compat、anthropic",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ProviderInfo": {
+            "type": "object",
+            "properties": {
+                "configured": {
+                    "type": "boolean"
+                },
+                "name": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "string"
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ProviderModel": {
+            "type": "object",
+            "properties": {
+                "attachment": {
+                    "description": "Attachment 是否支持图片等附件",
+                    "type": "boolean"
+                },
+                "cost": {
+                    "$ref": "#/definitions/models.ModelCost"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "limit": {
+                    "$ref": "#/definitions/models.ModelLimit"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "reasoning": {
+                    "description": "Reasoning 是否支持推理",
+                    "type": "boolean"
+                },
+                "reasoning_levels": {
+                    "description": "ReasoningLevels 支持的推理强度，例如 low、medium、high",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.ProviderTestRequest": {
+            "type": "object",
+            "properties": {
+                "api_key": {
+                    "description": "APIKey 要测试的 API Key，可以是 $VAR 形式的引用",
+                    "type": "string"
+                },
+                "base_url": {
+                    "description": "BaseURL 要测试的接口地址",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ProviderTestResponse": {
+            "type": "object",
+            "properties": {
+                "duration_ms": {
+                    "description": "DurationMs 测试耗时（毫秒）",
+                    "type": "integer"
+                },
+                "error": {
+                    "description": "Error 连接失败的原因",
+                    "type": "string"
+                },
+                "ok": {
+                    "description": "OK 是否连接成功",
+                    "type": "boolean"
+                },
+                "provider_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ProvidersResponse": {
+            "type": "object",
+            "properties": {
+                "default": {
+                    "description": "Default 每个 provider 的默认模型 ID",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "providers": {
+                    "description": "Providers catwalk 已知的 provider 和配置文件中添加的 provider",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Provider"
+                    }
+                }
+            }
+        },
+        "models.Range": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "$ref": "#/definitions/models.Position"
+                },
+                "start": {
+                    "$ref": "#/definitions/models.Position"
+                }
+            }
+        },
+        "models.RunResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "CreatedAt 创建时间（Unix 秒）",
+                    "type": "integer"
                 },
-                "modelID": {
+                "directory": {
+                    "description": "Directory 所属项目路径",
                     "type": "string"
                 },
-                "parentID": {
+                "error": {
+                    "description": "Error 运行失败时的错误信息",
                     "type": "string"
                 },
-                "providerID": {
-                    "type": "string"
+                "finished_at": {
+                    "description": "FinishedAt 结束时间（Unix 秒），运行中为空",
+                    "type": "integer"
                 },
-                "role": {
-                    "description": "\"assistant\"",
+                "id": {
+                    "description": "ID 运行 ID",
                     "type": "string"
                 },
-                "sessionID": {
+                "message": {
+                    "description": "Message 本次运行最新的 assistant 消息，运行结束后即为最终消息",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.PromptResponse"
+                        }
+                    ]
+                },
+                "message_ids": {
+                    "description": "MessageIDs 本次运行产生的 assistant 消息 ID",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "output": {
+                    "description": "Output 本次运行中 assistant 已输出的文本（运行中为部分输出）",
                     "type": "string"
                 },
-                "time": {
-                    "$ref": "#/definitions/models.MessageTime"
+                "session_id": {
+                    "description": "SessionID 所属会话 ID",
+                    "type": "string"
                 },
-                "tokens": {
-                    "$ref": "#/definitions/models.TokenInfo"
+                "status": {
+                    "description": "Status 运行状态：running、completed、failed、cancelled",
+                    "type": "string"
                 }
             }
         },
-        "models.CacheTokenInfo": {
+        "models.RunsResponse": {
             "type": "object",
             "properties": {
-                "read": {
-                    "type": "integer"
+                "runs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RunResponse"
+                    }
                 },
-                "write": {
+                "total": {
                     "type": "integer"
                 }
             }
         },
-        "models.ConfigResponse": {
+        "models.SSEEvent": {
             "type": "object",
             "properties": {
-                "configured": {
-                    "type": "boolean"
+                "properties": {
+                    "description": "Properties 字段包含了事件的具体数据。其结构取决于 Type 字段。"
                 },
-                "data_dir": {
+                "type": {
+                    "description": "Type 字段指示事件的类型，例如 \"message.created\", \"session.updated\" 等。",
                     "type": "string"
+                }
+            }
+        },
+        "models.SearchResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
                 },
-                "debug": {
-                    "type": "boolean"
+                "query": {
+                    "type": "string"
                 },
-                "providers": {
+                "results": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.ProviderInfo"
+                        "$ref": "#/definitions/models.SearchResultItem"
                     }
-                },
-                "working_dir": {
-                    "type": "string"
                 }
             }
         },
-        "models.CreateProjectRequest": {
+        "models.SearchResultItem": {
             "type": "object",
             "properties": {
-                "data_dir": {
+                "content": {
                     "type": "string"
                 },
+                "line": {
+                    "type": "integer"
+                },
+                "match_end": {
+                    "type": "integer"
+                },
+                "match_start": {
+                    "type": "integer"
+                },
                 "path": {
                     "type": "string"
                 }
             }
         },
-        "models.CreateProjectResponse": {
+        "models.Session": {
             "type": "object",
             "properties": {
-                "project": {
-                    "$ref": "#/definitions/models.ProjectResponse"
+                "directory": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "parentID": {
+                    "type": "string"
+                },
+                "projectID": {
+                    "type": "string"
+                },
+                "revert": {
+                    "$ref": "#/definitions/models.SessionRevert"
+                },
+                "time": {
+                    "$ref": "#/definitions/models.SessionTime"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
                 }
             }
         },
-        "models.CreateSessionRequest": {
+        "models.SessionCommandRequest": {
             "type": "object",
             "properties": {
-                "title": {
+                "agent": {
+                    "type": "string"
+                },
+                "args": {
+                    "description": "named arguments, take precedence over arguments",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "arguments": {
+                    "type": "string"
+                },
+                "command": {
+                    "type": "string"
+                },
+                "messageID": {
+                    "type": "string"
+                },
+                "model": {
+                    "description": "\"providerID/modelID\"",
                     "type": "string"
                 }
             }
         },
-        "models.CreateSessionResponse": {
+        "models.SessionDetailResponse": {
             "type": "object",
             "properties": {
                 "session": {
@@ -1614,486 +6236,702 @@ const docTemplate = `{
                 }
             }
         },
-        "models.CurrentProjectResponse": {
+        "models.SessionInitRequest": {
             "type": "object",
             "properties": {
-                "project": {
-                    "$ref": "#/definitions/models.ProjectResponse"
+                "messageID": {
+                    "type": "string"
+                },
+                "modelID": {
+                    "type": "string"
+                },
+                "providerID": {
+                    "type": "string"
                 }
             }
         },
-        "models.DisposeAllResponse": {
+        "models.SessionResponse": {
             "type": "object",
             "properties": {
-                "disposed_count": {
+                "completion_tokens": {
                     "type": "integer"
                 },
-                "projects": {
+                "cost": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "message_count": {
+                    "type": "integer"
+                },
+                "parent_session_id": {
+                    "type": "string"
+                },
+                "prompt_tokens": {
+                    "type": "integer"
+                },
+                "summary_message_id": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "todos": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/models.TodoResponse"
                     }
                 },
-                "status": {
-                    "type": "string"
+                "updated_at": {
+                    "type": "integer"
                 }
             }
         },
-        "models.DisposeProjectResponse": {
+        "models.SessionRevert": {
             "type": "object",
             "properties": {
-                "message": {
+                "diff": {
                     "type": "string"
                 },
-                "project_path": {
+                "messageID": {
                     "type": "string"
                 },
-                "status": {
+                "partID": {
+                    "type": "string"
+                },
+                "snapshot": {
                     "type": "string"
                 }
             }
         },
-        "models.FileContentResponse": {
+        "models.SessionRevertRequest": {
             "type": "object",
             "properties": {
-                "content": {
+                "messageID": {
                     "type": "string"
                 },
-                "encoding": {
-                    "description": "\"utf8\", \"base64\" for images",
+                "partID": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SessionShellRequest": {
+            "type": "object",
+            "properties": {
+                "agent": {
                     "type": "string"
                 },
-                "is_binary": {
-                    "type": "boolean"
-                },
-                "limit": {
-                    "type": "integer"
+                "command": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SessionSummarizeRequest": {
+            "type": "object",
+            "properties": {
+                "modelID": {
+                    "type": "string"
                 },
-                "lines": {
-                    "description": "行数（如果是文本文件）",
+                "providerID": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SessionTime": {
+            "type": "object",
+            "properties": {
+                "created": {
                     "type": "integer"
                 },
-                "offset": {
+                "updated": {
                     "type": "integer"
-                },
-                "path": {
-                    "type": "string"
                 }
             }
         },
-        "models.FileInfo": {
+        "models.SessionsResponse": {
             "type": "object",
             "properties": {
-                "children": {
+                "sessions": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.FileInfo"
+                        "$ref": "#/definitions/models.SessionResponse"
                     }
                 },
-                "mod_time": {
-                    "type": "string"
-                },
-                "mode": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "path": {
-                    "type": "string"
-                },
-                "size": {
+                "total": {
                     "type": "integer"
-                },
-                "type": {
-                    "description": "\"file\" or \"directory\"",
-                    "type": "string"
                 }
             }
         },
-        "models.FileListResponse": {
+        "models.Skill": {
             "type": "object",
             "properties": {
-                "count": {
-                    "type": "integer"
+                "compatibility": {
+                    "type": "string"
                 },
-                "files": {
+                "description": {
+                    "type": "string"
+                },
+                "errors": {
+                    "description": "Errors 解析或校验失败的原因",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.FileInfo"
+                        "type": "string"
+                    }
+                },
+                "file": {
+                    "description": "File SKILL.md 的路径，agent 通过它读取 skill",
+                    "type": "string"
+                },
+                "instructions": {
+                    "description": "Instructions SKILL.md 正文，仅 /skill/{name} 返回",
+                    "type": "string"
+                },
+                "license": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
                     }
                 },
+                "name": {
+                    "description": "Name frontmatter 中的名称，SKILL.md 无法解析时为空",
+                    "type": "string"
+                },
                 "path": {
+                    "description": "Path skill 所在目录",
                     "type": "string"
+                },
+                "valid": {
+                    "description": "Valid 是否通过校验，只有通过校验的 skill 会提供给 agent",
+                    "type": "boolean"
                 }
             }
         },
-        "models.GetSystemPromptResponse": {
+        "models.SkillsResponse": {
             "type": "object",
             "properties": {
-                "is_custom": {
-                    "description": "IsCustom 是否为自定义提示词（非空表示已自定义）",
-                    "type": "boolean"
-                },
-                "length": {
-                    "description": "Length 系统提示词的字符数",
-                    "type": "integer"
+                "paths": {
+                    "description": "Paths 查找 SKILL.md 的目录（options.skills_paths 和全局 skills 目录，已展开）",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "system_prompt": {
-                    "description": "SystemPrompt 当前的系统提示词内容",
-                    "type": "string"
+                "skills": {
+                    "description": "Skills 找到的所有 SKILL.md，按路径排序，包括未通过校验的",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Skill"
+                    }
                 }
             }
         },
-        "models.GitFileStatus": {
+        "models.StatsResponse": {
             "type": "object",
             "properties": {
-                "old_path": {
-                    "description": "for renamed files",
-                    "type": "string"
+                "avg_response_time_ms": {
+                    "type": "number"
                 },
-                "path": {
+                "generated_at": {
                     "type": "string"
                 },
-                "status": {
-                    "description": "\"modified\", \"added\", \"deleted\", \"renamed\", \"untracked\"",
-                    "type": "string"
-                }
-            }
-        },
-        "models.GitStatusResponse": {
-            "type": "object",
-            "properties": {
-                "branch": {
-                    "type": "string"
+                "hour_day_heatmap": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stats.HourDayHeatmapPt"
+                    }
                 },
-                "deleted": {
+                "recent_activity": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.GitFileStatus"
+                        "$ref": "#/definitions/stats.DailyActivity"
                     }
                 },
-                "head": {
-                    "type": "string"
+                "session_costs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stats.SessionCost"
+                    }
                 },
-                "is_clean": {
-                    "type": "boolean"
+                "tool_usage": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stats.ToolUsage"
+                    }
                 },
-                "modified": {
+                "total": {
+                    "$ref": "#/definitions/stats.TotalStats"
+                },
+                "usage_by_day": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.GitFileStatus"
+                        "$ref": "#/definitions/stats.DailyUsage"
                     }
                 },
-                "staged": {
+                "usage_by_day_of_week": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.GitFileStatus"
+                        "$ref": "#/definitions/stats.DayOfWeekUsage"
                     }
                 },
-                "untracked": {
+                "usage_by_hour": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.GitFileStatus"
+                        "$ref": "#/definitions/stats.HourlyUsage"
+                    }
+                },
+                "usage_by_model": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stats.ModelUsage"
                     }
                 }
             }
         },
-        "models.LSPStatus": {
+        "models.Symbol": {
             "type": "object",
             "properties": {
-                "id": {
-                    "type": "string"
+                "kind": {
+                    "description": "Kind LSP SymbolKind，例如 5 Class、6 Method、12 Function、13 Variable",
+                    "type": "integer"
+                },
+                "location": {
+                    "description": "Location 符号声明的位置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Location"
+                        }
+                    ]
                 },
                 "name": {
+                    "description": "Name 符号名称",
+                    "type": "string"
+                }
+            }
+        },
+        "models.TodoResponse": {
+            "type": "object",
+            "properties": {
+                "active_form": {
                     "type": "string"
                 },
-                "root": {
+                "content": {
                     "type": "string"
                 },
                 "status": {
-                    "description": "\"connected\" or \"error\"",
                     "type": "string"
                 }
             }
         },
-        "models.MCPStatus": {
+        "models.TokenInfo": {
             "type": "object",
-            "additionalProperties": true
+            "properties": {
+                "cache": {
+                    "$ref": "#/definitions/models.CacheTokenInfo"
+                },
+                "input": {
+                    "type": "integer"
+                },
+                "output": {
+                    "type": "integer"
+                },
+                "reasoning": {
+                    "type": "integer"
+                }
+            }
         },
-        "models.MessageDetailResponse": {
+        "models.UpdateSessionRequest": {
             "type": "object",
             "properties": {
-                "message": {
-                    "$ref": "#/definitions/models.MessageResponse"
+                "title": {
+                    "type": "string"
                 }
             }
         },
-        "models.MessageResponse": {
+        "models.UpdateSessionResponse": {
             "type": "object",
             "properties": {
-                "content": {
+                "session": {
+                    "$ref": "#/definitions/models.SessionResponse"
+                }
+            }
+        },
+        "models.UpdateSystemPromptRequest": {
+            "type": "object",
+            "required": [
+                "system_prompt"
+            ],
+            "properties": {
+                "system_prompt": {
+                    "description": "SystemPrompt 新的系统提示词内容",
+                    "type": "string"
+                }
+            }
+        },
+        "models.UpdateSystemPromptResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "description": "Message 操作结果消息（可选）",
                     "type": "string"
                 },
-                "created_at": {
-                    "type": "integer"
+                "success": {
+                    "description": "Success 是否成功更新",
+                    "type": "boolean"
                 },
-                "finish_reason": {
+                "system_prompt": {
+                    "description": "SystemPrompt 更新后的系统提示词内容",
+                    "type": "string"
+                }
+            }
+        },
+        "models.WSEvent": {
+            "type": "object",
+            "properties": {
+                "id": {
                     "type": "string"
                 },
-                "finished_at": {
+                "properties": {},
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WebhookStatus": {
+            "type": "object",
+            "properties": {
+                "cost_threshold": {
+                    "type": "number"
+                },
+                "delivered": {
                     "type": "integer"
                 },
-                "id": {
-                    "type": "string"
+                "deliveries": {
+                    "description": "Deliveries are the most recent deliveries, newest first.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/webhook.Delivery"
+                    }
                 },
-                "is_summary_message": {
+                "disabled": {
                     "type": "boolean"
                 },
-                "model": {
+                "dropped": {
+                    "type": "integer"
+                },
+                "error": {
+                    "description": "Error is set when the endpoint is misconfigured and not started.",
                     "type": "string"
                 },
-                "parts": {
+                "events": {
                     "type": "array",
                     "items": {
-                        "type": "object",
-                        "additionalProperties": true
+                        "type": "string"
                     }
                 },
-                "provider": {
+                "failed": {
+                    "type": "integer"
+                },
+                "last_delivered_at": {
                     "type": "string"
                 },
-                "role": {
+                "last_error": {
                     "type": "string"
                 },
-                "session_id": {
+                "last_failed_at": {
                     "type": "string"
                 },
-                "updated_at": {
-                    "type": "integer"
-                }
-            }
-        },
-        "models.MessageTime": {
-            "type": "object",
-            "properties": {
-                "completed": {
-                    "type": "integer"
+                "name": {
+                    "type": "string"
                 },
-                "created": {
+                "queued": {
                     "type": "integer"
+                },
+                "signed": {
+                    "type": "boolean"
+                },
+                "url": {
+                    "description": "URL is the URL of the endpoint without credentials and query.",
+                    "type": "string"
                 }
             }
         },
-        "models.MessagesResponse": {
+        "models.WebhookStatusResponse": {
             "type": "object",
             "properties": {
-                "messages": {
+                "webhooks": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.MessageResponse"
+                        "$ref": "#/definitions/models.WebhookStatus"
                     }
-                },
-                "total": {
-                    "type": "integer"
                 }
             }
         },
-        "models.ModelSpec": {
+        "session.Todo": {
             "type": "object",
             "properties": {
-                "modelID": {
+                "active_form": {
                     "type": "string"
                 },
-                "providerID": {
+                "content": {
                     "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/session.TodoStatus"
                 }
             }
         },
-        "models.PermissionReplyRequest": {
+        "session.TodoStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "in_progress",
+                "completed"
+            ],
+            "x-enum-varnames": [
+                "TodoStatusPending",
+                "TodoStatusInProgress",
+                "TodoStatusCompleted"
+            ]
+        },
+        "stats.DailyActivity": {
             "type": "object",
             "properties": {
-                "granted": {
-                    "type": "boolean"
+                "cost": {
+                    "type": "number"
                 },
-                "persistent": {
-                    "type": "boolean"
+                "day": {
+                    "type": "string"
+                },
+                "session_count": {
+                    "type": "integer"
+                },
+                "total_tokens": {
+                    "type": "integer"
                 }
             }
         },
-        "models.PermissionRequest": {
+        "stats.DailyUsage": {
             "type": "object",
             "properties": {
-                "action": {
-                    "type": "string"
-                },
-                "description": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
+                "completion_tokens": {
+                    "type": "integer"
                 },
-                "params": {},
-                "path": {
-                    "type": "string"
+                "cost": {
+                    "type": "number"
                 },
-                "session_id": {
+                "day": {
                     "type": "string"
                 },
-                "tool_call_id": {
-                    "type": "string"
+                "prompt_tokens": {
+                    "type": "integer"
                 },
-                "tool_name": {
-                    "type": "string"
-                }
-            }
-        },
-        "models.PermissionsResponse": {
-            "type": "object",
-            "properties": {
-                "pending": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.PermissionRequest"
-                    }
+                "session_count": {
+                    "type": "integer"
                 },
-                "skip_requests": {
-                    "type": "boolean"
+                "total_tokens": {
+                    "type": "integer"
                 }
             }
         },
-        "models.ProjectResponse": {
+        "stats.DayOfWeekUsage": {
             "type": "object",
             "properties": {
-                "data_dir": {
-                    "type": "string"
+                "completion_tokens": {
+                    "type": "integer"
                 },
-                "last_accessed": {
+                "day_name": {
                     "type": "string"
                 },
-                "path": {
-                    "type": "string"
+                "day_of_week": {
+                    "type": "integer"
+                },
+                "prompt_tokens": {
+                    "type": "integer"
+                },
+                "session_count": {
+                    "type": "integer"
                 }
             }
         },
-        "models.ProjectsResponse": {
+        "stats.HourDayHeatmapPt": {
             "type": "object",
             "properties": {
-                "projects": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.ProjectResponse"
-                    }
+                "day_of_week": {
+                    "type": "integer"
+                },
+                "hour": {
+                    "type": "integer"
+                },
+                "session_count": {
+                    "type": "integer"
                 }
             }
         },
-        "models.PromptRequest": {
+        "stats.HourlyUsage": {
             "type": "object",
             "properties": {
-                "agent": {
-                    "type": "string"
-                },
-                "messageID": {
-                    "type": "string"
-                },
-                "model": {
-                    "$ref": "#/definitions/models.ModelSpec"
-                },
-                "noReply": {
-                    "type": "boolean"
+                "hour": {
+                    "type": "integer"
                 },
-                "parts": {
-                    "type": "array",
-                    "items": {}
+                "session_count": {
+                    "type": "integer"
                 }
             }
         },
-        "models.PromptResponse": {
+        "stats.ModelUsage": {
             "type": "object",
             "properties": {
-                "info": {
-                    "$ref": "#/definitions/models.AssistantMessage"
+                "message_count": {
+                    "type": "integer"
                 },
-                "parts": {
-                    "type": "array",
-                    "items": {}
+                "model": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
                 }
             }
         },
-        "models.ProviderInfo": {
+        "stats.SessionCost": {
             "type": "object",
             "properties": {
-                "configured": {
-                    "type": "boolean"
+                "completion_tokens": {
+                    "type": "integer"
                 },
-                "name": {
+                "cost": {
+                    "type": "number"
+                },
+                "created_at": {
                     "type": "string"
                 },
-                "type": {
+                "id": {
+                    "type": "string"
+                },
+                "message_count": {
+                    "type": "integer"
+                },
+                "project": {
+                    "description": "Project is the path of the project the session belongs to. It is only\nset by the caller when merging the stats of several projects.",
+                    "type": "string"
+                },
+                "prompt_tokens": {
+                    "type": "integer"
+                },
+                "title": {
                     "type": "string"
+                },
+                "total_tokens": {
+                    "type": "integer"
                 }
             }
         },
-        "models.SSEEvent": {
+        "stats.ToolUsage": {
             "type": "object",
             "properties": {
-                "properties": {
-                    "description": "Properties 字段包含了事件的具体数据。其结构取决于 Type 字段。"
+                "call_count": {
+                    "type": "integer"
                 },
-                "type": {
-                    "description": "Type 字段指示事件的类型，例如 \"message.created\", \"session.updated\" 等。",
+                "tool_name": {
                     "type": "string"
                 }
             }
         },
-        "models.SearchResponse": {
+        "stats.TotalStats": {
             "type": "object",
             "properties": {
-                "count": {
+                "avg_messages_per_session": {
+                    "type": "number"
+                },
+                "avg_tokens_per_session": {
+                    "type": "number"
+                },
+                "total_completion_tokens": {
                     "type": "integer"
                 },
-                "query": {
-                    "type": "string"
+                "total_cost": {
+                    "type": "number"
                 },
-                "results": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.SearchResultItem"
-                    }
+                "total_messages": {
+                    "type": "integer"
+                },
+                "total_prompt_tokens": {
+                    "type": "integer"
+                },
+                "total_sessions": {
+                    "type": "integer"
+                },
+                "total_tokens": {
+                    "type": "integer"
                 }
             }
         },
-        "models.SearchResultItem": {
+        "transcript.File": {
             "type": "object",
             "properties": {
                 "content": {
                     "type": "string"
                 },
-                "line": {
+                "created_at": {
                     "type": "integer"
                 },
-                "match_end": {
-                    "type": "integer"
+                "path": {
+                    "type": "string"
                 },
-                "match_start": {
+                "updated_at": {
                     "type": "integer"
                 },
-                "path": {
-                    "type": "string"
+                "version": {
+                    "type": "integer"
                 }
             }
         },
-        "models.SessionDetailResponse": {
+        "transcript.Message": {
             "type": "object",
             "properties": {
-                "session": {
-                    "$ref": "#/definitions/models.SessionResponse"
+                "created_at": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_summary_message": {
+                    "type": "boolean"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "parts": {
+                    "description": "Parts are encoded as {\"type\": \"text\", \"data\": {...}} objects, where type\nis one of text, reasoning, image_url, binary, tool_call, tool_result and\nfinish.",
+                    "type": "array",
+                    "items": {}
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/message.MessageRole"
+                },
+                "updated_at": {
+                    "type": "integer"
                 }
             }
         },
-        "models.SessionResponse": {
+        "transcript.Session": {
             "type": "object",
             "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/transcript.Session"
+                    }
+                },
                 "completion_tokens": {
                     "type": "integer"
                 },
@@ -2103,14 +6941,20 @@ const docTemplate = `{
                 "created_at": {
                     "type": "integer"
                 },
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/transcript.File"
+                    }
+                },
                 "id": {
                     "type": "string"
                 },
-                "message_count": {
-                    "type": "integer"
-                },
-                "parent_session_id": {
-                    "type": "string"
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/transcript.Message"
+                    }
                 },
                 "prompt_tokens": {
                     "type": "integer"
@@ -2124,7 +6968,7 @@ const docTemplate = `{
                 "todos": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.TodoResponse"
+                        "$ref": "#/definitions/session.Todo"
                     }
                 },
                 "updated_at": {
@@ -2132,107 +6976,86 @@ const docTemplate = `{
                 }
             }
         },
-        "models.SessionsResponse": {
+        "transcript.Transcript": {
             "type": "object",
             "properties": {
-                "sessions": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.SessionResponse"
-                    }
+                "directory": {
+                    "type": "string"
                 },
-                "total": {
+                "exported_at": {
+                    "type": "integer"
+                },
+                "session": {
+                    "$ref": "#/definitions/transcript.Session"
+                },
+                "version": {
                     "type": "integer"
                 }
             }
         },
-        "models.TodoResponse": {
+        "webhook.Delivery": {
             "type": "object",
             "properties": {
-                "active_form": {
-                    "type": "string"
+                "attempts": {
+                    "type": "integer"
                 },
-                "content": {
+                "created_at": {
                     "type": "string"
                 },
-                "status": {
+                "error": {
+                    "description": "Error is the error of the last attempt.",
                     "type": "string"
-                }
-            }
-        },
-        "models.TokenInfo": {
-            "type": "object",
-            "properties": {
-                "cache": {
-                    "$ref": "#/definitions/models.CacheTokenInfo"
                 },
-                "input": {
-                    "type": "integer"
+                "event": {
+                    "type": "string"
                 },
-                "output": {
-                    "type": "integer"
+                "finished_at": {
+                    "type": "string"
                 },
-                "reasoning": {
-                    "type": "integer"
-                }
-            }
-        },
-        "models.UpdateSessionRequest": {
-            "type": "object",
-            "properties": {
-                "title": {
+                "id": {
+                    "description": "ID is the ID of the event.",
                     "type": "string"
-                }
-            }
-        },
-        "models.UpdateSessionResponse": {
-            "type": "object",
-            "properties": {
-                "session": {
-                    "$ref": "#/definitions/models.SessionResponse"
-                }
-            }
-        },
-        "models.UpdateSystemPromptRequest": {
-            "type": "object",
-            "required": [
-                "system_prompt"
-            ],
-            "properties": {
-                "system_prompt": {
-                    "description": "SystemPrompt 新的系统提示词内容",
+                },
+                "next_attempt_at": {
                     "type": "string"
-                }
-            }
-        },
-        "models.UpdateSystemPromptResponse": {
-            "type": "object",
-            "properties": {
-                "message": {
-                    "description": "Message 操作结果消息（可选）",
+                },
+                "session_id": {
                     "type": "string"
                 },
-                "success": {
-                    "description": "Success 是否成功更新",
-                    "type": "boolean"
+                "status": {
+                    "$ref": "#/definitions/webhook.DeliveryStatus"
                 },
-                "system_prompt": {
-                    "description": "SystemPrompt 更新后的系统提示词内容",
-                    "type": "string"
+                "status_code": {
+                    "type": "integer"
                 }
             }
+        },
+        "webhook.DeliveryStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "delivered",
+                "failed",
+                "dropped"
+            ],
+            "x-enum-varnames": [
+                "DeliveryPending",
+                "DeliveryDelivered",
+                "DeliveryFailed",
+                "DeliveryDropped"
+            ]
         }
     }
 }`
 
 // SwaggerInfo holds exported Swagger Info so clients can modify it
 var SwaggerInfo = &swag.Spec{
-	Version:          "",
-	Host:             "",
-	BasePath:         "",
-	Schemes:          []string{},
-	Title:            "",
-	Description:      "",
+	Version:          "1.0",
+	Host:             "localhost:8080",
+	BasePath:         "/",
+	Schemes:          []string{"http", "https"},
+	Title:            "Zork Agent API",
+	Description:      "AI 项目管理 API 服务",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
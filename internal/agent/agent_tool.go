@@ -25,11 +25,11 @@ const (
 )
 
 func (c *coordinator) agentTool(ctx context.Context) (fantasy.AgentTool, error) {
-	agentCfg, ok := c.cfg.Agents[config.AgentTask]
+	agentCfg, ok := c.cfg.Load().Agents[config.AgentTask]
 	if !ok {
 		return nil, errors.New("task agent not configured")
 	}
-	prompt, err := taskPrompt(prompt.WithWorkingDir(c.cfg.WorkingDir()))
+	prompt, err := taskPrompt(prompt.WithWorkingDir(c.cfg.Load().WorkingDir()))
 	if err != nil {
 		return nil, err
 	}
@@ -61,13 +61,14 @@ func (c *coordinator) agentTool(ctx context.Context) (fantasy.AgentTool, error)
 			if err != nil {
 				return fantasy.ToolResponse{}, fmt.Errorf("error creating session: %s", err)
 			}
+			c.permissions.SetSessionMode(session.ID, c.permissions.SessionMode(sessionID))
 			model := agent.Model()
 			maxTokens := model.CatwalkCfg.DefaultMaxTokens
 			if model.ModelCfg.MaxTokens != 0 {
 				maxTokens = model.ModelCfg.MaxTokens
 			}
 
-			providerCfg, ok := c.cfg.Providers.Get(model.ModelCfg.Provider)
+			providerCfg, ok := c.cfg.Load().Providers.Get(model.ModelCfg.Provider)
 			if !ok {
 				return fantasy.ToolResponse{}, errors.New("model provider not configured")
 			}
@@ -14,12 +14,14 @@ import (
 	"os"
 	"slices"
 	"strings"
+	"sync/atomic"
 
 	"charm.land/catwalk/pkg/catwalk"
 	"charm.land/fantasy"
 	"github.com/charmbracelet/crush/internal/agent/hyper"
 	"github.com/charmbracelet/crush/internal/agent/prompt"
 	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/charmbracelet/crush/internal/audit"
 	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/csync"
 	"github.com/charmbracelet/crush/internal/filetracker"
@@ -47,6 +49,7 @@ type Coordinator interface {
 	// INFO: (kujtim) this is not used yet we will use this when we have multiple agents
 	// SetMainAgent(string)
 	Run(ctx context.Context, sessionID, prompt string, attachments ...message.Attachment) (*fantasy.AgentResult, error)
+	RunWithOptions(ctx context.Context, sessionID, prompt string, opts RunOptions, attachments ...message.Attachment) (*fantasy.AgentResult, error)
 	Cancel(sessionID string)
 	CancelAll()
 	IsSessionBusy(sessionID string) bool
@@ -55,17 +58,40 @@ type Coordinator interface {
 	QueuedPromptsList(sessionID string) []string
 	ClearQueue(sessionID string)
 	Summarize(context.Context, string) error
+	// SummarizeWithOptions summarizes the session like Summarize, with the
+	// large model of opts when set. The other fields of opts are ignored.
+	SummarizeWithOptions(ctx context.Context, sessionID string, opts RunOptions) error
 	Model() Model
 	UpdateModels(ctx context.Context) error
+	// SetConfig replaces the configuration the models and tools are built
+	// from. It takes effect on the next UpdateModels or run.
+	SetConfig(cfg *config.Config)
+}
+
+// RunOptions overrides the model selection and call parameters for a single
+// run. Zero values fall back to the configuration. The shared configuration
+// is never modified, so other sessions are not affected.
+type RunOptions struct {
+	// LargeModel replaces the configured large model for this run.
+	LargeModel *config.SelectedModel
+	// SmallModel replaces the configured small model for this run.
+	SmallModel *config.SelectedModel
+	// ReasoningEffort overrides the reasoning effort of the large model.
+	ReasoningEffort string
+	// Temperature overrides the sampling temperature.
+	Temperature *float64
+	// MaxOutputTokens overrides the maximum number of output tokens.
+	MaxOutputTokens int64
 }
 
 type coordinator struct {
-	cfg         *config.Config
+	cfg         atomic.Pointer[config.Config]
 	sessions    session.Service
 	messages    message.Service
 	permissions permission.Service
 	history     history.Service
 	filetracker filetracker.Service
+	audit       audit.Service
 	lspClients  *csync.Map[string, *lsp.Client]
 
 	currentAgent SessionAgent
@@ -82,18 +108,20 @@ func NewCoordinator(
 	permissions permission.Service,
 	history history.Service,
 	filetracker filetracker.Service,
+	audit audit.Service,
 	lspClients *csync.Map[string, *lsp.Client],
 ) (Coordinator, error) {
 	c := &coordinator{
-		cfg:         cfg,
 		sessions:    sessions,
 		messages:    messages,
 		permissions: permissions,
 		history:     history,
 		filetracker: filetracker,
+		audit:       audit,
 		lspClients:  lspClients,
 		agents:      make(map[string]SessionAgent),
 	}
+	c.cfg.Store(cfg)
 
 	agentCfg, ok := cfg.Agents[config.AgentCoder]
 	if !ok {
@@ -101,7 +129,7 @@ func NewCoordinator(
 	}
 
 	// TODO: make this dynamic when we support multiple agents
-	prompt, err := coderPrompt(prompt.WithWorkingDir(c.cfg.WorkingDir()))
+	prompt, err := coderPrompt(prompt.WithWorkingDir(c.cfg.Load().WorkingDir()))
 	if err != nil {
 		return nil, err
 	}
@@ -117,6 +145,11 @@ func NewCoordinator(
 
 // Run implements Coordinator.
 func (c *coordinator) Run(ctx context.Context, sessionID string, prompt string, attachments ...message.Attachment) (*fantasy.AgentResult, error) {
+	return c.RunWithOptions(ctx, sessionID, prompt, RunOptions{}, attachments...)
+}
+
+// RunWithOptions implements Coordinator.
+func (c *coordinator) RunWithOptions(ctx context.Context, sessionID string, prompt string, opts RunOptions, attachments ...message.Attachment) (*fantasy.AgentResult, error) {
 	if err := c.readyWg.Wait(); err != nil {
 		return nil, err
 	}
@@ -127,6 +160,37 @@ func (c *coordinator) Run(ctx context.Context, sessionID string, prompt string,
 	}
 
 	model := c.currentAgent.Model()
+	if opts.LargeModel != nil {
+		largeModel, err := c.buildModel(ctx, *opts.LargeModel, false)
+		if err != nil {
+			return nil, err
+		}
+		model = largeModel
+	}
+	var smallModel *Model
+	if opts.SmallModel != nil {
+		m, err := c.buildModel(ctx, *opts.SmallModel, true)
+		if err != nil {
+			return nil, err
+		}
+		smallModel = &m
+	}
+
+	// model is a copy, so these overrides only apply to this run.
+	if opts.ReasoningEffort != "" {
+		model.ModelCfg.ReasoningEffort = opts.ReasoningEffort
+	}
+	if opts.Temperature != nil {
+		model.ModelCfg.Temperature = opts.Temperature
+	}
+	if opts.MaxOutputTokens > 0 {
+		model.ModelCfg.MaxTokens = opts.MaxOutputTokens
+	}
+	var largeModel *Model
+	if opts.LargeModel != nil {
+		largeModel = &model
+	}
+
 	maxTokens := model.CatwalkCfg.DefaultMaxTokens
 	if model.ModelCfg.MaxTokens != 0 {
 		maxTokens = model.ModelCfg.MaxTokens
@@ -143,7 +207,7 @@ func (c *coordinator) Run(ctx context.Context, sessionID string, prompt string,
 		attachments = filteredAttachments
 	}
 
-	providerCfg, ok := c.cfg.Providers.Get(model.ModelCfg.Provider)
+	providerCfg, ok := c.cfg.Load().Providers.Get(model.ModelCfg.Provider)
 	if !ok {
 		return nil, errors.New("model provider not configured")
 	}
@@ -169,6 +233,8 @@ func (c *coordinator) Run(ctx context.Context, sessionID string, prompt string,
 			TopK:             topK,
 			FrequencyPenalty: freqPenalty,
 			PresencePenalty:  presPenalty,
+			LargeModel:       largeModel,
+			SmallModel:       smallModel,
 		})
 	}
 	result, originalErr := run()
@@ -331,14 +397,14 @@ func (c *coordinator) buildAgent(ctx context.Context, prompt *prompt.Prompt, age
 		return nil, err
 	}
 
-	largeProviderCfg, _ := c.cfg.Providers.Get(large.ModelCfg.Provider)
+	largeProviderCfg, _ := c.cfg.Load().Providers.Get(large.ModelCfg.Provider)
 	result := NewSessionAgent(SessionAgentOptions{
 		large,
 		small,
 		largeProviderCfg.SystemPromptPrefix,
 		"",
 		isSubAgent,
-		c.cfg.Options.DisableAutoSummarize,
+		c.cfg.Load().Options.DisableAutoSummarize,
 		c.permissions.SkipRequests(),
 		c.sessions,
 		c.messages,
@@ -346,7 +412,7 @@ func (c *coordinator) buildAgent(ctx context.Context, prompt *prompt.Prompt, age
 	})
 
 	c.readyWg.Go(func() error {
-		systemPrompt, err := prompt.Build(ctx, large.Model.Provider(), large.Model.Model(), *c.cfg)
+		systemPrompt, err := prompt.Build(ctx, large.Model.Provider(), large.Model.Model(), *c.cfg.Load())
 		if err != nil {
 			return err
 		}
@@ -386,30 +452,30 @@ func (c *coordinator) buildTools(ctx context.Context, agent config.Agent) ([]fan
 
 	// Get the model name for the agent
 	modelName := ""
-	if modelCfg, ok := c.cfg.Models[agent.Model]; ok {
-		if model := c.cfg.GetModel(modelCfg.Provider, modelCfg.Model); model != nil {
+	if modelCfg, ok := c.cfg.Load().Models[agent.Model]; ok {
+		if model := c.cfg.Load().GetModel(modelCfg.Provider, modelCfg.Model); model != nil {
 			modelName = model.Name
 		}
 	}
 
 	allTools = append(allTools,
-		tools.NewBashTool(c.permissions, c.cfg.WorkingDir(), c.cfg.Options.Attribution, modelName),
+		tools.NewBashTool(c.permissions, c.cfg.Load().WorkingDir(), c.cfg.Load().Options.Attribution, modelName),
 		tools.NewJobOutputTool(),
 		tools.NewJobKillTool(),
-		tools.NewDownloadTool(c.permissions, c.cfg.WorkingDir(), nil),
-		tools.NewEditTool(c.lspClients, c.permissions, c.history, c.filetracker, c.cfg.WorkingDir()),
-		tools.NewMultiEditTool(c.lspClients, c.permissions, c.history, c.filetracker, c.cfg.WorkingDir()),
-		tools.NewFetchTool(c.permissions, c.cfg.WorkingDir(), nil),
-		tools.NewGlobTool(c.cfg.WorkingDir()),
-		tools.NewGrepTool(c.cfg.WorkingDir()),
-		tools.NewLsTool(c.permissions, c.cfg.WorkingDir(), c.cfg.Tools.Ls),
+		tools.NewDownloadTool(c.permissions, c.cfg.Load().WorkingDir(), nil),
+		tools.NewEditTool(c.lspClients, c.permissions, c.history, c.filetracker, c.cfg.Load().WorkingDir()),
+		tools.NewMultiEditTool(c.lspClients, c.permissions, c.history, c.filetracker, c.cfg.Load().WorkingDir()),
+		tools.NewFetchTool(c.permissions, c.cfg.Load().WorkingDir(), nil),
+		tools.NewGlobTool(c.cfg.Load().WorkingDir()),
+		tools.NewGrepTool(c.cfg.Load().WorkingDir()),
+		tools.NewLsTool(c.permissions, c.cfg.Load().WorkingDir(), c.cfg.Load().Tools.Ls),
 		tools.NewSourcegraphTool(nil),
 		tools.NewTodosTool(c.sessions),
-		tools.NewViewTool(c.lspClients, c.permissions, c.filetracker, c.cfg.WorkingDir(), c.cfg.Options.SkillsPaths...),
-		tools.NewWriteTool(c.lspClients, c.permissions, c.history, c.filetracker, c.cfg.WorkingDir()),
+		tools.NewViewTool(c.lspClients, c.permissions, c.filetracker, c.cfg.Load().WorkingDir(), c.cfg.Load().Options.SkillsPaths...),
+		tools.NewWriteTool(c.lspClients, c.permissions, c.history, c.filetracker, c.cfg.Load().WorkingDir()),
 	)
 
-	if len(c.cfg.LSP) > 0 {
+	if len(c.cfg.Load().LSP) > 0 {
 		allTools = append(allTools, tools.NewDiagnosticsTool(c.lspClients), tools.NewReferencesTool(c.lspClients), tools.NewLSPRestartTool(c.lspClients))
 	}
 
@@ -420,7 +486,7 @@ func (c *coordinator) buildTools(ctx context.Context, agent config.Agent) ([]fan
 		}
 	}
 
-	for _, tool := range tools.GetMCPTools(c.permissions, c.cfg.WorkingDir()) {
+	for _, tool := range tools.GetMCPTools(c.permissions, c.cfg.Load().WorkingDir()) {
 		if agent.AllowedMCP == nil {
 			// No MCP restrictions
 			filteredTools = append(filteredTools, tool)
@@ -445,21 +511,24 @@ func (c *coordinator) buildTools(ctx context.Context, agent config.Agent) ([]fan
 	slices.SortFunc(filteredTools, func(a, b fantasy.AgentTool) int {
 		return strings.Compare(a.Info().Name, b.Info().Name)
 	})
+	for i, tool := range filteredTools {
+		filteredTools[i] = auditedTool{measuredTool{tool}, c.audit}
+	}
 	return filteredTools, nil
 }
 
 // TODO: when we support multiple agents we need to change this so that we pass in the agent specific model config
 func (c *coordinator) buildAgentModels(ctx context.Context, isSubAgent bool) (Model, Model, error) {
-	largeModelCfg, ok := c.cfg.Models[config.SelectedModelTypeLarge]
+	largeModelCfg, ok := c.cfg.Load().Models[config.SelectedModelTypeLarge]
 	if !ok {
 		return Model{}, Model{}, errors.New("large model not selected")
 	}
-	smallModelCfg, ok := c.cfg.Models[config.SelectedModelTypeSmall]
+	smallModelCfg, ok := c.cfg.Load().Models[config.SelectedModelTypeSmall]
 	if !ok {
 		return Model{}, Model{}, errors.New("small model not selected")
 	}
 
-	largeProviderCfg, ok := c.cfg.Providers.Get(largeModelCfg.Provider)
+	largeProviderCfg, ok := c.cfg.Load().Providers.Get(largeModelCfg.Provider)
 	if !ok {
 		return Model{}, Model{}, errors.New("large model provider not configured")
 	}
@@ -469,7 +538,7 @@ func (c *coordinator) buildAgentModels(ctx context.Context, isSubAgent bool) (Mo
 		return Model{}, Model{}, err
 	}
 
-	smallProviderCfg, ok := c.cfg.Providers.Get(smallModelCfg.Provider)
+	smallProviderCfg, ok := c.cfg.Load().Providers.Get(smallModelCfg.Provider)
 	if !ok {
 		return Model{}, Model{}, errors.New("large model provider not configured")
 	}
@@ -532,6 +601,46 @@ func (c *coordinator) buildAgentModels(ctx context.Context, isSubAgent bool) (Mo
 		}, nil
 }
 
+// buildModel builds a single model from the given selection, without
+// touching the models selected in the configuration.
+func (c *coordinator) buildModel(ctx context.Context, selected config.SelectedModel, isSubAgent bool) (Model, error) {
+	providerCfg, ok := c.cfg.Load().Providers.Get(selected.Provider)
+	if !ok || providerCfg.Disable {
+		return Model{}, fmt.Errorf("%w: provider %q not configured", ErrModelNotFound, selected.Provider)
+	}
+
+	var catwalkModel *catwalk.Model
+	for _, m := range providerCfg.Models {
+		if m.ID == selected.Model {
+			catwalkModel = &m
+			break
+		}
+	}
+	if catwalkModel == nil {
+		return Model{}, fmt.Errorf("%w: %q not found in provider %q", ErrModelNotFound, selected.Model, selected.Provider)
+	}
+
+	provider, err := c.buildProvider(providerCfg, selected, isSubAgent)
+	if err != nil {
+		return Model{}, err
+	}
+
+	modelID := selected.Model
+	if selected.Provider == openrouter.Name && isExactoSupported(modelID) {
+		modelID += ":exacto"
+	}
+	languageModel, err := provider.LanguageModel(ctx, modelID)
+	if err != nil {
+		return Model{}, err
+	}
+
+	return Model{
+		Model:      languageModel,
+		CatwalkCfg: *catwalkModel,
+		ModelCfg:   selected,
+	}, nil
+}
+
 func (c *coordinator) buildAnthropicProvider(baseURL, apiKey string, headers map[string]string) (fantasy.Provider, error) {
 	var opts []anthropic.Option
 
@@ -552,7 +661,7 @@ func (c *coordinator) buildAnthropicProvider(baseURL, apiKey string, headers map
 		opts = append(opts, anthropic.WithBaseURL(baseURL))
 	}
 
-	if c.cfg.Options.Debug {
+	if c.cfg.Load().Options.Debug {
 		httpClient := log.NewHTTPClient()
 		opts = append(opts, anthropic.WithHTTPClient(httpClient))
 	}
@@ -564,7 +673,7 @@ func (c *coordinator) buildOpenaiProvider(baseURL, apiKey string, headers map[st
 		openai.WithAPIKey(apiKey),
 		openai.WithUseResponsesAPI(),
 	}
-	if c.cfg.Options.Debug {
+	if c.cfg.Load().Options.Debug {
 		httpClient := log.NewHTTPClient()
 		opts = append(opts, openai.WithHTTPClient(httpClient))
 	}
@@ -581,7 +690,7 @@ func (c *coordinator) buildOpenrouterProvider(_, apiKey string, headers map[stri
 	opts := []openrouter.Option{
 		openrouter.WithAPIKey(apiKey),
 	}
-	if c.cfg.Options.Debug {
+	if c.cfg.Load().Options.Debug {
 		httpClient := log.NewHTTPClient()
 		opts = append(opts, openrouter.WithHTTPClient(httpClient))
 	}
@@ -601,8 +710,8 @@ func (c *coordinator) buildOpenaiCompatProvider(baseURL, apiKey string, headers
 	var httpClient *http.Client
 	if providerID == string(catwalk.InferenceProviderCopilot) {
 		opts = append(opts, openaicompat.WithUseResponsesAPI())
-		httpClient = copilot.NewClient(isSubAgent, c.cfg.Options.Debug)
-	} else if c.cfg.Options.Debug {
+		httpClient = copilot.NewClient(isSubAgent, c.cfg.Load().Options.Debug)
+	} else if c.cfg.Load().Options.Debug {
 		httpClient = log.NewHTTPClient()
 	}
 	if httpClient != nil {
@@ -626,7 +735,7 @@ func (c *coordinator) buildAzureProvider(baseURL, apiKey string, headers map[str
 		azure.WithAPIKey(apiKey),
 		azure.WithUseResponsesAPI(),
 	}
-	if c.cfg.Options.Debug {
+	if c.cfg.Load().Options.Debug {
 		httpClient := log.NewHTTPClient()
 		opts = append(opts, azure.WithHTTPClient(httpClient))
 	}
@@ -645,7 +754,7 @@ func (c *coordinator) buildAzureProvider(baseURL, apiKey string, headers map[str
 
 func (c *coordinator) buildBedrockProvider(headers map[string]string) (fantasy.Provider, error) {
 	var opts []bedrock.Option
-	if c.cfg.Options.Debug {
+	if c.cfg.Load().Options.Debug {
 		httpClient := log.NewHTTPClient()
 		opts = append(opts, bedrock.WithHTTPClient(httpClient))
 	}
@@ -664,7 +773,7 @@ func (c *coordinator) buildGoogleProvider(baseURL, apiKey string, headers map[st
 		google.WithBaseURL(baseURL),
 		google.WithGeminiAPIKey(apiKey),
 	}
-	if c.cfg.Options.Debug {
+	if c.cfg.Load().Options.Debug {
 		httpClient := log.NewHTTPClient()
 		opts = append(opts, google.WithHTTPClient(httpClient))
 	}
@@ -676,7 +785,7 @@ func (c *coordinator) buildGoogleProvider(baseURL, apiKey string, headers map[st
 
 func (c *coordinator) buildGoogleVertexProvider(headers map[string]string, options map[string]string) (fantasy.Provider, error) {
 	opts := []google.Option{}
-	if c.cfg.Options.Debug {
+	if c.cfg.Load().Options.Debug {
 		httpClient := log.NewHTTPClient()
 		opts = append(opts, google.WithHTTPClient(httpClient))
 	}
@@ -697,7 +806,7 @@ func (c *coordinator) buildHyperProvider(baseURL, apiKey string) (fantasy.Provid
 		hyper.WithBaseURL(baseURL),
 		hyper.WithAPIKey(apiKey),
 	}
-	if c.cfg.Options.Debug {
+	if c.cfg.Load().Options.Debug {
 		httpClient := log.NewHTTPClient()
 		opts = append(opts, hyper.WithHTTPClient(httpClient))
 	}
@@ -738,8 +847,8 @@ func (c *coordinator) buildProvider(providerCfg config.ProviderConfig, model con
 		}
 	}
 
-	apiKey, _ := c.cfg.Resolve(providerCfg.APIKey)
-	baseURL, _ := c.cfg.Resolve(providerCfg.BaseURL)
+	apiKey, _ := c.cfg.Load().Resolve(providerCfg.APIKey)
+	baseURL, _ := c.cfg.Load().Resolve(providerCfg.BaseURL)
 
 	switch providerCfg.Type {
 	case openai.Name:
@@ -806,6 +915,10 @@ func (c *coordinator) Model() Model {
 	return c.currentAgent.Model()
 }
 
+func (c *coordinator) SetConfig(cfg *config.Config) {
+	c.cfg.Store(cfg)
+}
+
 func (c *coordinator) UpdateModels(ctx context.Context) error {
 	// build the models again so we make sure we get the latest config
 	large, small, err := c.buildAgentModels(ctx, false)
@@ -814,7 +927,7 @@ func (c *coordinator) UpdateModels(ctx context.Context) error {
 	}
 	c.currentAgent.SetModels(large, small)
 
-	agentCfg, ok := c.cfg.Agents[config.AgentCoder]
+	agentCfg, ok := c.cfg.Load().Agents[config.AgentCoder]
 	if !ok {
 		return errors.New("coder agent not configured")
 	}
@@ -836,11 +949,23 @@ func (c *coordinator) QueuedPromptsList(sessionID string) []string {
 }
 
 func (c *coordinator) Summarize(ctx context.Context, sessionID string) error {
-	providerCfg, ok := c.cfg.Providers.Get(c.currentAgent.Model().ModelCfg.Provider)
+	return c.SummarizeWithOptions(ctx, sessionID, RunOptions{})
+}
+
+func (c *coordinator) SummarizeWithOptions(ctx context.Context, sessionID string, opts RunOptions) error {
+	model := c.currentAgent.Model()
+	if opts.LargeModel != nil {
+		largeModel, err := c.buildModel(ctx, *opts.LargeModel, false)
+		if err != nil {
+			return err
+		}
+		model = largeModel
+	}
+	providerCfg, ok := c.cfg.Load().Providers.Get(model.ModelCfg.Provider)
 	if !ok {
 		return errors.New("model provider not configured")
 	}
-	return c.currentAgent.Summarize(ctx, sessionID, getProviderOptions(c.currentAgent.Model(), providerCfg))
+	return c.currentAgent.SummarizeWithModel(ctx, sessionID, model, getProviderOptions(model, providerCfg))
 }
 
 func (c *coordinator) isUnauthorized(err error) bool {
@@ -849,7 +974,7 @@ func (c *coordinator) isUnauthorized(err error) bool {
 }
 
 func (c *coordinator) refreshOAuth2Token(ctx context.Context, providerCfg config.ProviderConfig) error {
-	if err := c.cfg.RefreshOAuthToken(ctx, providerCfg.ID); err != nil {
+	if err := c.cfg.Load().RefreshOAuthToken(ctx, providerCfg.ID); err != nil {
 		slog.Error("Failed to refresh OAuth token after 401 error", "provider", providerCfg.ID, "error", err)
 		return err
 	}
@@ -860,14 +985,14 @@ func (c *coordinator) refreshOAuth2Token(ctx context.Context, providerCfg config
 }
 
 func (c *coordinator) refreshApiKeyTemplate(ctx context.Context, providerCfg config.ProviderConfig) error {
-	newAPIKey, err := c.cfg.Resolve(providerCfg.APIKeyTemplate)
+	newAPIKey, err := c.cfg.Load().Resolve(providerCfg.APIKeyTemplate)
 	if err != nil {
 		slog.Error("Failed to re-resolve API key after 401 error", "provider", providerCfg.ID, "error", err)
 		return err
 	}
 
 	providerCfg.APIKey = newAPIKey
-	c.cfg.Providers.Set(providerCfg.ID, providerCfg)
+	c.cfg.Load().Providers.Set(providerCfg.ID, providerCfg)
 
 	if err := c.UpdateModels(ctx); err != nil {
 		return err
@@ -167,11 +167,7 @@ func (p *Prompt) promptData(ctx context.Context, provider, model string, cfg con
 	// Discover and load skills metadata.
 	var availSkillXML string
 	if len(cfg.Options.SkillsPaths) > 0 {
-		expandedPaths := make([]string, 0, len(cfg.Options.SkillsPaths))
-		for _, pth := range cfg.Options.SkillsPaths {
-			expandedPaths = append(expandedPaths, expandPath(pth, cfg))
-		}
-		if discoveredSkills := skills.Discover(expandedPaths); len(discoveredSkills) > 0 {
+		if discoveredSkills := skills.Discover(cfg.SkillsDirs()); len(discoveredSkills) > 0 {
 			availSkillXML = skills.ToPromptXML(discoveredSkills)
 		}
 	}
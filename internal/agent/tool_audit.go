@@ -0,0 +1,51 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/agent/tools"
+	"github.com/charmbracelet/crush/internal/audit"
+)
+
+// auditedTool records the executions of the wrapped tool in the audit log.
+type auditedTool struct {
+	fantasy.AgentTool
+	audit audit.Service
+}
+
+func (t auditedTool) Run(ctx context.Context, params fantasy.ToolCall) (fantasy.ToolResponse, error) {
+	start := time.Now()
+	resp, err := t.AgentTool.Run(ctx, params)
+
+	entry := audit.Entry{
+		Kind:       audit.KindTool,
+		SessionID:  tools.GetSessionFromContext(ctx),
+		ToolCallID: params.ID,
+		ToolName:   t.Info().Name,
+		Params:     params.Input,
+		Status:     audit.StatusSuccess,
+		CreatedAt:  start,
+		FinishedAt: time.Now(),
+	}
+	switch {
+	case err != nil:
+		entry.Status = audit.StatusError
+		entry.Error = err.Error()
+	case resp.IsError:
+		entry.Status = audit.StatusError
+		entry.Error = resp.Content
+	}
+	// Tools running commands report the exit code in their metadata, see
+	// [tools.BashResponseMetadata].
+	var metadata struct {
+		ExitCode *int `json:"exit_code"`
+	}
+	if resp.Metadata != "" && json.Unmarshal([]byte(resp.Metadata), &metadata) == nil {
+		entry.ExitCode = metadata.ExitCode
+	}
+	t.audit.Record(ctx, entry)
+	return resp, err
+}
@@ -0,0 +1,21 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	"charm.land/fantasy"
+	"github.com/charmbracelet/crush/internal/metrics"
+)
+
+// measuredTool records the call count and duration of the wrapped tool.
+type measuredTool struct {
+	fantasy.AgentTool
+}
+
+func (t measuredTool) Run(ctx context.Context, params fantasy.ToolCall) (fantasy.ToolResponse, error) {
+	start := time.Now()
+	resp, err := t.AgentTool.Run(ctx, params)
+	metrics.ObserveToolCall(t.Info().Name, err != nil || resp.IsError, time.Since(start))
+	return resp, err
+}
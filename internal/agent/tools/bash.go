@@ -41,6 +41,8 @@ type BashResponseMetadata struct {
 	WorkingDirectory string `json:"working_directory"`
 	Background       bool   `json:"background,omitempty"`
 	ShellID          string `json:"shell_id,omitempty"`
+	// ExitCode is set for commands that finished before the tool returned.
+	ExitCode *int `json:"exit_code,omitempty"`
 }
 
 const (
@@ -268,6 +270,7 @@ func NewBashTool(permissions permission.Service, workingDir string, attribution
 						Description:      params.Description,
 						Background:       params.RunInBackground,
 						WorkingDirectory: bgShell.WorkingDir,
+						ExitCode:         &exitCode,
 					}
 					if stdout == "" {
 						return fantasy.WithResponseMetadata(fantasy.NewTextResponse(BashNoOutput), metadata), nil
@@ -349,6 +352,7 @@ func NewBashTool(permissions permission.Service, workingDir string, attribution
 					Description:      params.Description,
 					Background:       params.RunInBackground,
 					WorkingDirectory: bgShell.WorkingDir,
+					ExitCode:         &exitCode,
 				}
 				if stdout == "" {
 					return fantasy.WithResponseMetadata(fantasy.NewTextResponse(BashNoOutput), metadata), nil
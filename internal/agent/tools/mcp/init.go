@@ -31,6 +31,11 @@ var (
 	broker   = pubsub.NewBroker[Event]()
 	initOnce sync.Once
 	initDone = make(chan struct{})
+
+	// users counts the app instances sharing the MCP clients, so that one
+	// project shutting down in server mode doesn't close them for the others.
+	usersMu sync.Mutex
+	users   int
 )
 
 // State represents the current state of an MCP client
@@ -107,8 +112,27 @@ func GetState(name string) (ClientInfo, bool) {
 	return states.Get(name)
 }
 
-// Close closes all MCP clients. This should be called during application shutdown.
+// Retain registers an app instance using the MCP clients. Every call must be
+// paired with a call to Close.
+func Retain() {
+	usersMu.Lock()
+	defer usersMu.Unlock()
+	users++
+}
+
+// Close releases the MCP clients held by an app instance and closes them once
+// the last instance has released them. This should be called during
+// application shutdown.
 func Close() error {
+	usersMu.Lock()
+	if users > 1 {
+		users--
+		usersMu.Unlock()
+		return nil
+	}
+	users = 0
+	usersMu.Unlock()
+
 	var wg sync.WaitGroup
 	done := make(chan struct{}, 1)
 	go func() {
@@ -147,59 +171,101 @@ func Initialize(ctx context.Context, permissions permission.Service, cfg *config
 		// Set initial starting state
 		updateState(name, StateStarting, nil, nil, Counts{})
 
-		wg.Add(1)
-		go func(name string, m config.MCPConfig) {
-			defer func() {
-				wg.Done()
-				if r := recover(); r != nil {
-					var err error
-					switch v := r.(type) {
-					case error:
-						err = v
-					case string:
-						err = fmt.Errorf("panic: %s", v)
-					default:
-						err = fmt.Errorf("panic: %v", v)
-					}
-					updateState(name, StateError, err, nil, Counts{})
-					slog.Error("Panic in MCP client initialization", "error", err, "name", name)
-				}
-			}()
+		wg.Go(func() { connect(ctx, name, m, cfg.Resolver()) })
+	}
+	wg.Wait()
+	initOnce.Do(func() { close(initDone) })
+}
 
-			// createSession handles its own timeout internally.
-			session, err := createSession(ctx, name, m, cfg.Resolver())
-			if err != nil {
-				return
+// connect creates the session of an MCP client and loads its tools and
+// prompts, recording failures in the client state.
+func connect(ctx context.Context, name string, m config.MCPConfig, resolver config.VariableResolver) {
+	defer func() {
+		if r := recover(); r != nil {
+			var err error
+			switch v := r.(type) {
+			case error:
+				err = v
+			case string:
+				err = fmt.Errorf("panic: %s", v)
+			default:
+				err = fmt.Errorf("panic: %v", v)
 			}
+			updateState(name, StateError, err, nil, Counts{})
+			slog.Error("Panic in MCP client initialization", "error", err, "name", name)
+		}
+	}()
 
-			tools, err := getTools(ctx, session)
-			if err != nil {
-				slog.Error("Error listing tools", "error", err)
-				updateState(name, StateError, err, nil, Counts{})
-				session.Close()
-				return
-			}
+	// createSession handles its own timeout internally.
+	session, err := createSession(ctx, name, m, resolver)
+	if err != nil {
+		return
+	}
 
-			prompts, err := getPrompts(ctx, session)
-			if err != nil {
-				slog.Error("Error listing prompts", "error", err)
-				updateState(name, StateError, err, nil, Counts{})
-				session.Close()
-				return
-			}
+	tools, err := getTools(ctx, session)
+	if err != nil {
+		slog.Error("Error listing tools", "error", err)
+		updateState(name, StateError, err, nil, Counts{})
+		session.Close()
+		return
+	}
 
-			toolCount := updateTools(name, tools)
-			updatePrompts(name, prompts)
-			sessions.Set(name, session)
+	prompts, err := getPrompts(ctx, session)
+	if err != nil {
+		slog.Error("Error listing prompts", "error", err)
+		updateState(name, StateError, err, nil, Counts{})
+		session.Close()
+		return
+	}
 
-			updateState(name, StateConnected, nil, session, Counts{
-				Tools:   toolCount,
-				Prompts: len(prompts),
-			})
-		}(name, m)
+	toolCount := updateTools(name, tools)
+	updatePrompts(name, prompts)
+	sessions.Set(name, session)
+
+	updateState(name, StateConnected, nil, session, Counts{
+		Tools:   toolCount,
+		Prompts: len(prompts),
+	})
+}
+
+// Reconnect closes the client of the named MCP server, if any, and connects
+// again with m, for when its configuration changed. A disabled configuration
+// only closes the client.
+func Reconnect(ctx context.Context, name string, m config.MCPConfig, resolver config.VariableResolver) {
+	disconnect(name)
+	if m.Disabled {
+		updateState(name, StateDisabled, nil, nil, Counts{})
+		return
 	}
-	wg.Wait()
-	initOnce.Do(func() { close(initDone) })
+	updateState(name, StateStarting, nil, nil, Counts{})
+	connect(ctx, name, m, resolver)
+}
+
+// Remove closes the client of the named MCP server and forgets it, for when
+// it was removed from the configuration.
+func Remove(name string) {
+	disconnect(name)
+	states.Del(name)
+	broker.Publish(pubsub.DeletedEvent, Event{
+		Type:  EventStateChanged,
+		Name:  name,
+		State: StateDisabled,
+	})
+}
+
+// disconnect closes the session of an MCP client and drops its tools and
+// prompts.
+func disconnect(name string) {
+	if session, ok := sessions.Take(name); ok {
+		if err := session.Close(); err != nil &&
+			!errors.Is(err, io.EOF) &&
+			!errors.Is(err, context.Canceled) &&
+			err.Error() != "signal: killed" {
+			slog.Warn("Failed to close MCP client", "name", name, "error", err)
+		}
+	}
+	allTools.Del(name)
+	allPrompts.Del(name)
 }
 
 // WaitForInit blocks until MCP initialization is complete.
@@ -18,6 +18,15 @@ func Prompts() iter.Seq2[string, []*Prompt] {
 	return allPrompts.Seq2()
 }
 
+// ListPrompts fetches the prompts of the named MCP server.
+func ListPrompts(ctx context.Context, name string) ([]*Prompt, error) {
+	c, err := getOrRenewClient(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return getPrompts(ctx, c)
+}
+
 // GetPromptMessages retrieves the content of an MCP prompt with the given arguments.
 func GetPromptMessages(ctx context.Context, clientName, promptName string, args map[string]string) ([]string, error) {
 	c, err := getOrRenewClient(ctx, clientName)
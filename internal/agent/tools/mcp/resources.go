@@ -0,0 +1,30 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type Resource = mcp.Resource
+
+// ListResources fetches the resources of the named MCP server. Servers that
+// don't support resources have none.
+func ListResources(ctx context.Context, name string) ([]*Resource, error) {
+	c, err := getOrRenewClient(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if c.InitializeResult().Capabilities.Resources == nil {
+		return nil, nil
+	}
+
+	var resources []*Resource
+	for resource, err := range c.Resources(ctx, nil) {
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, resource)
+	}
+	return resources, nil
+}
@@ -31,6 +31,16 @@ func Tools() iter.Seq2[string, []*Tool] {
 	return allTools.Seq2()
 }
 
+// ListTools fetches the tools of the named MCP server, including the ones
+// disabled in its configuration.
+func ListTools(ctx context.Context, name string) ([]*Tool, error) {
+	c, err := getOrRenewClient(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return getTools(ctx, c)
+}
+
 // RunTool runs an MCP tool with the given input parameters.
 func RunTool(ctx context.Context, name, toolName string, input string) (ToolResult, error) {
 	var args map[string]any
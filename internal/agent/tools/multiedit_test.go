@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/charmbracelet/crush/internal/history"
 	"github.com/charmbracelet/crush/internal/permission"
@@ -28,12 +29,28 @@ func (m *mockPermissionService) GrantPersistent(req permission.PermissionRequest
 
 func (m *mockPermissionService) AutoApproveSession(sessionID string) {}
 
+func (m *mockPermissionService) SetSessionMode(sessionID string, mode permission.SessionMode) {}
+
+func (m *mockPermissionService) SessionMode(sessionID string) permission.SessionMode {
+	return permission.SessionModeAuto
+}
+
+func (m *mockPermissionService) SetRequestTimeout(timeout time.Duration) {}
+
+func (m *mockPermissionService) PendingRequests() []permission.PermissionRequest {
+	return nil
+}
+
 func (m *mockPermissionService) SetSkipRequests(skip bool) {}
 
 func (m *mockPermissionService) SkipRequests() bool {
 	return false
 }
 
+func (m *mockPermissionService) SetAllowedTools(tools []string) {}
+
+func (m *mockPermissionService) SetRecorder(recorder permission.Recorder) {}
+
 func (m *mockPermissionService) SubscribeNotifications(ctx context.Context) <-chan pubsub.Event[permission.PermissionNotification] {
 	return make(<-chan pubsub.Event[permission.PermissionNotification])
 }
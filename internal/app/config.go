@@ -0,0 +1,151 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sync"
+
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
+	"github.com/charmbracelet/crush/internal/config"
+)
+
+// ErrConfigNotApplied is returned by UpdateConfig when the change was saved
+// but the agent could not be updated to use it.
+var ErrConfigNotApplied = errors.New("configuration saved but not applied")
+
+// UpdateConfig applies edit to the configuration, see [config.Config.Update],
+// and re-initializes what the change affects without restarting the app:
+// changed MCP servers are reconnected, changed LSP servers restarted, and the
+// agent models and tools rebuilt. The configuration is replaced as a whole,
+// so readers of [App.Config] see either the old or the new one.
+func (app *App) UpdateConfig(ctx context.Context, edit, check func(*config.Config) error) error {
+	app.configMu.Lock()
+	defer app.configMu.Unlock()
+
+	old := app.Config()
+	cfg, err := old.Update(edit, check)
+	if err != nil {
+		return err
+	}
+
+	// The MCP clients read the process-wide configuration, which also sees
+	// the data config file.
+	reloadGlobalConfig(old, cfg)
+
+	return app.applyConfig(ctx, old, cfg, true)
+}
+
+// ReloadConfig re-reads the configuration files after another instance
+// changed them through UpdateConfig. MCP clients are shared by all instances
+// and were already reconnected by that instance, so only the agent tools are
+// rebuilt here.
+func (app *App) ReloadConfig(ctx context.Context) error {
+	app.configMu.Lock()
+	defer app.configMu.Unlock()
+
+	old := app.Config()
+	cfg, err := old.Reloaded()
+	if err != nil {
+		return err
+	}
+	return app.applyConfig(ctx, old, cfg, false)
+}
+
+// applyConfig swaps in cfg and updates what changed since old.
+func (app *App) applyConfig(ctx context.Context, old, cfg *config.Config, reconnectMCP bool) error {
+	app.setConfig(cfg)
+
+	var allowedTools []string
+	if cfg.Permissions != nil {
+		allowedTools = cfg.Permissions.AllowedTools
+	}
+	app.Permissions.SetAllowedTools(allowedTools)
+
+	for name := range changedKeys(old.LSP, cfg.LSP) {
+		app.restartLSPClient(app.globalCtx, name)
+	}
+
+	if changed := changedKeys(old.MCP, cfg.MCP); reconnectMCP && len(changed) > 0 {
+		resolver := cfg.Resolver()
+		go func() {
+			var wg sync.WaitGroup
+			for name := range changed {
+				m, ok := cfg.MCP[name]
+				if !ok {
+					mcp.Remove(name)
+					continue
+				}
+				wg.Go(func() { mcp.Reconnect(app.globalCtx, name, m, resolver) })
+			}
+			wg.Wait()
+			// Pick up the tools of the reconnected servers.
+			app.updateAgentTools(app.globalCtx)
+		}()
+	}
+
+	switch {
+	case !cfg.IsConfigured():
+		return nil
+	case app.AgentCoordinator == nil:
+		if err := app.InitCoderAgent(app.globalCtx); err != nil {
+			return fmt.Errorf("%w: failed to start the agent: %w", ErrConfigNotApplied, err)
+		}
+	default:
+		if err := app.AgentCoordinator.UpdateModels(ctx); err != nil {
+			return fmt.Errorf("%w: failed to update the agent models: %w", ErrConfigNotApplied, err)
+		}
+	}
+	return nil
+}
+
+// setConfig replaces the configuration of the app and its agent. Callers
+// hold configMu.
+func (app *App) setConfig(cfg *config.Config) {
+	app.config.Store(cfg)
+	if app.AgentCoordinator != nil {
+		app.AgentCoordinator.SetConfig(cfg)
+	}
+}
+
+// reloadGlobalConfig replaces the process-wide configuration after old was
+// updated to cfg. When the global one is old it becomes cfg, otherwise it is
+// reloaded from the files.
+func reloadGlobalConfig(old, cfg *config.Config) {
+	for {
+		global := config.Get()
+		if global == nil {
+			return
+		}
+		next := cfg
+		if global != old {
+			var err error
+			if next, err = global.Reloaded(); err != nil {
+				slog.Warn("Failed to reload global config", "error", err)
+				return
+			}
+		}
+		if config.Swap(global, next) {
+			return
+		}
+	}
+}
+
+// changedKeys returns the keys whose values differ between before and after,
+// including the keys only one of them has.
+func changedKeys[V any](before, after map[string]V) map[string]struct{} {
+	changed := make(map[string]struct{})
+	for name, v := range before {
+		if w, ok := after[name]; !ok || !reflect.DeepEqual(v, w) {
+			changed[name] = struct{}{}
+		}
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			changed[name] = struct{}{}
+		}
+	}
+	return changed
+}
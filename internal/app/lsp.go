@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"os/exec"
 	"slices"
@@ -12,6 +13,10 @@ import (
 	powernapconfig "github.com/charmbracelet/x/powernap/pkg/config"
 )
 
+// ErrLSPNotFound is returned when the named LSP client is neither running nor
+// configured.
+var ErrLSPNotFound = errors.New("lsp client not found")
+
 // initLSPClients initializes LSP clients.
 func (app *App) initLSPClients(ctx context.Context) {
 	slog.Info("LSP clients initialization started")
@@ -20,7 +25,7 @@ func (app *App) initLSPClients(ctx context.Context) {
 	manager.LoadDefaults()
 
 	var userConfiguredLSPs []string
-	for name, clientConfig := range app.config.LSP {
+	for name, clientConfig := range app.Config().LSP {
 		if clientConfig.Disabled {
 			slog.Info("Skipping disabled LSP client", "name", name)
 			manager.RemoveServer(name)
@@ -51,7 +56,7 @@ func (app *App) initLSPClients(ctx context.Context) {
 	}
 
 	servers := manager.GetServers()
-	filtered := lsp.FilterMatching(app.config.WorkingDir(), servers)
+	filtered := lsp.FilterMatching(app.Config().WorkingDir(), servers)
 
 	for _, name := range userConfiguredLSPs {
 		if _, ok := filtered[name]; !ok {
@@ -59,7 +64,7 @@ func (app *App) initLSPClients(ctx context.Context) {
 		}
 	}
 	for name, server := range filtered {
-		if app.config.Options.AutoLSP != nil && !*app.config.Options.AutoLSP && !slices.Contains(userConfiguredLSPs, name) {
+		if app.Config().Options.AutoLSP != nil && !*app.Config().Options.AutoLSP && !slices.Contains(userConfiguredLSPs, name) {
 			slog.Debug("Ignoring non user-define LSP client due to AutoLSP being disabled", "name", name)
 			continue
 		}
@@ -98,7 +103,7 @@ func (app *App) createAndStartLSPClient(ctx context.Context, name string, config
 	updateLSPState(name, lsp.StateStarting, nil, nil, 0)
 
 	// Create LSP client.
-	lspClient, err := lsp.New(ctx, name, config, app.config.Resolver())
+	lspClient, err := lsp.New(ctx, name, config, app.Config().Resolver())
 	if err != nil {
 		if !userConfigured {
 			slog.Warn("Default LSP config skipped due to error", "name", name, "error", err)
@@ -118,7 +123,7 @@ func (app *App) createAndStartLSPClient(ctx context.Context, name string, config
 	defer cancel()
 
 	// Initialize LSP client.
-	_, err = lspClient.Initialize(initCtx, app.config.WorkingDir())
+	_, err = lspClient.Initialize(initCtx, app.Config().WorkingDir())
 	if err != nil {
 		slog.Error("LSP client initialization failed", "name", name, "error", err)
 		updateLSPState(name, lsp.StateError, err, lspClient, 0)
@@ -145,3 +150,58 @@ func (app *App) createAndStartLSPClient(ctx context.Context, name string, config
 	// Add to map with mutex protection before starting goroutine
 	app.LSPClients.Set(name, lspClient)
 }
+
+// restartLSPClient stops the named LSP client, if running, and starts it
+// again with the current configuration, for when that configuration changed.
+func (app *App) restartLSPClient(ctx context.Context, name string) {
+	if client, ok := app.LSPClients.Take(name); ok {
+		shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		if err := client.Close(shutdownCtx); err != nil {
+			slog.Warn("Failed to shutdown LSP client", "name", name, "error", err)
+		}
+		cancel()
+	}
+
+	clientConfig, ok := app.Config().LSP[name]
+	if !ok || clientConfig.Disabled {
+		updateLSPState(name, lsp.StateDisabled, nil, nil, 0)
+		return
+	}
+	server := &powernapconfig.ServerConfig{
+		Command:     clientConfig.Command,
+		Args:        clientConfig.Args,
+		Environment: clientConfig.Env,
+		FileTypes:   clientConfig.FileTypes,
+		RootMarkers: clientConfig.RootMarkers,
+		InitOptions: clientConfig.InitOptions,
+		Settings:    clientConfig.Options,
+	}
+	if len(lsp.FilterMatching(app.Config().WorkingDir(), map[string]*powernapconfig.ServerConfig{name: server})) == 0 {
+		updateLSPState(name, lsp.StateDisabled, nil, nil, 0)
+		return
+	}
+	go app.createAndStartLSPClient(ctx, name, toOurConfig(server), true)
+}
+
+// RestartLSP restarts the named LSP client, reopening the files it had open.
+// A configured client that isn't running, e.g. because it failed to start, is
+// started in the background instead.
+func (app *App) RestartLSP(name string) error {
+	client, ok := app.LSPClients.Get(name)
+	if !ok {
+		if _, configured := app.Config().LSP[name]; !configured {
+			return ErrLSPNotFound
+		}
+		app.restartLSPClient(app.globalCtx, name)
+		return nil
+	}
+
+	updateLSPState(name, lsp.StateStarting, nil, client, 0)
+	if err := client.Restart(); err != nil {
+		slog.Error("Failed to restart LSP client", "name", name, "error", err)
+		updateLSPState(name, lsp.StateError, err, client, 0)
+		return err
+	}
+	updateLSPState(name, lsp.StateReady, nil, client, client.GetDiagnosticCounts().Total())
+	return nil
+}
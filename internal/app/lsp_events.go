@@ -24,6 +24,8 @@ type LSPEvent struct {
 	State           lsp.ServerState
 	Error           error
 	DiagnosticCount int
+	// Diagnostics holds the counts by severity for diagnostics events.
+	Diagnostics lsp.DiagnosticCounts
 }
 
 // LSPClientInfo holds information about an LSP client's state
@@ -86,6 +88,11 @@ func updateLSPDiagnostics(name string, diagnosticCount int) {
 		info.DiagnosticCount = diagnosticCount
 		lspStates.Set(name, info)
 
+		var counts lsp.DiagnosticCounts
+		if info.Client != nil {
+			counts = info.Client.GetDiagnosticCounts()
+		}
+
 		// Publish diagnostics change event
 		lspBroker.Publish(pubsub.UpdatedEvent, LSPEvent{
 			Type:            LSPEventDiagnosticsChanged,
@@ -93,6 +100,7 @@ func updateLSPDiagnostics(name string, diagnosticCount int) {
 			State:           info.State,
 			Error:           info.Error,
 			DiagnosticCount: diagnosticCount,
+			Diagnostics:     counts,
 		})
 	}
 }
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
+	"github.com/charmbracelet/crush/internal/config"
+)
+
+// ErrMCPNotFound is returned when the named MCP server isn't configured.
+var ErrMCPNotFound = errors.New("mcp server not found")
+
+// RestartMCP reconnects the named MCP server with its current configuration,
+// reloading its tools and prompts, and rebuilds the agent tools.
+func (app *App) RestartMCP(ctx context.Context, name string) error {
+	m, ok := app.Config().MCP[name]
+	if !ok {
+		return ErrMCPNotFound
+	}
+	mcp.Reconnect(ctx, name, m, app.Config().Resolver())
+	app.updateAgentTools(ctx)
+	return nil
+}
+
+// SetMCPDisabled enables or disables the named MCP server without saving the
+// change, so the configuration files win again once they are reloaded.
+func (app *App) SetMCPDisabled(ctx context.Context, name string, disabled bool) error {
+	app.configMu.Lock()
+	cfg := app.Config()
+	m, ok := cfg.MCP[name]
+	if !ok {
+		app.configMu.Unlock()
+		return ErrMCPNotFound
+	}
+	m.Disabled = disabled
+	app.setConfig(withMCPConfig(cfg, name, m))
+	app.configMu.Unlock()
+
+	// The MCP clients read the process-wide configuration.
+	for {
+		global := config.Get()
+		if global == nil || global == cfg {
+			break
+		}
+		gm, ok := global.MCP[name]
+		if !ok {
+			break
+		}
+		gm.Disabled = disabled
+		if config.Swap(global, withMCPConfig(global, name, gm)) {
+			break
+		}
+	}
+
+	mcp.Reconnect(ctx, name, m, cfg.Resolver())
+	app.updateAgentTools(ctx)
+	return nil
+}
+
+// SyncMCP updates the instance after the named MCP server was restarted,
+// enabled or disabled through another instance. MCP clients are shared by
+// all instances, so the disabled flag is copied and the agent tools are
+// rebuilt from the shared tools.
+func (app *App) SyncMCP(ctx context.Context, name string, disabled bool) {
+	app.configMu.Lock()
+	cfg := app.Config()
+	if m, ok := cfg.MCP[name]; ok && m.Disabled != disabled {
+		m.Disabled = disabled
+		app.setConfig(withMCPConfig(cfg, name, m))
+	}
+	app.configMu.Unlock()
+	app.updateAgentTools(ctx)
+}
+
+// withMCPConfig returns a copy of cfg with the named MCP server replaced, as
+// a loaded Config is read without a lock and must not change.
+func withMCPConfig(cfg *config.Config, name string, m config.MCPConfig) *config.Config {
+	next := *cfg
+	next.MCP = maps.Clone(cfg.MCP)
+	next.MCP[name] = m
+	return &next
+}
+
+// updateAgentTools rebuilds the agent tools after MCP servers changed.
+func (app *App) updateAgentTools(ctx context.Context) {
+	if app.AgentCoordinator == nil {
+		return
+	}
+	if err := app.AgentCoordinator.UpdateModels(ctx); err != nil {
+		slog.Warn("Failed to update agent tools after MCP change", "error", err)
+	}
+}
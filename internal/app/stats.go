@@ -0,0 +1,12 @@
+package app
+
+import (
+	"context"
+
+	"github.com/charmbracelet/crush/internal/stats"
+)
+
+// Stats gathers the usage and cost statistics of the project's sessions.
+func (app *App) Stats(ctx context.Context, filter stats.Filter) (*stats.Stats, error) {
+	return stats.Gather(ctx, app.conn, filter)
+}
@@ -0,0 +1,184 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/charmbracelet/crush/internal/webhook"
+)
+
+// maxWebhookTextSize is the size above which the text of finished messages
+// is truncated in webhook events.
+const maxWebhookTextSize = 4 * 1024
+
+// setupWebhooks forwards session, message, permission, MCP and LSP events to
+// the configured webhooks. It must be called after setupEvents.
+func (app *App) setupWebhooks() {
+	if len(app.Config().Webhooks) == 0 {
+		return
+	}
+
+	ctx, wg := app.eventsCtx, app.serviceEventsWG
+	forwardWebhookEvents(ctx, wg, app.Sessions.Subscribe, app.sessionWebhook)
+	forwardWebhookEvents(ctx, wg, app.Messages.Subscribe, newMessageWebhook(app.Webhooks))
+	forwardWebhookEvents(ctx, wg, app.Permissions.Subscribe, app.permissionWebhook)
+	forwardWebhookEvents(ctx, wg, mcp.SubscribeEvents, app.mcpWebhook)
+	forwardWebhookEvents(ctx, wg, SubscribeLSPEvents, app.lspWebhook)
+
+	// Runs after the subscriptions are cancelled by the events cleanup.
+	app.cleanupFuncs = append(app.cleanupFuncs, app.Webhooks.Close)
+}
+
+func forwardWebhookEvents[T any](
+	ctx context.Context,
+	wg *sync.WaitGroup,
+	subscriber func(context.Context) <-chan pubsub.Event[T],
+	handle func(pubsub.Event[T]),
+) {
+	wg.Go(func() {
+		for event := range subscriber(ctx) {
+			handle(event)
+		}
+	})
+}
+
+func (app *App) sessionWebhook(e pubsub.Event[session.Session]) {
+	data := sessionWebhookData(e.Payload)
+	ev := webhook.Event{SessionID: data.ID, Data: data}
+	switch e.Type {
+	case pubsub.CreatedEvent:
+		ev.Type = webhook.EventSessionCreated
+	case pubsub.UpdatedEvent:
+		ev.Type = webhook.EventSessionUpdated
+	case pubsub.DeletedEvent:
+		ev.Type = webhook.EventSessionDeleted
+	default:
+		return
+	}
+	app.Webhooks.Send(ev)
+	if e.Type == pubsub.UpdatedEvent {
+		app.Webhooks.CheckCost(data)
+	}
+}
+
+// newMessageWebhook returns a handler sending an event when an assistant
+// message finishes the turn. Messages finishing with a tool call are skipped
+// since the agent continues with the tool results.
+func newMessageWebhook(webhooks webhook.Service) func(pubsub.Event[message.Message]) {
+	// Messages are updated again after they finish, remember the last
+	// finished message of each session to send a single event.
+	lastFinished := make(map[string]string)
+	return func(e pubsub.Event[message.Message]) {
+		msg := e.Payload
+		if e.Type == pubsub.DeletedEvent {
+			if lastFinished[msg.SessionID] == msg.ID {
+				delete(lastFinished, msg.SessionID)
+			}
+			return
+		}
+		if msg.Role != message.Assistant {
+			return
+		}
+		finish := msg.FinishPart()
+		if finish == nil || finish.Reason == message.FinishReasonToolUse || lastFinished[msg.SessionID] == msg.ID {
+			return
+		}
+		lastFinished[msg.SessionID] = msg.ID
+
+		data := webhook.MessageData{
+			ID:        msg.ID,
+			SessionID: msg.SessionID,
+			Model:     msg.Model,
+			Provider:  msg.Provider,
+			Reason:    string(finish.Reason),
+			Text:      truncateWebhookText(msg.Content().Text),
+		}
+		eventType := webhook.EventMessageFinished
+		if finish.Reason == message.FinishReasonError {
+			eventType = webhook.EventMessageFailed
+			data.Error = finish.Message
+			if finish.Details != "" {
+				data.Error += ": " + finish.Details
+			}
+		}
+		webhooks.Send(webhook.Event{Type: eventType, SessionID: msg.SessionID, Data: data})
+	}
+}
+
+func (app *App) permissionWebhook(e pubsub.Event[permission.PermissionRequest]) {
+	p := e.Payload
+	app.Webhooks.Send(webhook.Event{
+		Type:      webhook.EventPermissionRequested,
+		SessionID: p.SessionID,
+		Data: webhook.PermissionData{
+			ID:          p.ID,
+			SessionID:   p.SessionID,
+			ToolCallID:  p.ToolCallID,
+			ToolName:    p.ToolName,
+			Action:      p.Action,
+			Path:        p.Path,
+			Description: p.Description,
+			Params:      p.Params,
+		},
+	})
+}
+
+func (app *App) mcpWebhook(e pubsub.Event[mcp.Event]) {
+	if e.Payload.Type != mcp.EventStateChanged || e.Payload.State != mcp.StateError {
+		return
+	}
+	app.Webhooks.Send(webhook.Event{
+		Type: webhook.EventMCPError,
+		Data: webhook.ServerErrorData{Name: e.Payload.Name, Error: errorString(e.Payload.Error)},
+	})
+}
+
+func (app *App) lspWebhook(e pubsub.Event[LSPEvent]) {
+	if e.Payload.Type != LSPEventStateChanged || e.Payload.State != lsp.StateError {
+		return
+	}
+	app.Webhooks.Send(webhook.Event{
+		Type: webhook.EventLSPError,
+		Data: webhook.ServerErrorData{Name: e.Payload.Name, Error: errorString(e.Payload.Error)},
+	})
+}
+
+func sessionWebhookData(s session.Session) webhook.SessionData {
+	return webhook.SessionData{
+		ID:               s.ID,
+		ParentSessionID:  s.ParentSessionID,
+		Title:            s.Title,
+		MessageCount:     s.MessageCount,
+		PromptTokens:     s.PromptTokens,
+		CompletionTokens: s.CompletionTokens,
+		Cost:             s.Cost,
+		CreatedAt:        time.Unix(s.CreatedAt, 0),
+		UpdatedAt:        time.Unix(s.UpdatedAt, 0),
+	}
+}
+
+func truncateWebhookText(s string) string {
+	if len(s) <= maxWebhookTextSize {
+		return s
+	}
+	n := maxWebhookTextSize
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "…"
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
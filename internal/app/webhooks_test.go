@@ -0,0 +1,60 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/charmbracelet/crush/internal/webhook"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingWebhooks struct {
+	webhook.Service
+	events []webhook.Event
+}
+
+func (r *recordingWebhooks) Send(ev webhook.Event) {
+	r.events = append(r.events, ev)
+}
+
+func TestMessageWebhook(t *testing.T) {
+	t.Parallel()
+
+	webhooks := &recordingWebhooks{}
+	handle := newMessageWebhook(webhooks)
+	update := func(msg message.Message) {
+		handle(pubsub.Event[message.Message]{Type: pubsub.UpdatedEvent, Payload: msg})
+	}
+
+	user := message.Message{ID: "u1", SessionID: "s1", Role: message.User}
+	user.AddFinish(message.FinishReasonEndTurn, "", "")
+	update(user)
+
+	step := message.Message{ID: "a1", SessionID: "s1", Role: message.Assistant}
+	step.AddFinish(message.FinishReasonToolUse, "", "")
+	update(step)
+
+	reply := message.Message{ID: "a2", SessionID: "s1", Role: message.Assistant, Model: "gpt-4o"}
+	reply.AppendContent("All tests pass.")
+	update(reply)
+	reply.AddFinish(message.FinishReasonEndTurn, "", "")
+	update(reply)
+	update(reply)
+
+	failed := message.Message{ID: "a3", SessionID: "s1", Role: message.Assistant}
+	failed.AddFinish(message.FinishReasonError, "Provider error", "rate limited")
+	update(failed)
+
+	require.Len(t, webhooks.events, 2)
+	require.Equal(t, webhook.EventMessageFinished, webhooks.events[0].Type)
+	require.Equal(t, webhook.MessageData{
+		ID:        "a2",
+		SessionID: "s1",
+		Model:     "gpt-4o",
+		Reason:    "end_turn",
+		Text:      "All tests pass.",
+	}, webhooks.events[0].Data)
+	require.Equal(t, webhook.EventMessageFailed, webhooks.events[1].Type)
+	require.Equal(t, "Provider error: rate limited", webhooks.events[1].Data.(webhook.MessageData).Error)
+}
@@ -0,0 +1,291 @@
+// Package audit records tool executions and permission decisions in an
+// append-only log.
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/google/uuid"
+)
+
+// Kind is the kind of an audit entry.
+type Kind string
+
+const (
+	// KindTool is the execution of a tool.
+	KindTool Kind = "tool"
+	// KindPermission is the answer to a permission request.
+	KindPermission Kind = "permission"
+)
+
+// Decisions recorded for permission entries.
+const (
+	DecisionGranted        = "granted"
+	DecisionGrantedSession = "granted_session"
+	DecisionDenied         = "denied"
+)
+
+// Statuses recorded for tool entries.
+const (
+	StatusSuccess = "success"
+	StatusError   = "error"
+)
+
+// maxFieldSize is the size above which params and errors are truncated.
+const maxFieldSize = 16 * 1024
+
+// Entry is an entry of the audit log.
+type Entry struct {
+	ID         string `json:"id"`
+	Kind       Kind   `json:"kind"`
+	SessionID  string `json:"session_id,omitempty"`
+	ToolCallID string `json:"tool_call_id,omitempty"`
+	ToolName   string `json:"tool_name"`
+	// Action and Path are those of the permission request.
+	Action string `json:"action,omitempty"`
+	Path   string `json:"path,omitempty"`
+	// Params are the JSON encoded parameters of the tool call, truncated if
+	// they are too large.
+	Params string `json:"params,omitempty"`
+
+	// Decision, Decider and Reason are set for permission entries, see
+	// [permission.Decision].
+	Decision string `json:"decision,omitempty"`
+	Decider  string `json:"decider,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+
+	// Status, ExitCode and Error are set for tool entries. ExitCode is set
+	// for commands that finished before the tool returned.
+	Status   string `json:"status,omitempty"`
+	ExitCode *int   `json:"exit_code,omitempty"`
+	Error    string `json:"error,omitempty"`
+
+	// Actor is the API caller that started the audited action, see
+	// [WithActor]. It is empty for actions started from the TUI.
+	Actor string `json:"actor,omitempty"`
+
+	CreatedAt  time.Time `json:"created_at"`
+	FinishedAt time.Time `json:"finished_at,omitzero"`
+}
+
+// Filter selects the entries returned by [Service.List]. Zero fields match
+// all entries.
+type Filter struct {
+	SessionID string
+	Kind      Kind
+	ToolName  string
+	Decider   string
+	Actor     string
+	// From and To restrict entries to those created in [From, To).
+	From time.Time
+	To   time.Time
+
+	Limit  int
+	Offset int
+}
+
+// Service appends to and reads the audit log.
+type Service interface {
+	permission.Recorder
+
+	// Record appends an entry to the log. Errors are logged, auditing never
+	// fails the audited action.
+	Record(ctx context.Context, entry Entry)
+	// List returns the entries matching filter, newest first, and the
+	// number of matching entries.
+	List(ctx context.Context, filter Filter) ([]Entry, int, error)
+}
+
+type service struct {
+	q *db.Queries
+}
+
+type actorContextKey struct{}
+
+// WithActor returns a context whose audit entries are attributed to actor,
+// unless the entry sets its own.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext returns the actor set with WithActor.
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorContextKey{}).(string)
+	return actor
+}
+
+// NewService creates a new audit service.
+func NewService(q *db.Queries) Service {
+	return &service{q: q}
+}
+
+func (s *service) Record(ctx context.Context, entry Entry) {
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	if entry.Actor == "" {
+		entry.Actor = ActorFromContext(ctx)
+	}
+	params := db.CreateAuditEntryParams{
+		ID:         entry.ID,
+		Kind:       string(entry.Kind),
+		SessionID:  entry.SessionID,
+		ToolCallID: entry.ToolCallID,
+		ToolName:   entry.ToolName,
+		Action:     entry.Action,
+		Path:       entry.Path,
+		Params:     truncate(entry.Params),
+		Decision:   entry.Decision,
+		Decider:    entry.Decider,
+		Reason:     entry.Reason,
+		Status:     entry.Status,
+		Error:      truncate(entry.Error),
+		CreatedAt:  entry.CreatedAt.UnixMilli(),
+		Actor:      entry.Actor,
+	}
+	if entry.ExitCode != nil {
+		params.ExitCode = sql.NullInt64{Int64: int64(*entry.ExitCode), Valid: true}
+	}
+	if !entry.FinishedAt.IsZero() {
+		params.FinishedAt = sql.NullInt64{Int64: entry.FinishedAt.UnixMilli(), Valid: true}
+	}
+
+	// Record even if the audited action was cancelled.
+	if err := s.q.CreateAuditEntry(context.WithoutCancel(ctx), params); err != nil {
+		slog.Error("Failed to record audit entry", "kind", entry.Kind, "tool", entry.ToolName, "error", err)
+	}
+}
+
+// RecordDecision implements [permission.Recorder].
+func (s *service) RecordDecision(d permission.Decision) {
+	entry := Entry{
+		Kind:       KindPermission,
+		SessionID:  d.Request.SessionID,
+		ToolCallID: d.Request.ToolCallID,
+		ToolName:   d.Request.ToolName,
+		Action:     d.Request.Action,
+		Path:       d.Request.Path,
+		Decision:   DecisionDenied,
+		Decider:    d.Decider,
+		Reason:     d.Reason,
+		CreatedAt:  d.Time,
+	}
+	switch {
+	case d.Granted && d.Persistent:
+		entry.Decision = DecisionGrantedSession
+	case d.Granted:
+		entry.Decision = DecisionGranted
+	}
+	if d.Request.Params != nil {
+		if data, err := json.Marshal(d.Request.Params); err == nil {
+			entry.Params = string(data)
+		}
+	}
+	s.Record(context.Background(), entry)
+}
+
+func (s *service) List(ctx context.Context, filter Filter) ([]Entry, int, error) {
+	var (
+		sessionID = nullString(filter.SessionID)
+		kind      = nullString(string(filter.Kind))
+		toolName  = nullString(filter.ToolName)
+		decider   = nullString(filter.Decider)
+		actor     = nullString(filter.Actor)
+		startTime = nullTime(filter.From)
+		endTime   = nullTime(filter.To)
+	)
+	total, err := s.q.CountAuditEntries(ctx, db.CountAuditEntriesParams{
+		SessionID: sessionID,
+		Kind:      kind,
+		ToolName:  toolName,
+		Decider:   decider,
+		Actor:     actor,
+		StartTime: startTime,
+		EndTime:   endTime,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	limit := int64(filter.Limit)
+	if limit <= 0 {
+		limit = -1 // no limit
+	}
+	rows, err := s.q.ListAuditEntries(ctx, db.ListAuditEntriesParams{
+		SessionID: sessionID,
+		Kind:      kind,
+		ToolName:  toolName,
+		Decider:   decider,
+		Actor:     actor,
+		StartTime: startTime,
+		EndTime:   endTime,
+		Limit:     limit,
+		Offset:    int64(max(filter.Offset, 0)),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	entries := make([]Entry, len(rows))
+	for i, row := range rows {
+		entries[i] = fromDBItem(row)
+	}
+	return entries, int(total), nil
+}
+
+func fromDBItem(item db.AuditLog) Entry {
+	entry := Entry{
+		ID:         item.ID,
+		Kind:       Kind(item.Kind),
+		SessionID:  item.SessionID,
+		ToolCallID: item.ToolCallID,
+		ToolName:   item.ToolName,
+		Action:     item.Action,
+		Path:       item.Path,
+		Params:     item.Params,
+		Decision:   item.Decision,
+		Decider:    item.Decider,
+		Reason:     item.Reason,
+		Status:     item.Status,
+		Error:      item.Error,
+		Actor:      item.Actor,
+		CreatedAt:  time.UnixMilli(item.CreatedAt),
+	}
+	if item.ExitCode.Valid {
+		exitCode := int(item.ExitCode.Int64)
+		entry.ExitCode = &exitCode
+	}
+	if item.FinishedAt.Valid {
+		entry.FinishedAt = time.UnixMilli(item.FinishedAt.Int64)
+	}
+	return entry
+}
+
+func truncate(s string) string {
+	if len(s) <= maxFieldSize {
+		return s
+	}
+	n := maxFieldSize
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "…"
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func nullTime(t time.Time) sql.NullInt64 {
+	return sql.NullInt64{Int64: t.UnixMilli(), Valid: !t.IsZero()}
+}
@@ -0,0 +1,151 @@
+package audit
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/stretchr/testify/require"
+)
+
+func setupService(t *testing.T) (Service, *sql.DB) {
+	t.Helper()
+
+	conn, err := db.Connect(t.Context(), t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return NewService(db.New(conn)), conn
+}
+
+func TestRecordAndList(t *testing.T) {
+	t.Parallel()
+
+	svc, _ := setupService(t)
+	start := time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC)
+	exitCode := 2
+
+	svc.RecordDecision(permission.Decision{
+		Request: permission.PermissionRequest{
+			SessionID:  "s1",
+			ToolCallID: "call-1",
+			ToolName:   "bash",
+			Action:     "execute",
+			Path:       "/project",
+			Params:     map[string]string{"command": "make"},
+		},
+		Granted:    true,
+		Persistent: true,
+		Decider:    "api_key:ci",
+		Time:       start,
+	})
+	svc.Record(WithActor(t.Context(), "api_key:ci"), Entry{
+		Kind:       KindTool,
+		SessionID:  "s1",
+		ToolCallID: "call-1",
+		ToolName:   "bash",
+		Params:     `{"command":"make"}`,
+		Status:     StatusError,
+		ExitCode:   &exitCode,
+		Error:      "Exit code 2",
+		CreatedAt:  start.Add(time.Second),
+		FinishedAt: start.Add(3 * time.Second),
+	})
+	svc.RecordDecision(permission.Decision{
+		Request: permission.PermissionRequest{SessionID: "s2", ToolName: "edit"},
+		Decider: permission.DeciderAuto,
+		Reason:  permission.ReasonTimeout,
+		Time:    start.AddDate(0, 0, 1),
+	})
+
+	entries, total, err := svc.List(t.Context(), Filter{})
+	require.NoError(t, err)
+	require.Equal(t, 3, total)
+	require.Len(t, entries, 3)
+	require.Equal(t, "edit", entries[0].ToolName)
+	require.Equal(t, DecisionDenied, entries[0].Decision)
+	require.Equal(t, permission.ReasonTimeout, entries[0].Reason)
+
+	tool := entries[1]
+	require.Equal(t, KindTool, tool.Kind)
+	require.Equal(t, StatusError, tool.Status)
+	require.Equal(t, &exitCode, tool.ExitCode)
+	require.Equal(t, start.Add(3*time.Second).UnixMilli(), tool.FinishedAt.UnixMilli())
+	require.Equal(t, "api_key:ci", tool.Actor)
+
+	decision := entries[2]
+	require.Equal(t, KindPermission, decision.Kind)
+	require.Equal(t, DecisionGrantedSession, decision.Decision)
+	require.Equal(t, "api_key:ci", decision.Decider)
+	require.Equal(t, `{"command":"make"}`, decision.Params)
+	require.Nil(t, decision.ExitCode)
+	require.True(t, decision.FinishedAt.IsZero())
+
+	t.Run("filter", func(t *testing.T) {
+		t.Parallel()
+
+		entries, total, err := svc.List(t.Context(), Filter{SessionID: "s1", Kind: KindPermission})
+		require.NoError(t, err)
+		require.Equal(t, 1, total)
+		require.Equal(t, decision.ID, entries[0].ID)
+
+		entries, total, err = svc.List(t.Context(), Filter{From: start.Add(time.Second), To: start.AddDate(0, 0, 1)})
+		require.NoError(t, err)
+		require.Equal(t, 1, total)
+		require.Equal(t, tool.ID, entries[0].ID)
+
+		_, total, err = svc.List(t.Context(), Filter{Decider: permission.DeciderAuto})
+		require.NoError(t, err)
+		require.Equal(t, 1, total)
+
+		entries, total, err = svc.List(t.Context(), Filter{Actor: "api_key:ci"})
+		require.NoError(t, err)
+		require.Equal(t, 1, total)
+		require.Equal(t, tool.ID, entries[0].ID)
+	})
+
+	t.Run("pagination", func(t *testing.T) {
+		t.Parallel()
+
+		entries, total, err := svc.List(t.Context(), Filter{Limit: 1, Offset: 1})
+		require.NoError(t, err)
+		require.Equal(t, 3, total)
+		require.Len(t, entries, 1)
+		require.Equal(t, tool.ID, entries[0].ID)
+	})
+}
+
+func TestRecordTruncates(t *testing.T) {
+	t.Parallel()
+
+	svc, _ := setupService(t)
+	svc.Record(t.Context(), Entry{
+		Kind:     KindTool,
+		ToolName: "write",
+		Params:   strings.Repeat("é", maxFieldSize),
+	})
+
+	entries, _, err := svc.List(t.Context(), Filter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.LessOrEqual(t, len(entries[0].Params), maxFieldSize+len("…"))
+	require.True(t, strings.HasSuffix(entries[0].Params, "é…"))
+}
+
+func TestAppendOnly(t *testing.T) {
+	t.Parallel()
+
+	svc, conn := setupService(t)
+	svc.Record(t.Context(), Entry{Kind: KindTool, ToolName: "bash"})
+
+	_, err := conn.ExecContext(t.Context(), "UPDATE audit_log SET tool_name = 'view'")
+	require.ErrorContains(t, err, "append-only")
+	_, err = conn.ExecContext(t.Context(), "DELETE FROM audit_log")
+	require.ErrorContains(t, err, "append-only")
+
+	_, total, err := svc.List(t.Context(), Filter{ToolName: "bash"})
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+}
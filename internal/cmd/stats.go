@@ -2,8 +2,6 @@ package cmd
 
 import (
 	"bytes"
-	"context"
-	"database/sql"
 	_ "embed"
 	"encoding/json"
 	"fmt"
@@ -12,10 +10,10 @@ import (
 	"os/user"
 	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/stats"
 	"github.com/pkg/browser"
 	"github.com/spf13/cobra"
 )
@@ -45,80 +43,6 @@ var statsCmd = &cobra.Command{
 	RunE:  runStats,
 }
 
-// Day names for day of week statistics.
-var dayNames = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
-
-// Stats holds all the statistics data.
-type Stats struct {
-	GeneratedAt       time.Time          `json:"generated_at"`
-	Total             TotalStats         `json:"total"`
-	UsageByDay        []DailyUsage       `json:"usage_by_day"`
-	UsageByModel      []ModelUsage       `json:"usage_by_model"`
-	UsageByHour       []HourlyUsage      `json:"usage_by_hour"`
-	UsageByDayOfWeek  []DayOfWeekUsage   `json:"usage_by_day_of_week"`
-	RecentActivity    []DailyActivity    `json:"recent_activity"`
-	AvgResponseTimeMs float64            `json:"avg_response_time_ms"`
-	ToolUsage         []ToolUsage        `json:"tool_usage"`
-	HourDayHeatmap    []HourDayHeatmapPt `json:"hour_day_heatmap"`
-}
-
-type TotalStats struct {
-	TotalSessions         int64   `json:"total_sessions"`
-	TotalPromptTokens     int64   `json:"total_prompt_tokens"`
-	TotalCompletionTokens int64   `json:"total_completion_tokens"`
-	TotalTokens           int64   `json:"total_tokens"`
-	TotalCost             float64 `json:"total_cost"`
-	TotalMessages         int64   `json:"total_messages"`
-	AvgTokensPerSession   float64 `json:"avg_tokens_per_session"`
-	AvgMessagesPerSession float64 `json:"avg_messages_per_session"`
-}
-
-type DailyUsage struct {
-	Day              string  `json:"day"`
-	PromptTokens     int64   `json:"prompt_tokens"`
-	CompletionTokens int64   `json:"completion_tokens"`
-	TotalTokens      int64   `json:"total_tokens"`
-	Cost             float64 `json:"cost"`
-	SessionCount     int64   `json:"session_count"`
-}
-
-type ModelUsage struct {
-	Model        string `json:"model"`
-	Provider     string `json:"provider"`
-	MessageCount int64  `json:"message_count"`
-}
-
-type HourlyUsage struct {
-	Hour         int   `json:"hour"`
-	SessionCount int64 `json:"session_count"`
-}
-
-type DayOfWeekUsage struct {
-	DayOfWeek        int    `json:"day_of_week"`
-	DayName          string `json:"day_name"`
-	SessionCount     int64  `json:"session_count"`
-	PromptTokens     int64  `json:"prompt_tokens"`
-	CompletionTokens int64  `json:"completion_tokens"`
-}
-
-type DailyActivity struct {
-	Day          string  `json:"day"`
-	SessionCount int64   `json:"session_count"`
-	TotalTokens  int64   `json:"total_tokens"`
-	Cost         float64 `json:"cost"`
-}
-
-type ToolUsage struct {
-	ToolName  string `json:"tool_name"`
-	CallCount int64  `json:"call_count"`
-}
-
-type HourDayHeatmapPt struct {
-	DayOfWeek    int   `json:"day_of_week"`
-	Hour         int   `json:"hour"`
-	SessionCount int64 `json:"session_count"`
-}
-
 func runStats(cmd *cobra.Command, _ []string) error {
 	dataDir, _ := cmd.Flags().GetString("data-dir")
 	ctx := cmd.Context()
@@ -137,12 +61,12 @@ func runStats(cmd *cobra.Command, _ []string) error {
 	}
 	defer conn.Close()
 
-	stats, err := gatherStats(ctx, conn)
+	usage, err := stats.Gather(ctx, conn, stats.Filter{})
 	if err != nil {
 		return fmt.Errorf("failed to gather stats: %w", err)
 	}
 
-	if stats.Total.TotalSessions == 0 {
+	if usage.Total.TotalSessions == 0 {
 		return fmt.Errorf("no data available: no sessions found in database")
 	}
 
@@ -158,7 +82,7 @@ func runStats(cmd *cobra.Command, _ []string) error {
 	project = strings.Replace(project, currentUser.HomeDir, "~", 1)
 
 	htmlPath := filepath.Join(dataDir, "stats/index.html")
-	if err := generateHTML(stats, project, username, htmlPath); err != nil {
+	if err := generateHTML(usage, project, username, htmlPath); err != nil {
 		return fmt.Errorf("failed to generate HTML: %w", err)
 	}
 
@@ -172,173 +96,8 @@ func runStats(cmd *cobra.Command, _ []string) error {
 	return nil
 }
 
-func gatherStats(ctx context.Context, conn *sql.DB) (*Stats, error) {
-	queries := db.New(conn)
-
-	stats := &Stats{
-		GeneratedAt: time.Now(),
-	}
-
-	// Total stats.
-	total, err := queries.GetTotalStats(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("get total stats: %w", err)
-	}
-	stats.Total = TotalStats{
-		TotalSessions:         total.TotalSessions,
-		TotalPromptTokens:     toInt64(total.TotalPromptTokens),
-		TotalCompletionTokens: toInt64(total.TotalCompletionTokens),
-		TotalTokens:           toInt64(total.TotalPromptTokens) + toInt64(total.TotalCompletionTokens),
-		TotalCost:             toFloat64(total.TotalCost),
-		TotalMessages:         toInt64(total.TotalMessages),
-		AvgTokensPerSession:   toFloat64(total.AvgTokensPerSession),
-		AvgMessagesPerSession: toFloat64(total.AvgMessagesPerSession),
-	}
-
-	// Usage by day.
-	dailyUsage, err := queries.GetUsageByDay(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("get usage by day: %w", err)
-	}
-	for _, d := range dailyUsage {
-		prompt := nullFloat64ToInt64(d.PromptTokens)
-		completion := nullFloat64ToInt64(d.CompletionTokens)
-		stats.UsageByDay = append(stats.UsageByDay, DailyUsage{
-			Day:              fmt.Sprintf("%v", d.Day),
-			PromptTokens:     prompt,
-			CompletionTokens: completion,
-			TotalTokens:      prompt + completion,
-			Cost:             d.Cost.Float64,
-			SessionCount:     d.SessionCount,
-		})
-	}
-
-	// Usage by model.
-	modelUsage, err := queries.GetUsageByModel(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("get usage by model: %w", err)
-	}
-	for _, m := range modelUsage {
-		stats.UsageByModel = append(stats.UsageByModel, ModelUsage{
-			Model:        m.Model,
-			Provider:     m.Provider,
-			MessageCount: m.MessageCount,
-		})
-	}
-
-	// Usage by hour.
-	hourlyUsage, err := queries.GetUsageByHour(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("get usage by hour: %w", err)
-	}
-	for _, h := range hourlyUsage {
-		stats.UsageByHour = append(stats.UsageByHour, HourlyUsage{
-			Hour:         int(h.Hour),
-			SessionCount: h.SessionCount,
-		})
-	}
-
-	// Usage by day of week.
-	dowUsage, err := queries.GetUsageByDayOfWeek(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("get usage by day of week: %w", err)
-	}
-	for _, d := range dowUsage {
-		stats.UsageByDayOfWeek = append(stats.UsageByDayOfWeek, DayOfWeekUsage{
-			DayOfWeek:        int(d.DayOfWeek),
-			DayName:          dayNames[int(d.DayOfWeek)],
-			SessionCount:     d.SessionCount,
-			PromptTokens:     nullFloat64ToInt64(d.PromptTokens),
-			CompletionTokens: nullFloat64ToInt64(d.CompletionTokens),
-		})
-	}
-
-	// Recent activity (last 30 days).
-	recent, err := queries.GetRecentActivity(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("get recent activity: %w", err)
-	}
-	for _, r := range recent {
-		stats.RecentActivity = append(stats.RecentActivity, DailyActivity{
-			Day:          fmt.Sprintf("%v", r.Day),
-			SessionCount: r.SessionCount,
-			TotalTokens:  nullFloat64ToInt64(r.TotalTokens),
-			Cost:         r.Cost.Float64,
-		})
-	}
-
-	// Average response time.
-	avgResp, err := queries.GetAverageResponseTime(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("get average response time: %w", err)
-	}
-	stats.AvgResponseTimeMs = toFloat64(avgResp) * 1000
-
-	// Tool usage.
-	toolUsage, err := queries.GetToolUsage(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("get tool usage: %w", err)
-	}
-	for _, t := range toolUsage {
-		if name, ok := t.ToolName.(string); ok && name != "" {
-			stats.ToolUsage = append(stats.ToolUsage, ToolUsage{
-				ToolName:  name,
-				CallCount: t.CallCount,
-			})
-		}
-	}
-
-	// Hour/day heatmap.
-	heatmap, err := queries.GetHourDayHeatmap(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("get hour day heatmap: %w", err)
-	}
-	for _, h := range heatmap {
-		stats.HourDayHeatmap = append(stats.HourDayHeatmap, HourDayHeatmapPt{
-			DayOfWeek:    int(h.DayOfWeek),
-			Hour:         int(h.Hour),
-			SessionCount: h.SessionCount,
-		})
-	}
-
-	return stats, nil
-}
-
-func toInt64(v any) int64 {
-	switch val := v.(type) {
-	case int64:
-		return val
-	case float64:
-		return int64(val)
-	case int:
-		return int64(val)
-	default:
-		return 0
-	}
-}
-
-func toFloat64(v any) float64 {
-	switch val := v.(type) {
-	case float64:
-		return val
-	case int64:
-		return float64(val)
-	case int:
-		return float64(val)
-	default:
-		return 0
-	}
-}
-
-func nullFloat64ToInt64(n sql.NullFloat64) int64 {
-	if n.Valid {
-		return int64(n.Float64)
-	}
-	return 0
-}
-
-func generateHTML(stats *Stats, projName, username, path string) error {
-	statsJSON, err := json.Marshal(stats)
+func generateHTML(usage *stats.Stats, projName, username, path string) error {
+	statsJSON, err := json.Marshal(usage)
 	if err != nil {
 		return err
 	}
@@ -365,7 +124,7 @@ func generateHTML(stats *Stats, projName, username, path string) error {
 		Header:      template.HTML(headerSVG),
 		Heartbit:    template.HTML(heartbitSVG),
 		Footer:      template.HTML(footerSVG),
-		GeneratedAt: stats.GeneratedAt.Format("2006-01-02"),
+		GeneratedAt: usage.GeneratedAt.Format("2006-01-02"),
 		ProjectName: projName,
 		Username:    username,
 	}
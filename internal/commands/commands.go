@@ -2,11 +2,14 @@ package commands
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"unicode"
 
 	"github.com/charmbracelet/crush/internal/agent/tools/mcp"
 	"github.com/charmbracelet/crush/internal/config"
@@ -18,8 +21,15 @@ var namedArgPattern = regexp.MustCompile(`\$([A-Z][A-Z0-9_]*)`)
 const (
 	userCommandPrefix    = "user:"
 	projectCommandPrefix = "project:"
+
+	// allArgumentsName is the placeholder that receives the whole argument
+	// string when a command is run with raw arguments.
+	allArgumentsName = "ARGUMENTS"
 )
 
+// ErrMissingArgument is returned when a required argument has no value.
+var ErrMissingArgument = errors.New("missing required argument")
+
 // Argument represents a command argument with its metadata.
 type Argument struct {
 	ID          string
@@ -46,6 +56,83 @@ type CustomCommand struct {
 	Arguments []Argument
 }
 
+// Expand renders the command content with its $NAME placeholders filled from
+// a raw argument string. $ARGUMENTS receives the whole string; the other
+// arguments are filled in order from whitespace separated fields, with the
+// last one receiving the remainder.
+func (c CustomCommand) Expand(raw string) (string, error) {
+	return c.ExpandArgs(parseArguments(c.Arguments, raw))
+}
+
+// ExpandArgs renders the command content with its $NAME placeholders filled
+// from named values. Values for unknown arguments are ignored.
+func (c CustomCommand) ExpandArgs(values map[string]string) (string, error) {
+	if err := checkRequired(c.Arguments, values); err != nil {
+		return "", err
+	}
+	return namedArgPattern.ReplaceAllStringFunc(c.Content, func(match string) string {
+		if value, ok := values[match[1:]]; ok {
+			return value
+		}
+		return match
+	}), nil
+}
+
+// Expand fetches the prompt from its MCP server with the arguments filled
+// from a raw argument string, in the same way as CustomCommand.Expand.
+func (p MCPPrompt) Expand(ctx context.Context, raw string) (string, error) {
+	return p.ExpandArgs(ctx, parseArguments(p.Arguments, raw))
+}
+
+// ExpandArgs fetches the prompt from its MCP server with named argument
+// values.
+func (p MCPPrompt) ExpandArgs(ctx context.Context, values map[string]string) (string, error) {
+	if err := checkRequired(p.Arguments, values); err != nil {
+		return "", err
+	}
+	return GetMCPPrompt(ctx, p.ClientID, p.PromptID, values)
+}
+
+// parseArguments splits a raw argument string into named values as
+// described in CustomCommand.Expand.
+func parseArguments(args []Argument, raw string) map[string]string {
+	raw = strings.TrimSpace(raw)
+	values := make(map[string]string, len(args))
+
+	var positional []Argument
+	for _, arg := range args {
+		if arg.ID == allArgumentsName {
+			values[arg.ID] = raw
+			continue
+		}
+		positional = append(positional, arg)
+	}
+
+	rest := raw
+	for i, arg := range positional {
+		if i == len(positional)-1 {
+			values[arg.ID] = rest
+			break
+		}
+		field, remainder := rest, ""
+		if idx := strings.IndexFunc(rest, unicode.IsSpace); idx >= 0 {
+			field, remainder = rest[:idx], rest[idx:]
+		}
+		values[arg.ID] = field
+		rest = strings.TrimSpace(remainder)
+	}
+	return values
+}
+
+func checkRequired(args []Argument, values map[string]string) error {
+	for _, arg := range args {
+		if arg.Required && values[arg.ID] == "" {
+			return fmt.Errorf("%w %s", ErrMissingArgument, arg.ID)
+		}
+	}
+	return nil
+}
+
 type commandSource struct {
 	path   string
 	prefix string
@@ -227,9 +314,10 @@ func isMarkdownFile(name string) bool {
 	return strings.HasSuffix(strings.ToLower(name), ".md")
 }
 
-func GetMCPPrompt(clientID, promptID string, args map[string]string) (string, error) {
-	// TODO: we should pass the context down
-	result, err := mcp.GetPromptMessages(context.Background(), clientID, promptID, args)
+// GetMCPPrompt fetches a prompt from an MCP server and joins its user
+// messages.
+func GetMCPPrompt(ctx context.Context, clientID, promptID string, args map[string]string) (string, error) {
+	result, err := mcp.GetPromptMessages(ctx, clientID, promptID, args)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomCommandExpand(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "no arguments",
+			content: "Review the code.",
+			raw:     "ignored",
+			want:    "Review the code.",
+		},
+		{
+			name:    "all arguments",
+			content: "Fix: $ARGUMENTS",
+			raw:     "  the failing test  ",
+			want:    "Fix: the failing test",
+		},
+		{
+			name:    "positional with remainder",
+			content: "Rename $FROM to $TO in $FILES",
+			raw:     "foo\tbar  a.go b.go",
+			want:    "Rename foo to bar in a.go b.go",
+		},
+		{
+			name:    "prefix names are replaced exactly",
+			content: "$NAME and $NAME_SUFFIX",
+			raw:     "one two",
+			want:    "one and two",
+		},
+		{
+			name:    "missing required argument",
+			content: "Compare $LEFT with $RIGHT",
+			raw:     "only-left",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := CustomCommand{
+				ID:        "project:test",
+				Content:   tt.content,
+				Arguments: extractArgNames(tt.content),
+			}
+			got, err := cmd.Expand(tt.raw)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCustomCommandExpandArgs(t *testing.T) {
+	t.Parallel()
+
+	cmd := CustomCommand{
+		ID:        "user:runbook",
+		Content:   "Restart $SERVICE in $REGION, then $SERVICE_CHECK.",
+		Arguments: extractArgNames("Restart $SERVICE in $REGION, then $SERVICE_CHECK."),
+	}
+
+	got, err := cmd.ExpandArgs(map[string]string{
+		"SERVICE":       "api",
+		"REGION":        "eu west",
+		"SERVICE_CHECK": "curl /health",
+		"UNKNOWN":       "ignored",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Restart api in eu west, then curl /health.", got)
+
+	_, err = cmd.ExpandArgs(map[string]string{"SERVICE": "api"})
+	require.ErrorContains(t, err, "REGION")
+}
+
+func TestMCPPromptExpandArgsMissingRequired(t *testing.T) {
+	t.Parallel()
+
+	prompt := MCPPrompt{
+		ID:       "docs:summarize",
+		PromptID: "summarize",
+		ClientID: "docs",
+		Arguments: []Argument{
+			{ID: "topic", Required: true},
+			{ID: "style"},
+		},
+	}
+
+	// The required check runs before the MCP server is contacted.
+	_, err := prompt.ExpandArgs(t.Context(), map[string]string{"style": "short"})
+	require.ErrorContains(t, err, "missing required argument topic")
+}
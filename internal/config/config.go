@@ -18,6 +18,7 @@ import (
 	hyperp "github.com/charmbracelet/crush/internal/agent/hyper"
 	"github.com/charmbracelet/crush/internal/csync"
 	"github.com/charmbracelet/crush/internal/env"
+	"github.com/charmbracelet/crush/internal/home"
 	"github.com/charmbracelet/crush/internal/oauth"
 	"github.com/charmbracelet/crush/internal/oauth/copilot"
 	"github.com/charmbracelet/crush/internal/oauth/hyper"
@@ -383,6 +384,10 @@ type Config struct {
 
 	Tools Tools `json:"tools,omitempty" jsonschema:"description=Tool configurations"`
 
+	Server *ServerOptions `json:"server,omitempty" jsonschema:"description=Headless API server settings"`
+
+	Webhooks Webhooks `json:"webhooks,omitempty" jsonschema:"description=Outbound webhooks notified of session and message events and permission requests and MCP/LSP errors"`
+
 	Agents map[string]Agent `json:"-"`
 
 	// Internal
@@ -397,6 +402,28 @@ func (c *Config) WorkingDir() string {
 	return c.workingDir
 }
 
+// SkillsDirs returns the skills paths with ~ and environment variables
+// expanded and relative paths resolved against the working directory.
+func (c *Config) SkillsDirs() []string {
+	if c.Options == nil {
+		return nil
+	}
+	dirs := make([]string, 0, len(c.Options.SkillsPaths))
+	for _, dir := range c.Options.SkillsPaths {
+		dir = home.Long(dir)
+		if strings.HasPrefix(dir, "$") && c.resolver != nil {
+			if expanded, err := c.resolver.ResolveValue(dir); err == nil {
+				dir = expanded
+			}
+		}
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(c.workingDir, dir)
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
 func (c *Config) EnabledProviders() []ProviderConfig {
 	var enabled []ProviderConfig
 	for p := range c.Providers.Seq() {
@@ -764,6 +791,15 @@ func (c *Config) SetupAgents() {
 	c.Agents = agents
 }
 
+// KnownProviders returns the providers from the catwalk catalog, whether
+// configured or not. It is empty when default providers are disabled.
+func (c *Config) KnownProviders() []catwalk.Provider {
+	if c.Options != nil && c.Options.DisableDefaultProviders {
+		return nil
+	}
+	return c.knownProviders
+}
+
 func (c *Config) Resolver() VariableResolver {
 	return c.resolver
 }
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/tidwall/gjson"
+)
+
+// editMu serializes edits of the data config file, which is shared by every
+// Config loaded in the process.
+var editMu sync.Mutex
+
+// Reloaded re-reads the configuration files and returns the result as a new
+// Config, leaving c untouched so it can keep being read concurrently. The
+// working directory and the runtime-only settings (data directory, debug and
+// skipped permission requests) are kept.
+func (c *Config) Reloaded() (*Config, error) {
+	var dataDir string
+	var debug, skipRequests bool
+	if c.Options != nil {
+		dataDir, debug = c.Options.DataDirectory, c.Options.Debug
+	}
+	if c.Permissions != nil {
+		skipRequests = c.Permissions.SkipRequests
+	}
+
+	fresh, err := Load(c.workingDir, dataDir, debug)
+	if err != nil {
+		return nil, err
+	}
+	if fresh.Permissions != nil || c.Permissions != nil {
+		if fresh.Permissions == nil {
+			fresh.Permissions = &Permissions{}
+		}
+		fresh.Permissions.SkipRequests = skipRequests
+	}
+	return fresh, nil
+}
+
+// Update runs edit against a copy of the configuration, which changes the
+// data config file through SetConfigField and friends, reloads the
+// configuration and runs check, if not nil, against the result. When any of
+// them fails the file is restored, so a bad edit never sticks. c is never
+// modified; the updated configuration is returned for the caller to swap in.
+func (c *Config) Update(edit, check func(*Config) error) (*Config, error) {
+	editMu.Lock()
+	defer editMu.Unlock()
+
+	previous, err := os.ReadFile(c.dataConfigDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	existed := err == nil
+
+	// Setters such as UpdatePreferredModel also change the Config they are
+	// called on, so edit gets a scratch copy instead of c.
+	scratch, err := c.Reloaded()
+	if err == nil {
+		err = edit(scratch)
+	}
+	var fresh *Config
+	if err == nil {
+		fresh, err = c.Reloaded()
+	}
+	if err == nil && check != nil {
+		err = check(fresh)
+	}
+	if err == nil {
+		return fresh, nil
+	}
+
+	var restoreErr error
+	if existed {
+		restoreErr = os.WriteFile(c.dataConfigDir, previous, 0o600)
+	} else if rmErr := os.Remove(c.dataConfigDir); rmErr != nil && !os.IsNotExist(rmErr) {
+		restoreErr = rmErr
+	}
+	if restoreErr != nil {
+		return nil, errors.Join(err, fmt.Errorf("failed to restore config file: %w", restoreErr))
+	}
+	return nil, err
+}
+
+// ConfigField returns the raw JSON value of key in the data config file, or
+// nil when it isn't set there.
+func (c *Config) ConfigField(key string) []byte {
+	data, err := os.ReadFile(c.dataConfigDir)
+	if err != nil {
+		return nil
+	}
+	result := gjson.GetBytes(data, key)
+	if !result.Exists() {
+		return nil
+	}
+	return []byte(result.Raw)
+}
+
+// DataConfigPath returns the path of the config file that SetConfigField
+// writes to.
+func (c *Config) DataConfigPath() string {
+	return filepath.Clean(c.dataConfigDir)
+}
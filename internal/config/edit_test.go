@@ -0,0 +1,70 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func loadEditTestConfig(t *testing.T) *Config {
+	t.Helper()
+	globalData := t.TempDir()
+	t.Setenv("CRUSH_GLOBAL_CONFIG", t.TempDir())
+	t.Setenv("CRUSH_GLOBAL_DATA", globalData)
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	require.NoError(t, os.WriteFile(
+		filepath.Join(globalData, "crush.json"),
+		[]byte(`{"options":{"disable_provider_auto_update":true}}`),
+		0o600,
+	))
+
+	workingDir := t.TempDir()
+	cfg, err := Load(workingDir, filepath.Join(workingDir, ".crush"), false)
+	require.NoError(t, err)
+	cfg.Permissions = &Permissions{SkipRequests: true}
+	return cfg
+}
+
+func TestUpdate_ReturnsNewConfig(t *testing.T) {
+	cfg := loadEditTestConfig(t)
+
+	var edited *Config
+	fresh, err := cfg.Update(func(c *Config) error {
+		edited = c
+		return c.SetConfigField("mcp.fs", map[string]any{"type": "stdio", "command": "fs"})
+	}, func(c *Config) error {
+		require.Contains(t, c.MCP, "fs")
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.NotSame(t, cfg, edited)
+	require.NotSame(t, cfg, fresh)
+	require.NotContains(t, cfg.MCP, "fs")
+	require.Equal(t, "fs", fresh.MCP["fs"].Command)
+	require.Equal(t, cfg.WorkingDir(), fresh.WorkingDir())
+	require.True(t, fresh.Permissions.SkipRequests)
+}
+
+func TestUpdate_RestoresFileOnFailure(t *testing.T) {
+	cfg := loadEditTestConfig(t)
+	before, err := os.ReadFile(cfg.dataConfigDir)
+	require.NoError(t, err)
+
+	checkErr := errors.New("rejected")
+	fresh, err := cfg.Update(func(c *Config) error {
+		return c.SetConfigField("mcp.fs", map[string]any{"type": "stdio", "command": "fs"})
+	}, func(*Config) error {
+		return checkErr
+	})
+	require.ErrorIs(t, err, checkErr)
+	require.Nil(t, fresh)
+
+	after, err := os.ReadFile(cfg.dataConfigDir)
+	require.NoError(t, err)
+	require.Equal(t, string(before), string(after))
+	require.NotContains(t, cfg.MCP, "fs")
+}
@@ -36,6 +36,12 @@ func Get() *Config {
 	return cfg
 }
 
+// Swap replaces the global config with cfg if it is still old, so a reload
+// never overwrites a newer one. It reports whether cfg was stored.
+func Swap(old, cfg *Config) bool {
+	return instance.CompareAndSwap(old, cfg)
+}
+
 func ProjectNeedsInitialization() (bool, error) {
 	cfg := Get()
 	if cfg == nil {
@@ -367,6 +367,9 @@ func (c *Config) setDefaults(workingDir, dataDir string) {
 	if c.LSP == nil {
 		c.LSP = make(map[string]LSPConfig)
 	}
+	if c.Server == nil {
+		c.Server = &ServerOptions{}
+	}
 
 	// Apply defaults to LSP configurations
 	c.applyLSPDefaults()
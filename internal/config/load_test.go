@@ -1458,3 +1458,22 @@ func TestConfig_configureSelectedModels(t *testing.T) {
 		require.Equal(t, int64(100), large.MaxTokens)
 	})
 }
+
+func TestConfig_SkillsDirs(t *testing.T) {
+	t.Parallel()
+
+	workingDir := t.TempDir()
+	cfg := &Config{
+		workingDir: workingDir,
+		resolver:   NewEnvironmentVariableResolver(env.NewFromMap(map[string]string{"TEAM_SKILLS": "/srv/skills"})),
+		Options: &Options{
+			SkillsPaths: []string{"./skills", "/opt/skills", "$TEAM_SKILLS"},
+		},
+	}
+
+	require.Equal(t, []string{
+		filepath.Join(workingDir, "skills"),
+		"/opt/skills",
+		"/srv/skills",
+	}, cfg.SkillsDirs())
+}
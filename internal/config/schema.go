@@ -0,0 +1,54 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	validator "github.com/google/jsonschema-go/jsonschema"
+	"github.com/invopop/jsonschema"
+)
+
+// schemaDefs caches the resolved schema of each definition.
+var schemaDefs sync.Map // map[string]*validator.Resolved
+
+// ValidateSchema validates data, a JSON document, against the definition
+// named def (e.g. "ProviderConfig" or "Options") of the configuration
+// schema, the one published as schema.json.
+func ValidateSchema(def string, data []byte) error {
+	resolved, err := resolveSchemaDef(def)
+	if err != nil {
+		return err
+	}
+	var instance any
+	if err := json.Unmarshal(data, &instance); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	return resolved.Validate(instance)
+}
+
+func resolveSchemaDef(def string) (*validator.Resolved, error) {
+	if v, ok := schemaDefs.Load(def); ok {
+		return v.(*validator.Resolved), nil
+	}
+
+	reflected := new(jsonschema.Reflector).Reflect(&Config{})
+	if _, ok := reflected.Definitions[def]; !ok {
+		return nil, fmt.Errorf("unknown schema definition %q", def)
+	}
+	reflected.Ref = "#/$defs/" + def
+	data, err := json.Marshal(reflected)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+	var schema validator.Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	resolved, err := schema.Resolve(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve schema: %w", err)
+	}
+	schemaDefs.Store(def, resolved)
+	return resolved, nil
+}
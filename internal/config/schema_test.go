@@ -0,0 +1,58 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSchema(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name  string
+		def   string
+		data  string
+		valid bool
+	}{
+		{"provider", "ProviderConfig", `{"type":"openai-compat","base_url":"http://localhost:8080/v1","api_key":"$KEY"}`, true},
+		{"provider unknown field", "ProviderConfig", `{"type":"openai","apikey":"x"}`, false},
+		{"provider bad type", "ProviderConfig", `{"type":"carrier-pigeon"}`, false},
+		{"mcp", "MCPConfig", `{"type":"stdio","command":"server","args":["--flag"]}`, true},
+		{"mcp without type", "MCPConfig", `{"command":"server"}`, false},
+		{"lsp", "LSPConfig", `{"command":"gopls","filetypes":["go"]}`, true},
+		{"model", "SelectedModel", `{"model":"gpt-4o","provider":"openai"}`, true},
+		{"model without provider", "SelectedModel", `{"model":"gpt-4o"}`, false},
+		{"options", "Options", `{"debug":true,"context_paths":["NOTES.md"]}`, true},
+		{"options wrong type", "Options", `{"debug":"yes"}`, false},
+		{"permissions", "Permissions", `{"allowed_tools":["view","bash:run"]}`, true},
+		{"not json", "Options", `{`, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := ValidateSchema(tc.def, []byte(tc.data))
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+
+	require.Error(t, ValidateSchema("Nope", []byte(`{}`)))
+}
+
+func TestConfigField(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cfg := &Config{}
+	cfg.setDefaults(dir, "")
+	cfg.dataConfigDir = dir + "/crush.json"
+
+	require.Nil(t, cfg.ConfigField("mcp.fs"))
+	require.NoError(t, cfg.SetConfigField("mcp.fs", map[string]any{"type": "stdio", "command": "fs"}))
+	require.JSONEq(t, `{"type":"stdio","command":"fs"}`, string(cfg.ConfigField("mcp.fs")))
+	require.Nil(t, cfg.ConfigField("mcp.other"))
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// ServerOptions configures the headless API server started by `serve`.
+type ServerOptions struct {
+	Tokens         []string       `json:"tokens,omitempty" jsonschema:"description=Static bearer tokens granting full access to the API server. Values support shell variable expansion,example=$CRUSH_API_TOKEN"`
+	APIKeys        []ServerAPIKey `json:"api_keys,omitempty" jsonschema:"description=Hashed API keys scoped to project directories. Managed with 'serve keys'"`
+	DisableSwagger bool           `json:"disable_swagger,omitempty" jsonschema:"description=Do not serve the Swagger and Redoc documentation pages,default=false"`
+	PublicSwagger  bool           `json:"public_swagger,omitempty" jsonschema:"description=Serve the documentation pages without authentication,default=false"`
+
+	// PermissionMode is the permission mode of sessions that don't choose one
+	// when they are created.
+	PermissionMode string `json:"permission_mode,omitempty" jsonschema:"description=Default permission mode of API sessions,enum=auto,enum=ask,enum=deny-dangerous,default=ask"`
+	// PermissionTimeout is how long a permission request waits for a reply
+	// before it is denied, in seconds. Zero waits indefinitely.
+	PermissionTimeout *int `json:"permission_timeout,omitempty" jsonschema:"description=Seconds a permission request waits for a reply before it is denied. 0 waits indefinitely,default=120,minimum=0"`
+
+	// RateLimit and RateBurst configure the per-client token bucket. Clients
+	// are identified by API key or token, or by remote address. A negative
+	// rate disables rate limiting.
+	RateLimit int `json:"rate_limit,omitempty" jsonschema:"description=Requests per second allowed for each client. Negative disables rate limiting,default=100"`
+	RateBurst int `json:"rate_burst,omitempty" jsonschema:"description=Maximum burst of requests for each client,default=200,minimum=1"`
+	// MaxConcurrentRuns and MaxProjectRuns cap the agent runs in progress
+	// across the server and within a single project. Negative means no limit.
+	MaxConcurrentRuns int `json:"max_concurrent_runs,omitempty" jsonschema:"description=Maximum agent runs in progress across all projects. Negative means no limit,default=16"`
+	MaxProjectRuns    int `json:"max_project_runs,omitempty" jsonschema:"description=Maximum agent runs in progress within one project. Negative means no limit,default=4"`
+	// RequestTimeout bounds regular requests, in seconds. Agent runs and the
+	// event stream are not affected. Negative disables the timeout.
+	RequestTimeout int `json:"request_timeout,omitempty" jsonschema:"description=Seconds before a regular API request times out. Agent runs and the event stream are not affected. Negative disables the timeout,default=30"`
+	// InstanceIdleTTL is how long a project app instance may stay unused
+	// before it is shut down, in seconds. MaxInstances caps the number of
+	// live instances; the least recently used idle one is evicted first.
+	// Instances with agent runs, running background jobs or event
+	// subscribers are never evicted. Negative values disable either limit.
+	InstanceIdleTTL int `json:"instance_idle_ttl,omitempty" jsonschema:"description=Seconds a project app instance may stay unused before it is shut down. Negative keeps instances until disposed,default=1800"`
+	MaxInstances    int `json:"max_instances,omitempty" jsonschema:"description=Maximum live project app instances. The least recently used idle instance is evicted first. Negative means no limit,default=16"`
+}
+
+// ServerAPIKey is an API key accepted by the API server. Only the SHA-256
+// hash of the key is stored.
+type ServerAPIKey struct {
+	Name      string    `json:"name" jsonschema:"required,description=Unique name of the key"`
+	Hash      string    `json:"hash" jsonschema:"required,description=Hex encoded SHA-256 hash of the key"`
+	Projects  []string  `json:"projects,omitempty" jsonschema:"description=Project directories the key can access. Empty means all projects"`
+	ReadOnly  bool      `json:"read_only,omitempty" jsonschema:"description=Only allow read requests,default=false"`
+	CreatedAt time.Time `json:"created_at,omitzero" jsonschema:"description=When the key was created"`
+}
+
+// HasCredentials reports whether any token or API key is configured.
+func (s *ServerOptions) HasCredentials() bool {
+	return s != nil && (len(s.Tokens) > 0 || len(s.APIKeys) > 0)
+}
+
+// AddServerAPIKey stores a new API key in the data config. Key names must be
+// unique.
+func (c *Config) AddServerAPIKey(key ServerAPIKey) error {
+	if key.Name == "" {
+		return fmt.Errorf("api key name is required")
+	}
+	keys, err := c.storedServerAPIKeys()
+	if err != nil {
+		return err
+	}
+	if slices.ContainsFunc(keys, func(k ServerAPIKey) bool { return k.Name == key.Name }) {
+		return fmt.Errorf("api key %q already exists", key.Name)
+	}
+	keys = append(keys, key)
+	if err := c.SetConfigField("server.api_keys", keys); err != nil {
+		return fmt.Errorf("failed to persist api keys: %w", err)
+	}
+
+	if c.Server == nil {
+		c.Server = &ServerOptions{}
+	}
+	c.Server.APIKeys = append(c.Server.APIKeys, key)
+	return nil
+}
+
+// RemoveServerAPIKey deletes the API key with the given name from the data
+// config. It reports whether a key was removed.
+func (c *Config) RemoveServerAPIKey(name string) (bool, error) {
+	keys, err := c.storedServerAPIKeys()
+	if err != nil {
+		return false, err
+	}
+	remaining := slices.DeleteFunc(slices.Clone(keys), func(k ServerAPIKey) bool { return k.Name == name })
+	if len(remaining) == len(keys) {
+		return false, nil
+	}
+	if err := c.SetConfigField("server.api_keys", remaining); err != nil {
+		return false, fmt.Errorf("failed to persist api keys: %w", err)
+	}
+
+	if c.Server != nil {
+		c.Server.APIKeys = slices.DeleteFunc(c.Server.APIKeys, func(k ServerAPIKey) bool { return k.Name == name })
+	}
+	return true, nil
+}
+
+// storedServerAPIKeys reads the API keys from the data config only, so keys
+// defined in project config files are never copied into it.
+func (c *Config) storedServerAPIKeys() ([]ServerAPIKey, error) {
+	data, err := os.ReadFile(c.dataConfigDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	raw := gjson.GetBytes(data, "server.api_keys")
+	if !raw.Exists() {
+		return nil, nil
+	}
+	var keys []ServerAPIKey
+	if err := json.Unmarshal([]byte(raw.Raw), &keys); err != nil {
+		return nil, fmt.Errorf("failed to parse api keys: %w", err)
+	}
+	return keys, nil
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerAPIKeys_AddAndRemove(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cfg := &Config{}
+	cfg.setDefaults(dir, "")
+	cfg.dataConfigDir = filepath.Join(dir, "config.json")
+
+	require.False(t, cfg.Server.HasCredentials())
+
+	err := cfg.AddServerAPIKey(ServerAPIKey{Name: "bot", Hash: "abc", Projects: []string{"/srv/repo"}, ReadOnly: true})
+	require.NoError(t, err)
+	require.True(t, cfg.Server.HasCredentials())
+	require.Len(t, cfg.Server.APIKeys, 1)
+
+	err = cfg.AddServerAPIKey(ServerAPIKey{Name: "bot", Hash: "def"})
+	require.Error(t, err)
+
+	out := readConfigJSON(t, cfg.dataConfigDir)
+	server, ok := out["server"].(map[string]any)
+	require.True(t, ok)
+	keys, ok := server["api_keys"].([]any)
+	require.True(t, ok)
+	require.Len(t, keys, 1)
+	item, ok := keys[0].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, "bot", item["name"])
+	require.Equal(t, "abc", item["hash"])
+	require.Equal(t, true, item["read_only"])
+
+	removed, err := cfg.RemoveServerAPIKey("bot")
+	require.NoError(t, err)
+	require.True(t, removed)
+	require.Empty(t, cfg.Server.APIKeys)
+
+	removed, err = cfg.RemoveServerAPIKey("bot")
+	require.NoError(t, err)
+	require.False(t, removed)
+}
+
+func TestServerAPIKeys_OnlyDataConfigIsRewritten(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cfg := &Config{
+		Server: &ServerOptions{
+			APIKeys: []ServerAPIKey{{Name: "from-project", Hash: "xyz"}},
+		},
+	}
+	cfg.setDefaults(dir, "")
+	cfg.dataConfigDir = filepath.Join(dir, "config.json")
+	require.NoError(t, os.WriteFile(cfg.dataConfigDir, []byte(`{"server":{"api_keys":[{"name":"stored","hash":"123"}]}}`), 0o600))
+
+	require.NoError(t, cfg.AddServerAPIKey(ServerAPIKey{Name: "new", Hash: "456"}))
+
+	out := readConfigJSON(t, cfg.dataConfigDir)
+	keys := out["server"].(map[string]any)["api_keys"].([]any)
+	require.Len(t, keys, 2)
+	require.Equal(t, "stored", keys[0].(map[string]any)["name"])
+	require.Equal(t, "new", keys[1].(map[string]any)["name"])
+	require.Len(t, cfg.Server.APIKeys, 2)
+}
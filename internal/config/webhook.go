@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/env"
+)
+
+// WebhookConfig is an HTTP endpoint that receives session, message,
+// permission and MCP/LSP error events.
+type WebhookConfig struct {
+	URL string `json:"url" jsonschema:"required,description=URL the events are posted to,format=uri,example=https://chatops.example.com/hooks/crush"`
+	// Secret signs deliveries with HMAC-SHA256 so receivers can verify them.
+	Secret  string            `json:"secret,omitempty" jsonschema:"description=Secret used to sign deliveries with HMAC-SHA256. Supports shell variable expansion,example=$CRUSH_WEBHOOK_SECRET"`
+	Headers map[string]string `json:"headers,omitempty" jsonschema:"description=Additional HTTP headers sent with each delivery. Values support shell variable expansion"`
+	// Events filters the event types sent to the endpoint. A trailing ".*"
+	// matches every event of a group.
+	Events []string `json:"events,omitempty" jsonschema:"description=Event types to send. A trailing .* matches a group of events. Empty sends all events,example=message.finished,example=session.*"`
+	// CostThreshold enables the session.cost_exceeded event, sent once per
+	// session when its cost reaches the threshold.
+	CostThreshold float64 `json:"cost_threshold,omitempty" jsonschema:"description=Session cost in USD above which a session.cost_exceeded event is sent. Zero disables the event,minimum=0,example=5"`
+	MaxAttempts   int     `json:"max_attempts,omitempty" jsonschema:"description=Maximum delivery attempts of an event including retries,default=5,minimum=1"`
+	Timeout       int     `json:"timeout,omitempty" jsonschema:"description=Timeout in seconds of each delivery attempt,default=10,minimum=1"`
+	Disabled      bool    `json:"disabled,omitempty" jsonschema:"description=Whether this webhook is disabled,default=false"`
+}
+
+type Webhooks map[string]WebhookConfig
+
+type Webhook struct {
+	Name    string        `json:"name"`
+	Webhook WebhookConfig `json:"webhook"`
+}
+
+func (w Webhooks) Sorted() []Webhook {
+	sorted := make([]Webhook, 0, len(w))
+	for _, name := range slices.Sorted(maps.Keys(w)) {
+		sorted = append(sorted, Webhook{Name: name, Webhook: w[name]})
+	}
+	return sorted
+}
+
+// ResolvedSecret returns the secret with shell variables expanded.
+func (w WebhookConfig) ResolvedSecret() (string, error) {
+	if w.Secret == "" {
+		return "", nil
+	}
+	secret, err := NewShellVariableResolver(env.New()).ResolveValue(w.Secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve secret: %w", err)
+	}
+	if strings.TrimSpace(secret) == "" {
+		return "", fmt.Errorf("secret %q resolved to an empty value", w.Secret)
+	}
+	return secret, nil
+}
+
+// ResolvedHeaders returns a copy of the headers with shell variables
+// expanded.
+func (w WebhookConfig) ResolvedHeaders() (map[string]string, error) {
+	resolver := NewShellVariableResolver(env.New())
+	headers := make(map[string]string, len(w.Headers))
+	for k, v := range w.Headers {
+		resolved, err := resolver.ResolveValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve header %s: %w", k, err)
+		}
+		headers[k] = resolved
+	}
+	return headers, nil
+}
@@ -3,7 +3,9 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"path/filepath"
 
@@ -41,3 +43,59 @@ func Connect(ctx context.Context, dataDir string) (*sql.DB, error) {
 
 	return db, nil
 }
+
+// ErrOutdatedSchema is returned by OpenReadOnly when the database was not
+// migrated to the latest schema yet.
+var ErrOutdatedSchema = errors.New("database schema is outdated")
+
+// OpenReadOnly opens an existing SQLite database in read-only mode. Unlike
+// Connect it never runs migrations, so it can be used on the database of a
+// project owned by another process. It returns ErrOutdatedSchema when the
+// database is older than the latest migration.
+func OpenReadOnly(ctx context.Context, dataDir string) (*sql.DB, error) {
+	if dataDir == "" {
+		return nil, fmt.Errorf("data.dir is not set")
+	}
+	dbPath := filepath.Join(dataDir, "crush.db")
+
+	db, err := openReadOnlyDB(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var version int64
+	if err := db.QueryRowContext(ctx,
+		"SELECT COALESCE(MAX(version_id), 0) FROM goose_db_version WHERE is_applied = 1",
+	).Scan(&version); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	latest, err := latestMigration()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if version < latest {
+		db.Close()
+		return nil, fmt.Errorf("%w: version %d, expected %d", ErrOutdatedSchema, version, latest)
+	}
+
+	return db, nil
+}
+
+// latestMigration returns the version of the latest embedded migration.
+func latestMigration() (int64, error) {
+	entries, err := fs.ReadDir(FS, "migrations")
+	if err != nil {
+		return 0, fmt.Errorf("failed to read migrations: %w", err)
+	}
+	var latest int64
+	for _, e := range entries {
+		version, err := goose.NumericComponent(e.Name())
+		if err != nil {
+			continue
+		}
+		latest = max(latest, version)
+	}
+	return latest, nil
+}
@@ -29,3 +29,17 @@ func openDB(dbPath string) (*sql.DB, error) {
 	}
 	return db, nil
 }
+
+func openReadOnlyDB(dbPath string) (*sql.DB, error) {
+	params := url.Values{}
+	params.Add("mode", "ro")
+	params.Add("_pragma", "query_only(on)")
+	params.Add("_pragma", "busy_timeout(5000)")
+
+	dsn := fmt.Sprintf("file:%s?%s", dbPath, params.Encode())
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	return db, nil
+}
@@ -36,3 +36,23 @@ func openDB(dbPath string) (*sql.DB, error) {
 	}
 	return db, nil
 }
+
+func openReadOnlyDB(dbPath string) (*sql.DB, error) {
+	pragmas := []string{
+		"PRAGMA query_only = ON;",
+		"PRAGMA busy_timeout = 5000;",
+	}
+
+	db, err := driver.Open("file:"+dbPath+"?mode=ro", func(c *sqlite3.Conn) error {
+		for _, pragma := range pragmas {
+			if err := c.Exec(pragma); err != nil {
+				return fmt.Errorf("failed to set pragma %q: %w", pragma, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	return db, nil
+}
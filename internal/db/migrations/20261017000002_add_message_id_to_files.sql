@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- ID of the message whose tool call created the version, empty when unknown.
+ALTER TABLE files ADD COLUMN message_id TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE files DROP COLUMN message_id;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS session_reverts (
+    session_id TEXT PRIMARY KEY,
+    message_id TEXT NOT NULL,
+    part_id TEXT NOT NULL DEFAULT '',
+    diff TEXT NOT NULL DEFAULT '',
+    files TEXT NOT NULL DEFAULT '[]',  -- JSON snapshot of the reverted files before the revert
+    created_at INTEGER NOT NULL,  -- Unix timestamp in seconds
+    FOREIGN KEY (session_id) REFERENCES sessions (id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS session_reverts;
+-- +goose StatementEnd
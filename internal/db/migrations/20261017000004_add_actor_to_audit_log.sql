@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- API caller that started the audited action, empty when unknown.
+ALTER TABLE audit_log ADD COLUMN actor TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE audit_log DROP COLUMN actor;
+-- +goose StatementEnd
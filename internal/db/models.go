@@ -8,6 +8,26 @@ import (
 	"database/sql"
 )
 
+type AuditLog struct {
+	ID         string        `json:"id"`
+	Kind       string        `json:"kind"`
+	SessionID  string        `json:"session_id"`
+	ToolCallID string        `json:"tool_call_id"`
+	ToolName   string        `json:"tool_name"`
+	Action     string        `json:"action"`
+	Path       string        `json:"path"`
+	Params     string        `json:"params"`
+	Decision   string        `json:"decision"`
+	Decider    string        `json:"decider"`
+	Reason     string        `json:"reason"`
+	Status     string        `json:"status"`
+	ExitCode   sql.NullInt64 `json:"exit_code"`
+	Error      string        `json:"error"`
+	CreatedAt  int64         `json:"created_at"`  // Unix timestamp in milliseconds
+	FinishedAt sql.NullInt64 `json:"finished_at"` // Unix timestamp in milliseconds when the tool finished
+	Actor      string        `json:"actor"`
+}
+
 type File struct {
 	ID        string `json:"id"`
 	SessionID string `json:"session_id"`
@@ -16,6 +36,7 @@ type File struct {
 	Version   int64  `json:"version"`
 	CreatedAt int64  `json:"created_at"`
 	UpdatedAt int64  `json:"updated_at"`
+	MessageID string `json:"message_id"`
 }
 
 type Message struct {
@@ -49,4 +70,14 @@ type Session struct {
 	CreatedAt        int64          `json:"created_at"`
 	SummaryMessageID sql.NullString `json:"summary_message_id"`
 	Todos            sql.NullString `json:"todos"`
+	PermissionMode   string         `json:"permission_mode"`
+}
+
+type SessionRevert struct {
+	SessionID string `json:"session_id"`
+	MessageID string `json:"message_id"`
+	PartID    string `json:"part_id"`
+	Diff      string `json:"diff"`
+	Files     string `json:"files"`
+	CreatedAt int64  `json:"created_at"`
 }
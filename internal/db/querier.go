@@ -6,9 +6,12 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
 type Querier interface {
+	CountAuditEntries(ctx context.Context, arg CountAuditEntriesParams) (int64, error)
+	CreateAuditEntry(ctx context.Context, arg CreateAuditEntryParams) error
 	CreateFile(ctx context.Context, arg CreateFileParams) (File, error)
 	CreateMessage(ctx context.Context, arg CreateMessageParams) (Message, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
@@ -17,21 +20,29 @@ type Querier interface {
 	DeleteSession(ctx context.Context, id string) error
 	DeleteSessionFiles(ctx context.Context, sessionID string) error
 	DeleteSessionMessages(ctx context.Context, sessionID string) error
-	GetAverageResponseTime(ctx context.Context) (int64, error)
+	DeleteSessionRevert(ctx context.Context, sessionID string) error
+	GetAverageResponseTime(ctx context.Context, arg GetAverageResponseTimeParams) (GetAverageResponseTimeRow, error)
 	GetFile(ctx context.Context, id string) (File, error)
 	GetFileByPathAndSession(ctx context.Context, arg GetFileByPathAndSessionParams) (File, error)
 	GetFileRead(ctx context.Context, arg GetFileReadParams) (ReadFile, error)
-	GetHourDayHeatmap(ctx context.Context) ([]GetHourDayHeatmapRow, error)
+	GetHourDayHeatmap(ctx context.Context, arg GetHourDayHeatmapParams) ([]GetHourDayHeatmapRow, error)
 	GetMessage(ctx context.Context, id string) (Message, error)
-	GetRecentActivity(ctx context.Context) ([]GetRecentActivityRow, error)
+	GetRecentActivity(ctx context.Context, arg GetRecentActivityParams) ([]GetRecentActivityRow, error)
 	GetSessionByID(ctx context.Context, id string) (Session, error)
-	GetToolUsage(ctx context.Context) ([]GetToolUsageRow, error)
-	GetTotalStats(ctx context.Context) (GetTotalStatsRow, error)
-	GetUsageByDay(ctx context.Context) ([]GetUsageByDayRow, error)
-	GetUsageByDayOfWeek(ctx context.Context) ([]GetUsageByDayOfWeekRow, error)
-	GetUsageByHour(ctx context.Context) ([]GetUsageByHourRow, error)
-	GetUsageByModel(ctx context.Context) ([]GetUsageByModelRow, error)
+	GetSessionCosts(ctx context.Context, arg GetSessionCostsParams) ([]GetSessionCostsRow, error)
+	GetSessionRevert(ctx context.Context, sessionID string) (SessionRevert, error)
+	GetToolUsage(ctx context.Context, arg GetToolUsageParams) ([]GetToolUsageRow, error)
+	GetTotalStats(ctx context.Context, arg GetTotalStatsParams) (GetTotalStatsRow, error)
+	GetUsageByDay(ctx context.Context, arg GetUsageByDayParams) ([]GetUsageByDayRow, error)
+	GetUsageByDayOfWeek(ctx context.Context, arg GetUsageByDayOfWeekParams) ([]GetUsageByDayOfWeekRow, error)
+	GetUsageByHour(ctx context.Context, arg GetUsageByHourParams) ([]GetUsageByHourRow, error)
+	GetUsageByModel(ctx context.Context, arg GetUsageByModelParams) ([]GetUsageByModelRow, error)
+	ImportFile(ctx context.Context, arg ImportFileParams) error
+	ImportMessage(ctx context.Context, arg ImportMessageParams) error
+	ImportSession(ctx context.Context, arg ImportSessionParams) error
 	ListAllUserMessages(ctx context.Context) ([]Message, error)
+	ListAuditEntries(ctx context.Context, arg ListAuditEntriesParams) ([]AuditLog, error)
+	ListChildSessions(ctx context.Context, parentSessionID sql.NullString) ([]Session, error)
 	ListFilesByPath(ctx context.Context, path string) ([]File, error)
 	ListFilesBySession(ctx context.Context, sessionID string) ([]File, error)
 	ListLatestSessionFiles(ctx context.Context, sessionID string) ([]File, error)
@@ -40,8 +51,10 @@ type Querier interface {
 	ListSessions(ctx context.Context) ([]Session, error)
 	ListUserMessagesBySession(ctx context.Context, sessionID string) ([]Message, error)
 	RecordFileRead(ctx context.Context, arg RecordFileReadParams) error
+	SetSessionRevert(ctx context.Context, arg SetSessionRevertParams) error
 	UpdateMessage(ctx context.Context, arg UpdateMessageParams) error
 	UpdateSession(ctx context.Context, arg UpdateSessionParams) (Session, error)
+	UpdateSessionPermissionMode(ctx context.Context, arg UpdateSessionPermissionModeParams) error
 	UpdateSessionTitleAndUsage(ctx context.Context, arg UpdateSessionTitleAndUsageParams) error
 }
 
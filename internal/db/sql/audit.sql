@@ -0,0 +1,46 @@
+-- name: CreateAuditEntry :exec
+INSERT INTO audit_log (
+    id,
+    kind,
+    session_id,
+    tool_call_id,
+    tool_name,
+    action,
+    path,
+    params,
+    decision,
+    decider,
+    reason,
+    status,
+    exit_code,
+    error,
+    created_at,
+    finished_at,
+    actor
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: ListAuditEntries :many
+SELECT *
+FROM audit_log
+WHERE (sqlc.narg('session_id') IS NULL OR session_id = sqlc.narg('session_id'))
+  AND (sqlc.narg('kind') IS NULL OR kind = sqlc.narg('kind'))
+  AND (sqlc.narg('tool_name') IS NULL OR tool_name = sqlc.narg('tool_name'))
+  AND (sqlc.narg('decider') IS NULL OR decider = sqlc.narg('decider'))
+  AND (sqlc.narg('actor') IS NULL OR actor = sqlc.narg('actor'))
+  AND (sqlc.narg('start_time') IS NULL OR created_at >= sqlc.narg('start_time'))
+  AND (sqlc.narg('end_time') IS NULL OR created_at < sqlc.narg('end_time'))
+ORDER BY created_at DESC, rowid DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountAuditEntries :one
+SELECT COUNT(*)
+FROM audit_log
+WHERE (sqlc.narg('session_id') IS NULL OR session_id = sqlc.narg('session_id'))
+  AND (sqlc.narg('kind') IS NULL OR kind = sqlc.narg('kind'))
+  AND (sqlc.narg('tool_name') IS NULL OR tool_name = sqlc.narg('tool_name'))
+  AND (sqlc.narg('decider') IS NULL OR decider = sqlc.narg('decider'))
+  AND (sqlc.narg('actor') IS NULL OR actor = sqlc.narg('actor'))
+  AND (sqlc.narg('start_time') IS NULL OR created_at >= sqlc.narg('start_time'))
+  AND (sqlc.narg('end_time') IS NULL OR created_at < sqlc.narg('end_time'));
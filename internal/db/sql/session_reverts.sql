@@ -0,0 +1,26 @@
+-- name: GetSessionRevert :one
+SELECT *
+FROM session_reverts
+WHERE session_id = ? LIMIT 1;
+
+-- name: SetSessionRevert :exec
+INSERT INTO session_reverts (
+    session_id,
+    message_id,
+    part_id,
+    diff,
+    files,
+    created_at
+) VALUES (
+    ?, ?, ?, ?, ?, strftime('%s', 'now')
+)
+ON CONFLICT (session_id) DO UPDATE SET
+    message_id = excluded.message_id,
+    part_id = excluded.part_id,
+    diff = excluded.diff,
+    files = excluded.files,
+    created_at = excluded.created_at;
+
+-- name: DeleteSessionRevert :exec
+DELETE FROM session_reverts
+WHERE session_id = ?;
@@ -28,6 +28,12 @@ SELECT *
 FROM sessions
 WHERE id = ? LIMIT 1;
 
+-- name: ListChildSessions :many
+SELECT *
+FROM sessions
+WHERE parent_session_id = ?
+ORDER BY created_at ASC;
+
 -- name: ListSessions :many
 SELECT *
 FROM sessions
@@ -55,7 +61,38 @@ SET
     cost = cost + ?
 WHERE id = ?;
 
+-- name: UpdateSessionPermissionMode :exec
+UPDATE sessions
+SET permission_mode = ?
+WHERE id = ?;
 
 -- name: DeleteSession :exec
 DELETE FROM sessions
 WHERE id = ?;
+
+-- name: ImportSession :exec
+INSERT INTO sessions (
+    id,
+    parent_session_id,
+    title,
+    message_count,
+    prompt_tokens,
+    completion_tokens,
+    cost,
+    summary_message_id,
+    todos,
+    updated_at,
+    created_at
+) VALUES (
+    ?,
+    ?,
+    ?,
+    0,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?
+);
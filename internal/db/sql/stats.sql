@@ -7,6 +7,11 @@ SELECT
     COUNT(*) as session_count
 FROM sessions
 WHERE parent_session_id IS NULL
+  AND (sqlc.narg('start_time') IS NULL OR created_at >= sqlc.narg('start_time'))
+  AND (sqlc.narg('end_time') IS NULL OR created_at < sqlc.narg('end_time'))
+  AND (sqlc.narg('model') IS NULL OR id IN (
+    SELECT session_id FROM messages WHERE role = 'assistant' AND model = sqlc.narg('model')
+  ))
 GROUP BY date(created_at, 'unixepoch')
 ORDER BY day DESC;
 
@@ -17,6 +22,9 @@ SELECT
     COUNT(*) as message_count
 FROM messages
 WHERE role = 'assistant'
+  AND (sqlc.narg('start_time') IS NULL OR created_at >= sqlc.narg('start_time'))
+  AND (sqlc.narg('end_time') IS NULL OR created_at < sqlc.narg('end_time'))
+  AND (sqlc.narg('model') IS NULL OR model = sqlc.narg('model'))
 GROUP BY model, provider
 ORDER BY message_count DESC;
 
@@ -26,6 +34,11 @@ SELECT
     COUNT(*) as session_count
 FROM sessions
 WHERE parent_session_id IS NULL
+  AND (sqlc.narg('start_time') IS NULL OR created_at >= sqlc.narg('start_time'))
+  AND (sqlc.narg('end_time') IS NULL OR created_at < sqlc.narg('end_time'))
+  AND (sqlc.narg('model') IS NULL OR id IN (
+    SELECT session_id FROM messages WHERE role = 'assistant' AND model = sqlc.narg('model')
+  ))
 GROUP BY hour
 ORDER BY hour;
 
@@ -37,6 +50,11 @@ SELECT
     SUM(completion_tokens) as completion_tokens
 FROM sessions
 WHERE parent_session_id IS NULL
+  AND (sqlc.narg('start_time') IS NULL OR created_at >= sqlc.narg('start_time'))
+  AND (sqlc.narg('end_time') IS NULL OR created_at < sqlc.narg('end_time'))
+  AND (sqlc.narg('model') IS NULL OR id IN (
+    SELECT session_id FROM messages WHERE role = 'assistant' AND model = sqlc.narg('model')
+  ))
 GROUP BY day_of_week
 ORDER BY day_of_week;
 
@@ -50,7 +68,12 @@ SELECT
     COALESCE(AVG(prompt_tokens + completion_tokens), 0) as avg_tokens_per_session,
     COALESCE(AVG(message_count), 0) as avg_messages_per_session
 FROM sessions
-WHERE parent_session_id IS NULL;
+WHERE parent_session_id IS NULL
+  AND (sqlc.narg('start_time') IS NULL OR created_at >= sqlc.narg('start_time'))
+  AND (sqlc.narg('end_time') IS NULL OR created_at < sqlc.narg('end_time'))
+  AND (sqlc.narg('model') IS NULL OR id IN (
+    SELECT session_id FROM messages WHERE role = 'assistant' AND model = sqlc.narg('model')
+  ));
 
 -- name: GetRecentActivity :many
 SELECT
@@ -60,17 +83,26 @@ SELECT
     SUM(cost) as cost
 FROM sessions
 WHERE parent_session_id IS NULL
+  AND (sqlc.narg('start_time') IS NULL OR created_at >= sqlc.narg('start_time'))
+  AND (sqlc.narg('end_time') IS NULL OR created_at < sqlc.narg('end_time'))
+  AND (sqlc.narg('model') IS NULL OR id IN (
+    SELECT session_id FROM messages WHERE role = 'assistant' AND model = sqlc.narg('model')
+  ))
   AND created_at >= strftime('%s', 'now', '-30 days')
 GROUP BY date(created_at, 'unixepoch')
 ORDER BY day ASC;
 
 -- name: GetAverageResponseTime :one
 SELECT
-    CAST(COALESCE(AVG(finished_at - created_at), 0) AS INTEGER) as avg_response_seconds
+    CAST(COALESCE(AVG(finished_at - created_at), 0) AS INTEGER) as avg_response_seconds,
+    COUNT(*) as response_count
 FROM messages
 WHERE role = 'assistant'
   AND finished_at IS NOT NULL
-  AND finished_at > created_at;
+  AND finished_at > created_at
+  AND (sqlc.narg('start_time') IS NULL OR created_at >= sqlc.narg('start_time'))
+  AND (sqlc.narg('end_time') IS NULL OR created_at < sqlc.narg('end_time'))
+  AND (sqlc.narg('model') IS NULL OR model = sqlc.narg('model'));
 
 -- name: GetToolUsage :many
 SELECT
@@ -79,6 +111,9 @@ SELECT
 FROM messages, json_each(parts)
 WHERE json_extract(value, '$.type') = 'tool_call'
   AND json_extract(value, '$.data.name') IS NOT NULL
+  AND (sqlc.narg('start_time') IS NULL OR messages.created_at >= sqlc.narg('start_time'))
+  AND (sqlc.narg('end_time') IS NULL OR messages.created_at < sqlc.narg('end_time'))
+  AND (sqlc.narg('model') IS NULL OR messages.model = sqlc.narg('model'))
 GROUP BY tool_name
 ORDER BY call_count DESC;
 
@@ -89,5 +124,29 @@ SELECT
     COUNT(*) as session_count
 FROM sessions
 WHERE parent_session_id IS NULL
+  AND (sqlc.narg('start_time') IS NULL OR created_at >= sqlc.narg('start_time'))
+  AND (sqlc.narg('end_time') IS NULL OR created_at < sqlc.narg('end_time'))
+  AND (sqlc.narg('model') IS NULL OR id IN (
+    SELECT session_id FROM messages WHERE role = 'assistant' AND model = sqlc.narg('model')
+  ))
 GROUP BY day_of_week, hour
 ORDER BY day_of_week, hour;
+
+-- name: GetSessionCosts :many
+SELECT
+    id,
+    title,
+    message_count,
+    prompt_tokens,
+    completion_tokens,
+    cost,
+    created_at
+FROM sessions
+WHERE parent_session_id IS NULL
+  AND (sqlc.narg('start_time') IS NULL OR created_at >= sqlc.narg('start_time'))
+  AND (sqlc.narg('end_time') IS NULL OR created_at < sqlc.narg('end_time'))
+  AND (sqlc.narg('model') IS NULL OR id IN (
+    SELECT session_id FROM messages WHERE role = 'assistant' AND model = sqlc.narg('model')
+  ))
+ORDER BY cost DESC, created_at DESC
+LIMIT sqlc.arg('limit');
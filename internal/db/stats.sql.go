@@ -12,18 +12,33 @@ import (
 
 const getAverageResponseTime = `-- name: GetAverageResponseTime :one
 SELECT
-    CAST(COALESCE(AVG(finished_at - created_at), 0) AS INTEGER) as avg_response_seconds
+    CAST(COALESCE(AVG(finished_at - created_at), 0) AS INTEGER) as avg_response_seconds,
+    COUNT(*) as response_count
 FROM messages
 WHERE role = 'assistant'
   AND finished_at IS NOT NULL
   AND finished_at > created_at
+  AND (?1 IS NULL OR created_at >= ?1)
+  AND (?2 IS NULL OR created_at < ?2)
+  AND (?3 IS NULL OR model = ?3)
 `
 
-func (q *Queries) GetAverageResponseTime(ctx context.Context) (int64, error) {
-	row := q.queryRow(ctx, q.getAverageResponseTimeStmt, getAverageResponseTime)
-	var avg_response_seconds int64
-	err := row.Scan(&avg_response_seconds)
-	return avg_response_seconds, err
+type GetAverageResponseTimeParams struct {
+	StartTime sql.NullInt64  `json:"start_time"`
+	EndTime   sql.NullInt64  `json:"end_time"`
+	Model     sql.NullString `json:"model"`
+}
+
+type GetAverageResponseTimeRow struct {
+	AvgResponseSeconds int64 `json:"avg_response_seconds"`
+	ResponseCount      int64 `json:"response_count"`
+}
+
+func (q *Queries) GetAverageResponseTime(ctx context.Context, arg GetAverageResponseTimeParams) (GetAverageResponseTimeRow, error) {
+	row := q.queryRow(ctx, q.getAverageResponseTimeStmt, getAverageResponseTime, arg.StartTime, arg.EndTime, arg.Model)
+	var i GetAverageResponseTimeRow
+	err := row.Scan(&i.AvgResponseSeconds, &i.ResponseCount)
+	return i, err
 }
 
 const getHourDayHeatmap = `-- name: GetHourDayHeatmap :many
@@ -33,18 +48,29 @@ SELECT
     COUNT(*) as session_count
 FROM sessions
 WHERE parent_session_id IS NULL
+  AND (?1 IS NULL OR created_at >= ?1)
+  AND (?2 IS NULL OR created_at < ?2)
+  AND (?3 IS NULL OR id IN (
+    SELECT session_id FROM messages WHERE role = 'assistant' AND model = ?3
+  ))
 GROUP BY day_of_week, hour
 ORDER BY day_of_week, hour
 `
 
+type GetHourDayHeatmapParams struct {
+	StartTime sql.NullInt64  `json:"start_time"`
+	EndTime   sql.NullInt64  `json:"end_time"`
+	Model     sql.NullString `json:"model"`
+}
+
 type GetHourDayHeatmapRow struct {
 	DayOfWeek    int64 `json:"day_of_week"`
 	Hour         int64 `json:"hour"`
 	SessionCount int64 `json:"session_count"`
 }
 
-func (q *Queries) GetHourDayHeatmap(ctx context.Context) ([]GetHourDayHeatmapRow, error) {
-	rows, err := q.query(ctx, q.getHourDayHeatmapStmt, getHourDayHeatmap)
+func (q *Queries) GetHourDayHeatmap(ctx context.Context, arg GetHourDayHeatmapParams) ([]GetHourDayHeatmapRow, error) {
+	rows, err := q.query(ctx, q.getHourDayHeatmapStmt, getHourDayHeatmap, arg.StartTime, arg.EndTime, arg.Model)
 	if err != nil {
 		return nil, err
 	}
@@ -74,11 +100,22 @@ SELECT
     SUM(cost) as cost
 FROM sessions
 WHERE parent_session_id IS NULL
+  AND (?1 IS NULL OR created_at >= ?1)
+  AND (?2 IS NULL OR created_at < ?2)
+  AND (?3 IS NULL OR id IN (
+    SELECT session_id FROM messages WHERE role = 'assistant' AND model = ?3
+  ))
   AND created_at >= strftime('%s', 'now', '-30 days')
 GROUP BY date(created_at, 'unixepoch')
 ORDER BY day ASC
 `
 
+type GetRecentActivityParams struct {
+	StartTime sql.NullInt64  `json:"start_time"`
+	EndTime   sql.NullInt64  `json:"end_time"`
+	Model     sql.NullString `json:"model"`
+}
+
 type GetRecentActivityRow struct {
 	Day          interface{}     `json:"day"`
 	SessionCount int64           `json:"session_count"`
@@ -86,8 +123,8 @@ type GetRecentActivityRow struct {
 	Cost         sql.NullFloat64 `json:"cost"`
 }
 
-func (q *Queries) GetRecentActivity(ctx context.Context) ([]GetRecentActivityRow, error) {
-	rows, err := q.query(ctx, q.getRecentActivityStmt, getRecentActivity)
+func (q *Queries) GetRecentActivity(ctx context.Context, arg GetRecentActivityParams) ([]GetRecentActivityRow, error) {
+	rows, err := q.query(ctx, q.getRecentActivityStmt, getRecentActivity, arg.StartTime, arg.EndTime, arg.Model)
 	if err != nil {
 		return nil, err
 	}
@@ -114,6 +151,79 @@ func (q *Queries) GetRecentActivity(ctx context.Context) ([]GetRecentActivityRow
 	return items, nil
 }
 
+const getSessionCosts = `-- name: GetSessionCosts :many
+SELECT
+    id,
+    title,
+    message_count,
+    prompt_tokens,
+    completion_tokens,
+    cost,
+    created_at
+FROM sessions
+WHERE parent_session_id IS NULL
+  AND (?1 IS NULL OR created_at >= ?1)
+  AND (?2 IS NULL OR created_at < ?2)
+  AND (?3 IS NULL OR id IN (
+    SELECT session_id FROM messages WHERE role = 'assistant' AND model = ?3
+  ))
+ORDER BY cost DESC, created_at DESC
+LIMIT ?4
+`
+
+type GetSessionCostsParams struct {
+	StartTime sql.NullInt64  `json:"start_time"`
+	EndTime   sql.NullInt64  `json:"end_time"`
+	Model     sql.NullString `json:"model"`
+	Limit     int64          `json:"limit"`
+}
+
+type GetSessionCostsRow struct {
+	ID               string  `json:"id"`
+	Title            string  `json:"title"`
+	MessageCount     int64   `json:"message_count"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	Cost             float64 `json:"cost"`
+	CreatedAt        int64   `json:"created_at"`
+}
+
+func (q *Queries) GetSessionCosts(ctx context.Context, arg GetSessionCostsParams) ([]GetSessionCostsRow, error) {
+	rows, err := q.query(ctx, q.getSessionCostsStmt, getSessionCosts,
+		arg.StartTime,
+		arg.EndTime,
+		arg.Model,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetSessionCostsRow{}
+	for rows.Next() {
+		var i GetSessionCostsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.MessageCount,
+			&i.PromptTokens,
+			&i.CompletionTokens,
+			&i.Cost,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getToolUsage = `-- name: GetToolUsage :many
 SELECT
     json_extract(value, '$.data.name') as tool_name,
@@ -121,17 +231,26 @@ SELECT
 FROM messages, json_each(parts)
 WHERE json_extract(value, '$.type') = 'tool_call'
   AND json_extract(value, '$.data.name') IS NOT NULL
+  AND (?1 IS NULL OR messages.created_at >= ?1)
+  AND (?2 IS NULL OR messages.created_at < ?2)
+  AND (?3 IS NULL OR messages.model = ?3)
 GROUP BY tool_name
 ORDER BY call_count DESC
 `
 
+type GetToolUsageParams struct {
+	StartTime sql.NullInt64  `json:"start_time"`
+	EndTime   sql.NullInt64  `json:"end_time"`
+	Model     sql.NullString `json:"model"`
+}
+
 type GetToolUsageRow struct {
 	ToolName  interface{} `json:"tool_name"`
 	CallCount int64       `json:"call_count"`
 }
 
-func (q *Queries) GetToolUsage(ctx context.Context) ([]GetToolUsageRow, error) {
-	rows, err := q.query(ctx, q.getToolUsageStmt, getToolUsage)
+func (q *Queries) GetToolUsage(ctx context.Context, arg GetToolUsageParams) ([]GetToolUsageRow, error) {
+	rows, err := q.query(ctx, q.getToolUsageStmt, getToolUsage, arg.StartTime, arg.EndTime, arg.Model)
 	if err != nil {
 		return nil, err
 	}
@@ -164,8 +283,19 @@ SELECT
     COALESCE(AVG(message_count), 0) as avg_messages_per_session
 FROM sessions
 WHERE parent_session_id IS NULL
+  AND (?1 IS NULL OR created_at >= ?1)
+  AND (?2 IS NULL OR created_at < ?2)
+  AND (?3 IS NULL OR id IN (
+    SELECT session_id FROM messages WHERE role = 'assistant' AND model = ?3
+  ))
 `
 
+type GetTotalStatsParams struct {
+	StartTime sql.NullInt64  `json:"start_time"`
+	EndTime   sql.NullInt64  `json:"end_time"`
+	Model     sql.NullString `json:"model"`
+}
+
 type GetTotalStatsRow struct {
 	TotalSessions         int64       `json:"total_sessions"`
 	TotalPromptTokens     interface{} `json:"total_prompt_tokens"`
@@ -176,8 +306,8 @@ type GetTotalStatsRow struct {
 	AvgMessagesPerSession interface{} `json:"avg_messages_per_session"`
 }
 
-func (q *Queries) GetTotalStats(ctx context.Context) (GetTotalStatsRow, error) {
-	row := q.queryRow(ctx, q.getTotalStatsStmt, getTotalStats)
+func (q *Queries) GetTotalStats(ctx context.Context, arg GetTotalStatsParams) (GetTotalStatsRow, error) {
+	row := q.queryRow(ctx, q.getTotalStatsStmt, getTotalStats, arg.StartTime, arg.EndTime, arg.Model)
 	var i GetTotalStatsRow
 	err := row.Scan(
 		&i.TotalSessions,
@@ -200,10 +330,21 @@ SELECT
     COUNT(*) as session_count
 FROM sessions
 WHERE parent_session_id IS NULL
+  AND (?1 IS NULL OR created_at >= ?1)
+  AND (?2 IS NULL OR created_at < ?2)
+  AND (?3 IS NULL OR id IN (
+    SELECT session_id FROM messages WHERE role = 'assistant' AND model = ?3
+  ))
 GROUP BY date(created_at, 'unixepoch')
 ORDER BY day DESC
 `
 
+type GetUsageByDayParams struct {
+	StartTime sql.NullInt64  `json:"start_time"`
+	EndTime   sql.NullInt64  `json:"end_time"`
+	Model     sql.NullString `json:"model"`
+}
+
 type GetUsageByDayRow struct {
 	Day              interface{}     `json:"day"`
 	PromptTokens     sql.NullFloat64 `json:"prompt_tokens"`
@@ -212,8 +353,8 @@ type GetUsageByDayRow struct {
 	SessionCount     int64           `json:"session_count"`
 }
 
-func (q *Queries) GetUsageByDay(ctx context.Context) ([]GetUsageByDayRow, error) {
-	rows, err := q.query(ctx, q.getUsageByDayStmt, getUsageByDay)
+func (q *Queries) GetUsageByDay(ctx context.Context, arg GetUsageByDayParams) ([]GetUsageByDayRow, error) {
+	rows, err := q.query(ctx, q.getUsageByDayStmt, getUsageByDay, arg.StartTime, arg.EndTime, arg.Model)
 	if err != nil {
 		return nil, err
 	}
@@ -249,10 +390,21 @@ SELECT
     SUM(completion_tokens) as completion_tokens
 FROM sessions
 WHERE parent_session_id IS NULL
+  AND (?1 IS NULL OR created_at >= ?1)
+  AND (?2 IS NULL OR created_at < ?2)
+  AND (?3 IS NULL OR id IN (
+    SELECT session_id FROM messages WHERE role = 'assistant' AND model = ?3
+  ))
 GROUP BY day_of_week
 ORDER BY day_of_week
 `
 
+type GetUsageByDayOfWeekParams struct {
+	StartTime sql.NullInt64  `json:"start_time"`
+	EndTime   sql.NullInt64  `json:"end_time"`
+	Model     sql.NullString `json:"model"`
+}
+
 type GetUsageByDayOfWeekRow struct {
 	DayOfWeek        int64           `json:"day_of_week"`
 	SessionCount     int64           `json:"session_count"`
@@ -260,8 +412,8 @@ type GetUsageByDayOfWeekRow struct {
 	CompletionTokens sql.NullFloat64 `json:"completion_tokens"`
 }
 
-func (q *Queries) GetUsageByDayOfWeek(ctx context.Context) ([]GetUsageByDayOfWeekRow, error) {
-	rows, err := q.query(ctx, q.getUsageByDayOfWeekStmt, getUsageByDayOfWeek)
+func (q *Queries) GetUsageByDayOfWeek(ctx context.Context, arg GetUsageByDayOfWeekParams) ([]GetUsageByDayOfWeekRow, error) {
+	rows, err := q.query(ctx, q.getUsageByDayOfWeekStmt, getUsageByDayOfWeek, arg.StartTime, arg.EndTime, arg.Model)
 	if err != nil {
 		return nil, err
 	}
@@ -294,17 +446,28 @@ SELECT
     COUNT(*) as session_count
 FROM sessions
 WHERE parent_session_id IS NULL
+  AND (?1 IS NULL OR created_at >= ?1)
+  AND (?2 IS NULL OR created_at < ?2)
+  AND (?3 IS NULL OR id IN (
+    SELECT session_id FROM messages WHERE role = 'assistant' AND model = ?3
+  ))
 GROUP BY hour
 ORDER BY hour
 `
 
+type GetUsageByHourParams struct {
+	StartTime sql.NullInt64  `json:"start_time"`
+	EndTime   sql.NullInt64  `json:"end_time"`
+	Model     sql.NullString `json:"model"`
+}
+
 type GetUsageByHourRow struct {
 	Hour         int64 `json:"hour"`
 	SessionCount int64 `json:"session_count"`
 }
 
-func (q *Queries) GetUsageByHour(ctx context.Context) ([]GetUsageByHourRow, error) {
-	rows, err := q.query(ctx, q.getUsageByHourStmt, getUsageByHour)
+func (q *Queries) GetUsageByHour(ctx context.Context, arg GetUsageByHourParams) ([]GetUsageByHourRow, error) {
+	rows, err := q.query(ctx, q.getUsageByHourStmt, getUsageByHour, arg.StartTime, arg.EndTime, arg.Model)
 	if err != nil {
 		return nil, err
 	}
@@ -333,18 +496,27 @@ SELECT
     COUNT(*) as message_count
 FROM messages
 WHERE role = 'assistant'
+  AND (?1 IS NULL OR created_at >= ?1)
+  AND (?2 IS NULL OR created_at < ?2)
+  AND (?3 IS NULL OR model = ?3)
 GROUP BY model, provider
 ORDER BY message_count DESC
 `
 
+type GetUsageByModelParams struct {
+	StartTime sql.NullInt64  `json:"start_time"`
+	EndTime   sql.NullInt64  `json:"end_time"`
+	Model     sql.NullString `json:"model"`
+}
+
 type GetUsageByModelRow struct {
 	Model        string `json:"model"`
 	Provider     string `json:"provider"`
 	MessageCount int64  `json:"message_count"`
 }
 
-func (q *Queries) GetUsageByModel(ctx context.Context) ([]GetUsageByModelRow, error) {
-	rows, err := q.query(ctx, q.getUsageByModelStmt, getUsageByModel)
+func (q *Queries) GetUsageByModel(ctx context.Context, arg GetUsageByModelParams) ([]GetUsageByModelRow, error) {
+	rows, err := q.query(ctx, q.getUsageByModelStmt, getUsageByModel, arg.StartTime, arg.EndTime, arg.Model)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,220 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const noNewlineMarker = `\ No newline at end of file`
+
+type hunk struct {
+	oldStart, oldCount int
+	newCount           int
+	// old and new hold the lines the hunk expects and produces.
+	old, new []string
+	// oldNoEOL and newNoEOL are set by "\ No newline at end of file" markers.
+	oldNoEOL, newNoEOL bool
+}
+
+// Apply applies a unified diff for a single file to content. Hunks whose
+// context has moved are searched for in the rest of the file; a hunk that
+// cannot be found fails the whole patch.
+func Apply(content, patch string) (string, error) {
+	hunks, err := parseHunks(patch)
+	if err != nil {
+		return "", err
+	}
+	if len(hunks) == 0 {
+		return "", errors.New("patch contains no hunks")
+	}
+
+	finalNewline := content == "" || strings.HasSuffix(content, "\n")
+	var lines []string
+	if content != "" {
+		lines = strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	}
+
+	var out []string
+	pos := 0
+	for i, h := range hunks {
+		expected := h.oldStart - 1
+		if h.oldCount == 0 {
+			// Pure insertions are placed after line oldStart.
+			expected = h.oldStart
+		}
+		at := findHunk(lines, h.old, pos, expected)
+		if at < 0 {
+			return "", fmt.Errorf("hunk %d (@@ -%d,%d @@) does not apply", i+1, h.oldStart, h.oldCount)
+		}
+		out = append(out, lines[pos:at]...)
+		out = append(out, h.new...)
+		pos = at + len(h.old)
+
+		if pos == len(lines) {
+			switch {
+			case h.newNoEOL:
+				finalNewline = false
+			case h.oldNoEOL:
+				finalNewline = true
+			}
+		}
+	}
+	out = append(out, lines[pos:]...)
+
+	if len(out) == 0 {
+		return "", nil
+	}
+	result := strings.Join(out, "\n")
+	if finalNewline {
+		result += "\n"
+	}
+	return result, nil
+}
+
+// findHunk returns where old occurs in lines at or after start, preferring
+// the position closest to expected, or -1.
+func findHunk(lines, old []string, start, expected int) int {
+	matches := func(at int) bool {
+		if at < start || at+len(old) > len(lines) {
+			return false
+		}
+		for i, l := range old {
+			if lines[at+i] != l {
+				return false
+			}
+		}
+		return true
+	}
+	if matches(expected) {
+		return expected
+	}
+	for d := 1; expected-d >= start || expected+d <= len(lines); d++ {
+		if matches(expected - d) {
+			return expected - d
+		}
+		if matches(expected + d) {
+			return expected + d
+		}
+	}
+	return -1
+}
+
+func parseHunks(patch string) ([]hunk, error) {
+	var hunks []hunk
+	var cur *hunk
+	oldLeft, newLeft := 0, 0
+	// last is the kind of the previous hunk line; the no-newline marker
+	// applies to the old side, the new side or both depending on it.
+	var last byte
+	markNoEOL := func() {
+		if last != '+' {
+			cur.oldNoEOL = true
+		}
+		if last != '-' {
+			cur.newNoEOL = true
+		}
+	}
+
+	lines := strings.Split(strings.ReplaceAll(patch, "\r\n", "\n"), "\n")
+	for n, line := range lines {
+		if cur != nil && (oldLeft > 0 || newLeft > 0) {
+			kind := byte(' ')
+			text := ""
+			if line != "" {
+				kind, text = line[0], line[1:]
+			} else if n == len(lines)-1 {
+				break
+			}
+			switch kind {
+			case ' ':
+				cur.old = append(cur.old, text)
+				cur.new = append(cur.new, text)
+				oldLeft--
+				newLeft--
+			case '-':
+				cur.old = append(cur.old, text)
+				oldLeft--
+			case '+':
+				cur.new = append(cur.new, text)
+				newLeft--
+			case '\\':
+				markNoEOL()
+				continue
+			default:
+				return nil, fmt.Errorf("line %d: unexpected %q inside hunk", n+1, line)
+			}
+			if oldLeft < 0 || newLeft < 0 {
+				return nil, fmt.Errorf("line %d: hunk is longer than its header", n+1)
+			}
+			last = kind
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, noNewlineMarker[:2]):
+			if cur == nil {
+				return nil, fmt.Errorf("line %d: unexpected %q", n+1, line)
+			}
+			markNoEOL()
+		case strings.HasPrefix(line, "@@"):
+			h, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n+1, err)
+			}
+			hunks = append(hunks, h)
+			cur = &hunks[len(hunks)-1]
+			oldLeft, newLeft = h.oldCount, h.newCount
+		case strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "+++ "),
+			strings.HasPrefix(line, "diff "), strings.HasPrefix(line, "index "):
+			if len(hunks) > 0 {
+				return nil, fmt.Errorf("line %d: patch must change a single file", n+1)
+			}
+		case strings.TrimSpace(line) == "":
+		default:
+			if cur != nil {
+				return nil, fmt.Errorf("line %d: unexpected %q after hunk", n+1, line)
+			}
+		}
+	}
+	if cur != nil && (oldLeft > 0 || newLeft > 0) {
+		return nil, errors.New("patch ends in the middle of a hunk")
+	}
+	return hunks, nil
+}
+
+// parseHunkHeader parses "@@ -l,s +l,s @@ optional section".
+func parseHunkHeader(line string) (hunk, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 || fields[0] != "@@" || fields[3] != "@@" ||
+		!strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return hunk{}, fmt.Errorf("invalid hunk header %q", line)
+	}
+	oldStart, oldCount, err := parseRange(fields[1][1:])
+	if err != nil {
+		return hunk{}, fmt.Errorf("invalid hunk header %q: %w", line, err)
+	}
+	_, newCount, err := parseRange(fields[2][1:])
+	if err != nil {
+		return hunk{}, fmt.Errorf("invalid hunk header %q: %w", line, err)
+	}
+	return hunk{oldStart: oldStart, oldCount: oldCount, newCount: newCount}, nil
+}
+
+func parseRange(s string) (start, count int, err error) {
+	count = 1
+	startStr, countStr, hasCount := strings.Cut(s, ",")
+	if start, err = strconv.Atoi(startStr); err != nil {
+		return 0, 0, err
+	}
+	if hasCount {
+		if count, err = strconv.Atoi(countStr); err != nil {
+			return 0, 0, err
+		}
+	}
+	if start < 0 || count < 0 {
+		return 0, 0, errors.New("negative range")
+	}
+	return start, count, nil
+}
@@ -0,0 +1,62 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct{ before, after string }{
+		"modify":           {"a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n", "a\nb\nC\nd\ne\nf\ng\nh\nI\nj\n"},
+		"create":           {"", "hello\nworld\n"},
+		"delete all":       {"hello\nworld\n", ""},
+		"append":           {"a\n", "a\nb\nc\n"},
+		"add final eol":    {"a\nb", "a\nb\n"},
+		"remove final eol": {"a\nb\n", "a\nb"},
+		"prepend":          {"b\nc\n", "a\nb\nc\n"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			patch, _, _ := GenerateDiff(tc.before, tc.after, "file.txt")
+			got, err := Apply(tc.before, patch)
+			require.NoError(t, err, patch)
+			require.Equal(t, tc.after, got, patch)
+		})
+	}
+}
+
+func TestApplyShiftedHunk(t *testing.T) {
+	t.Parallel()
+
+	patch := `--- a/file.txt
++++ b/file.txt
+@@ -1,3 +1,3 @@
+ one
+-two
++TWO
+ three
+`
+	got, err := Apply("zero\none\ntwo\nthree\n", patch)
+	require.NoError(t, err)
+	require.Equal(t, "zero\none\nTWO\nthree\n", got)
+}
+
+func TestApplyErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := Apply("a\n", "no hunks here")
+	require.ErrorContains(t, err, "no hunks")
+
+	_, err = Apply("a\nb\n", "@@ -1,2 +1,2 @@\n a\n-x\n+y\n")
+	require.ErrorContains(t, err, "does not apply")
+
+	_, err = Apply("a\n", "@@ -1,2 +1,2 @@\n a\n")
+	require.ErrorContains(t, err, "middle of a hunk")
+
+	_, err = Apply("a\n", "@@ -1 +1 @@\n-a\n+b\n--- a/other\n+++ b/other\n@@ -1 +1 @@\n-c\n+d\n")
+	require.ErrorContains(t, err, "single file")
+}
@@ -21,10 +21,27 @@ type File struct {
 	Path      string
 	Content   string
 	Version   int64
+	// MessageID is the message whose tool call created the version, empty
+	// when the version was not created by the agent.
+	MessageID string
 	CreatedAt int64
 	UpdatedAt int64
 }
 
+type messageIDContextKey struct{}
+
+// WithMessageID returns a context whose new file versions are attributed to
+// messageID.
+func WithMessageID(ctx context.Context, messageID string) context.Context {
+	return context.WithValue(ctx, messageIDContextKey{}, messageID)
+}
+
+// MessageIDFromContext returns the message ID set with WithMessageID.
+func MessageIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(messageIDContextKey{}).(string)
+	return id
+}
+
 // Service manages file versions and history for sessions.
 type Service interface {
 	pubsub.Subscriber[File]
@@ -105,6 +122,7 @@ func (s *service) createWithVersion(ctx context.Context, sessionID, path, conten
 			Path:      path,
 			Content:   content,
 			Version:   version,
+			MessageID: MessageIDFromContext(ctx),
 		})
 		if txErr != nil {
 			// Rollback the transaction
@@ -211,6 +229,7 @@ func (s *service) fromDBItem(item db.File) File {
 		Path:      item.Path,
 		Content:   item.Content,
 		Version:   item.Version,
+		MessageID: item.MessageID,
 		CreatedAt: item.CreatedAt,
 		UpdatedAt: item.UpdatedAt,
 	}
@@ -32,6 +32,11 @@ type DiagnosticCounts struct {
 	Hint        int
 }
 
+// Total returns the number of diagnostics of all severities.
+func (d DiagnosticCounts) Total() int {
+	return d.Error + d.Warning + d.Information + d.Hint
+}
+
 type Client struct {
 	client *powernap.Client
 	name   string
@@ -187,7 +192,11 @@ func (c *Client) registerHandlers() {
 func (c *Client) Restart() error {
 	var openFiles []string
 	for uri := range c.openFiles.Seq2() {
-		openFiles = append(openFiles, string(uri))
+		path, err := protocol.DocumentURI(uri).Path()
+		if err != nil {
+			continue
+		}
+		openFiles = append(openFiles, path)
 	}
 
 	closeCtx, cancel := context.WithTimeout(c.ctx, 10*time.Second)
@@ -197,6 +206,13 @@ func (c *Client) Restart() error {
 		slog.Warn("Error closing client during restart", "name", c.name, "error", err)
 	}
 
+	// Files that failed to close, e.g. because the server crashed, would
+	// otherwise be skipped when reopening them, and the new server publishes
+	// its own diagnostics.
+	c.openFiles.Reset(map[string]*OpenFileInfo{})
+	for uri := range c.diagnostics.Copy() {
+		c.diagnostics.Del(uri)
+	}
 	c.diagCountsCache = DiagnosticCounts{}
 	c.diagCountsVersion = 0
 
@@ -222,9 +238,9 @@ func (c *Client) Restart() error {
 		return err
 	}
 
-	for _, uri := range openFiles {
-		if err := c.OpenFile(initCtx, uri); err != nil {
-			slog.Warn("Failed to reopen file after restart", "file", uri, "error", err)
+	for _, path := range openFiles {
+		if err := c.OpenFile(initCtx, path); err != nil {
+			slog.Warn("Failed to reopen file after restart", "file", path, "error", err)
 		}
 	}
 	return nil
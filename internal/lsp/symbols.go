@@ -0,0 +1,338 @@
+package lsp
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/x/powernap/pkg/lsp/protocol"
+)
+
+const (
+	// maxSymbolFileSize is the largest file SearchSymbols scans for
+	// candidate identifiers.
+	maxSymbolFileSize = 1 << 20
+	// symbolAttempts is how many occurrences of an identifier SearchSymbols
+	// tries before giving up on finding its declaration.
+	symbolAttempts = 2
+)
+
+var identifierRe = regexp.MustCompile(`[\p{L}_$][\p{L}\p{N}_$]*`)
+
+// Symbol is a declaration found through a language server.
+type Symbol struct {
+	Name     string
+	Kind     protocol.SymbolKind
+	Location protocol.Location
+}
+
+// FindDefinition returns the declaration of the symbol at the given 1-based
+// position. powernap only exposes textDocument/references, so the
+// declaration is taken to be whatever the server adds to the references when
+// includeDeclaration is set.
+func (c *Client) FindDefinition(ctx context.Context, filepath string, line, character int) ([]protocol.Location, error) {
+	all, err := c.FindReferences(ctx, filepath, line, character, true)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := c.FindReferences(ctx, filepath, line, character, false)
+	if err != nil {
+		return nil, err
+	}
+	return subtractLocations(all, refs), nil
+}
+
+// subtractLocations returns the locations in all that are not in remove.
+func subtractLocations(all, remove []protocol.Location) []protocol.Location {
+	var out []protocol.Location
+	for _, loc := range all {
+		if !slices.ContainsFunc(remove, func(r protocol.Location) bool { return sameLocation(loc, r) }) &&
+			!slices.ContainsFunc(out, func(r protocol.Location) bool { return sameLocation(loc, r) }) {
+			out = append(out, loc)
+		}
+	}
+	return out
+}
+
+func sameLocation(a, b protocol.Location) bool {
+	return a.URI == b.URI && a.Range.Start == b.Range.Start
+}
+
+// SortLocations sorts locations by file and position and drops duplicates.
+func SortLocations(locations []protocol.Location) []protocol.Location {
+	slices.SortFunc(locations, compareLocations)
+	return slices.CompactFunc(locations, sameLocation)
+}
+
+func compareLocations(a, b protocol.Location) int {
+	return cmp.Or(
+		strings.Compare(string(a.URI), string(b.URI)),
+		cmp.Compare(a.Range.Start.Line, b.Range.Start.Line),
+		cmp.Compare(a.Range.Start.Character, b.Range.Start.Character),
+	)
+}
+
+type symbolCandidate struct {
+	path      string
+	line, col int // 1-based, col counted in UTF-16 units
+}
+
+// SearchSymbols finds declarations whose name contains query, ignoring case,
+// in the files under root handled by the given clients. Without
+// workspace/symbol in powernap, identifiers are found by scanning the files
+// and each distinct name is resolved with FindDefinition. At most limit
+// symbols are returned, exact matches first.
+func SearchSymbols(ctx context.Context, clients []*Client, root, query string, limit int) ([]Symbol, error) {
+	query = strings.ToLower(query)
+	if query == "" || len(clients) == 0 || limit <= 0 {
+		return nil, nil
+	}
+
+	clientFor := func(path string) *Client {
+		for _, c := range clients {
+			if c.HandlesFile(path) {
+				return c
+			}
+		}
+		return nil
+	}
+
+	// Collect the first occurrences of each matching identifier.
+	var names []string
+	candidates := make(map[string][]symbolCandidate)
+	walker := fsext.NewFastGlobWalker(root)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if walker.ShouldSkip(path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || clientFor(path) == nil {
+			return nil
+		}
+		if info, err := d.Info(); err != nil || info.Size() > maxSymbolFileSize {
+			return nil
+		}
+		scanIdentifiers(path, func(name string, line, col int) bool {
+			if !strings.Contains(strings.ToLower(name), query) {
+				return true
+			}
+			if _, ok := candidates[name]; !ok {
+				if len(names) >= limit {
+					return true
+				}
+				names = append(names, name)
+			}
+			if len(candidates[name]) < symbolAttempts {
+				candidates[name] = append(candidates[name], symbolCandidate{path: path, line: line, col: col})
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var symbols []Symbol
+	reader := symbolReader{}
+	for _, name := range names {
+		found := false
+		for _, cand := range candidates[name] {
+			locs, err := clientFor(cand.path).FindDefinition(ctx, cand.path, cand.line, cand.col)
+			if err != nil {
+				if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+					return nil, err
+				}
+				slog.Debug("Failed to find definition", "symbol", name, "path", cand.path, "error", err)
+				continue
+			}
+			for _, loc := range locs {
+				if slices.ContainsFunc(symbols, func(s Symbol) bool { return sameLocation(s.Location, loc) }) {
+					continue
+				}
+				sym := reader.symbol(loc)
+				if sym.Name == "" {
+					sym.Name = name
+				}
+				symbols = append(symbols, sym)
+				found = true
+			}
+			if found {
+				break
+			}
+		}
+	}
+
+	slices.SortStableFunc(symbols, func(a, b Symbol) int {
+		aExact, bExact := strings.ToLower(a.Name) == query, strings.ToLower(b.Name) == query
+		if aExact != bExact {
+			if aExact {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Or(strings.Compare(a.Name, b.Name), compareLocations(a.Location, b.Location))
+	})
+	return symbols, nil
+}
+
+// scanIdentifiers calls fn with every identifier in the file and its 1-based
+// line and UTF-16 column, until fn returns false.
+func scanIdentifiers(path string, fn func(name string, line, col int) bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxSymbolFileSize)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		for _, m := range identifierRe.FindAllStringIndex(text, -1) {
+			if !fn(text[m[0]:m[1]], line, utf16Len(text[:m[0]])+1) {
+				return
+			}
+		}
+	}
+}
+
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+// symbolReader reads the names and guesses the kinds of declarations,
+// caching file contents.
+type symbolReader map[protocol.DocumentURI][]string
+
+// Symbols describes the declarations at locs.
+func Symbols(locs []protocol.Location) []Symbol {
+	r := symbolReader{}
+	symbols := make([]Symbol, 0, len(locs))
+	for _, loc := range locs {
+		symbols = append(symbols, r.symbol(loc))
+	}
+	return symbols
+}
+
+func (r symbolReader) symbol(loc protocol.Location) Symbol {
+	content, ok := r[loc.URI]
+	if !ok {
+		if path, err := loc.URI.Path(); err == nil {
+			if data, err := os.ReadFile(path); err == nil {
+				content = strings.Split(string(data), "\n")
+			}
+		}
+		r[loc.URI] = content
+	}
+
+	sym := Symbol{Kind: protocol.Variable, Location: loc}
+	if int(loc.Range.Start.Line) >= len(content) {
+		return sym
+	}
+	line := content[loc.Range.Start.Line]
+	before, after := splitUTF16(line, int(loc.Range.Start.Character))
+	sym.Name = identifierRe.FindString(after)
+	if !strings.HasPrefix(after, sym.Name) {
+		sym.Name = ""
+	}
+	sym.Kind = guessSymbolKind(before, strings.TrimSpace(after[len(sym.Name):]), sym.Name)
+	return sym
+}
+
+// splitUTF16 splits s at the given offset in UTF-16 code units.
+func splitUTF16(s string, offset int) (string, string) {
+	n := 0
+	for i, r := range s {
+		if n >= offset {
+			return s[:i], s[i:]
+		}
+		n++
+		if r >= 0x10000 {
+			n++
+		}
+	}
+	return s, ""
+}
+
+// guessSymbolKind guesses the kind of the symbol name declared between
+// before and after from the keywords that introduce it in common languages.
+// References do not carry the kind that workspace/symbol would report.
+func guessSymbolKind(before, after, name string) protocol.SymbolKind {
+	words := strings.FieldsFunc(before, func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '_'
+	})
+	for i := len(words) - 1; i >= 0; i-- {
+		switch words[i] {
+		case "func":
+			if strings.HasPrefix(strings.TrimSpace(before), "func (") {
+				return protocol.Method
+			}
+			return protocol.Function
+		case "def", "fn", "function", "fun", "sub":
+			if strings.HasPrefix(before, " ") || strings.HasPrefix(before, "\t") {
+				return protocol.Method
+			}
+			return protocol.Function
+		case "class", "record":
+			return protocol.Class
+		case "interface", "trait", "protocol":
+			return protocol.Interface
+		case "struct":
+			return protocol.Struct
+		case "enum":
+			return protocol.Enum
+		case "type", "typedef":
+			switch {
+			case strings.HasPrefix(after, "struct"):
+				return protocol.Struct
+			case strings.HasPrefix(after, "interface"):
+				return protocol.Interface
+			}
+			return protocol.Class
+		case "const":
+			return protocol.Constant
+		case "var", "let", "val":
+			return protocol.Variable
+		case "module", "mod":
+			return protocol.Module
+		case "namespace":
+			return protocol.Namespace
+		case "package":
+			return protocol.Package
+		}
+	}
+	if strings.HasPrefix(after, "(") {
+		return protocol.Function
+	}
+	if name == strings.ToUpper(name) && strings.ContainsFunc(name, unicode.IsLetter) {
+		return protocol.Constant
+	}
+	return protocol.Variable
+}
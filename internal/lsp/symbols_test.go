@@ -0,0 +1,102 @@
+package lsp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/x/powernap/pkg/lsp/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func location(uri string, line, char uint32) protocol.Location {
+	pos := protocol.Position{Line: line, Character: char}
+	return protocol.Location{URI: protocol.DocumentURI(uri), Range: protocol.Range{Start: pos, End: pos}}
+}
+
+func TestSubtractLocations(t *testing.T) {
+	t.Parallel()
+
+	decl := location("file:///a.go", 2, 5)
+	ref := location("file:///b.go", 10, 1)
+	got := subtractLocations([]protocol.Location{ref, decl, decl}, []protocol.Location{ref})
+	require.Equal(t, []protocol.Location{decl}, got)
+}
+
+func TestSymbols(t *testing.T) {
+	t.Parallel()
+
+	src := `package main
+
+const MaxSize = 10
+
+type Server struct{}
+
+type Handler interface{}
+
+func (s *Server) Serve() {}
+
+func main() {
+	var count int
+	label := "héllo"; 𝔸name := 1
+}
+`
+	path := filepath.Join(t.TempDir(), "main.go")
+	require.NoError(t, os.WriteFile(path, []byte(src), 0o644))
+	uri := string(protocol.URIFromPath(path))
+
+	symbols := Symbols([]protocol.Location{
+		location(uri, 2, 6),
+		location(uri, 4, 5),
+		location(uri, 6, 5),
+		location(uri, 8, 17),
+		location(uri, 10, 5),
+		location(uri, 11, 5),
+		location(uri, 12, 1),
+		// 𝔸 is two UTF-16 code units.
+		location(uri, 12, 19),
+		location(uri, 99, 0),
+	})
+
+	type nameKind struct {
+		Name string
+		Kind protocol.SymbolKind
+	}
+	var got []nameKind
+	for _, s := range symbols {
+		got = append(got, nameKind{s.Name, s.Kind})
+	}
+	require.Equal(t, []nameKind{
+		{"MaxSize", protocol.Constant},
+		{"Server", protocol.Struct},
+		{"Handler", protocol.Interface},
+		{"Serve", protocol.Method},
+		{"main", protocol.Function},
+		{"count", protocol.Variable},
+		{"label", protocol.Variable},
+		{"𝔸name", protocol.Variable},
+		{"", protocol.Variable},
+	}, got)
+}
+
+func TestGuessSymbolKind(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		before, after, name string
+		want                protocol.SymbolKind
+	}{
+		{"def ", "(x):", "run", protocol.Function},
+		{"    def ", "(self):", "run", protocol.Method},
+		{"pub fn ", "() {", "run", protocol.Function},
+		{"export class ", " {", "App", protocol.Class},
+		{"pub trait ", " {", "Draw", protocol.Interface},
+		{"enum ", " {", "Color", protocol.Enum},
+		{"let ", " = 1", "x", protocol.Variable},
+		{"\t", " = iota", "ModeFast", protocol.Variable},
+		{"\t", " = 3", "LIMIT", protocol.Constant},
+	}
+	for _, tc := range cases {
+		require.Equal(t, tc.want, guessSymbolKind(tc.before, tc.after, tc.name), "%q %q", tc.before, tc.name)
+	}
+}
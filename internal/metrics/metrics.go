@@ -0,0 +1,139 @@
+// Package metrics collects Prometheus metrics about HTTP requests, agent
+// runs, token usage and tool calls, and renders them in the Prometheus text
+// exposition format.
+package metrics
+
+import (
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/common/expfmt"
+)
+
+const namespace = "zorkagent"
+
+var registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Total number of HTTP requests handled by the API server.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of HTTP requests handled by the API server.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	tokens = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "agent",
+		Name:      "tokens_total",
+		Help:      "Total number of tokens used, by provider, model and token type.",
+	}, []string{"provider", "model", "type"})
+
+	cost = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "agent",
+		Name:      "cost_dollars_total",
+		Help:      "Estimated cost of model usage in US dollars, by provider and model.",
+	}, []string{"provider", "model"})
+
+	toolCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "agent",
+		Name:      "tool_calls_total",
+		Help:      "Total number of tool calls, by tool name and result.",
+	}, []string{"tool", "result"})
+
+	toolDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "agent",
+		Name:      "tool_call_duration_seconds",
+		Help:      "Duration of tool calls, by tool name.",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+	}, []string{"tool"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		tokens,
+		cost,
+		toolCalls,
+		toolDuration,
+	)
+}
+
+// Usage is the token usage of a single model step.
+type Usage struct {
+	InputTokens         int64
+	OutputTokens        int64
+	CacheReadTokens     int64
+	CacheCreationTokens int64
+	Cost                float64
+}
+
+// ObserveHTTPRequest records a handled HTTP request. route should be the
+// route pattern rather than the raw path to keep label cardinality bounded.
+func ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// AddUsage records the tokens and cost used by a model step.
+func AddUsage(provider, model string, usage Usage) {
+	tokens.WithLabelValues(provider, model, "input").Add(float64(usage.InputTokens))
+	tokens.WithLabelValues(provider, model, "output").Add(float64(usage.OutputTokens))
+	tokens.WithLabelValues(provider, model, "cache_read").Add(float64(usage.CacheReadTokens))
+	tokens.WithLabelValues(provider, model, "cache_creation").Add(float64(usage.CacheCreationTokens))
+	if usage.Cost > 0 {
+		cost.WithLabelValues(provider, model).Add(usage.Cost)
+	}
+}
+
+// ObserveToolCall records a finished tool call.
+func ObserveToolCall(tool string, isError bool, duration time.Duration) {
+	result := "success"
+	if isError {
+		result = "error"
+	}
+	toolCalls.WithLabelValues(tool, result).Inc()
+	toolDuration.WithLabelValues(tool).Observe(duration.Seconds())
+}
+
+// Register adds a collector whose values are computed at scrape time, such
+// as gauges derived from live application state.
+func Register(c prometheus.Collector) error {
+	return registry.Register(c)
+}
+
+// ContentType is the content type of the output of WriteText.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// WriteText gathers all metrics and writes them in the Prometheus text
+// exposition format.
+func WriteText(w io.Writer) error {
+	families, err := registry.Gather()
+	if err != nil {
+		return err
+	}
+	enc := expfmt.NewEncoder(w, expfmt.NewFormat(expfmt.TypeTextPlain))
+	for _, family := range families {
+		if err := enc.Encode(family); err != nil {
+			return err
+		}
+	}
+	return nil
+}
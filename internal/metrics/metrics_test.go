@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteText(t *testing.T) {
+	ObserveHTTPRequest("GET", "/session/:id", 200, 50*time.Millisecond)
+	AddUsage("test-provider", "test-model", Usage{InputTokens: 10, OutputTokens: 5, Cost: 0.25})
+	ObserveToolCall("test_tool", true, time.Second)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteText(&buf))
+	out := buf.String()
+
+	require.Contains(t, out, `zorkagent_http_requests_total{method="GET",route="/session/:id",status="200"} 1`)
+	require.Contains(t, out, `zorkagent_http_request_duration_seconds_count{method="GET",route="/session/:id"} 1`)
+	require.Contains(t, out, `zorkagent_agent_tokens_total{model="test-model",provider="test-provider",type="input"} 10`)
+	require.Contains(t, out, `zorkagent_agent_tokens_total{model="test-model",provider="test-provider",type="output"} 5`)
+	require.Contains(t, out, `zorkagent_agent_cost_dollars_total{model="test-model",provider="test-provider"} 0.25`)
+	require.Contains(t, out, `zorkagent_agent_tool_calls_total{result="error",tool="test_tool"} 1`)
+	require.Contains(t, out, `zorkagent_agent_tool_call_duration_seconds_count{tool="test_tool"} 1`)
+}
+
+func TestRegister(t *testing.T) {
+	gauge := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "test_gauge",
+		Help:      "Test gauge.",
+	}, func() float64 { return 3 })
+	require.NoError(t, Register(gauge))
+	t.Cleanup(func() { registry.Unregister(gauge) })
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteText(&buf))
+	require.Contains(t, buf.String(), "zorkagent_test_gauge 3")
+}
@@ -6,7 +6,9 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/charmbracelet/crush/internal/csync"
 	"github.com/charmbracelet/crush/internal/pubsub"
@@ -15,6 +17,50 @@ import (
 
 var ErrorPermissionDenied = errors.New("user denied permission")
 
+// SessionMode controls how the permission requests of a session are answered.
+type SessionMode string
+
+const (
+	// SessionModeAsk publishes requests and waits for a reply.
+	SessionModeAsk SessionMode = "ask"
+	// SessionModeAuto grants every request.
+	SessionModeAuto SessionMode = "auto"
+	// SessionModeDenyDangerous grants requests unless they are dangerous,
+	// see [IsDangerous], and denies dangerous ones without asking.
+	SessionModeDenyDangerous SessionMode = "deny-dangerous"
+)
+
+// Valid reports whether m is a known session mode.
+func (m SessionMode) Valid() bool {
+	switch m {
+	case SessionModeAsk, SessionModeAuto, SessionModeDenyDangerous:
+		return true
+	}
+	return false
+}
+
+// Deciders of permission requests recorded in a [Decision]. Requests answered
+// through the API are decided by the caller, see [PermissionRequest.Decider].
+const (
+	// DeciderTUI is the user answering in the TUI.
+	DeciderTUI = "tui"
+	// DeciderAuto is the service answering without asking, the reason is
+	// recorded in [Decision.Reason].
+	DeciderAuto = "auto"
+)
+
+// Reasons for requests answered by [DeciderAuto].
+const (
+	ReasonSkipRequests      = "skip_requests"
+	ReasonAllowedTools      = "allowed_tools"
+	ReasonSessionPermission = "session_permission"
+	ReasonTimeout           = "timeout"
+)
+
+// dangerousTools are tools that can run arbitrary commands or fetch
+// arbitrary content onto the machine.
+var dangerousTools = []string{"bash", "download"}
+
 type CreatePermissionRequest struct {
 	SessionID   string `json:"session_id"`
 	ToolCallID  string `json:"tool_call_id"`
@@ -40,6 +86,29 @@ type PermissionRequest struct {
 	Action      string `json:"action"`
 	Params      any    `json:"params"`
 	Path        string `json:"path"`
+
+	// Decider identifies who answers the request. It is set by callers of
+	// Grant, GrantPersistent and Deny other than the TUI, which is assumed
+	// when it is empty.
+	Decider string `json:"-"`
+}
+
+// Decision is the answer to a permission request.
+type Decision struct {
+	Request    PermissionRequest
+	Granted    bool
+	Persistent bool
+	Decider    string
+	// Reason explains why the request was answered without asking, for
+	// example [ReasonAllowedTools] or the session mode.
+	Reason string
+	Time   time.Time
+}
+
+// Recorder records the answers to permission requests, for example in an
+// audit log.
+type Recorder interface {
+	RecordDecision(decision Decision)
 }
 
 type Service interface {
@@ -49,23 +118,35 @@ type Service interface {
 	Deny(permission PermissionRequest)
 	Request(ctx context.Context, opts CreatePermissionRequest) (bool, error)
 	AutoApproveSession(sessionID string)
+	SetSessionMode(sessionID string, mode SessionMode)
+	SessionMode(sessionID string) SessionMode
+	SetRequestTimeout(timeout time.Duration)
+	PendingRequests() []PermissionRequest
 	SetSkipRequests(skip bool)
 	SkipRequests() bool
+	SetAllowedTools(tools []string)
+	SetRecorder(recorder Recorder)
 	SubscribeNotifications(ctx context.Context) <-chan pubsub.Event[PermissionNotification]
 }
 
 type permissionService struct {
 	*pubsub.Broker[PermissionRequest]
 
-	notificationBroker    *pubsub.Broker[PermissionNotification]
-	workingDir            string
-	sessionPermissions    []PermissionRequest
-	sessionPermissionsMu  sync.RWMutex
-	pendingRequests       *csync.Map[string, chan bool]
-	autoApproveSessions   map[string]bool
-	autoApproveSessionsMu sync.RWMutex
-	skip                  bool
-	allowedTools          []string
+	notificationBroker   *pubsub.Broker[PermissionNotification]
+	workingDir           string
+	sessionPermissions   []PermissionRequest
+	sessionPermissionsMu sync.RWMutex
+	pendingRequests      *csync.Map[string, chan bool]
+	pendingPermissions   *csync.Map[string, PermissionRequest]
+	sessionModes         map[string]SessionMode
+	sessionModesMu       sync.RWMutex
+
+	// settings below can be changed while requests are being answered
+	settingsMu     sync.RWMutex
+	requestTimeout time.Duration
+	skip           bool
+	allowedTools   []string
+	recorder       Recorder
 
 	// used to make sure we only process one request at a time
 	requestMu       sync.Mutex
@@ -74,6 +155,7 @@ type permissionService struct {
 }
 
 func (s *permissionService) GrantPersistent(permission PermissionRequest) {
+	s.record(permission, true, true, "")
 	s.notificationBroker.Publish(pubsub.CreatedEvent, PermissionNotification{
 		ToolCallID: permission.ToolCallID,
 		Granted:    true,
@@ -95,6 +177,7 @@ func (s *permissionService) GrantPersistent(permission PermissionRequest) {
 }
 
 func (s *permissionService) Grant(permission PermissionRequest) {
+	s.record(permission, true, false, "")
 	s.notificationBroker.Publish(pubsub.CreatedEvent, PermissionNotification{
 		ToolCallID: permission.ToolCallID,
 		Granted:    true,
@@ -112,6 +195,11 @@ func (s *permissionService) Grant(permission PermissionRequest) {
 }
 
 func (s *permissionService) Deny(permission PermissionRequest) {
+	s.deny(permission, "")
+}
+
+func (s *permissionService) deny(permission PermissionRequest, reason string) {
+	s.record(permission, false, false, reason)
 	s.notificationBroker.Publish(pubsub.CreatedEvent, PermissionNotification{
 		ToolCallID: permission.ToolCallID,
 		Granted:    false,
@@ -130,7 +218,12 @@ func (s *permissionService) Deny(permission PermissionRequest) {
 }
 
 func (s *permissionService) Request(ctx context.Context, opts CreatePermissionRequest) (bool, error) {
-	if s.skip {
+	s.settingsMu.RLock()
+	skip, allowedTools, requestTimeout := s.skip, s.allowedTools, s.requestTimeout
+	s.settingsMu.RUnlock()
+
+	if skip {
+		s.recordAuto(opts, true, ReasonSkipRequests)
 		return true, nil
 	}
 
@@ -138,27 +231,38 @@ func (s *permissionService) Request(ctx context.Context, opts CreatePermissionRe
 	s.notificationBroker.Publish(pubsub.CreatedEvent, PermissionNotification{
 		ToolCallID: opts.ToolCallID,
 	})
-	s.requestMu.Lock()
-	defer s.requestMu.Unlock()
 
 	// Check if the tool/action combination is in the allowlist
 	commandKey := opts.ToolName + ":" + opts.Action
-	if slices.Contains(s.allowedTools, commandKey) || slices.Contains(s.allowedTools, opts.ToolName) {
+	if slices.Contains(allowedTools, commandKey) || slices.Contains(allowedTools, opts.ToolName) {
+		s.recordAuto(opts, true, ReasonAllowedTools)
 		return true, nil
 	}
 
-	s.autoApproveSessionsMu.RLock()
-	autoApprove := s.autoApproveSessions[opts.SessionID]
-	s.autoApproveSessionsMu.RUnlock()
-
-	if autoApprove {
+	// Sessions that don't ask are answered right away, without waiting for
+	// the requests of other sessions.
+	switch mode := s.SessionMode(opts.SessionID); mode {
+	case SessionModeAuto:
+		s.recordAuto(opts, true, string(mode))
 		s.notificationBroker.Publish(pubsub.CreatedEvent, PermissionNotification{
 			ToolCallID: opts.ToolCallID,
 			Granted:    true,
 		})
 		return true, nil
+	case SessionModeDenyDangerous:
+		granted := !IsDangerous(s.workingDir, opts)
+		s.recordAuto(opts, granted, string(mode))
+		s.notificationBroker.Publish(pubsub.CreatedEvent, PermissionNotification{
+			ToolCallID: opts.ToolCallID,
+			Granted:    granted,
+			Denied:     !granted,
+		})
+		return granted, nil
 	}
 
+	s.requestMu.Lock()
+	defer s.requestMu.Unlock()
+
 	fileInfo, err := os.Stat(opts.Path)
 	dir := opts.Path
 	if err == nil {
@@ -187,6 +291,8 @@ func (s *permissionService) Request(ctx context.Context, opts CreatePermissionRe
 	for _, p := range s.sessionPermissions {
 		if p.ToolName == permission.ToolName && p.Action == permission.Action && p.SessionID == permission.SessionID && p.Path == permission.Path {
 			s.sessionPermissionsMu.RUnlock()
+			permission.Decider = DeciderAuto
+			s.record(permission, true, false, ReasonSessionPermission)
 			s.notificationBroker.Publish(pubsub.CreatedEvent, PermissionNotification{
 				ToolCallID: opts.ToolCallID,
 				Granted:    true,
@@ -202,23 +308,78 @@ func (s *permissionService) Request(ctx context.Context, opts CreatePermissionRe
 
 	respCh := make(chan bool, 1)
 	s.pendingRequests.Set(permission.ID, respCh)
+	s.pendingPermissions.Set(permission.ID, permission)
 	defer s.pendingRequests.Del(permission.ID)
+	defer s.pendingPermissions.Del(permission.ID)
 
 	// Publish the request
 	s.Publish(pubsub.CreatedEvent, permission)
 
+	var timeout <-chan time.Time
+	if requestTimeout > 0 {
+		timer := time.NewTimer(requestTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
 	select {
 	case <-ctx.Done():
 		return false, ctx.Err()
 	case granted := <-respCh:
 		return granted, nil
+	case <-timeout:
+		// Nobody answered in time, deny the request.
+		permission.Decider = DeciderAuto
+		s.deny(permission, ReasonTimeout)
+		return false, nil
 	}
 }
 
 func (s *permissionService) AutoApproveSession(sessionID string) {
-	s.autoApproveSessionsMu.Lock()
-	s.autoApproveSessions[sessionID] = true
-	s.autoApproveSessionsMu.Unlock()
+	s.SetSessionMode(sessionID, SessionModeAuto)
+}
+
+func (s *permissionService) SetSessionMode(sessionID string, mode SessionMode) {
+	s.sessionModesMu.Lock()
+	s.sessionModes[sessionID] = mode
+	s.sessionModesMu.Unlock()
+}
+
+// SessionMode returns the mode of the given session, [SessionModeAsk] if
+// none was set.
+func (s *permissionService) SessionMode(sessionID string) SessionMode {
+	s.sessionModesMu.RLock()
+	defer s.sessionModesMu.RUnlock()
+	if mode, ok := s.sessionModes[sessionID]; ok {
+		return mode
+	}
+	return SessionModeAsk
+}
+
+// SetRequestTimeout sets how long a request waits for a reply before it is
+// denied. Zero waits forever.
+func (s *permissionService) SetRequestTimeout(timeout time.Duration) {
+	s.settingsMu.Lock()
+	s.requestTimeout = timeout
+	s.settingsMu.Unlock()
+}
+
+// PendingRequests returns the requests that are waiting for a reply.
+func (s *permissionService) PendingRequests() []PermissionRequest {
+	return slices.Collect(s.pendingPermissions.Seq())
+}
+
+// IsDangerous reports whether a request runs commands, downloads files,
+// calls an MCP tool or touches a path outside the working directory.
+func IsDangerous(workingDir string, req CreatePermissionRequest) bool {
+	if slices.Contains(dangerousTools, req.ToolName) || strings.HasPrefix(req.ToolName, "mcp_") {
+		return true
+	}
+	if req.Path == "" || workingDir == "" {
+		return false
+	}
+	rel, err := filepath.Rel(workingDir, req.Path)
+	return err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
 }
 
 func (s *permissionService) SubscribeNotifications(ctx context.Context) <-chan pubsub.Event[PermissionNotification] {
@@ -226,22 +387,78 @@ func (s *permissionService) SubscribeNotifications(ctx context.Context) <-chan p
 }
 
 func (s *permissionService) SetSkipRequests(skip bool) {
+	s.settingsMu.Lock()
 	s.skip = skip
+	s.settingsMu.Unlock()
 }
 
 func (s *permissionService) SkipRequests() bool {
+	s.settingsMu.RLock()
+	defer s.settingsMu.RUnlock()
 	return s.skip
 }
 
+// SetAllowedTools replaces the tools, or tool:action pairs, that are granted
+// without asking.
+func (s *permissionService) SetAllowedTools(tools []string) {
+	s.settingsMu.Lock()
+	s.allowedTools = tools
+	s.settingsMu.Unlock()
+}
+
+// SetRecorder sets the recorder the answers to requests are passed to.
+func (s *permissionService) SetRecorder(recorder Recorder) {
+	s.settingsMu.Lock()
+	s.recorder = recorder
+	s.settingsMu.Unlock()
+}
+
+// record passes the answer to a request to the recorder, if any.
+func (s *permissionService) record(permission PermissionRequest, granted, persistent bool, reason string) {
+	s.settingsMu.RLock()
+	recorder := s.recorder
+	s.settingsMu.RUnlock()
+	if recorder == nil {
+		return
+	}
+	decider := permission.Decider
+	if decider == "" {
+		decider = DeciderTUI
+	}
+	recorder.RecordDecision(Decision{
+		Request:    permission,
+		Granted:    granted,
+		Persistent: persistent,
+		Decider:    decider,
+		Reason:     reason,
+		Time:       time.Now(),
+	})
+}
+
+// recordAuto records a request answered without asking.
+func (s *permissionService) recordAuto(opts CreatePermissionRequest, granted bool, reason string) {
+	s.record(PermissionRequest{
+		SessionID:   opts.SessionID,
+		ToolCallID:  opts.ToolCallID,
+		ToolName:    opts.ToolName,
+		Description: opts.Description,
+		Action:      opts.Action,
+		Params:      opts.Params,
+		Path:        opts.Path,
+		Decider:     DeciderAuto,
+	}, granted, false, reason)
+}
+
 func NewPermissionService(workingDir string, skip bool, allowedTools []string) Service {
 	return &permissionService{
-		Broker:              pubsub.NewBroker[PermissionRequest](),
-		notificationBroker:  pubsub.NewBroker[PermissionNotification](),
-		workingDir:          workingDir,
-		sessionPermissions:  make([]PermissionRequest, 0),
-		autoApproveSessions: make(map[string]bool),
-		skip:                skip,
-		allowedTools:        allowedTools,
-		pendingRequests:     csync.NewMap[string, chan bool](),
+		Broker:             pubsub.NewBroker[PermissionRequest](),
+		notificationBroker: pubsub.NewBroker[PermissionNotification](),
+		workingDir:         workingDir,
+		sessionPermissions: make([]PermissionRequest, 0),
+		sessionModes:       make(map[string]SessionMode),
+		skip:               skip,
+		allowedTools:       allowedTools,
+		pendingRequests:    csync.NewMap[string, chan bool](),
+		pendingPermissions: csync.NewMap[string, PermissionRequest](),
 	}
 }
@@ -3,6 +3,7 @@ package permission
 import (
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -251,3 +252,181 @@ func TestPermissionService_SequentialProperties(t *testing.T) {
 		assert.True(t, result, "Repeated request should be auto-approved due to persistent permission")
 	})
 }
+
+func TestPermissionService_SessionModes(t *testing.T) {
+	t.Run("auto grants without publishing", func(t *testing.T) {
+		service := NewPermissionService("/tmp/project", false, []string{})
+		service.SetSessionMode("auto-session", SessionModeAuto)
+
+		granted, err := service.Request(t.Context(), CreatePermissionRequest{
+			SessionID: "auto-session",
+			ToolName:  "bash",
+			Action:    "execute",
+			Path:      "/tmp/project",
+		})
+		require.NoError(t, err)
+		assert.True(t, granted)
+		assert.Empty(t, service.PendingRequests())
+	})
+
+	t.Run("deny-dangerous", func(t *testing.T) {
+		service := NewPermissionService("/tmp/project", false, []string{})
+		service.SetSessionMode("careful", SessionModeDenyDangerous)
+
+		tests := []struct {
+			name    string
+			req     CreatePermissionRequest
+			granted bool
+		}{
+			{"edit inside project", CreatePermissionRequest{ToolName: "edit", Action: "write", Path: "/tmp/project/main.go"}, true},
+			{"fetch", CreatePermissionRequest{ToolName: "fetch", Action: "fetch", Path: "/tmp/project"}, true},
+			{"bash", CreatePermissionRequest{ToolName: "bash", Action: "execute", Path: "/tmp/project"}, false},
+			{"download", CreatePermissionRequest{ToolName: "download", Action: "download", Path: "/tmp/project/file"}, false},
+			{"mcp tool", CreatePermissionRequest{ToolName: "mcp_github_create_issue", Action: "execute", Path: "/tmp/project"}, false},
+			{"write outside project", CreatePermissionRequest{ToolName: "write", Action: "write", Path: "/etc/hosts"}, false},
+			{"sibling directory", CreatePermissionRequest{ToolName: "view", Action: "read", Path: "/tmp/project-other/a.txt"}, false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				tt.req.SessionID = "careful"
+				granted, err := service.Request(t.Context(), tt.req)
+				require.NoError(t, err)
+				assert.Equal(t, tt.granted, granted)
+			})
+		}
+	})
+
+	t.Run("ask is the default", func(t *testing.T) {
+		service := NewPermissionService("/tmp", false, []string{})
+		assert.Equal(t, SessionModeAsk, service.SessionMode("unknown"))
+
+		service.AutoApproveSession("yolo")
+		assert.Equal(t, SessionModeAuto, service.SessionMode("yolo"))
+	})
+}
+
+func TestPermissionService_PendingAndTimeout(t *testing.T) {
+	service := NewPermissionService("/tmp", false, []string{})
+	service.SetRequestTimeout(50 * time.Millisecond)
+
+	events := service.Subscribe(t.Context())
+	notifications := service.SubscribeNotifications(t.Context())
+
+	var granted bool
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		granted, _ = service.Request(t.Context(), CreatePermissionRequest{
+			SessionID:  "slow",
+			ToolCallID: "call-1",
+			ToolName:   "bash",
+			Action:     "execute",
+			Path:       "/tmp",
+		})
+	})
+
+	event := <-events
+	pending := service.PendingRequests()
+	require.Len(t, pending, 1)
+	assert.Equal(t, event.Payload.ID, pending[0].ID)
+
+	wg.Wait()
+	assert.False(t, granted, "unanswered request should be denied after the timeout")
+	assert.Empty(t, service.PendingRequests())
+
+	for notification := range notifications {
+		if notification.Payload.Denied {
+			assert.Equal(t, "call-1", notification.Payload.ToolCallID)
+			break
+		}
+	}
+}
+
+type recorderFunc func(Decision)
+
+func (f recorderFunc) RecordDecision(d Decision) { f(d) }
+
+func TestPermissionService_Recorder(t *testing.T) {
+	service := NewPermissionService("/tmp", false, []string{"view"})
+	service.SetRequestTimeout(time.Second)
+
+	var mu sync.Mutex
+	var decisions []Decision
+	service.SetRecorder(recorderFunc(func(d Decision) {
+		mu.Lock()
+		defer mu.Unlock()
+		decisions = append(decisions, d)
+	}))
+	last := func() Decision {
+		mu.Lock()
+		defer mu.Unlock()
+		require.NotEmpty(t, decisions)
+		return decisions[len(decisions)-1]
+	}
+
+	// Allowed tools are granted without asking.
+	granted, err := service.Request(t.Context(), CreatePermissionRequest{SessionID: "s", ToolCallID: "call-1", ToolName: "view", Path: "/tmp"})
+	require.NoError(t, err)
+	require.True(t, granted)
+	assert.Equal(t, DeciderAuto, last().Decider)
+	assert.Equal(t, ReasonAllowedTools, last().Reason)
+	assert.Equal(t, "call-1", last().Request.ToolCallID)
+
+	// Answers default to the TUI, API callers set the decider.
+	events := service.Subscribe(t.Context())
+	reply := func(answer func(PermissionRequest), decider string) {
+		var wg sync.WaitGroup
+		wg.Go(func() {
+			_, _ = service.Request(t.Context(), CreatePermissionRequest{SessionID: "s", ToolName: "bash", Action: "execute", Path: "/tmp"})
+		})
+		req := (<-events).Payload
+		req.Decider = decider
+		answer(req)
+		wg.Wait()
+	}
+
+	reply(service.Deny, "")
+	assert.False(t, last().Granted)
+	assert.Equal(t, DeciderTUI, last().Decider)
+	assert.Equal(t, "bash", last().Request.ToolName)
+
+	reply(service.GrantPersistent, "api_key:ci")
+	assert.True(t, last().Granted)
+	assert.True(t, last().Persistent)
+	assert.Equal(t, "api_key:ci", last().Decider)
+
+	// Later requests are granted by the session permission.
+	granted, err = service.Request(t.Context(), CreatePermissionRequest{SessionID: "s", ToolName: "bash", Action: "execute", Path: "/tmp"})
+	require.NoError(t, err)
+	require.True(t, granted)
+	assert.Equal(t, DeciderAuto, last().Decider)
+	assert.Equal(t, ReasonSessionPermission, last().Reason)
+
+	service.SetSessionMode("yolo", SessionModeDenyDangerous)
+	granted, err = service.Request(t.Context(), CreatePermissionRequest{SessionID: "yolo", ToolName: "bash", Path: "/tmp"})
+	require.NoError(t, err)
+	require.False(t, granted)
+	assert.False(t, last().Granted)
+	assert.Equal(t, string(SessionModeDenyDangerous), last().Reason)
+}
+
+func TestPermissionService_ConcurrentSettings(t *testing.T) {
+	service := NewPermissionService("/tmp", false, []string{})
+	service.SetSessionMode("auto", SessionModeAuto)
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Go(func() {
+			service.SetRequestTimeout(time.Duration(i) * time.Millisecond)
+			service.SetAllowedTools([]string{"view"})
+			service.SetSkipRequests(i%2 == 0)
+			service.SetRecorder(recorderFunc(func(Decision) {}))
+		})
+		wg.Go(func() {
+			granted, err := service.Request(t.Context(), CreatePermissionRequest{SessionID: "auto", ToolName: "view", Path: "/tmp"})
+			assert.NoError(t, err)
+			assert.True(t, granted)
+			_ = service.SkipRequests()
+		})
+	}
+	wg.Wait()
+}
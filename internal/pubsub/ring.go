@@ -0,0 +1,90 @@
+package pubsub
+
+import "sync"
+
+// RingEntry is a value stored in a Ring together with its sequence ID.
+type RingEntry[T any] struct {
+	ID    uint64
+	Value T
+}
+
+// Ring is a bounded, thread-safe buffer of recent values. Every appended
+// value gets a monotonically increasing ID so that a subscriber that
+// reconnects can ask for everything it missed since the last ID it saw.
+type Ring[T any] struct {
+	mu      sync.RWMutex
+	entries []RingEntry[T]
+	head    int
+	count   int
+	nextID  uint64
+}
+
+// NewRing creates a ring holding at most capacity values. IDs start at
+// firstID, which must be greater than zero since zero means "nothing seen".
+func NewRing[T any](capacity int, firstID uint64) *Ring[T] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	if firstID == 0 {
+		firstID = 1
+	}
+	return &Ring[T]{
+		entries: make([]RingEntry[T], capacity),
+		nextID:  firstID,
+	}
+}
+
+// Append stores v, evicting the oldest value when the ring is full, and
+// returns the ID assigned to it.
+func (r *Ring[T]) Append(v T) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := r.nextID
+	r.nextID++
+
+	idx := (r.head + r.count) % len(r.entries)
+	r.entries[idx] = RingEntry[T]{ID: id, Value: v}
+	if r.count < len(r.entries) {
+		r.count++
+	} else {
+		r.head = (r.head + 1) % len(r.entries)
+	}
+	return id
+}
+
+// LastID returns the ID of the most recently appended value, or the value
+// just before the first ID if nothing has been appended yet.
+func (r *Ring[T]) LastID() uint64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.nextID - 1
+}
+
+// Since returns all values appended after id, oldest first. The boolean is
+// false when the values following id are no longer complete: either some of
+// them were already evicted, or id was never issued by this ring.
+func (r *Ring[T]) Since(id uint64) ([]RingEntry[T], bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	lastID := r.nextID - 1
+	if id > lastID {
+		return nil, false
+	}
+	if id == lastID {
+		return nil, true
+	}
+
+	oldestID := r.nextID - uint64(r.count)
+	if id+1 < oldestID {
+		return nil, false
+	}
+
+	skip := int(id + 1 - oldestID)
+	result := make([]RingEntry[T], 0, r.count-skip)
+	for i := skip; i < r.count; i++ {
+		result = append(result, r.entries[(r.head+i)%len(r.entries)])
+	}
+	return result, true
+}
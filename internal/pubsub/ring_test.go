@@ -0,0 +1,73 @@
+package pubsub
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func ringValues(entries []RingEntry[string]) []string {
+	values := make([]string, 0, len(entries))
+	for _, e := range entries {
+		values = append(values, e.Value)
+	}
+	return values
+}
+
+func TestRing(t *testing.T) {
+	t.Parallel()
+
+	t.Run("assigns increasing ids", func(t *testing.T) {
+		t.Parallel()
+		r := NewRing[string](3, 10)
+		require.Equal(t, uint64(9), r.LastID())
+		require.Equal(t, uint64(10), r.Append("a"))
+		require.Equal(t, uint64(11), r.Append("b"))
+		require.Equal(t, uint64(11), r.LastID())
+	})
+
+	t.Run("since returns missed values", func(t *testing.T) {
+		t.Parallel()
+		r := NewRing[string](3, 1)
+		r.Append("a")
+		r.Append("b")
+		r.Append("c")
+
+		entries, ok := r.Since(1)
+		require.True(t, ok)
+		require.Equal(t, []string{"b", "c"}, ringValues(entries))
+		require.Equal(t, uint64(2), entries[0].ID)
+
+		entries, ok = r.Since(0)
+		require.True(t, ok)
+		require.Equal(t, []string{"a", "b", "c"}, ringValues(entries))
+
+		entries, ok = r.Since(3)
+		require.True(t, ok)
+		require.Empty(t, entries)
+	})
+
+	t.Run("since reports evicted gap", func(t *testing.T) {
+		t.Parallel()
+		r := NewRing[string](2, 1)
+		r.Append("a")
+		r.Append("b")
+		r.Append("c")
+
+		_, ok := r.Since(0)
+		require.False(t, ok)
+
+		entries, ok := r.Since(1)
+		require.True(t, ok)
+		require.Equal(t, []string{"b", "c"}, ringValues(entries))
+	})
+
+	t.Run("since rejects unknown future id", func(t *testing.T) {
+		t.Parallel()
+		r := NewRing[string](2, 1)
+		r.Append("a")
+
+		_, ok := r.Since(5)
+		require.False(t, ok)
+	})
+}
@@ -38,10 +38,33 @@ type Session struct {
 	SummaryMessageID string
 	Cost             float64
 	Todos            []Todo
+	PermissionMode   string
 	CreatedAt        int64
 	UpdatedAt        int64
 }
 
+// Revert is a pending revert of a session. The files changed since the
+// revert point were restored, and the reverted messages are deleted when the
+// revert is committed. Until then it can be undone with the snapshot in
+// Files.
+type Revert struct {
+	SessionID string
+	// MessageID is the first reverted message.
+	MessageID string
+	PartID    string
+	Diff      string
+	// Files holds the state of the reverted files before the revert.
+	Files     []RevertedFile
+	CreatedAt int64
+}
+
+// RevertedFile is the state of a file before it was reverted.
+type RevertedFile struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+	Existed bool   `json:"existed"`
+}
+
 type Service interface {
 	pubsub.Subscriber[Session]
 	Create(ctx context.Context, title string) (Session, error)
@@ -49,8 +72,14 @@ type Service interface {
 	CreateTaskSession(ctx context.Context, toolCallID, parentSessionID, title string) (Session, error)
 	Get(ctx context.Context, id string) (Session, error)
 	List(ctx context.Context) ([]Session, error)
+	ListChildren(ctx context.Context, parentSessionID string) ([]Session, error)
 	Save(ctx context.Context, session Session) (Session, error)
 	UpdateTitleAndUsage(ctx context.Context, sessionID, title string, promptTokens, completionTokens int64, cost float64) error
+	SetPermissionMode(ctx context.Context, sessionID, mode string) error
+	// GetRevert returns the pending revert of the session, or sql.ErrNoRows.
+	GetRevert(ctx context.Context, sessionID string) (Revert, error)
+	SetRevert(ctx context.Context, revert Revert) error
+	DeleteRevert(ctx context.Context, sessionID string) error
 	Delete(ctx context.Context, id string) error
 
 	// Agent tool session management
@@ -88,6 +117,14 @@ func (s *service) CreateTaskSession(ctx context.Context, toolCallID, parentSessi
 	if err != nil {
 		return Session{}, err
 	}
+	// Task sessions run with the permission mode of the session that
+	// started them.
+	if parent, err := s.q.GetSessionByID(ctx, parentSessionID); err == nil && parent.PermissionMode != "" {
+		if err := s.SetPermissionMode(ctx, dbSession.ID, parent.PermissionMode); err != nil {
+			return Session{}, err
+		}
+		dbSession.PermissionMode = parent.PermissionMode
+	}
 	session := s.fromDBItem(dbSession)
 	s.Publish(pubsub.CreatedEvent, session)
 	return session, nil
@@ -126,6 +163,9 @@ func (s *service) Delete(ctx context.Context, id string) error {
 	if err = qtx.DeleteSessionFiles(ctx, dbSession.ID); err != nil {
 		return fmt.Errorf("deleting session files: %w", err)
 	}
+	if err = qtx.DeleteSessionRevert(ctx, dbSession.ID); err != nil {
+		return fmt.Errorf("deleting session revert: %w", err)
+	}
 	if err = qtx.DeleteSession(ctx, dbSession.ID); err != nil {
 		return fmt.Errorf("deleting session: %w", err)
 	}
@@ -188,6 +228,56 @@ func (s *service) UpdateTitleAndUsage(ctx context.Context, sessionID, title stri
 	})
 }
 
+// SetPermissionMode stores the permission mode of the session.
+func (s *service) SetPermissionMode(ctx context.Context, sessionID, mode string) error {
+	return s.q.UpdateSessionPermissionMode(ctx, db.UpdateSessionPermissionModeParams{
+		ID:             sessionID,
+		PermissionMode: mode,
+	})
+}
+
+func (s *service) GetRevert(ctx context.Context, sessionID string) (Revert, error) {
+	dbRevert, err := s.q.GetSessionRevert(ctx, sessionID)
+	if err != nil {
+		return Revert{}, err
+	}
+	var files []RevertedFile
+	if err := json.Unmarshal([]byte(dbRevert.Files), &files); err != nil {
+		return Revert{}, fmt.Errorf("decoding reverted files: %w", err)
+	}
+	return Revert{
+		SessionID: dbRevert.SessionID,
+		MessageID: dbRevert.MessageID,
+		PartID:    dbRevert.PartID,
+		Diff:      dbRevert.Diff,
+		Files:     files,
+		CreatedAt: dbRevert.CreatedAt,
+	}, nil
+}
+
+// SetRevert stores the pending revert of a session, replacing the previous
+// one.
+func (s *service) SetRevert(ctx context.Context, revert Revert) error {
+	if revert.Files == nil {
+		revert.Files = []RevertedFile{}
+	}
+	files, err := json.Marshal(revert.Files)
+	if err != nil {
+		return err
+	}
+	return s.q.SetSessionRevert(ctx, db.SetSessionRevertParams{
+		SessionID: revert.SessionID,
+		MessageID: revert.MessageID,
+		PartID:    revert.PartID,
+		Diff:      revert.Diff,
+		Files:     string(files),
+	})
+}
+
+func (s *service) DeleteRevert(ctx context.Context, sessionID string) error {
+	return s.q.DeleteSessionRevert(ctx, sessionID)
+}
+
 func (s *service) List(ctx context.Context) ([]Session, error) {
 	dbSessions, err := s.q.ListSessions(ctx)
 	if err != nil {
@@ -200,6 +290,20 @@ func (s *service) List(ctx context.Context) ([]Session, error) {
 	return sessions, nil
 }
 
+// ListChildren returns the sessions created from the given parent session,
+// such as agent tool and task sessions, oldest first.
+func (s *service) ListChildren(ctx context.Context, parentSessionID string) ([]Session, error) {
+	dbSessions, err := s.q.ListChildSessions(ctx, sql.NullString{String: parentSessionID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]Session, len(dbSessions))
+	for i, dbSession := range dbSessions {
+		sessions[i] = s.fromDBItem(dbSession)
+	}
+	return sessions, nil
+}
+
 func (s service) fromDBItem(item db.Session) Session {
 	todos, err := unmarshalTodos(item.Todos.String)
 	if err != nil {
@@ -215,6 +319,7 @@ func (s service) fromDBItem(item db.Session) Session {
 		SummaryMessageID: item.SummaryMessageID.String,
 		Cost:             item.Cost,
 		Todos:            todos,
+		PermissionMode:   item.PermissionMode,
 		CreatedAt:        item.CreatedAt,
 		UpdatedAt:        item.UpdatedAt,
 	}
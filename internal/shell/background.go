@@ -4,12 +4,15 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"path/filepath"
 	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/pubsub"
 )
 
 const (
@@ -43,6 +46,16 @@ func (sb *syncBuffer) String() string {
 	return sb.buf.String()
 }
 
+// since returns what was written after offset, and the offset of the end of
+// the buffer.
+func (sb *syncBuffer) since(offset int) (string, int) {
+	sb.mu.RLock()
+	defer sb.mu.RUnlock()
+	data := sb.buf.Bytes()
+	offset = min(max(offset, 0), len(data))
+	return string(data[offset:]), len(data)
+}
+
 // BackgroundShell represents a shell running in the background.
 type BackgroundShell struct {
 	ID          string
@@ -50,6 +63,7 @@ type BackgroundShell struct {
 	Description string
 	Shell       *Shell
 	WorkingDir  string
+	StartedAt   time.Time
 	ctx         context.Context
 	cancel      context.CancelFunc
 	stdout      *syncBuffer
@@ -68,8 +82,33 @@ var (
 	backgroundManager     *BackgroundShellManager
 	backgroundManagerOnce sync.Once
 	idCounter             atomic.Uint64
+	jobBroker             = pubsub.NewBroker[JobEvent]()
+)
+
+// JobEventType is the type of a background job event.
+type JobEventType string
+
+const (
+	// JobEventStarted is published when a background job starts.
+	JobEventStarted JobEventType = "started"
+	// JobEventFinished is published when a background job exits, including
+	// when it is killed.
+	JobEventFinished JobEventType = "finished"
+	// JobEventRemoved is published when a job is no longer tracked.
+	JobEventRemoved JobEventType = "removed"
 )
 
+// JobEvent is published when a background job changes.
+type JobEvent struct {
+	Type JobEventType
+	Job  BackgroundShellInfo
+}
+
+// SubscribeJobEvents returns a channel for background job events.
+func SubscribeJobEvents(ctx context.Context) <-chan pubsub.Event[JobEvent] {
+	return jobBroker.Subscribe(ctx)
+}
+
 // newBackgroundShellManager creates a new BackgroundShellManager instance.
 func newBackgroundShellManager() *BackgroundShellManager {
 	return &BackgroundShellManager{
@@ -106,6 +145,7 @@ func (m *BackgroundShellManager) Start(ctx context.Context, workingDir string, b
 		Command:     command,
 		Description: description,
 		WorkingDir:  workingDir,
+		StartedAt:   time.Now(),
 		Shell:       shell,
 		ctx:         shellCtx,
 		cancel:      cancel,
@@ -115,6 +155,7 @@ func (m *BackgroundShellManager) Start(ctx context.Context, workingDir string, b
 	}
 
 	m.shells.Set(id, bgShell)
+	jobBroker.Publish(pubsub.CreatedEvent, JobEvent{Type: JobEventStarted, Job: bgShell.Info()})
 
 	go func() {
 		defer close(bgShell.done)
@@ -123,6 +164,9 @@ func (m *BackgroundShellManager) Start(ctx context.Context, workingDir string, b
 
 		bgShell.exitErr = err
 		atomic.StoreInt64(&bgShell.completedAt, time.Now().Unix())
+		// Published before done is closed so that it precedes the removed
+		// event of a kill.
+		jobBroker.Publish(pubsub.UpdatedEvent, JobEvent{Type: JobEventFinished, Job: bgShell.info(true)})
 	}()
 
 	return bgShell, nil
@@ -136,10 +180,11 @@ func (m *BackgroundShellManager) Get(id string) (*BackgroundShell, bool) {
 // Remove removes a background shell from the manager without terminating it.
 // This is useful when a shell has already completed and you just want to clean up tracking.
 func (m *BackgroundShellManager) Remove(id string) error {
-	_, ok := m.shells.Take(id)
+	shell, ok := m.shells.Take(id)
 	if !ok {
 		return fmt.Errorf("background shell not found: %s", id)
 	}
+	publishRemoved(shell)
 	return nil
 }
 
@@ -152,6 +197,7 @@ func (m *BackgroundShellManager) Kill(id string) error {
 
 	shell.cancel()
 	<-shell.done
+	publishRemoved(shell)
 	return nil
 }
 
@@ -160,6 +206,27 @@ type BackgroundShellInfo struct {
 	ID          string
 	Command     string
 	Description string
+	WorkingDir  string
+	StartedAt   time.Time
+	// CompletedAt is zero while the job is running.
+	CompletedAt time.Time
+	Done        bool
+	ExitCode    int
+}
+
+// Jobs returns information about all background shells, oldest first.
+func (m *BackgroundShellManager) Jobs() []BackgroundShellInfo {
+	jobs := make([]BackgroundShellInfo, 0, m.shells.Len())
+	for shell := range m.shells.Seq() {
+		jobs = append(jobs, shell.Info())
+	}
+	slices.SortFunc(jobs, func(a, b BackgroundShellInfo) int {
+		if c := a.StartedAt.Compare(b.StartedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return jobs
 }
 
 // List returns all background shell IDs.
@@ -195,6 +262,58 @@ func (m *BackgroundShellManager) Cleanup() int {
 func (m *BackgroundShellManager) KillAll() {
 	shells := slices.Collect(m.shells.Seq())
 	m.shells.Reset(map[string]*BackgroundShell{})
+	killShells(shells)
+	for _, shell := range shells {
+		publishRemoved(shell)
+	}
+}
+
+// KillInDir terminates the background shells whose working directory is dir
+// or one of its subdirectories. This is used when a single project shuts down
+// while other projects in the same process keep running.
+func (m *BackgroundShellManager) KillInDir(dir string) {
+	dir = filepath.Clean(dir)
+	var shells []*BackgroundShell
+	for id, shell := range m.shells.Seq2() {
+		if !isInDir(dir, shell.WorkingDir) {
+			continue
+		}
+		m.shells.Del(id)
+		shells = append(shells, shell)
+	}
+	killShells(shells)
+	for _, shell := range shells {
+		publishRemoved(shell)
+	}
+}
+
+// HasRunningInDir reports whether a background shell whose working directory
+// is dir or one of its subdirectories is still running.
+func (m *BackgroundShellManager) HasRunningInDir(dir string) bool {
+	dir = filepath.Clean(dir)
+	for shell := range m.shells.Seq() {
+		if isInDir(dir, shell.WorkingDir) && !shell.IsDone() {
+			return true
+		}
+	}
+	return false
+}
+
+// isInDir reports whether path is dir or one of its subdirectories. dir must
+// be clean.
+func isInDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, filepath.Clean(path))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// publishRemoved publishes that the shell is no longer tracked.
+func publishRemoved(shell *BackgroundShell) {
+	jobBroker.Publish(pubsub.DeletedEvent, JobEvent{Type: JobEventRemoved, Job: shell.Info()})
+}
+
+// killShells cancels the given shells and waits up to five seconds for them
+// to exit.
+func killShells(shells []*BackgroundShell) {
 	done := make(chan struct{}, 1)
 	go func() {
 		var wg sync.WaitGroup
@@ -226,6 +345,35 @@ func (bs *BackgroundShell) GetOutput() (stdout string, stderr string, done bool,
 	}
 }
 
+// OutputSince returns the stdout and stderr written after the given byte
+// offsets, along with the offsets to continue reading from.
+func (bs *BackgroundShell) OutputSince(stdoutOffset, stderrOffset int) (stdout string, stderr string, nextStdoutOffset int, nextStderrOffset int) {
+	stdout, nextStdoutOffset = bs.stdout.since(stdoutOffset)
+	stderr, nextStderrOffset = bs.stderr.since(stderrOffset)
+	return stdout, stderr, nextStdoutOffset, nextStderrOffset
+}
+
+// Info returns a snapshot of the background shell's state.
+func (bs *BackgroundShell) Info() BackgroundShellInfo {
+	return bs.info(bs.IsDone())
+}
+
+func (bs *BackgroundShell) info(done bool) BackgroundShellInfo {
+	info := BackgroundShellInfo{
+		ID:          bs.ID,
+		Command:     bs.Command,
+		Description: bs.Description,
+		WorkingDir:  bs.WorkingDir,
+		StartedAt:   bs.StartedAt,
+	}
+	if done {
+		info.Done = true
+		info.ExitCode = ExitCode(bs.exitErr)
+		info.CompletedAt = time.Unix(atomic.LoadInt64(&bs.completedAt), 0)
+	}
+	return info
+}
+
 // IsDone checks if the background shell has finished execution.
 func (bs *BackgroundShell) IsDone() bool {
 	select {
@@ -2,6 +2,8 @@ package shell
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
@@ -280,3 +282,186 @@ func TestBackgroundShellManager_KillAll(t *testing.T) {
 		}
 	}
 }
+
+func TestBackgroundShellManager_KillInDir(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	projectDir := t.TempDir()
+	subDir := filepath.Join(projectDir, "sub")
+	if err := os.Mkdir(subDir, 0o755); err != nil {
+		t.Fatalf("failed to create subdirectory: %v", err)
+	}
+	otherDir := t.TempDir()
+	manager := newBackgroundShellManager()
+
+	inProject, err := manager.Start(ctx, projectDir, nil, "sleep 10", "")
+	if err != nil {
+		t.Fatalf("failed to start project shell: %v", err)
+	}
+	inSubDir, err := manager.Start(ctx, subDir, nil, "sleep 10", "")
+	if err != nil {
+		t.Fatalf("failed to start subdirectory shell: %v", err)
+	}
+	elsewhere, err := manager.Start(ctx, otherDir, nil, "sleep 10", "")
+	if err != nil {
+		t.Fatalf("failed to start other shell: %v", err)
+	}
+	t.Cleanup(func() { manager.Kill(elsewhere.ID) })
+
+	manager.KillInDir(projectDir)
+
+	if !inProject.IsDone() || !inSubDir.IsDone() {
+		t.Error("shells in the project directory should be done after KillInDir")
+	}
+	if _, ok := manager.Get(inProject.ID); ok {
+		t.Error("project shell should be removed from manager")
+	}
+	if elsewhere.IsDone() {
+		t.Error("shell outside the project directory should keep running")
+	}
+	if _, ok := manager.Get(elsewhere.ID); !ok {
+		t.Error("shell outside the project directory should stay in manager")
+	}
+}
+
+func TestBackgroundShellManager_HasRunningInDir(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	projectDir := t.TempDir()
+	subDir := filepath.Join(projectDir, "sub")
+	if err := os.Mkdir(subDir, 0o755); err != nil {
+		t.Fatalf("failed to create subdirectory: %v", err)
+	}
+	manager := newBackgroundShellManager()
+
+	quick, err := manager.Start(ctx, projectDir, nil, "echo done", "")
+	if err != nil {
+		t.Fatalf("failed to start quick shell: %v", err)
+	}
+	quick.Wait()
+	if manager.HasRunningInDir(projectDir) {
+		t.Error("finished shells should not count as running")
+	}
+
+	server, err := manager.Start(ctx, subDir, nil, "sleep 10", "")
+	if err != nil {
+		t.Fatalf("failed to start subdirectory shell: %v", err)
+	}
+	if !manager.HasRunningInDir(projectDir) {
+		t.Error("expected the subdirectory shell to count as running in the project")
+	}
+	if manager.HasRunningInDir(t.TempDir()) {
+		t.Error("shells in other directories should not count as running")
+	}
+
+	manager.Kill(server.ID)
+	if manager.HasRunningInDir(projectDir) {
+		t.Error("killed shells should not count as running")
+	}
+}
+
+func TestBackgroundShell_OutputSince(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	manager := newBackgroundShellManager()
+
+	bgShell, err := manager.Start(ctx, t.TempDir(), nil, "echo first; echo oops >&2", "")
+	if err != nil {
+		t.Fatalf("failed to start background shell: %v", err)
+	}
+	bgShell.Wait()
+
+	stdout, stderr, stdoutOffset, stderrOffset := bgShell.OutputSince(0, 0)
+	if stdout != "first\n" || stderr != "oops\n" {
+		t.Fatalf("unexpected output: stdout=%q stderr=%q", stdout, stderr)
+	}
+	if stdoutOffset != len("first\n") || stderrOffset != len("oops\n") {
+		t.Errorf("unexpected offsets: %d, %d", stdoutOffset, stderrOffset)
+	}
+
+	stdout, stderr, _, _ = bgShell.OutputSince(3, 100)
+	if stdout != "st\n" {
+		t.Errorf("expected output after offset 3, got %q", stdout)
+	}
+	if stderr != "" {
+		t.Errorf("expected no output past the end, got %q", stderr)
+	}
+
+	manager.Remove(bgShell.ID)
+}
+
+func TestBackgroundShellManager_Jobs(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	workingDir := t.TempDir()
+	manager := newBackgroundShellManager()
+
+	first, err := manager.Start(ctx, workingDir, nil, "exit 3", "fails")
+	if err != nil {
+		t.Fatalf("failed to start background shell: %v", err)
+	}
+	second, err := manager.Start(ctx, workingDir, nil, "sleep 10", "sleeps")
+	if err != nil {
+		t.Fatalf("failed to start background shell: %v", err)
+	}
+	defer manager.Kill(second.ID)
+	first.Wait()
+
+	jobs := manager.Jobs()
+	if len(jobs) != 2 {
+		t.Fatalf("expected 2 jobs, got %d", len(jobs))
+	}
+	if jobs[0].ID != first.ID || jobs[1].ID != second.ID {
+		t.Errorf("expected jobs in start order, got %s, %s", jobs[0].ID, jobs[1].ID)
+	}
+	if !jobs[0].Done || jobs[0].ExitCode != 3 || jobs[0].CompletedAt.IsZero() {
+		t.Errorf("expected first job to be done with exit code 3, got %+v", jobs[0])
+	}
+	if jobs[1].Done || !jobs[1].CompletedAt.IsZero() {
+		t.Errorf("expected second job to be running, got %+v", jobs[1])
+	}
+	if jobs[1].Description != "sleeps" || jobs[1].WorkingDir != workingDir {
+		t.Errorf("unexpected job info: %+v", jobs[1])
+	}
+}
+
+func TestBackgroundShellManager_JobEvents(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := SubscribeJobEvents(ctx)
+	manager := newBackgroundShellManager()
+
+	bgShell, err := manager.Start(ctx, t.TempDir(), nil, "sleep 10", "")
+	if err != nil {
+		t.Fatalf("failed to start background shell: %v", err)
+	}
+	if err := manager.Kill(bgShell.ID); err != nil {
+		t.Fatalf("failed to kill background shell: %v", err)
+	}
+
+	// Other tests publish events too.
+	var got []JobEventType
+	timeout := time.After(5 * time.Second)
+	for len(got) < 3 {
+		select {
+		case event := <-events:
+			if event.Payload.Job.ID == bgShell.ID {
+				got = append(got, event.Payload.Type)
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for events, got %v", got)
+		}
+	}
+	want := []JobEventType{JobEventStarted, JobEventFinished, JobEventRemoved}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected events %v, got %v", want, got)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"sync"
 
@@ -109,9 +110,18 @@ func splitFrontmatter(content string) (frontmatter, body string, err error) {
 	return before, after, nil
 }
 
-// Discover finds all valid skills in the given paths.
-func Discover(paths []string) []*Skill {
-	var skills []*Skill
+// Result is a SKILL.md file found by Scan. Err explains why the skill isn't
+// available to the agent; Skill is nil when the file couldn't be parsed.
+type Result struct {
+	Path  string
+	Skill *Skill
+	Err   error
+}
+
+// Scan finds all SKILL.md files in the given paths, valid or not, sorted by
+// path.
+func Scan(paths []string) []Result {
+	var results []Result
 	var mu sync.Mutex
 	seen := make(map[string]bool)
 
@@ -119,7 +129,7 @@ func Discover(paths []string) []*Skill {
 		// We use fastwalk with Follow: true instead of filepath.WalkDir because
 		// WalkDir doesn't follow symlinked directories at any depth—only entry
 		// points. This ensures skills in symlinked subdirectories are discovered.
-		// fastwalk is concurrent, so we protect shared state (seen, skills) with mu.
+		// fastwalk is concurrent, so we protect shared state (seen, results) with mu.
 		conf := fastwalk.Config{
 			Follow:  true,
 			ToSlash: fastwalk.DefaultToSlash(),
@@ -138,26 +148,71 @@ func Discover(paths []string) []*Skill {
 			}
 			seen[path] = true
 			mu.Unlock()
-			skill, err := Parse(path)
-			if err != nil {
-				slog.Warn("Failed to parse skill file", "path", path, "error", err)
-				return nil
-			}
-			if err := skill.Validate(); err != nil {
-				slog.Warn("Skill validation failed", "path", path, "error", err)
-				return nil
+
+			result := Result{Path: path}
+			result.Skill, result.Err = Parse(path)
+			if result.Err != nil {
+				result.Skill = nil
+			} else {
+				result.Err = result.Skill.Validate()
 			}
-			slog.Debug("Successfully loaded skill", "name", skill.Name, "path", path)
 			mu.Lock()
-			skills = append(skills, skill)
+			results = append(results, result)
 			mu.Unlock()
 			return nil
 		})
 	}
 
+	slices.SortFunc(results, func(a, b Result) int { return strings.Compare(a.Path, b.Path) })
+	return results
+}
+
+// Discover finds all valid skills in the given paths.
+func Discover(paths []string) []*Skill {
+	var skills []*Skill
+	for _, result := range Scan(paths) {
+		switch {
+		case result.Skill == nil:
+			slog.Warn("Failed to parse skill file", "path", result.Path, "error", result.Err)
+		case result.Err != nil:
+			slog.Warn("Skill validation failed", "path", result.Path, "error", result.Err)
+		default:
+			slog.Debug("Successfully loaded skill", "name", result.Skill.Name, "path", result.Path)
+			skills = append(skills, result.Skill)
+		}
+	}
 	return skills
 }
 
+// Create scaffolds a new skill in dir/name with a SKILL.md containing the
+// frontmatter and a placeholder body, and returns the path of the SKILL.md.
+func Create(dir, name, description string) (string, error) {
+	skill := Skill{Name: name, Description: description}
+	if err := skill.Validate(); err != nil {
+		return "", err
+	}
+
+	skillDir := filepath.Join(dir, name)
+	path := filepath.Join(skillDir, SkillFileName)
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("skill already exists: %s", path)
+	}
+
+	frontmatter, err := yaml.Marshal(skill)
+	if err != nil {
+		return "", err
+	}
+	content := fmt.Sprintf("---\n%s---\n\n# %s\n\n## When to use this skill\n\nDescribe when the agent should use this skill.\n\n## Instructions\n\nStep-by-step instructions for the agent.\n", frontmatter, name)
+
+	if err := os.MkdirAll(skillDir, 0o755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
 // ToPromptXML generates XML for injection into the system prompt.
 func ToPromptXML(skills []*Skill) string {
 	if len(skills) == 0 {
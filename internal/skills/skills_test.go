@@ -226,6 +226,56 @@ description: Name doesn't match directory.
 	require.True(t, names["skill-two"])
 }
 
+func TestScan(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	write := func(dir, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, dir), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, dir, "SKILL.md"), []byte(content), 0o644))
+	}
+	write("a-valid", "---\nname: a-valid\ndescription: Valid skill.\n---\n# Valid\n")
+	write("b-mismatch", "---\nname: other\ndescription: Name doesn't match directory.\n---\n")
+	write("c-broken", "# No frontmatter\n")
+
+	results := Scan([]string{tmpDir})
+	require.Len(t, results, 3)
+
+	require.Equal(t, filepath.Join(tmpDir, "a-valid", "SKILL.md"), results[0].Path)
+	require.NoError(t, results[0].Err)
+	require.Equal(t, "a-valid", results[0].Skill.Name)
+
+	require.NotNil(t, results[1].Skill)
+	require.ErrorContains(t, results[1].Err, "must match directory")
+
+	require.Nil(t, results[2].Skill)
+	require.ErrorContains(t, results[2].Err, "no YAML frontmatter found")
+}
+
+func TestCreate(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+
+	path, err := Create(tmpDir, "deploy-service", "Deploys a service: build, push and roll out.")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(tmpDir, "deploy-service", "SKILL.md"), path)
+
+	skill, err := Parse(path)
+	require.NoError(t, err)
+	require.NoError(t, skill.Validate())
+	require.Equal(t, "deploy-service", skill.Name)
+	require.Equal(t, "Deploys a service: build, push and roll out.", skill.Description)
+	require.True(t, strings.HasPrefix(skill.Instructions, "# deploy-service"))
+
+	_, err = Create(tmpDir, "deploy-service", "Again.")
+	require.ErrorContains(t, err, "already exists")
+
+	_, err = Create(tmpDir, "Bad--Name", "Invalid name.")
+	require.Error(t, err)
+	require.NoDirExists(t, filepath.Join(tmpDir, "Bad--Name"))
+}
+
 func TestToPromptXML(t *testing.T) {
 	t.Parallel()
 
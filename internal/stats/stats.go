@@ -0,0 +1,450 @@
+// Package stats gathers usage and cost statistics from a project database.
+package stats
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/db"
+)
+
+// DefaultSessionLimit is the number of most expensive sessions included when
+// the filter doesn't set one.
+const DefaultSessionLimit = 20
+
+// Day names for day of week statistics.
+var dayNames = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+
+// Filter restricts the sessions and messages the statistics are gathered
+// from. The zero value includes everything.
+type Filter struct {
+	// From excludes anything created before it, if set.
+	From time.Time
+	// To excludes anything created at or after it, if set.
+	To time.Time
+	// Model only includes sessions with a response from this model, and only
+	// the messages from it.
+	Model string
+	// SessionLimit is the number of most expensive sessions included in
+	// SessionCosts, DefaultSessionLimit if zero.
+	SessionLimit int
+}
+
+// Stats holds all the statistics data.
+type Stats struct {
+	GeneratedAt       time.Time          `json:"generated_at"`
+	Total             TotalStats         `json:"total"`
+	UsageByDay        []DailyUsage       `json:"usage_by_day"`
+	UsageByModel      []ModelUsage       `json:"usage_by_model"`
+	UsageByHour       []HourlyUsage      `json:"usage_by_hour"`
+	UsageByDayOfWeek  []DayOfWeekUsage   `json:"usage_by_day_of_week"`
+	RecentActivity    []DailyActivity    `json:"recent_activity"`
+	AvgResponseTimeMs float64            `json:"avg_response_time_ms"`
+	ToolUsage         []ToolUsage        `json:"tool_usage"`
+	HourDayHeatmap    []HourDayHeatmapPt `json:"hour_day_heatmap"`
+	SessionCosts      []SessionCost      `json:"session_costs"`
+
+	// responseCount is the number of responses AvgResponseTimeMs is averaged
+	// over, used to weight it when merging.
+	responseCount int64
+}
+
+type TotalStats struct {
+	TotalSessions         int64   `json:"total_sessions"`
+	TotalPromptTokens     int64   `json:"total_prompt_tokens"`
+	TotalCompletionTokens int64   `json:"total_completion_tokens"`
+	TotalTokens           int64   `json:"total_tokens"`
+	TotalCost             float64 `json:"total_cost"`
+	TotalMessages         int64   `json:"total_messages"`
+	AvgTokensPerSession   float64 `json:"avg_tokens_per_session"`
+	AvgMessagesPerSession float64 `json:"avg_messages_per_session"`
+}
+
+type DailyUsage struct {
+	Day              string  `json:"day"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	TotalTokens      int64   `json:"total_tokens"`
+	Cost             float64 `json:"cost"`
+	SessionCount     int64   `json:"session_count"`
+}
+
+type ModelUsage struct {
+	Model        string `json:"model"`
+	Provider     string `json:"provider"`
+	MessageCount int64  `json:"message_count"`
+}
+
+type HourlyUsage struct {
+	Hour         int   `json:"hour"`
+	SessionCount int64 `json:"session_count"`
+}
+
+type DayOfWeekUsage struct {
+	DayOfWeek        int    `json:"day_of_week"`
+	DayName          string `json:"day_name"`
+	SessionCount     int64  `json:"session_count"`
+	PromptTokens     int64  `json:"prompt_tokens"`
+	CompletionTokens int64  `json:"completion_tokens"`
+}
+
+type DailyActivity struct {
+	Day          string  `json:"day"`
+	SessionCount int64   `json:"session_count"`
+	TotalTokens  int64   `json:"total_tokens"`
+	Cost         float64 `json:"cost"`
+}
+
+type ToolUsage struct {
+	ToolName  string `json:"tool_name"`
+	CallCount int64  `json:"call_count"`
+}
+
+type HourDayHeatmapPt struct {
+	DayOfWeek    int   `json:"day_of_week"`
+	Hour         int   `json:"hour"`
+	SessionCount int64 `json:"session_count"`
+}
+
+// SessionCost is the usage of a single session.
+type SessionCost struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	// Project is the path of the project the session belongs to. It is only
+	// set by the caller when merging the stats of several projects.
+	Project          string    `json:"project,omitempty"`
+	MessageCount     int64     `json:"message_count"`
+	PromptTokens     int64     `json:"prompt_tokens"`
+	CompletionTokens int64     `json:"completion_tokens"`
+	TotalTokens      int64     `json:"total_tokens"`
+	Cost             float64   `json:"cost"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// Gather gathers the statistics of the sessions in conn matching filter.
+func Gather(ctx context.Context, conn *sql.DB, filter Filter) (*Stats, error) {
+	queries := db.New(conn)
+
+	var (
+		startTime, endTime sql.NullInt64
+		model              sql.NullString
+	)
+	if !filter.From.IsZero() {
+		startTime = sql.NullInt64{Int64: filter.From.Unix(), Valid: true}
+	}
+	if !filter.To.IsZero() {
+		endTime = sql.NullInt64{Int64: filter.To.Unix(), Valid: true}
+	}
+	if filter.Model != "" {
+		model = sql.NullString{String: filter.Model, Valid: true}
+	}
+
+	stats := &Stats{
+		GeneratedAt: time.Now(),
+	}
+
+	// Total stats.
+	total, err := queries.GetTotalStats(ctx, db.GetTotalStatsParams{StartTime: startTime, EndTime: endTime, Model: model})
+	if err != nil {
+		return nil, fmt.Errorf("get total stats: %w", err)
+	}
+	stats.Total = TotalStats{
+		TotalSessions:         total.TotalSessions,
+		TotalPromptTokens:     toInt64(total.TotalPromptTokens),
+		TotalCompletionTokens: toInt64(total.TotalCompletionTokens),
+		TotalTokens:           toInt64(total.TotalPromptTokens) + toInt64(total.TotalCompletionTokens),
+		TotalCost:             toFloat64(total.TotalCost),
+		TotalMessages:         toInt64(total.TotalMessages),
+		AvgTokensPerSession:   toFloat64(total.AvgTokensPerSession),
+		AvgMessagesPerSession: toFloat64(total.AvgMessagesPerSession),
+	}
+
+	// Usage by day.
+	dailyUsage, err := queries.GetUsageByDay(ctx, db.GetUsageByDayParams{StartTime: startTime, EndTime: endTime, Model: model})
+	if err != nil {
+		return nil, fmt.Errorf("get usage by day: %w", err)
+	}
+	for _, d := range dailyUsage {
+		prompt := nullFloat64ToInt64(d.PromptTokens)
+		completion := nullFloat64ToInt64(d.CompletionTokens)
+		stats.UsageByDay = append(stats.UsageByDay, DailyUsage{
+			Day:              fmt.Sprintf("%v", d.Day),
+			PromptTokens:     prompt,
+			CompletionTokens: completion,
+			TotalTokens:      prompt + completion,
+			Cost:             d.Cost.Float64,
+			SessionCount:     d.SessionCount,
+		})
+	}
+
+	// Usage by model.
+	modelUsage, err := queries.GetUsageByModel(ctx, db.GetUsageByModelParams{StartTime: startTime, EndTime: endTime, Model: model})
+	if err != nil {
+		return nil, fmt.Errorf("get usage by model: %w", err)
+	}
+	for _, m := range modelUsage {
+		stats.UsageByModel = append(stats.UsageByModel, ModelUsage{
+			Model:        m.Model,
+			Provider:     m.Provider,
+			MessageCount: m.MessageCount,
+		})
+	}
+
+	// Usage by hour.
+	hourlyUsage, err := queries.GetUsageByHour(ctx, db.GetUsageByHourParams{StartTime: startTime, EndTime: endTime, Model: model})
+	if err != nil {
+		return nil, fmt.Errorf("get usage by hour: %w", err)
+	}
+	for _, h := range hourlyUsage {
+		stats.UsageByHour = append(stats.UsageByHour, HourlyUsage{
+			Hour:         int(h.Hour),
+			SessionCount: h.SessionCount,
+		})
+	}
+
+	// Usage by day of week.
+	dowUsage, err := queries.GetUsageByDayOfWeek(ctx, db.GetUsageByDayOfWeekParams{StartTime: startTime, EndTime: endTime, Model: model})
+	if err != nil {
+		return nil, fmt.Errorf("get usage by day of week: %w", err)
+	}
+	for _, d := range dowUsage {
+		stats.UsageByDayOfWeek = append(stats.UsageByDayOfWeek, DayOfWeekUsage{
+			DayOfWeek:        int(d.DayOfWeek),
+			DayName:          dayNames[int(d.DayOfWeek)],
+			SessionCount:     d.SessionCount,
+			PromptTokens:     nullFloat64ToInt64(d.PromptTokens),
+			CompletionTokens: nullFloat64ToInt64(d.CompletionTokens),
+		})
+	}
+
+	// Recent activity (last 30 days).
+	recent, err := queries.GetRecentActivity(ctx, db.GetRecentActivityParams{StartTime: startTime, EndTime: endTime, Model: model})
+	if err != nil {
+		return nil, fmt.Errorf("get recent activity: %w", err)
+	}
+	for _, r := range recent {
+		stats.RecentActivity = append(stats.RecentActivity, DailyActivity{
+			Day:          fmt.Sprintf("%v", r.Day),
+			SessionCount: r.SessionCount,
+			TotalTokens:  nullFloat64ToInt64(r.TotalTokens),
+			Cost:         r.Cost.Float64,
+		})
+	}
+
+	// Average response time.
+	avgResp, err := queries.GetAverageResponseTime(ctx, db.GetAverageResponseTimeParams{StartTime: startTime, EndTime: endTime, Model: model})
+	if err != nil {
+		return nil, fmt.Errorf("get average response time: %w", err)
+	}
+	stats.AvgResponseTimeMs = float64(avgResp.AvgResponseSeconds) * 1000
+	stats.responseCount = avgResp.ResponseCount
+
+	// Tool usage.
+	toolUsage, err := queries.GetToolUsage(ctx, db.GetToolUsageParams{StartTime: startTime, EndTime: endTime, Model: model})
+	if err != nil {
+		return nil, fmt.Errorf("get tool usage: %w", err)
+	}
+	for _, t := range toolUsage {
+		if name, ok := t.ToolName.(string); ok && name != "" {
+			stats.ToolUsage = append(stats.ToolUsage, ToolUsage{
+				ToolName:  name,
+				CallCount: t.CallCount,
+			})
+		}
+	}
+
+	// Hour/day heatmap.
+	heatmap, err := queries.GetHourDayHeatmap(ctx, db.GetHourDayHeatmapParams{StartTime: startTime, EndTime: endTime, Model: model})
+	if err != nil {
+		return nil, fmt.Errorf("get hour day heatmap: %w", err)
+	}
+	for _, h := range heatmap {
+		stats.HourDayHeatmap = append(stats.HourDayHeatmap, HourDayHeatmapPt{
+			DayOfWeek:    int(h.DayOfWeek),
+			Hour:         int(h.Hour),
+			SessionCount: h.SessionCount,
+		})
+	}
+
+	// Most expensive sessions.
+	sessionCosts, err := queries.GetSessionCosts(ctx, db.GetSessionCostsParams{
+		StartTime: startTime,
+		EndTime:   endTime,
+		Model:     model,
+		Limit:     int64(cmp.Or(filter.SessionLimit, DefaultSessionLimit)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get session costs: %w", err)
+	}
+	for _, s := range sessionCosts {
+		stats.SessionCosts = append(stats.SessionCosts, SessionCost{
+			ID:               s.ID,
+			Title:            s.Title,
+			MessageCount:     s.MessageCount,
+			PromptTokens:     s.PromptTokens,
+			CompletionTokens: s.CompletionTokens,
+			TotalTokens:      s.PromptTokens + s.CompletionTokens,
+			Cost:             s.Cost,
+			CreatedAt:        time.Unix(s.CreatedAt, 0),
+		})
+	}
+
+	return stats, nil
+}
+
+// Merge combines the stats of several projects into one, keeping the
+// sessionLimit most expensive sessions.
+func Merge(sessionLimit int, all ...*Stats) *Stats {
+	merged := &Stats{
+		GeneratedAt: time.Now(),
+	}
+
+	days := map[string]*DailyUsage{}
+	models := map[[2]string]*ModelUsage{}
+	hours := map[int]*HourlyUsage{}
+	weekdays := map[int]*DayOfWeekUsage{}
+	recent := map[string]*DailyActivity{}
+	tools := map[string]*ToolUsage{}
+	heatmap := map[[2]int]*HourDayHeatmapPt{}
+	var responseTimeMs float64
+
+	for _, s := range all {
+		merged.Total.TotalSessions += s.Total.TotalSessions
+		merged.Total.TotalPromptTokens += s.Total.TotalPromptTokens
+		merged.Total.TotalCompletionTokens += s.Total.TotalCompletionTokens
+		merged.Total.TotalTokens += s.Total.TotalTokens
+		merged.Total.TotalCost += s.Total.TotalCost
+		merged.Total.TotalMessages += s.Total.TotalMessages
+
+		for _, d := range s.UsageByDay {
+			m := mergeInto(days, d.Day, DailyUsage{Day: d.Day})
+			m.PromptTokens += d.PromptTokens
+			m.CompletionTokens += d.CompletionTokens
+			m.TotalTokens += d.TotalTokens
+			m.Cost += d.Cost
+			m.SessionCount += d.SessionCount
+		}
+		for _, u := range s.UsageByModel {
+			m := mergeInto(models, [2]string{u.Model, u.Provider}, ModelUsage{Model: u.Model, Provider: u.Provider})
+			m.MessageCount += u.MessageCount
+		}
+		for _, h := range s.UsageByHour {
+			m := mergeInto(hours, h.Hour, HourlyUsage{Hour: h.Hour})
+			m.SessionCount += h.SessionCount
+		}
+		for _, d := range s.UsageByDayOfWeek {
+			m := mergeInto(weekdays, d.DayOfWeek, DayOfWeekUsage{DayOfWeek: d.DayOfWeek, DayName: d.DayName})
+			m.SessionCount += d.SessionCount
+			m.PromptTokens += d.PromptTokens
+			m.CompletionTokens += d.CompletionTokens
+		}
+		for _, d := range s.RecentActivity {
+			m := mergeInto(recent, d.Day, DailyActivity{Day: d.Day})
+			m.SessionCount += d.SessionCount
+			m.TotalTokens += d.TotalTokens
+			m.Cost += d.Cost
+		}
+		for _, t := range s.ToolUsage {
+			m := mergeInto(tools, t.ToolName, ToolUsage{ToolName: t.ToolName})
+			m.CallCount += t.CallCount
+		}
+		for _, h := range s.HourDayHeatmap {
+			m := mergeInto(heatmap, [2]int{h.DayOfWeek, h.Hour}, HourDayHeatmapPt{DayOfWeek: h.DayOfWeek, Hour: h.Hour})
+			m.SessionCount += h.SessionCount
+		}
+
+		responseTimeMs += s.AvgResponseTimeMs * float64(s.responseCount)
+		merged.responseCount += s.responseCount
+		merged.SessionCosts = append(merged.SessionCosts, s.SessionCosts...)
+	}
+
+	if merged.Total.TotalSessions > 0 {
+		merged.Total.AvgTokensPerSession = float64(merged.Total.TotalTokens) / float64(merged.Total.TotalSessions)
+		merged.Total.AvgMessagesPerSession = float64(merged.Total.TotalMessages) / float64(merged.Total.TotalSessions)
+	}
+	if merged.responseCount > 0 {
+		merged.AvgResponseTimeMs = responseTimeMs / float64(merged.responseCount)
+	}
+
+	// Keep the order of the queries.
+	merged.UsageByDay = sortedValues(days, func(a, b DailyUsage) int { return cmp.Compare(b.Day, a.Day) })
+	merged.UsageByModel = sortedValues(models, func(a, b ModelUsage) int {
+		return cmp.Or(cmp.Compare(b.MessageCount, a.MessageCount), cmp.Compare(a.Model, b.Model), cmp.Compare(a.Provider, b.Provider))
+	})
+	merged.UsageByHour = sortedValues(hours, func(a, b HourlyUsage) int { return cmp.Compare(a.Hour, b.Hour) })
+	merged.UsageByDayOfWeek = sortedValues(weekdays, func(a, b DayOfWeekUsage) int { return cmp.Compare(a.DayOfWeek, b.DayOfWeek) })
+	merged.RecentActivity = sortedValues(recent, func(a, b DailyActivity) int { return cmp.Compare(a.Day, b.Day) })
+	merged.ToolUsage = sortedValues(tools, func(a, b ToolUsage) int {
+		return cmp.Or(cmp.Compare(b.CallCount, a.CallCount), cmp.Compare(a.ToolName, b.ToolName))
+	})
+	merged.HourDayHeatmap = sortedValues(heatmap, func(a, b HourDayHeatmapPt) int {
+		return cmp.Or(cmp.Compare(a.DayOfWeek, b.DayOfWeek), cmp.Compare(a.Hour, b.Hour))
+	})
+
+	slices.SortStableFunc(merged.SessionCosts, func(a, b SessionCost) int {
+		return cmp.Or(cmp.Compare(b.Cost, a.Cost), b.CreatedAt.Compare(a.CreatedAt))
+	})
+	limit := cmp.Or(sessionLimit, DefaultSessionLimit)
+	if len(merged.SessionCosts) > limit {
+		merged.SessionCosts = merged.SessionCosts[:limit]
+	}
+
+	return merged
+}
+
+// mergeInto returns the entry for key in m, adding initial if there is none.
+func mergeInto[K comparable, V any](m map[K]*V, key K, initial V) *V {
+	v, ok := m[key]
+	if !ok {
+		v = &initial
+		m[key] = v
+	}
+	return v
+}
+
+// sortedValues returns the values of m sorted by compare.
+func sortedValues[K comparable, V any](m map[K]*V, compare func(a, b V) int) []V {
+	values := make([]V, 0, len(m))
+	for _, v := range m {
+		values = append(values, *v)
+	}
+	slices.SortFunc(values, compare)
+	return values
+}
+
+func toInt64(v any) int64 {
+	switch val := v.(type) {
+	case int64:
+		return val
+	case float64:
+		return int64(val)
+	case int:
+		return int64(val)
+	default:
+		return 0
+	}
+}
+
+func toFloat64(v any) float64 {
+	switch val := v.(type) {
+	case float64:
+		return val
+	case int64:
+		return float64(val)
+	case int:
+		return float64(val)
+	default:
+		return 0
+	}
+}
+
+func nullFloat64ToInt64(n sql.NullFloat64) int64 {
+	if n.Valid {
+		return int64(n.Float64)
+	}
+	return 0
+}
@@ -0,0 +1,150 @@
+package stats
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	day1 = time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC) // Monday
+	day2 = time.Date(2025, 3, 5, 15, 0, 0, 0, time.UTC) // Wednesday
+)
+
+func setupDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	conn, err := db.Connect(t.Context(), t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	q := db.New(conn)
+	addSession := func(id string, createdAt time.Time, cost float64, model string) {
+		require.NoError(t, q.ImportSession(t.Context(), db.ImportSessionParams{
+			ID:               id,
+			Title:            "Session " + id,
+			PromptTokens:     100,
+			CompletionTokens: 50,
+			Cost:             cost,
+			CreatedAt:        createdAt.Unix(),
+			UpdatedAt:        createdAt.Unix(),
+		}))
+		require.NoError(t, q.ImportMessage(t.Context(), db.ImportMessageParams{
+			ID:         id + "-assistant",
+			SessionID:  id,
+			Role:       "assistant",
+			Parts:      `[{"type":"tool_call","data":{"name":"bash"}}]`,
+			Model:      sql.NullString{String: model, Valid: true},
+			Provider:   sql.NullString{String: "openai", Valid: true},
+			FinishedAt: sql.NullInt64{Int64: createdAt.Unix() + 2, Valid: true},
+			CreatedAt:  createdAt.Unix(),
+			UpdatedAt:  createdAt.Unix(),
+		}))
+	}
+	addSession("a", day1, 0.5, "gpt-4o")
+	addSession("b", day1.Add(time.Hour), 1.5, "gpt-4o-mini")
+	addSession("c", day2, 1, "gpt-4o")
+	return conn
+}
+
+func TestGather(t *testing.T) {
+	t.Parallel()
+
+	stats, err := Gather(t.Context(), setupDB(t), Filter{})
+	require.NoError(t, err)
+
+	require.Equal(t, int64(3), stats.Total.TotalSessions)
+	require.Equal(t, int64(450), stats.Total.TotalTokens)
+	require.InDelta(t, 3.0, stats.Total.TotalCost, 1e-9)
+	require.Len(t, stats.UsageByDay, 2)
+	require.Equal(t, "2025-03-05", stats.UsageByDay[0].Day)
+	require.Equal(t, []ModelUsage{
+		{Model: "gpt-4o", Provider: "openai", MessageCount: 2},
+		{Model: "gpt-4o-mini", Provider: "openai", MessageCount: 1},
+	}, stats.UsageByModel)
+	require.Equal(t, []ToolUsage{{ToolName: "bash", CallCount: 3}}, stats.ToolUsage)
+	require.InDelta(t, 2000.0, stats.AvgResponseTimeMs, 1e-9)
+
+	require.Len(t, stats.SessionCosts, 3)
+	require.Equal(t, "b", stats.SessionCosts[0].ID)
+	require.Equal(t, int64(150), stats.SessionCosts[0].TotalTokens)
+	require.Equal(t, day1.Add(time.Hour).Unix(), stats.SessionCosts[0].CreatedAt.Unix())
+}
+
+func TestGatherFilter(t *testing.T) {
+	t.Parallel()
+
+	conn := setupDB(t)
+
+	t.Run("date range", func(t *testing.T) {
+		t.Parallel()
+
+		stats, err := Gather(t.Context(), conn, Filter{From: day2, To: day2.AddDate(0, 0, 1)})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), stats.Total.TotalSessions)
+		require.Len(t, stats.SessionCosts, 1)
+		require.Equal(t, "c", stats.SessionCosts[0].ID)
+		require.Equal(t, []DayOfWeekUsage{
+			{DayOfWeek: 3, DayName: "Wednesday", SessionCount: 1, PromptTokens: 100, CompletionTokens: 50},
+		}, stats.UsageByDayOfWeek)
+	})
+
+	t.Run("model", func(t *testing.T) {
+		t.Parallel()
+
+		stats, err := Gather(t.Context(), conn, Filter{Model: "gpt-4o"})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), stats.Total.TotalSessions)
+		require.InDelta(t, 1.5, stats.Total.TotalCost, 1e-9)
+		require.Equal(t, []ModelUsage{{Model: "gpt-4o", Provider: "openai", MessageCount: 2}}, stats.UsageByModel)
+		require.Equal(t, []ToolUsage{{ToolName: "bash", CallCount: 2}}, stats.ToolUsage)
+	})
+
+	t.Run("session limit", func(t *testing.T) {
+		t.Parallel()
+
+		stats, err := Gather(t.Context(), conn, Filter{SessionLimit: 1})
+		require.NoError(t, err)
+		require.Equal(t, int64(3), stats.Total.TotalSessions)
+		require.Len(t, stats.SessionCosts, 1)
+		require.Equal(t, "b", stats.SessionCosts[0].ID)
+	})
+}
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+
+	a, err := Gather(t.Context(), setupDB(t), Filter{})
+	require.NoError(t, err)
+	b, err := Gather(t.Context(), setupDB(t), Filter{Model: "gpt-4o-mini"})
+	require.NoError(t, err)
+	for i := range a.SessionCosts {
+		a.SessionCosts[i].Project = "/a"
+	}
+	for i := range b.SessionCosts {
+		b.SessionCosts[i].Project = "/b"
+	}
+
+	merged := Merge(2, a, b)
+	require.Equal(t, int64(4), merged.Total.TotalSessions)
+	require.InDelta(t, 4.5, merged.Total.TotalCost, 1e-9)
+	require.InDelta(t, 150.0, merged.Total.AvgTokensPerSession, 1e-9)
+	require.InDelta(t, 2000.0, merged.AvgResponseTimeMs, 1e-9)
+	require.Equal(t, []ModelUsage{
+		{Model: "gpt-4o", Provider: "openai", MessageCount: 2},
+		{Model: "gpt-4o-mini", Provider: "openai", MessageCount: 2},
+	}, merged.UsageByModel)
+	require.Equal(t, []DailyUsage{
+		{Day: "2025-03-05", PromptTokens: 100, CompletionTokens: 50, TotalTokens: 150, Cost: 1, SessionCount: 1},
+		{Day: "2025-03-03", PromptTokens: 300, CompletionTokens: 150, TotalTokens: 450, Cost: 3.5, SessionCount: 3},
+	}, merged.UsageByDay)
+	require.Equal(t, []ToolUsage{{ToolName: "bash", CallCount: 4}}, merged.ToolUsage)
+
+	require.Len(t, merged.SessionCosts, 2)
+	require.Equal(t, "b", merged.SessionCosts[0].ID)
+	require.Equal(t, "b", merged.SessionCosts[1].ID)
+	require.ElementsMatch(t, []string{"/a", "/b"}, []string{merged.SessionCosts[0].Project, merged.SessionCosts[1].Project})
+}
@@ -0,0 +1,229 @@
+package transcript
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/diff"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/session"
+)
+
+// Markdown renders the transcript as a human readable document. Title
+// generation sessions are left out; everything else is included.
+func (t *Transcript) Markdown() string {
+	var b strings.Builder
+	r := markdownRenderer{b: &b, directory: t.Directory}
+	r.session(t.Session, 1)
+	return b.String()
+}
+
+type markdownRenderer struct {
+	b         *strings.Builder
+	directory string
+}
+
+func (r *markdownRenderer) heading(level int, format string, args ...any) {
+	level = min(level, 6)
+	fmt.Fprintf(r.b, "%s %s\n\n", strings.Repeat("#", level), fmt.Sprintf(format, args...))
+}
+
+func (r *markdownRenderer) session(s Session, level int) {
+	title := s.Title
+	if title == "" {
+		title = "Untitled session"
+	}
+	r.heading(level, "%s", title)
+
+	fmt.Fprintf(r.b, "- Session: `%s`\n", s.ID)
+	if level == 1 && r.directory != "" {
+		fmt.Fprintf(r.b, "- Directory: `%s`\n", r.directory)
+	}
+	fmt.Fprintf(r.b, "- Created: %s\n", formatTime(s.CreatedAt))
+	fmt.Fprintf(r.b, "- Updated: %s\n", formatTime(s.UpdatedAt))
+	fmt.Fprintf(r.b, "- Messages: %d\n", len(s.Messages))
+	fmt.Fprintf(r.b, "- Tokens: %d prompt, %d completion\n", s.PromptTokens, s.CompletionTokens)
+	fmt.Fprintf(r.b, "- Cost: $%.4f\n\n", s.Cost)
+
+	if len(s.Todos) > 0 {
+		r.heading(level+1, "Todos")
+		for _, todo := range s.Todos {
+			r.todo(todo)
+		}
+		r.b.WriteString("\n")
+	}
+
+	if len(s.Messages) > 0 {
+		r.heading(level+1, "Conversation")
+		for _, m := range s.Messages {
+			r.message(m, s.SummaryMessageID, level+2)
+		}
+	}
+
+	if len(s.Files) > 0 {
+		r.heading(level+1, "File history")
+		r.files(s.Files, level+2)
+	}
+
+	children := make([]Session, 0, len(s.Children))
+	for _, child := range s.Children {
+		if !strings.HasPrefix(child.ID, titleSessionPrefix) {
+			children = append(children, child)
+		}
+	}
+	if len(children) > 0 {
+		r.heading(level+1, "Child sessions")
+		for _, child := range children {
+			r.session(child, level+2)
+		}
+	}
+}
+
+func (r *markdownRenderer) todo(todo session.Todo) {
+	switch todo.Status {
+	case session.TodoStatusCompleted:
+		fmt.Fprintf(r.b, "- [x] %s\n", todo.Content)
+	case session.TodoStatusInProgress:
+		fmt.Fprintf(r.b, "- [ ] %s (in progress)\n", todo.Content)
+	default:
+		fmt.Fprintf(r.b, "- [ ] %s\n", todo.Content)
+	}
+}
+
+func (r *markdownRenderer) message(m Message, summaryID string, level int) {
+	header := roleName(m.Role)
+	if m.IsSummaryMessage || m.ID == summaryID {
+		header += " (summary)"
+	}
+	if m.Model != "" {
+		header += " · " + m.Model
+		if m.Provider != "" {
+			header += " (" + m.Provider + ")"
+		}
+	}
+	r.heading(level, "%s · %s", header, formatTime(m.CreatedAt))
+
+	for _, part := range m.Parts {
+		switch p := part.(type) {
+		case message.TextContent:
+			if strings.TrimSpace(p.Text) == "" {
+				continue
+			}
+			r.b.WriteString(p.Text)
+			r.b.WriteString("\n\n")
+		case message.ReasoningContent:
+			if strings.TrimSpace(p.Thinking) == "" {
+				continue
+			}
+			r.b.WriteString("<details>\n<summary>Thinking</summary>\n\n")
+			r.b.WriteString(p.Thinking)
+			r.b.WriteString("\n\n</details>\n\n")
+		case message.ImageURLContent:
+			fmt.Fprintf(r.b, "_Image: %s_\n\n", p.URL)
+		case message.BinaryContent:
+			fmt.Fprintf(r.b, "_Attachment: %s (%s, %d bytes)_\n\n", filepath.Base(p.Path), p.MIMEType, len(p.Data))
+		case message.ToolCall:
+			fmt.Fprintf(r.b, "**Tool call** `%s` (`%s`)\n\n", p.Name, p.ID)
+			r.codeBlock("json", indentJSON(p.Input))
+		case message.ToolResult:
+			status := ""
+			if p.IsError {
+				status = " (error)"
+			}
+			fmt.Fprintf(r.b, "**Tool result** `%s` (`%s`)%s\n\n", p.Name, p.ToolCallID, status)
+			r.codeBlock("", p.Content)
+			if p.Data != "" {
+				fmt.Fprintf(r.b, "_Attached data: %s_\n\n", p.MIMEType)
+			}
+		case message.Finish:
+			switch p.Reason {
+			case message.FinishReasonError, message.FinishReasonCanceled, message.FinishReasonPermissionDenied:
+				fmt.Fprintf(r.b, "> **%s**", p.Reason)
+				if p.Message != "" {
+					fmt.Fprintf(r.b, ": %s", p.Message)
+				}
+				if p.Details != "" {
+					fmt.Fprintf(r.b, " (%s)", p.Details)
+				}
+				r.b.WriteString("\n\n")
+			}
+		}
+	}
+}
+
+// files renders each path with its versions and the change between the first
+// and the last recorded version.
+func (r *markdownRenderer) files(files []File, level int) {
+	var paths []string
+	versions := make(map[string][]File)
+	for _, f := range files {
+		if _, ok := versions[f.Path]; !ok {
+			paths = append(paths, f.Path)
+		}
+		versions[f.Path] = append(versions[f.Path], f)
+	}
+	for _, path := range paths {
+		vs := versions[path]
+		name := path
+		if rel, err := filepath.Rel(r.directory, path); r.directory != "" && err == nil && !strings.HasPrefix(rel, "..") {
+			name = rel
+		}
+		r.heading(level, "`%s`", name)
+		fmt.Fprintf(r.b, "%d version(s), last changed %s\n\n", len(vs), formatTime(vs[len(vs)-1].UpdatedAt))
+		if len(vs) < 2 {
+			continue
+		}
+		patch, additions, removals := diff.GenerateDiff(vs[0].Content, vs[len(vs)-1].Content, name)
+		if patch == "" {
+			continue
+		}
+		fmt.Fprintf(r.b, "+%d -%d\n\n", additions, removals)
+		r.codeBlock("diff", patch)
+	}
+}
+
+// codeBlock writes content in a fence longer than any backtick run inside it.
+func (r *markdownRenderer) codeBlock(lang, content string) {
+	fence := "```"
+	for strings.Contains(content, fence) {
+		fence += "`"
+	}
+	fmt.Fprintf(r.b, "%s%s\n%s\n%s\n\n", fence, lang, strings.TrimRight(content, "\n"), fence)
+}
+
+func roleName(role message.MessageRole) string {
+	switch role {
+	case message.User:
+		return "User"
+	case message.Assistant:
+		return "Assistant"
+	case message.System:
+		return "System"
+	case message.Tool:
+		return "Tool"
+	default:
+		return string(role)
+	}
+}
+
+func indentJSON(input string) string {
+	var v any
+	if err := json.Unmarshal([]byte(input), &v); err != nil {
+		return input
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return input
+	}
+	return string(data)
+}
+
+func formatTime(t int64) string {
+	if t == 0 {
+		return "unknown"
+	}
+	return time.Unix(t, 0).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,465 @@
+// Package transcript exports sessions into portable transcripts and imports
+// them back, possibly into another project's database.
+package transcript
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/google/uuid"
+)
+
+// Version is the transcript format version written by Export.
+const Version = 1
+
+// titleSessionPrefix marks the internal sessions used for title generation.
+const titleSessionPrefix = "title-"
+
+// Transcript is a self-contained copy of a session tree.
+type Transcript struct {
+	Version    int     `json:"version"`
+	ExportedAt int64   `json:"exported_at"`
+	Directory  string  `json:"directory"`
+	Session    Session `json:"session"`
+}
+
+// Session is an exported session with its messages, file history and child
+// sessions.
+type Session struct {
+	ID               string         `json:"id"`
+	Title            string         `json:"title"`
+	PromptTokens     int64          `json:"prompt_tokens"`
+	CompletionTokens int64          `json:"completion_tokens"`
+	Cost             float64        `json:"cost"`
+	SummaryMessageID string         `json:"summary_message_id,omitempty"`
+	Todos            []session.Todo `json:"todos"`
+	CreatedAt        int64          `json:"created_at"`
+	UpdatedAt        int64          `json:"updated_at"`
+	Messages         []Message      `json:"messages"`
+	Files            []File         `json:"files"`
+	Children         []Session      `json:"children"`
+}
+
+// Message is an exported message.
+type Message struct {
+	ID               string              `json:"id"`
+	Role             message.MessageRole `json:"role"`
+	Model            string              `json:"model,omitempty"`
+	Provider         string              `json:"provider,omitempty"`
+	IsSummaryMessage bool                `json:"is_summary_message,omitempty"`
+	// Parts are encoded as {"type": "text", "data": {...}} objects, where type
+	// is one of text, reasoning, image_url, binary, tool_call, tool_result and
+	// finish.
+	Parts     Parts `json:"parts"`
+	CreatedAt int64 `json:"created_at"`
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+// Parts holds message content parts. It uses the same tagged encoding as the
+// messages table, so every part type survives a round trip.
+type Parts []message.ContentPart
+
+func (p Parts) MarshalJSON() ([]byte, error) {
+	return message.MarshalParts(p)
+}
+
+func (p *Parts) UnmarshalJSON(data []byte) error {
+	parts, err := message.UnmarshalParts(data)
+	if err != nil {
+		return err
+	}
+	*p = parts
+	return nil
+}
+
+// File is an exported file history version.
+type File struct {
+	Path      string `json:"path"`
+	Content   string `json:"content"`
+	Version   int64  `json:"version"`
+	CreatedAt int64  `json:"created_at"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+// Parse decodes and validates a JSON transcript.
+func Parse(data []byte) (*Transcript, error) {
+	var t Transcript
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("decoding transcript: %w", err)
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Validate reports whether the transcript can be imported. File history
+// paths must be absolute and inside the exported directory, so that an
+// import never refers to files outside the target project.
+func (t *Transcript) Validate() error {
+	if t.Version < 1 || t.Version > Version {
+		return fmt.Errorf("unsupported transcript version %d", t.Version)
+	}
+	if t.Directory != "" && !filepath.IsAbs(t.Directory) {
+		return fmt.Errorf("directory %q is not absolute", t.Directory)
+	}
+	return t.Session.validate(filepath.Clean(t.Directory))
+}
+
+// FilePaths returns the paths the file history of the transcript gets once
+// imported into the project at to.
+func (t *Transcript) FilePaths(to string) []string {
+	var paths []string
+	var collect func(s *Session)
+	collect = func(s *Session) {
+		for _, f := range s.Files {
+			if path, err := rebasePath(f.Path, t.Directory, to); err == nil {
+				paths = append(paths, path)
+			}
+		}
+		for i := range s.Children {
+			collect(&s.Children[i])
+		}
+	}
+	collect(&t.Session)
+	return paths
+}
+
+func (s *Session) validate(dir string) error {
+	if s.ID == "" {
+		return errors.New("session id is required")
+	}
+	seen := make(map[string]bool, len(s.Messages))
+	for _, m := range s.Messages {
+		if m.ID == "" {
+			return fmt.Errorf("session %s: message id is required", s.ID)
+		}
+		if seen[m.ID] {
+			return fmt.Errorf("session %s: duplicate message id %s", s.ID, m.ID)
+		}
+		seen[m.ID] = true
+		switch m.Role {
+		case message.Assistant, message.User, message.System, message.Tool:
+		default:
+			return fmt.Errorf("message %s: unknown role %q", m.ID, m.Role)
+		}
+	}
+	for _, f := range s.Files {
+		if f.Path == "" {
+			return fmt.Errorf("session %s: file path is required", s.ID)
+		}
+		if !filepath.IsAbs(f.Path) {
+			return fmt.Errorf("session %s: file path %q is not absolute", s.ID, f.Path)
+		}
+		if !within(dir, f.Path) {
+			return fmt.Errorf("session %s: file path %q is outside the exported directory", s.ID, f.Path)
+		}
+	}
+	for i := range s.Children {
+		if err := s.Children[i].validate(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Service exports and imports session transcripts.
+type Service interface {
+	// Export collects the session, its messages, file history and child
+	// sessions.
+	Export(ctx context.Context, sessionID string) (*Transcript, error)
+
+	// Import recreates the transcript under new IDs and returns the new root
+	// session. File history paths inside the exported directory are moved to
+	// the working directory of this service.
+	Import(ctx context.Context, t *Transcript) (session.Session, error)
+}
+
+type service struct {
+	db         *sql.DB
+	q          *db.Queries
+	sessions   session.Service
+	messages   message.Service
+	files      history.Service
+	workingDir string
+}
+
+// NewService creates a transcript service for the project at workingDir.
+func NewService(q *db.Queries, conn *sql.DB, sessions session.Service, messages message.Service, files history.Service, workingDir string) Service {
+	return &service{
+		db:         conn,
+		q:          q,
+		sessions:   sessions,
+		messages:   messages,
+		files:      files,
+		workingDir: workingDir,
+	}
+}
+
+func (s *service) Export(ctx context.Context, sessionID string) (*Transcript, error) {
+	sess, err := s.sessions.Get(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	exported, err := s.exportSession(ctx, sess)
+	if err != nil {
+		return nil, err
+	}
+	return &Transcript{
+		Version:    Version,
+		ExportedAt: time.Now().Unix(),
+		Directory:  s.workingDir,
+		Session:    exported,
+	}, nil
+}
+
+func (s *service) exportSession(ctx context.Context, sess session.Session) (Session, error) {
+	msgs, err := s.messages.List(ctx, sess.ID)
+	if err != nil {
+		return Session{}, fmt.Errorf("listing messages of session %s: %w", sess.ID, err)
+	}
+	files, err := s.files.ListBySession(ctx, sess.ID)
+	if err != nil {
+		return Session{}, fmt.Errorf("listing files of session %s: %w", sess.ID, err)
+	}
+	children, err := s.sessions.ListChildren(ctx, sess.ID)
+	if err != nil {
+		return Session{}, fmt.Errorf("listing children of session %s: %w", sess.ID, err)
+	}
+
+	exported := Session{
+		ID:               sess.ID,
+		Title:            sess.Title,
+		PromptTokens:     sess.PromptTokens,
+		CompletionTokens: sess.CompletionTokens,
+		Cost:             sess.Cost,
+		SummaryMessageID: sess.SummaryMessageID,
+		Todos:            sess.Todos,
+		CreatedAt:        sess.CreatedAt,
+		UpdatedAt:        sess.UpdatedAt,
+		Messages:         make([]Message, len(msgs)),
+		Files:            make([]File, len(files)),
+		Children:         make([]Session, 0, len(children)),
+	}
+	if exported.Todos == nil {
+		exported.Todos = []session.Todo{}
+	}
+	for i, m := range msgs {
+		exported.Messages[i] = Message{
+			ID:               m.ID,
+			Role:             m.Role,
+			Model:            m.Model,
+			Provider:         m.Provider,
+			IsSummaryMessage: m.IsSummaryMessage,
+			Parts:            m.Parts,
+			CreatedAt:        m.CreatedAt,
+			UpdatedAt:        m.UpdatedAt,
+		}
+	}
+	for i, f := range files {
+		exported.Files[i] = File{
+			Path:      f.Path,
+			Content:   f.Content,
+			Version:   f.Version,
+			CreatedAt: f.CreatedAt,
+			UpdatedAt: f.UpdatedAt,
+		}
+	}
+	for _, child := range children {
+		c, err := s.exportSession(ctx, child)
+		if err != nil {
+			return Session{}, err
+		}
+		exported.Children = append(exported.Children, c)
+	}
+	return exported, nil
+}
+
+func (s *service) Import(ctx context.Context, t *Transcript) (session.Session, error) {
+	if err := t.Validate(); err != nil {
+		return session.Session{}, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return session.Session{}, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	imp := &importer{
+		q:        s.q.WithTx(tx),
+		sessions: s.sessions,
+		from:     t.Directory,
+		to:       s.workingDir,
+		ids:      make(map[string]string),
+	}
+	id := uuid.New().String()
+	if err := imp.importSession(ctx, t.Session, "", id); err != nil {
+		return session.Session{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return session.Session{}, fmt.Errorf("committing transaction: %w", err)
+	}
+	return s.sessions.Get(ctx, id)
+}
+
+// importer inserts a session tree inside a transaction, keeping track of the
+// new IDs so that references between rows can be rewritten.
+type importer struct {
+	q        *db.Queries
+	sessions session.Service
+	from, to string
+	// ids maps exported message IDs to their new IDs.
+	ids map[string]string
+}
+
+func (imp *importer) importSession(ctx context.Context, src Session, parentID, id string) error {
+	for _, m := range src.Messages {
+		imp.ids[m.ID] = uuid.New().String()
+	}
+
+	todos := sql.NullString{}
+	if len(src.Todos) > 0 {
+		data, err := json.Marshal(src.Todos)
+		if err != nil {
+			return err
+		}
+		todos = sql.NullString{String: string(data), Valid: true}
+	}
+	summaryID := sql.NullString{}
+	if newID, ok := imp.ids[src.SummaryMessageID]; ok {
+		summaryID = sql.NullString{String: newID, Valid: true}
+	}
+
+	// The message count is maintained by triggers as messages are inserted.
+	if err := imp.q.ImportSession(ctx, db.ImportSessionParams{
+		ID:               id,
+		ParentSessionID:  sql.NullString{String: parentID, Valid: parentID != ""},
+		Title:            src.Title,
+		PromptTokens:     src.PromptTokens,
+		CompletionTokens: src.CompletionTokens,
+		Cost:             src.Cost,
+		SummaryMessageID: summaryID,
+		Todos:            todos,
+		UpdatedAt:        timestamp(src.UpdatedAt),
+		CreatedAt:        timestamp(src.CreatedAt),
+	}); err != nil {
+		return fmt.Errorf("importing session %s: %w", src.ID, err)
+	}
+
+	for _, m := range src.Messages {
+		parts, err := message.MarshalParts(m.Parts)
+		if err != nil {
+			return fmt.Errorf("encoding message %s: %w", m.ID, err)
+		}
+		finishedAt := sql.NullInt64{}
+		msg := message.Message{Parts: m.Parts}
+		if f := msg.FinishPart(); f != nil {
+			finishedAt = sql.NullInt64{Int64: f.Time, Valid: true}
+		}
+		isSummary := int64(0)
+		if m.IsSummaryMessage {
+			isSummary = 1
+		}
+		if err := imp.q.ImportMessage(ctx, db.ImportMessageParams{
+			ID:               imp.ids[m.ID],
+			SessionID:        id,
+			Role:             string(m.Role),
+			Parts:            string(parts),
+			Model:            sql.NullString{String: m.Model, Valid: m.Model != ""},
+			Provider:         sql.NullString{String: m.Provider, Valid: m.Provider != ""},
+			IsSummaryMessage: isSummary,
+			FinishedAt:       finishedAt,
+			CreatedAt:        timestamp(m.CreatedAt),
+			UpdatedAt:        timestamp(m.UpdatedAt),
+		}); err != nil {
+			return fmt.Errorf("importing message %s: %w", m.ID, err)
+		}
+	}
+
+	for _, f := range src.Files {
+		path, err := rebasePath(f.Path, imp.from, imp.to)
+		if err != nil {
+			return fmt.Errorf("importing file %s: %w", f.Path, err)
+		}
+		if err := imp.q.ImportFile(ctx, db.ImportFileParams{
+			ID:        uuid.New().String(),
+			SessionID: id,
+			Path:      path,
+			Content:   f.Content,
+			Version:   f.Version,
+			CreatedAt: timestamp(f.CreatedAt),
+			UpdatedAt: timestamp(f.UpdatedAt),
+		}); err != nil {
+			return fmt.Errorf("importing file %s: %w", f.Path, err)
+		}
+	}
+
+	for _, child := range src.Children {
+		if err := imp.importSession(ctx, child, id, imp.childID(child.ID, src.ID, id)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// childID derives the new ID of a child session. Agent tool sessions and
+// title sessions encode their parent in the ID, so the encoding is kept with
+// the new parent references.
+func (imp *importer) childID(oldID, oldParentID, newParentID string) string {
+	if messageID, toolCallID, ok := imp.sessions.ParseAgentToolSessionID(oldID); ok {
+		if newMessageID, ok := imp.ids[messageID]; ok {
+			return imp.sessions.CreateAgentToolSessionID(newMessageID, toolCallID)
+		}
+	}
+	if oldID == titleSessionPrefix+oldParentID {
+		return titleSessionPrefix + newParentID
+	}
+	return uuid.New().String()
+}
+
+// rebasePath moves an absolute path inside from to the same place inside to.
+// Paths that are relative or outside from are rejected.
+func rebasePath(path, from, to string) (string, error) {
+	if !filepath.IsAbs(path) || !within(from, path) {
+		return "", fmt.Errorf("path %q is outside the exported directory", path)
+	}
+	if to == "" {
+		return filepath.Clean(path), nil
+	}
+	rel, err := filepath.Rel(from, path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(to, rel), nil
+}
+
+// within reports whether path is dir or inside it. An empty dir contains no
+// path.
+func within(dir, path string) bool {
+	if dir == "" || dir == "." {
+		return false
+	}
+	rel, err := filepath.Rel(dir, filepath.Clean(path))
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func timestamp(t int64) int64 {
+	if t == 0 {
+		return time.Now().Unix()
+	}
+	return t
+}
@@ -0,0 +1,238 @@
+package transcript
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/stretchr/testify/require"
+)
+
+type testEnv struct {
+	sessions session.Service
+	messages message.Service
+	files    history.Service
+	svc      Service
+}
+
+func setupTest(t *testing.T, workingDir string) *testEnv {
+	t.Helper()
+
+	conn, err := db.Connect(t.Context(), t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	q := db.New(conn)
+	env := &testEnv{
+		sessions: session.NewService(q, conn),
+		messages: message.NewService(q),
+		files:    history.NewService(q, conn),
+	}
+	env.svc = NewService(q, conn, env.sessions, env.messages, env.files, workingDir)
+	return env
+}
+
+func TestExportImport(t *testing.T) {
+	ctx := t.Context()
+	src := setupTest(t, "/src/project")
+	dst := setupTest(t, "/dst/project")
+
+	sess, err := src.sessions.Create(ctx, "Refactor")
+	require.NoError(t, err)
+	sess.Todos = []session.Todo{{Content: "write tests", Status: session.TodoStatusInProgress}}
+	_, err = src.sessions.Save(ctx, sess)
+	require.NoError(t, err)
+
+	_, err = src.messages.Create(ctx, sess.ID, message.CreateMessageParams{
+		Role:  message.User,
+		Parts: []message.ContentPart{message.TextContent{Text: "rename foo"}},
+	})
+	require.NoError(t, err)
+	assistant, err := src.messages.Create(ctx, sess.ID, message.CreateMessageParams{
+		Role:     message.Assistant,
+		Model:    "gpt",
+		Provider: "openai",
+		Parts: []message.ContentPart{
+			message.ReasoningContent{Thinking: "look at main.go"},
+			message.ToolCall{ID: "call-1", Name: "agent", Input: `{"prompt":"find foo"}`, Finished: true},
+			message.Finish{Reason: message.FinishReasonToolUse, Time: 42},
+		},
+	})
+	require.NoError(t, err)
+	_, err = src.messages.Create(ctx, sess.ID, message.CreateMessageParams{
+		Role:  message.Tool,
+		Parts: []message.ContentPart{message.ToolResult{ToolCallID: "call-1", Name: "agent", Content: "found"}},
+	})
+	require.NoError(t, err)
+
+	child, err := src.sessions.CreateTaskSession(ctx, src.sessions.CreateAgentToolSessionID(assistant.ID, "call-1"), sess.ID, "Agent")
+	require.NoError(t, err)
+	_, err = src.messages.Create(ctx, child.ID, message.CreateMessageParams{
+		Role:  message.User,
+		Parts: []message.ContentPart{message.TextContent{Text: "find foo"}},
+	})
+	require.NoError(t, err)
+
+	_, err = src.files.Create(ctx, sess.ID, "/src/project/main.go", "foo")
+	require.NoError(t, err)
+	_, err = src.files.CreateVersion(ctx, sess.ID, "/src/project/main.go", "bar")
+	require.NoError(t, err)
+
+	exported, err := src.svc.Export(ctx, sess.ID)
+	require.NoError(t, err)
+	require.Len(t, exported.Session.Messages, 3)
+	require.Len(t, exported.Session.Files, 2)
+	require.Len(t, exported.Session.Children, 1)
+
+	data, err := json.Marshal(exported)
+	require.NoError(t, err)
+	parsed, err := Parse(data)
+	require.NoError(t, err)
+	require.Equal(t, exported.Session.Messages[1].Parts, parsed.Session.Messages[1].Parts)
+
+	imported, err := dst.svc.Import(ctx, parsed)
+	require.NoError(t, err)
+	require.NotEqual(t, sess.ID, imported.ID)
+	require.Equal(t, "Refactor", imported.Title)
+	require.Equal(t, int64(3), imported.MessageCount)
+	require.Equal(t, sess.Todos, imported.Todos)
+
+	msgs, err := dst.messages.List(ctx, imported.ID)
+	require.NoError(t, err)
+	require.Len(t, msgs, 3)
+	require.NotEqual(t, assistant.ID, msgs[1].ID)
+	require.Equal(t, "gpt", msgs[1].Model)
+	require.Len(t, msgs[1].ToolCalls(), 1)
+	require.Equal(t, "found", msgs[2].ToolResults()[0].Content)
+
+	children, err := dst.sessions.ListChildren(ctx, imported.ID)
+	require.NoError(t, err)
+	require.Len(t, children, 1)
+	messageID, toolCallID, ok := dst.sessions.ParseAgentToolSessionID(children[0].ID)
+	require.True(t, ok)
+	require.Equal(t, msgs[1].ID, messageID)
+	require.Equal(t, "call-1", toolCallID)
+
+	files, err := dst.files.ListBySession(ctx, imported.ID)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	require.Equal(t, "/dst/project/main.go", files[1].Path)
+	require.Equal(t, "bar", files[1].Content)
+	require.Equal(t, int64(1), files[1].Version)
+
+	// Importing the same transcript twice must not collide.
+	_, err = dst.svc.Import(ctx, parsed)
+	require.NoError(t, err)
+}
+
+func TestParseRejectsInvalid(t *testing.T) {
+	_, err := Parse([]byte(`{"version":99,"session":{"id":"a"}}`))
+	require.ErrorContains(t, err, "unsupported transcript version")
+
+	_, err = Parse([]byte(`{"version":1,"session":{"id":"a","messages":[{"id":"m","role":"robot","parts":[]}]}}`))
+	require.ErrorContains(t, err, "unknown role")
+
+	_, err = Parse([]byte(`{"version":1,"session":{"id":"a","messages":[{"id":"m","role":"user","parts":[{"type":"video","data":{}}]}]}}`))
+	require.Error(t, err)
+}
+
+func TestImportRejectsPathsOutsideDirectory(t *testing.T) {
+	dst := setupTest(t, "/dst/project")
+
+	for _, tt := range []struct {
+		name string
+		dir  string
+		path string
+		err  string
+	}{
+		{"absolute outside", "/src/project", "/etc/passwd", "outside the exported directory"},
+		{"relative", "/src/project", "../x", "not absolute"},
+		{"relative inside", "/src/project", "main.go", "not absolute"},
+		{"escaping", "/src/project", "/src/project/../../etc/cron.d/x", "outside the exported directory"},
+		{"sibling prefix", "/src/project", "/src/project-old/main.go", "outside the exported directory"},
+		{"no directory", "", "/src/project/main.go", "outside the exported directory"},
+		{"relative directory", "src", "/src/main.go", "not absolute"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := Transcript{
+				Version:   Version,
+				Directory: tt.dir,
+				Session: Session{
+					ID: "root",
+					Children: []Session{{
+						ID:    "child",
+						Files: []File{{Path: tt.path, Content: "x"}},
+					}},
+				},
+			}
+			data, err := json.Marshal(tr)
+			require.NoError(t, err)
+			_, err = Parse(data)
+			require.ErrorContains(t, err, tt.err)
+
+			_, err = dst.svc.Import(t.Context(), &tr)
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+
+	sessions, err := dst.sessions.List(t.Context())
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+}
+
+func TestFilePaths(t *testing.T) {
+	tr := Transcript{
+		Version:   Version,
+		Directory: "/src/project",
+		Session: Session{
+			ID:       "root",
+			Files:    []File{{Path: "/src/project/main.go"}},
+			Children: []Session{{ID: "child", Files: []File{{Path: "/src/project/pkg/a.go"}}}},
+		},
+	}
+	require.Equal(t, []string{"/dst/project/main.go", "/dst/project/pkg/a.go"}, tr.FilePaths("/dst/project"))
+}
+
+func TestMarkdown(t *testing.T) {
+	tr := &Transcript{
+		Version:   Version,
+		Directory: "/src/project",
+		Session: Session{
+			ID:    "s1",
+			Title: "Refactor",
+			Todos: []session.Todo{{Content: "write tests", Status: session.TodoStatusCompleted}},
+			Messages: []Message{
+				{ID: "m1", Role: message.User, Parts: Parts{message.TextContent{Text: "rename foo"}}},
+				{ID: "m2", Role: message.Assistant, Model: "gpt", Parts: Parts{
+					message.ToolCall{ID: "call-1", Name: "bash", Input: `{"command":"ls"}`},
+				}},
+				{ID: "m3", Role: message.Tool, Parts: Parts{
+					message.ToolResult{ToolCallID: "call-1", Name: "bash", Content: "a\n```\nb", IsError: true},
+				}},
+			},
+			Files: []File{
+				{Path: "/src/project/main.go", Content: "foo\n", Version: 0},
+				{Path: "/src/project/main.go", Content: "bar\n", Version: 1},
+			},
+			Children: []Session{
+				{ID: "title-s1", Title: "Generate a title"},
+				{ID: "m2$$call-1", Title: "Agent"},
+			},
+		},
+	}
+
+	md := tr.Markdown()
+	require.Contains(t, md, "# Refactor\n")
+	require.Contains(t, md, "- [x] write tests")
+	require.Contains(t, md, "rename foo")
+	require.Contains(t, md, "**Tool call** `bash` (`call-1`)")
+	require.Contains(t, md, "**Tool result** `bash` (`call-1`) (error)")
+	require.Contains(t, md, "````\na\n```\nb\n````")
+	require.Contains(t, md, "### `main.go`")
+	require.Contains(t, md, "+bar")
+	require.Contains(t, md, "### Agent")
+	require.NotContains(t, md, "Generate a title")
+}
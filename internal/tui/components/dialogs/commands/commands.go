@@ -79,6 +79,7 @@ type (
 	OpenReasoningDialogMsg struct{}
 	OpenExternalEditorMsg  struct{}
 	ToggleYoloModeMsg      struct{}
+	OpenJobsDialogMsg      struct{}
 	CompactMsg             struct {
 		SessionID string
 	}
@@ -337,6 +338,14 @@ func (c *commandDialogCmp) defaultCommands() []Command {
 				return util.CmdHandler(SwitchModelMsg{})
 			},
 		},
+		{
+			ID:          "background_jobs",
+			Title:       "Background Jobs",
+			Description: "Show the background jobs started by the agent",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(OpenJobsDialogMsg{})
+			},
+		},
 	}
 
 	// Only show compact command if there's an active session
@@ -0,0 +1,292 @@
+package jobs
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/crush/internal/home"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/charmbracelet/crush/internal/shell"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	JobsDialogID dialogs.DialogID = "jobs"
+
+	// refreshInterval is how often the output of the selected job is
+	// refreshed while the dialog is open.
+	refreshInterval = time.Second
+	// outputLines is the number of output lines shown for the selected job.
+	outputLines = 10
+	// maxJobsShown is the number of jobs shown before the list scrolls.
+	maxJobsShown = 8
+)
+
+// JobsDialog lists the background jobs started by the agent.
+type JobsDialog interface {
+	dialogs.DialogModel
+}
+
+// refreshMsg triggers a refresh of the jobs dialog.
+type refreshMsg struct{}
+
+type jobsDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+
+	workingDir string
+	jobs       []shell.BackgroundShellInfo
+	selected   int
+	output     string
+
+	keyMap KeyMap
+	help   help.Model
+}
+
+// NewJobsDialogCmp creates a dialog showing the background jobs running in
+// workingDir.
+func NewJobsDialogCmp(workingDir string) JobsDialog {
+	t := styles.CurrentTheme()
+	help := help.New()
+	help.Styles = t.S().Help
+	return &jobsDialogCmp{
+		workingDir: workingDir,
+		keyMap:     DefaultKeyMap(),
+		help:       help,
+	}
+}
+
+func (j *jobsDialogCmp) Init() tea.Cmd {
+	j.refresh()
+	return tickRefresh()
+}
+
+func (j *jobsDialogCmp) Update(msg tea.Msg) (util.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		j.wWidth = msg.Width
+		j.wHeight = msg.Height
+		j.width = min(120, j.wWidth-8)
+	case refreshMsg:
+		j.refresh()
+		return j, tickRefresh()
+	case pubsub.Event[shell.JobEvent]:
+		j.refresh()
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, j.keyMap.Next):
+			if len(j.jobs) > 0 {
+				j.selected = (j.selected + 1) % len(j.jobs)
+				j.refreshOutput()
+			}
+		case key.Matches(msg, j.keyMap.Previous):
+			if len(j.jobs) > 0 {
+				j.selected = (j.selected - 1 + len(j.jobs)) % len(j.jobs)
+				j.refreshOutput()
+			}
+		case key.Matches(msg, j.keyMap.Kill):
+			if job, ok := j.selectedJob(); ok {
+				return j, killJob(job)
+			}
+		case key.Matches(msg, j.keyMap.Close):
+			return j, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+	}
+	return j, nil
+}
+
+// refresh reloads the jobs, keeping the selected job selected.
+func (j *jobsDialogCmp) refresh() {
+	var selectedID string
+	if job, ok := j.selectedJob(); ok {
+		selectedID = job.ID
+	}
+
+	j.jobs = j.jobs[:0]
+	for _, job := range shell.GetBackgroundShellManager().Jobs() {
+		if isWithin(j.workingDir, job.WorkingDir) {
+			j.jobs = append(j.jobs, job)
+		}
+	}
+
+	j.selected = min(j.selected, max(len(j.jobs)-1, 0))
+	for i, job := range j.jobs {
+		if job.ID == selectedID {
+			j.selected = i
+			break
+		}
+	}
+	j.refreshOutput()
+}
+
+// refreshOutput loads the last lines of output of the selected job.
+func (j *jobsDialogCmp) refreshOutput() {
+	j.output = ""
+	job, ok := j.selectedJob()
+	if !ok {
+		return
+	}
+	bgShell, ok := shell.GetBackgroundShellManager().Get(job.ID)
+	if !ok {
+		return
+	}
+	stdout, stderr, _, _ := bgShell.GetOutput()
+	output := strings.TrimRight(ansi.Strip(stdout+stderr), "\n")
+	lines := strings.Split(output, "\n")
+	j.output = strings.Join(lines[max(len(lines)-outputLines, 0):], "\n")
+}
+
+func (j *jobsDialogCmp) selectedJob() (shell.BackgroundShellInfo, bool) {
+	if j.selected < 0 || j.selected >= len(j.jobs) {
+		return shell.BackgroundShellInfo{}, false
+	}
+	return j.jobs[j.selected], true
+}
+
+func (j *jobsDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	contentWidth := j.width - 4
+
+	parts := []string{
+		t.S().Base.Padding(0, 1, 1, 1).Render(core.Title("Background Jobs", j.width-4)),
+	}
+	if len(j.jobs) == 0 {
+		parts = append(parts, t.S().Base.PaddingLeft(1).Render(t.S().Subtle.Render("No background jobs")))
+	} else {
+		parts = append(parts, t.S().Base.PaddingLeft(1).Render(j.renderList(contentWidth)))
+		parts = append(parts, "", t.S().Base.PaddingLeft(1).Render(j.renderDetails(contentWidth)))
+	}
+	parts = append(parts,
+		"",
+		t.S().Base.Width(j.width-2).PaddingLeft(1).AlignHorizontal(lipgloss.Left).Render(j.help.View(j.keyMap)),
+	)
+
+	return j.style().Render(lipgloss.JoinVertical(lipgloss.Left, parts...))
+}
+
+func (j *jobsDialogCmp) renderList(width int) string {
+	t := styles.CurrentTheme()
+
+	start := max(0, min(j.selected-maxJobsShown/2, len(j.jobs)-maxJobsShown))
+	end := min(len(j.jobs), start+maxJobsShown)
+
+	rows := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		job := j.jobs[i]
+		icon := t.ItemBusyIcon
+		status := "running " + formatDuration(time.Since(job.StartedAt))
+		switch {
+		case job.Done && job.ExitCode == 0:
+			icon = t.ItemOnlineIcon
+			status = "done"
+		case job.Done:
+			icon = t.ItemErrorIcon
+			status = fmt.Sprintf("exit %d", job.ExitCode)
+		}
+
+		title := job.Description
+		if title == "" {
+			title = job.Command
+		}
+		title = strings.Join(strings.Fields(title), " ")
+		statusWidth := lipgloss.Width(status) + 1
+		prefix := fmt.Sprintf("%s %s ", icon.String(), job.ID)
+		title = ansi.Truncate(title, width-lipgloss.Width(prefix)-statusWidth-1, "…")
+		gap := max(1, width-lipgloss.Width(prefix)-lipgloss.Width(title)-statusWidth)
+
+		row := prefix + title + strings.Repeat(" ", gap) + t.S().Subtle.Render(status)
+		if i == j.selected {
+			row = t.S().TextSelected.Render(ansi.Strip(row))
+		}
+		rows = append(rows, row)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+func (j *jobsDialogCmp) renderDetails(width int) string {
+	t := styles.CurrentTheme()
+	job, _ := j.selectedJob()
+
+	label := t.S().Subtle.Width(11)
+	value := t.S().Text.Width(width - 11)
+	lines := []string{
+		label.Render("Command") + value.Render(ansi.Truncate(strings.Join(strings.Fields(job.Command), " "), width-11, "…")),
+		label.Render("Directory") + value.Render(ansi.Truncate(home.Short(job.WorkingDir), width-11, "…")),
+		label.Render("Started") + value.Render(job.StartedAt.Format(time.DateTime)),
+		"",
+		core.Section("Output", width),
+	}
+
+	if j.output == "" {
+		lines = append(lines, t.S().Subtle.Render("No output"))
+	} else {
+		for line := range strings.SplitSeq(j.output, "\n") {
+			lines = append(lines, t.S().Muted.Render(ansi.Truncate(line, width, "…")))
+		}
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func (j *jobsDialogCmp) style() lipgloss.Style {
+	t := styles.CurrentTheme()
+	return t.S().Base.
+		Width(j.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus)
+}
+
+func (j *jobsDialogCmp) Position() (int, int) {
+	row := j.wHeight/4 - 2 // just a bit above the center
+	col := j.wWidth / 2
+	col -= j.width / 2
+	return row, col
+}
+
+// ID implements JobsDialog.
+func (j *jobsDialogCmp) ID() dialogs.DialogID {
+	return JobsDialogID
+}
+
+func tickRefresh() tea.Cmd {
+	return tea.Tick(refreshInterval, func(time.Time) tea.Msg {
+		return refreshMsg{}
+	})
+}
+
+// killJob kills the job in the background, as it waits for the job to exit.
+func killJob(job shell.BackgroundShellInfo) tea.Cmd {
+	return func() tea.Msg {
+		if err := shell.GetBackgroundShellManager().Kill(job.ID); err != nil {
+			return util.ReportError(err)()
+		}
+		return util.ReportInfo(fmt.Sprintf("Killed background job %s", job.ID))()
+	}
+}
+
+// formatDuration formats d in the largest whole unit.
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+}
+
+// isWithin reports whether path is dir or one of its subdirectories.
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -3031,7 +3031,7 @@ func (m *UI) drawSessionDetails(scr uv.Screen, area uv.Rectangle) {
 
 func (m *UI) runMCPPrompt(clientID, promptID string, arguments map[string]string) tea.Cmd {
 	load := func() tea.Msg {
-		prompt, err := commands.GetMCPPrompt(clientID, promptID, arguments)
+		prompt, err := commands.GetMCPPrompt(context.Background(), clientID, promptID, arguments)
 		if err != nil {
 			// TODO: make this better
 			return uiutil.ReportError(err)()
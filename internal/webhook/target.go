@@ -0,0 +1,375 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/version"
+)
+
+// Headers set on each delivery.
+const (
+	HeaderEvent     = "X-ZorkAgent-Event"
+	HeaderDelivery  = "X-ZorkAgent-Delivery"
+	HeaderTimestamp = "X-ZorkAgent-Timestamp"
+	HeaderSignature = "X-ZorkAgent-Signature"
+)
+
+const (
+	defaultMaxAttempts = 5
+	defaultTimeout     = 10 * time.Second
+	// queueSize is the number of deliveries waiting for an endpoint above
+	// which new events are dropped.
+	queueSize = 256
+	// recentDeliveries is the number of deliveries kept for the status of
+	// each endpoint.
+	recentDeliveries = 50
+	retryBaseDelay   = 2 * time.Second
+	maxRetryDelay    = time.Minute
+)
+
+// DeliveryStatus is the state of a delivery.
+type DeliveryStatus string
+
+const (
+	// DeliveryPending is queued or waiting for a retry.
+	DeliveryPending DeliveryStatus = "pending"
+	// DeliveryDelivered was accepted with a 2xx response.
+	DeliveryDelivered DeliveryStatus = "delivered"
+	// DeliveryFailed was rejected, or every attempt failed.
+	DeliveryFailed DeliveryStatus = "failed"
+	// DeliveryDropped was discarded because the queue was full.
+	DeliveryDropped DeliveryStatus = "dropped"
+)
+
+// Delivery is the delivery of an event to an endpoint.
+type Delivery struct {
+	// ID is the ID of the event.
+	ID         string         `json:"id"`
+	Event      string         `json:"event"`
+	SessionID  string         `json:"session_id,omitempty"`
+	Status     DeliveryStatus `json:"status"`
+	Attempts   int            `json:"attempts"`
+	StatusCode int            `json:"status_code,omitempty"`
+	// Error is the error of the last attempt.
+	Error         string    `json:"error,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	NextAttemptAt time.Time `json:"next_attempt_at,omitzero"`
+	FinishedAt    time.Time `json:"finished_at,omitzero"`
+}
+
+// TargetStatus is the state of an endpoint.
+type TargetStatus struct {
+	Name string `json:"name"`
+	// URL is the URL of the endpoint without credentials and query.
+	URL           string   `json:"url"`
+	Events        []string `json:"events,omitempty"`
+	CostThreshold float64  `json:"cost_threshold,omitempty"`
+	Signed        bool     `json:"signed"`
+	Disabled      bool     `json:"disabled,omitempty"`
+	// Error is set when the endpoint is misconfigured and not started.
+	Error string `json:"error,omitempty"`
+
+	Queued          int       `json:"queued"`
+	Delivered       int64     `json:"delivered"`
+	Failed          int64     `json:"failed"`
+	Dropped         int64     `json:"dropped"`
+	LastDeliveredAt time.Time `json:"last_delivered_at,omitzero"`
+	LastFailedAt    time.Time `json:"last_failed_at,omitzero"`
+	LastError       string    `json:"last_error,omitempty"`
+	// Deliveries are the most recent deliveries, newest first.
+	Deliveries []Delivery `json:"deliveries"`
+}
+
+type queued struct {
+	delivery *Delivery
+	body     []byte
+}
+
+type target struct {
+	name      string
+	cfg       config.WebhookConfig
+	url       string
+	shownURL  string
+	secret    string
+	headers   map[string]string
+	client    *http.Client
+	baseDelay time.Duration
+	// err is the configuration error preventing the endpoint from starting.
+	err string
+	// queue is nil when the endpoint is disabled or misconfigured.
+	queue chan queued
+
+	mu         sync.Mutex
+	stats      TargetStatus
+	deliveries []*Delivery
+	// exceeded holds the sessions already reported over the cost
+	// threshold.
+	exceeded map[string]struct{}
+}
+
+func newTarget(name string, cfg config.WebhookConfig, baseDelay time.Duration) *target {
+	t := &target{
+		name:      name,
+		cfg:       cfg,
+		url:       cfg.URL,
+		baseDelay: baseDelay,
+		exceeded:  make(map[string]struct{}),
+	}
+	t.shownURL, t.err = t.configure()
+	if t.err == "" && !cfg.Disabled {
+		t.queue = make(chan queued, queueSize)
+	}
+	return t
+}
+
+// configure validates the config and resolves the secret and headers. It
+// returns the URL to show and the configuration error.
+func (t *target) configure() (string, string) {
+	u, err := url.Parse(t.cfg.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", "url must be an absolute http or https URL"
+	}
+	shown := displayURL(u)
+	for _, p := range t.cfg.Events {
+		if !validPattern(p) {
+			return shown, fmt.Sprintf("unknown event %q", p)
+		}
+	}
+	if t.cfg.CostThreshold < 0 {
+		return shown, "cost_threshold must not be negative"
+	}
+	if t.secret, err = t.cfg.ResolvedSecret(); err != nil {
+		return shown, err.Error()
+	}
+	if t.headers, err = t.cfg.ResolvedHeaders(); err != nil {
+		return shown, err.Error()
+	}
+
+	timeout := defaultTimeout
+	if t.cfg.Timeout > 0 {
+		timeout = time.Duration(t.cfg.Timeout) * time.Second
+	}
+	t.client = &http.Client{Timeout: timeout}
+	return shown, ""
+}
+
+func (t *target) active() bool {
+	return t.queue != nil
+}
+
+func (t *target) subscribed(eventType string) bool {
+	return matchEvent(t.cfg.Events, eventType)
+}
+
+func (t *target) maxAttempts() int {
+	if t.cfg.MaxAttempts > 0 {
+		return t.cfg.MaxAttempts
+	}
+	return defaultMaxAttempts
+}
+
+// exceeds reports whether session reached the cost threshold for the first
+// time.
+func (t *target) exceeds(session SessionData) bool {
+	if t.cfg.CostThreshold <= 0 || session.Cost < t.cfg.CostThreshold {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.exceeded[session.ID]; ok {
+		return false
+	}
+	t.exceeded[session.ID] = struct{}{}
+	return true
+}
+
+// enqueue queues the delivery of ev, or records it as dropped when the
+// queue is full.
+func (t *target) enqueue(ev Event, body []byte) {
+	d := &Delivery{
+		ID:        ev.ID,
+		Event:     ev.Type,
+		SessionID: ev.SessionID,
+		Status:    DeliveryPending,
+		CreatedAt: time.Now(),
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.deliveries = append(t.deliveries, d)
+	if len(t.deliveries) > recentDeliveries {
+		t.deliveries = slices.Delete(t.deliveries, 0, len(t.deliveries)-recentDeliveries)
+	}
+	select {
+	case t.queue <- queued{delivery: d, body: body}:
+	default:
+		d.Status = DeliveryDropped
+		d.Error = "queue full"
+		d.FinishedAt = d.CreatedAt
+		t.stats.Dropped++
+		slog.Warn("Webhook queue full, dropping event", "name", t.name, "event", ev.Type)
+	}
+}
+
+func (t *target) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case q := <-t.queue:
+			t.deliver(ctx, q)
+		}
+	}
+}
+
+// deliver posts the event until it is accepted, rejected or out of
+// attempts. Network errors, 408, 429 and 5xx responses are retried with
+// exponential backoff, honouring Retry-After.
+func (t *target) deliver(ctx context.Context, q queued) {
+	d := q.delivery
+	for attempt := 1; ; attempt++ {
+		statusCode, retryAfter, err := t.post(ctx, d, q.body)
+		if ctx.Err() != nil {
+			return
+		}
+
+		t.mu.Lock()
+		d.Attempts = attempt
+		d.StatusCode = statusCode
+		if err == nil {
+			now := time.Now()
+			d.Status = DeliveryDelivered
+			d.Error = ""
+			d.NextAttemptAt = time.Time{}
+			d.FinishedAt = now
+			t.stats.Delivered++
+			t.stats.LastDeliveredAt = now
+			t.mu.Unlock()
+			return
+		}
+		d.Error = err.Error()
+		if !retryable(statusCode) || attempt >= t.maxAttempts() {
+			now := time.Now()
+			d.Status = DeliveryFailed
+			d.NextAttemptAt = time.Time{}
+			d.FinishedAt = now
+			t.stats.Failed++
+			t.stats.LastFailedAt = now
+			t.stats.LastError = d.Error
+			t.mu.Unlock()
+			slog.Warn("Webhook delivery failed", "name", t.name, "event", d.Event, "attempts", attempt, "error", err)
+			return
+		}
+		delay := t.retryDelay(attempt, retryAfter)
+		d.NextAttemptAt = time.Now().Add(delay)
+		t.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// post sends one attempt. It returns the response status code, 0 when no
+// response was received, and the Retry-After delay if any.
+func (t *target) post(ctx context.Context, d *Delivery, body []byte) (int, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return 0, 0, err
+	}
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "zorkagent-webhook/"+version.Version)
+	req.Header.Set(HeaderEvent, d.Event)
+	req.Header.Set(HeaderDelivery, d.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	if t.secret != "" {
+		req.Header.Set(HeaderSignature, Sign(t.secret, timestamp, body))
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, 0, nil
+	}
+	var retryAfter time.Duration
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+		retryAfter = time.Duration(secs) * time.Second
+	}
+	return resp.StatusCode, retryAfter, fmt.Errorf("unexpected status %s", resp.Status)
+}
+
+// retryDelay returns the delay before the attempt following attempt.
+func (t *target) retryDelay(attempt int, retryAfter time.Duration) time.Duration {
+	delay := t.baseDelay << (attempt - 1)
+	delay = max(delay, retryAfter)
+	if delay <= 0 || delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
+
+// retryable reports whether a delivery that got statusCode should be
+// retried. A zero status code means no response was received.
+func retryable(statusCode int) bool {
+	return statusCode == 0 ||
+		statusCode == http.StatusRequestTimeout ||
+		statusCode == http.StatusTooManyRequests ||
+		statusCode >= 500
+}
+
+func (t *target) status() TargetStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	status := t.stats
+	status.Name = t.name
+	status.URL = t.shownURL
+	status.Events = t.cfg.Events
+	status.CostThreshold = t.cfg.CostThreshold
+	status.Signed = t.secret != ""
+	status.Disabled = t.cfg.Disabled
+	status.Error = t.err
+	status.Queued = len(t.queue)
+	status.Deliveries = make([]Delivery, 0, len(t.deliveries))
+	for _, d := range slices.Backward(t.deliveries) {
+		status.Deliveries = append(status.Deliveries, *d)
+	}
+	return status
+}
+
+// Sign returns the signature of a delivery as sent in the
+// X-ZorkAgent-Signature header: "sha256=" followed by the hex encoded
+// HMAC-SHA256 of the timestamp, a dot and the body, keyed with secret.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,261 @@
+// Package webhook delivers session, message, permission and MCP/LSP error
+// events to the HTTP endpoints configured in the webhooks section of the
+// config.
+//
+// Each endpoint has its own queue and worker, so a slow or failing endpoint
+// never delays the others. Deliveries are signed with HMAC-SHA256 when a
+// secret is configured and retried with exponential backoff.
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/google/uuid"
+)
+
+// Event types.
+const (
+	EventSessionCreated      = "session.created"
+	EventSessionUpdated      = "session.updated"
+	EventSessionDeleted      = "session.deleted"
+	EventSessionCostExceeded = "session.cost_exceeded"
+	EventMessageFinished     = "message.finished"
+	EventMessageFailed       = "message.failed"
+	EventPermissionRequested = "permission.requested"
+	EventMCPError            = "mcp.error"
+	EventLSPError            = "lsp.error"
+)
+
+// EventTypes lists all event types.
+var EventTypes = []string{
+	EventSessionCreated,
+	EventSessionUpdated,
+	EventSessionDeleted,
+	EventSessionCostExceeded,
+	EventMessageFinished,
+	EventMessageFailed,
+	EventPermissionRequested,
+	EventMCPError,
+	EventLSPError,
+}
+
+// Event is the JSON body posted to webhook endpoints.
+type Event struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Time      time.Time `json:"time"`
+	Project   string    `json:"project"`
+	SessionID string    `json:"session_id,omitempty"`
+	// Data is one of the *Data types below, depending on Type.
+	Data any `json:"data"`
+}
+
+// SessionData is the data of session events.
+type SessionData struct {
+	ID               string    `json:"id"`
+	ParentSessionID  string    `json:"parent_session_id,omitempty"`
+	Title            string    `json:"title"`
+	MessageCount     int64     `json:"message_count"`
+	PromptTokens     int64     `json:"prompt_tokens"`
+	CompletionTokens int64     `json:"completion_tokens"`
+	Cost             float64   `json:"cost"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// CostExceededData is the data of session.cost_exceeded events.
+type CostExceededData struct {
+	SessionData
+	// Threshold is the cost threshold of the endpoint.
+	Threshold float64 `json:"threshold"`
+}
+
+// MessageData is the data of message.finished and message.failed events.
+type MessageData struct {
+	ID        string `json:"id"`
+	SessionID string `json:"session_id"`
+	Model     string `json:"model,omitempty"`
+	Provider  string `json:"provider,omitempty"`
+	// Reason is the finish reason, such as end_turn, canceled or error.
+	Reason string `json:"reason"`
+	Error  string `json:"error,omitempty"`
+	// Text is the text of the message, truncated if it is too large.
+	Text string `json:"text,omitempty"`
+}
+
+// PermissionData is the data of permission.requested events.
+type PermissionData struct {
+	ID          string `json:"id"`
+	SessionID   string `json:"session_id"`
+	ToolCallID  string `json:"tool_call_id"`
+	ToolName    string `json:"tool_name"`
+	Action      string `json:"action"`
+	Path        string `json:"path"`
+	Description string `json:"description,omitempty"`
+	Params      any    `json:"params,omitempty"`
+}
+
+// ServerErrorData is the data of mcp.error and lsp.error events.
+type ServerErrorData struct {
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+// Service sends events to the configured webhook endpoints.
+type Service interface {
+	// Send queues ev for delivery to the endpoints subscribed to its type.
+	// ID, Time and Project are filled in when empty. Send never blocks;
+	// events are dropped when an endpoint's queue is full.
+	Send(ev Event)
+	// CheckCost sends a session.cost_exceeded event to each endpoint whose
+	// cost threshold the session reached, once per session.
+	CheckCost(session SessionData)
+	// Status returns the state and recent deliveries of each endpoint,
+	// sorted by name.
+	Status() []TargetStatus
+	// Close stops the workers. Queued deliveries are abandoned.
+	Close() error
+}
+
+type service struct {
+	project string
+	targets []*target
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewService creates a service delivering the events of project to
+// webhooks and starts a worker for each enabled endpoint.
+func NewService(project string, webhooks config.Webhooks) Service {
+	return newService(project, webhooks, retryBaseDelay)
+}
+
+func newService(project string, webhooks config.Webhooks, baseDelay time.Duration) *service {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &service{project: project, ctx: ctx, cancel: cancel}
+	for _, w := range webhooks.Sorted() {
+		t := newTarget(w.Name, w.Webhook, baseDelay)
+		s.targets = append(s.targets, t)
+		if t.err != "" {
+			slog.Warn("Webhook is not started", "name", w.Name, "error", t.err)
+			continue
+		}
+		if t.queue != nil {
+			s.wg.Go(func() { t.run(ctx) })
+		}
+	}
+	return s
+}
+
+func (s *service) Send(ev Event) {
+	if s.ctx.Err() != nil {
+		return
+	}
+	var body []byte
+	for _, t := range s.targets {
+		if !t.active() || !t.subscribed(ev.Type) {
+			continue
+		}
+		if body == nil {
+			ev = s.fill(ev)
+			var err error
+			if body, err = json.Marshal(ev); err != nil {
+				slog.Error("Failed to encode webhook event", "type", ev.Type, "error", err)
+				return
+			}
+		}
+		t.enqueue(ev, body)
+	}
+}
+
+func (s *service) CheckCost(session SessionData) {
+	if s.ctx.Err() != nil {
+		return
+	}
+	for _, t := range s.targets {
+		if !t.active() || !t.subscribed(EventSessionCostExceeded) || !t.exceeds(session) {
+			continue
+		}
+		ev := s.fill(Event{
+			Type:      EventSessionCostExceeded,
+			SessionID: session.ID,
+			Data:      CostExceededData{SessionData: session, Threshold: t.cfg.CostThreshold},
+		})
+		body, err := json.Marshal(ev)
+		if err != nil {
+			slog.Error("Failed to encode webhook event", "type", ev.Type, "error", err)
+			return
+		}
+		t.enqueue(ev, body)
+	}
+}
+
+func (s *service) Status() []TargetStatus {
+	status := make([]TargetStatus, len(s.targets))
+	for i, t := range s.targets {
+		status[i] = t.status()
+	}
+	return status
+}
+
+func (s *service) Close() error {
+	s.cancel()
+	s.wg.Wait()
+	return nil
+}
+
+func (s *service) fill(ev Event) Event {
+	if ev.ID == "" {
+		ev.ID = uuid.New().String()
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	if ev.Project == "" {
+		ev.Project = s.project
+	}
+	return ev
+}
+
+// matchEvent reports whether eventType matches one of patterns. Empty
+// patterns match all events.
+func matchEvent(patterns []string, eventType string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if p == "*" || p == eventType {
+			return true
+		}
+		if group, ok := strings.CutSuffix(p, ".*"); ok && strings.HasPrefix(eventType, group+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// validPattern reports whether p matches at least one event type.
+func validPattern(p string) bool {
+	for _, t := range EventTypes {
+		if matchEvent([]string{p}, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// displayURL returns u without credentials and query, which often carry
+// tokens.
+func displayURL(u *url.URL) string {
+	shown := url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}
+	return shown.String()
+}
@@ -0,0 +1,236 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type receiver struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *receiver) record(req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+}
+
+func (r *receiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+func startService(t *testing.T, webhooks config.Webhooks) *service {
+	t.Helper()
+
+	s := newService("/project", webhooks, time.Millisecond)
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func waitFor(t *testing.T, s *service, name string, cond func(TargetStatus) bool) TargetStatus {
+	t.Helper()
+
+	var status TargetStatus
+	require.Eventually(t, func() bool {
+		for _, st := range s.Status() {
+			if st.Name == name {
+				status = st
+				return cond(st)
+			}
+		}
+		return false
+	}, 5*time.Second, 5*time.Millisecond)
+	return status
+}
+
+func TestDeliver(t *testing.T) {
+	t.Parallel()
+
+	var rcv receiver
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rcv.record(req)
+	}))
+	t.Cleanup(srv.Close)
+
+	s := startService(t, config.Webhooks{
+		"chatops": {
+			URL:     srv.URL + "/hook?token=abc",
+			Secret:  "s3cret",
+			Headers: map[string]string{"X-Team": "infra"},
+			Events:  []string{"message.*"},
+		},
+	})
+	s.Send(Event{Type: EventSessionCreated, SessionID: "s1", Data: SessionData{ID: "s1"}})
+	s.Send(Event{
+		Type:      EventMessageFinished,
+		SessionID: "s1",
+		Data:      MessageData{ID: "m1", SessionID: "s1", Reason: "end_turn", Text: "done"},
+	})
+
+	status := waitFor(t, s, "chatops", func(st TargetStatus) bool { return st.Delivered == 1 })
+	require.Equal(t, srv.URL+"/hook", status.URL)
+	require.True(t, status.Signed)
+	require.Len(t, status.Deliveries, 1)
+	require.Equal(t, DeliveryDelivered, status.Deliveries[0].Status)
+	require.Equal(t, 1, status.Deliveries[0].Attempts)
+	require.Equal(t, http.StatusOK, status.Deliveries[0].StatusCode)
+	require.Equal(t, 1, rcv.count())
+
+	req, body := rcv.requests[0], rcv.bodies[0]
+	require.Equal(t, EventMessageFinished, req.Header.Get(HeaderEvent))
+	require.Equal(t, "infra", req.Header.Get("X-Team"))
+	require.Equal(t, "abc", req.URL.Query().Get("token"))
+	timestamp, err := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+	require.NoError(t, err)
+	require.Equal(t, Sign("s3cret", timestamp, body), req.Header.Get(HeaderSignature))
+
+	var ev struct {
+		Event
+		Data MessageData `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(body, &ev))
+	require.Equal(t, req.Header.Get(HeaderDelivery), ev.ID)
+	require.Equal(t, "/project", ev.Project)
+	require.Equal(t, "s1", ev.SessionID)
+	require.Equal(t, "done", ev.Data.Text)
+}
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/flaky":
+			if calls.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		case "/down":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	s := startService(t, config.Webhooks{
+		"flaky":    {URL: srv.URL + "/flaky"},
+		"down":     {URL: srv.URL + "/down", MaxAttempts: 2},
+		"rejected": {URL: srv.URL + "/rejected"},
+	})
+	s.Send(Event{Type: EventMCPError, Data: ServerErrorData{Name: "github", Error: "exit status 1"}})
+
+	status := waitFor(t, s, "flaky", func(st TargetStatus) bool { return st.Delivered == 1 })
+	require.Equal(t, 3, status.Deliveries[0].Attempts)
+	require.Empty(t, status.Deliveries[0].Error)
+
+	status = waitFor(t, s, "down", func(st TargetStatus) bool { return st.Failed == 1 })
+	require.Equal(t, 2, status.Deliveries[0].Attempts)
+	require.Equal(t, DeliveryFailed, status.Deliveries[0].Status)
+	require.Equal(t, http.StatusBadGateway, status.Deliveries[0].StatusCode)
+	require.Contains(t, status.LastError, "502")
+
+	status = waitFor(t, s, "rejected", func(st TargetStatus) bool { return st.Failed == 1 })
+	require.Equal(t, 1, status.Deliveries[0].Attempts)
+}
+
+func TestCheckCost(t *testing.T) {
+	t.Parallel()
+
+	var rcv receiver
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rcv.record(req)
+	}))
+	t.Cleanup(srv.Close)
+
+	s := startService(t, config.Webhooks{
+		"budget":   {URL: srv.URL, CostThreshold: 1},
+		"nobudget": {URL: srv.URL, Events: []string{"session.cost_exceeded"}},
+	})
+	s.CheckCost(SessionData{ID: "s1", Cost: 0.5})
+	s.CheckCost(SessionData{ID: "s1", Cost: 1.5})
+	s.CheckCost(SessionData{ID: "s1", Cost: 2})
+	s.CheckCost(SessionData{ID: "s2", Cost: 1})
+
+	status := waitFor(t, s, "budget", func(st TargetStatus) bool { return st.Delivered == 2 })
+	require.Equal(t, "s2", status.Deliveries[0].SessionID)
+	require.Equal(t, "s1", status.Deliveries[1].SessionID)
+	require.Equal(t, EventSessionCostExceeded, status.Deliveries[1].Event)
+	require.Equal(t, 2, rcv.count())
+
+	var ev struct {
+		Data CostExceededData `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rcv.bodies[0], &ev))
+	require.Equal(t, 1.0, ev.Data.Threshold)
+	require.Equal(t, 1.5, ev.Data.Cost)
+}
+
+func TestInvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	s := startService(t, config.Webhooks{
+		"relative": {URL: "/hook"},
+		"unknown":  {URL: "https://example.com", Events: []string{"session.finished"}},
+		"off":      {URL: "https://example.com", Disabled: true},
+	})
+	s.Send(Event{Type: EventSessionCreated})
+
+	status := s.Status()
+	require.Len(t, status, 3)
+	require.Equal(t, "off", status[0].Name)
+	require.True(t, status[0].Disabled)
+	require.Empty(t, status[0].Error)
+	require.Contains(t, status[1].Error, "absolute")
+	require.Contains(t, status[2].Error, `unknown event "session.finished"`)
+	for _, st := range status {
+		require.Empty(t, st.Deliveries)
+	}
+}
+
+func TestMatchEvent(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		patterns []string
+		event    string
+		want     bool
+	}{
+		{nil, EventLSPError, true},
+		{[]string{"*"}, EventSessionCreated, true},
+		{[]string{"message.finished"}, EventMessageFinished, true},
+		{[]string{"message.finished"}, EventMessageFailed, false},
+		{[]string{"session.*"}, EventSessionCostExceeded, true},
+		{[]string{"session.*"}, EventPermissionRequested, false},
+		{[]string{"mcp.*", "lsp.error"}, EventLSPError, true},
+	} {
+		require.Equal(t, tt.want, matchEvent(tt.patterns, tt.event), "%v %s", tt.patterns, tt.event)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	t.Parallel()
+
+	tg := &target{baseDelay: retryBaseDelay}
+	require.Equal(t, 2*time.Second, tg.retryDelay(1, 0))
+	require.Equal(t, 8*time.Second, tg.retryDelay(3, 0))
+	require.Equal(t, 30*time.Second, tg.retryDelay(1, 30*time.Second))
+	require.Equal(t, maxRetryDelay, tg.retryDelay(10, 0))
+	require.Equal(t, maxRetryDelay, tg.retryDelay(100, 0))
+}